@@ -2,6 +2,7 @@ package bdd
 
 import (
 	"math/big"
+	"runtime"
 
 	"github.com/booleworks/logicng-go/errorx"
 	"github.com/booleworks/logicng-go/event"
@@ -13,14 +14,20 @@ import (
 
 // A BDD is a canonical representation of a Boolean formula. It contains a
 // pointer to the kernel which was used to generate the BDD and the node
-// index of the BDD within this kernel.
+// index of the BDD within this kernel.  A BDD holds a reference on its node
+// which can be released with Free.
 type BDD struct {
 	Kernel *Kernel
 	Index  int32
+	freed  bool
 }
 
 func newBdd(index int32, kernel *Kernel) *BDD {
-	return &BDD{kernel, index}
+	bdd := &BDD{Kernel: kernel, Index: index}
+	if kernel.automaticFree {
+		runtime.SetFinalizer(bdd, (*BDD).finalize)
+	}
+	return bdd
 }
 
 // Compile creates a BDD for a given formula.  The variable ordering in this
@@ -75,14 +82,21 @@ func CompileWithVarOrderAndHandler(
 }
 
 // CompileWithKernel creates a BDD for a given formula with a given kernel.
+// Panics if the node limit of the kernel is reached, use
+// CompileWithKernelAndHandler to get a cancellation instead.
 func CompileWithKernel(fac f.Factory, formula f.Formula, kernel *Kernel) *BDD {
-	bdd, _ := CompileWithKernelAndHandler(fac, formula, kernel, handler.NopHandler)
+	bdd, state := CompileWithKernelAndHandler(fac, formula, kernel, handler.NopHandler)
+	if !state.Success {
+		panic(errorx.IllegalState("node limit of %d nodes reached", kernel.maxnodesize))
+	}
 	return bdd
 }
 
 // CompileWithKernelAndHandler creates a BDD for a given formula with a given
 // kernel and bddHandler.  The handler can cancel the BDD creation based on the
-// number of nodes created during the BDD compilation process.
+// number of nodes created during the BDD compilation process.  If the node
+// limit of the kernel is reached, the compilation is canceled with the event
+// BddNodeLimitReached.  The kernel remains usable in both cases.
 func CompileWithKernelAndHandler(
 	fac f.Factory,
 	formula f.Formula,
@@ -100,50 +114,40 @@ func CompileWithKernelAndHandler(
 }
 
 // CompileLiterals creates a BDD for a conjunction of literals with a given
-// kernel.
+// kernel.  Panics if the node limit of the kernel is reached, use
+// CompileLiteralsWithHandler to get a cancellation instead.
 func CompileLiterals(literals []f.Literal, kernel *Kernel) *BDD {
-	var bdd int32
+	return kernel.must(CompileLiteralsWithHandler(literals, kernel, handler.NopHandler))
+}
+
+// CompileLiteralsWithHandler creates a BDD for a conjunction of literals with
+// a given kernel and handler.  If the node limit of the kernel is reached,
+// the compilation is canceled with the event BddNodeLimitReached.
+func CompileLiteralsWithHandler(literals []f.Literal, kernel *Kernel, hdl handler.Handler) (*BDD, handler.State) {
+	bdd, state := compileLiterals(literals, kernel, hdl)
+	if !state.Success {
+		return nil, state
+	}
+	return newBdd(bdd, kernel), succ
+}
+
+func compileLiterals(literals []f.Literal, kernel *Kernel, hdl handler.Handler) (int32, handler.State) {
 	if len(literals) == 0 {
-		bdd = bddFalse
-	} else if len(literals) == 1 {
-		lit := literals[0]
-		variable := lit.Variable()
-		idx := kernel.getOrAddVarIndex(variable)
-		if lit.IsPos() {
-			bdd = kernel.ithVar(idx)
-		} else {
-			bdd = kernel.nithVar(idx)
-		}
-	} else {
-		lit := literals[0]
-		variable := lit.Variable()
-		idx := kernel.getOrAddVarIndex(variable)
-		if lit.IsPos() {
-			bdd = kernel.ithVar(idx)
-		} else {
-			bdd = kernel.nithVar(idx)
-		}
-		for i := 1; i < len(literals); i++ {
-			lit = literals[i]
-			variable := lit.Variable()
-			idx = kernel.getOrAddVarIndex(variable)
-			var operand int32
-			if lit.IsPos() {
-				operand = kernel.ithVar(idx)
-			} else {
-				operand = kernel.nithVar(idx)
-			}
-			previous := bdd
-			var state handler.State
-			bdd, state = kernel.addRef(kernel.and(bdd, operand), handler.NopHandler)
-			if !state.Success {
-				panic(errorx.IllegalState("bdd generation was canceled by handler"))
-			}
-			kernel.delRef(previous)
-			kernel.delRef(operand)
+		return bddFalse, succ
+	}
+	bdd := kernel.literal(literals[0])
+	for i := 1; i < len(literals); i++ {
+		operand := kernel.literal(literals[i])
+		previous := bdd
+		var state handler.State
+		bdd, state = kernel.addRef(kernel.and(bdd, operand), hdl)
+		kernel.delRef(previous)
+		kernel.delRef(operand)
+		if !state.Success {
+			return -1, state
 		}
 	}
-	return newBdd(bdd, kernel)
+	return bdd, succ
 }
 
 func compile(fac f.Factory, formula f.Formula, kernel *Kernel, hdl handler.Handler) (int32, handler.State) {
@@ -176,6 +180,7 @@ func compile(fac f.Factory, formula f.Formula, kernel *Kernel, hdl handler.Handl
 		}
 		right, state := compile(fac, r, kernel, hdl)
 		if !state.Success {
+			kernel.delRef(left)
 			return 0, state
 		}
 		var res int32
@@ -196,6 +201,7 @@ func compile(fac f.Factory, formula f.Formula, kernel *Kernel, hdl handler.Handl
 		for i := 1; i < len(ops); i++ {
 			operand, state := compile(fac, ops[i], kernel, hdl)
 			if !state.Success {
+				kernel.delRef(res)
 				return 0, state
 			}
 			previous := res
@@ -227,6 +233,7 @@ func compile(fac f.Factory, formula f.Formula, kernel *Kernel, hdl handler.Handl
 // generated formula can be more compact using the true paths or false paths,
 // respectively.
 func (b *BDD) ToFormula(fac f.Factory, followPathsToTrue ...bool) f.Formula {
+	b.Kernel.restoreVariables()
 	var fptt bool
 	if len(followPathsToTrue) > 1 {
 		fptt = followPathsToTrue[0]
@@ -236,64 +243,108 @@ func (b *BDD) ToFormula(fac f.Factory, followPathsToTrue ...bool) f.Formula {
 	return b.Kernel.toFormula(fac, b.Index, fptt)
 }
 
-// Negate returns a newBdd BDD which is the negation of the BDD.
+// Negate returns a new BDD which is the negation of the BDD.  Panics if the
+// node limit of the kernel is reached, use NegateWithHandler to get a
+// cancellation instead.
 func (b *BDD) Negate() *BDD {
-	bdd, _ := b.Kernel.addRef(b.Kernel.not(b.Index), handler.NopHandler)
-	return newBdd(bdd, b.Kernel)
+	return b.Kernel.must(b.NegateWithHandler(handler.NopHandler))
+}
+
+// NegateWithHandler returns a new BDD which is the negation of the BDD.  If
+// the node limit of the kernel is reached, the operation is canceled with the
+// event BddNodeLimitReached.
+func (b *BDD) NegateWithHandler(hdl handler.Handler) (*BDD, handler.State) {
+	return b.Kernel.result(b.Kernel.not(b.Index), hdl)
 }
 
-// Implies returns a newBdd BDD which is the implication of the BDD to the given
+// Implies returns a new BDD which is the implication of the BDD to the given
 // other BDD.  This method panics if the BDDs were constructed by different
-// kernels.
+// kernels or if the node limit of the kernel is reached, use
+// ImpliesWithHandler to get a cancellation instead.
 func (b *BDD) Implies(other *BDD) *BDD {
-	if other.Kernel != b.Kernel {
-		panic(errorx.BadInput("other BDD and receiver BDD have different kernels"))
-	}
-	bdd, _ := b.Kernel.addRef(b.Kernel.implication(b.Index, other.Index), handler.NopHandler)
-	return newBdd(bdd, b.Kernel)
+	return b.Kernel.must(b.ImpliesWithHandler(other, handler.NopHandler))
+}
+
+// ImpliesWithHandler returns a new BDD which is the implication of the BDD to
+// the given other BDD.  If the node limit of the kernel is reached, the
+// operation is canceled with the event BddNodeLimitReached.  This method
+// panics if the BDDs were constructed by different kernels.
+func (b *BDD) ImpliesWithHandler(other *BDD, hdl handler.Handler) (*BDD, handler.State) {
+	b.checkKernel(other)
+	defer runtime.KeepAlive(other)
+	return b.Kernel.result(b.Kernel.implication(b.Index, other.Index), hdl)
 }
 
-// ImpliedBy returns a newBdd BDD which is the implication of the other given BDD
+// ImpliedBy returns a new BDD which is the implication of the other given BDD
 // to the BDD.  This method panics if the BDDs were constructed by different
-// kernels.
+// kernels or if the node limit of the kernel is reached, use
+// ImpliedByWithHandler to get a cancellation instead.
 func (b *BDD) ImpliedBy(other *BDD) *BDD {
-	if other.Kernel != b.Kernel {
-		panic(errorx.BadInput("other BDD and receiver BDD have different kernels"))
-	}
-	bdd, _ := b.Kernel.addRef(b.Kernel.implication(other.Index, b.Index), handler.NopHandler)
-	return newBdd(bdd, b.Kernel)
+	return b.Kernel.must(b.ImpliedByWithHandler(other, handler.NopHandler))
+}
+
+// ImpliedByWithHandler returns a new BDD which is the implication of the
+// other given BDD to the BDD.  If the node limit of the kernel is reached,
+// the operation is canceled with the event BddNodeLimitReached.  This method
+// panics if the BDDs were constructed by different kernels.
+func (b *BDD) ImpliedByWithHandler(other *BDD, hdl handler.Handler) (*BDD, handler.State) {
+	b.checkKernel(other)
+	defer runtime.KeepAlive(other)
+	return b.Kernel.result(b.Kernel.implication(other.Index, b.Index), hdl)
 }
 
-// Equivalence returns a newBdd BDD which is the equivalence of the BDD and the
+// Equivalence returns a new BDD which is the equivalence of the BDD and the
 // other given BDD.  This method panics if the BDDs were constructed by
-// different kernels.
+// different kernels or if the node limit of the kernel is reached, use
+// EquivalenceWithHandler to get a cancellation instead.
 func (b *BDD) Equivalence(other *BDD) *BDD {
-	if other.Kernel != b.Kernel {
-		panic(errorx.BadInput("other BDD and receiver BDD have different kernels"))
-	}
-	bdd, _ := b.Kernel.addRef(b.Kernel.equivalence(b.Index, other.Index), handler.NopHandler)
-	return newBdd(bdd, b.Kernel)
+	return b.Kernel.must(b.EquivalenceWithHandler(other, handler.NopHandler))
 }
 
-// And returns a newBdd BDD which is the conjunction of the BDD and the given
+// EquivalenceWithHandler returns a new BDD which is the equivalence of the
+// BDD and the other given BDD.  If the node limit of the kernel is reached,
+// the operation is canceled with the event BddNodeLimitReached.  This method
+// panics if the BDDs were constructed by different kernels.
+func (b *BDD) EquivalenceWithHandler(other *BDD, hdl handler.Handler) (*BDD, handler.State) {
+	b.checkKernel(other)
+	defer runtime.KeepAlive(other)
+	return b.Kernel.result(b.Kernel.equivalence(b.Index, other.Index), hdl)
+}
+
+// And returns a new BDD which is the conjunction of the BDD and the given
 // other BDD.  This method panics if the BDDs were constructed by different
-// kernels.
+// kernels or if the node limit of the kernel is reached, use AndWithHandler
+// to get a cancellation instead.
 func (b *BDD) And(other *BDD) *BDD {
-	if other.Kernel != b.Kernel {
-		panic(errorx.BadInput("other BDD and receiver BDD have different kernels"))
-	}
-	bdd, _ := b.Kernel.addRef(b.Kernel.and(b.Index, other.Index), handler.NopHandler)
-	return newBdd(bdd, b.Kernel)
+	return b.Kernel.must(b.AndWithHandler(other, handler.NopHandler))
 }
 
-// Or returns a newBdd BDD which is the disjunction of the BDD and the given other
-// BDD.  This method panics if the BDDs were constructed by different kernels.
+// AndWithHandler returns a new BDD which is the conjunction of the BDD and
+// the given other BDD.  If the node limit of the kernel is reached, the
+// operation is canceled with the event BddNodeLimitReached.  This method
+// panics if the BDDs were constructed by different kernels.
+func (b *BDD) AndWithHandler(other *BDD, hdl handler.Handler) (*BDD, handler.State) {
+	b.checkKernel(other)
+	defer runtime.KeepAlive(other)
+	return b.Kernel.result(b.Kernel.and(b.Index, other.Index), hdl)
+}
+
+// Or returns a new BDD which is the disjunction of the BDD and the given
+// other BDD.  This method panics if the BDDs were constructed by different
+// kernels or if the node limit of the kernel is reached, use OrWithHandler to
+// get a cancellation instead.
 func (b *BDD) Or(other *BDD) *BDD {
-	if other.Kernel != b.Kernel {
-		panic(errorx.BadInput("other BDD and receiver BDD have different kernels"))
-	}
-	bdd, _ := b.Kernel.addRef(b.Kernel.or(b.Index, other.Index), handler.NopHandler)
-	return newBdd(bdd, b.Kernel)
+	return b.Kernel.must(b.OrWithHandler(other, handler.NopHandler))
+}
+
+// OrWithHandler returns a new BDD which is the disjunction of the BDD and the
+// given other BDD.  If the node limit of the kernel is reached, the operation
+// is canceled with the event BddNodeLimitReached.  This method panics if the
+// BDDs were constructed by different kernels.
+func (b *BDD) OrWithHandler(other *BDD, hdl handler.Handler) (*BDD, handler.State) {
+	b.checkKernel(other)
+	defer runtime.KeepAlive(other)
+	return b.Kernel.result(b.Kernel.or(b.Index, other.Index), hdl)
 }
 
 // IsTautology reports whether the BDD is a tautology.
@@ -313,50 +364,215 @@ func (b *BDD) ModelCount() *big.Int {
 
 // ModelEnumeration enumerates all models of the BDD wrt. a given set of variables.
 func (b *BDD) ModelEnumeration(variables ...f.Variable) []*model.Model {
+	b.Kernel.restoreVariables()
 	return bddModelEnum(b, variables)
 }
 
 // CNF returns a CNF formula for the BDD.
 func (b *BDD) CNF() f.Formula {
+	b.Kernel.restoreVariables()
 	return cnf(b)
 }
 
 // DNF returns a DNF formula for the BDD.
 func (b *BDD) DNF() f.Formula {
+	b.Kernel.restoreVariables()
 	return dnf(b)
 }
 
 // NumberOfCNFClauses returns the number of clauses for the CNF formula of the
 // BDD.
 func (b *BDD) NumberOfCNFClauses() *big.Int {
+	b.Kernel.restoreVariables()
 	return b.Kernel.pathCountZero(b.Index)
 }
 
-// Restrict returns a newBdd BDD where the literals of the restriction are
+// Restrict returns a new BDD where the literals of the restriction are
 // assigned to their respective polarity and therefore the BDD does not contain
-// the respective variables anymore.
+// the respective variables anymore.  Panics if the node limit of the kernel
+// is reached, use RestrictWithHandler to get a cancellation instead.
 func (b *BDD) Restrict(restriction ...f.Literal) *BDD {
-	resBdd := CompileLiterals(restriction, b.Kernel)
-	return newBdd(b.Kernel.restrict(b.Index, resBdd.Index), b.Kernel)
+	return b.Kernel.must(b.RestrictWithHandler(handler.NopHandler, restriction...))
+}
+
+// RestrictWithHandler returns a new BDD where the literals of the restriction
+// are assigned to their respective polarity.  If the node limit of the kernel
+// is reached, the operation is canceled with the event BddNodeLimitReached.
+func (b *BDD) RestrictWithHandler(hdl handler.Handler, restriction ...f.Literal) (*BDD, handler.State) {
+	return b.withLiterals(restriction, hdl, func(lits int32) int32 {
+		return b.Kernel.restrict(b.Index, lits)
+	})
 }
 
 // Exists performs existential quantifier elimination for a given set of
-// variables and return the resulting BDD.
+// variables and return the resulting BDD.  Panics if the node limit of the
+// kernel is reached, use ExistsWithHandler to get a cancellation instead.
 func (b *BDD) Exists(variable ...f.Variable) *BDD {
-	resBdd := CompileLiterals(f.VariablesAsLiterals(variable), b.Kernel)
-	return newBdd(b.Kernel.exists(b.Index, resBdd.Index), b.Kernel)
+	return b.Kernel.must(b.ExistsWithHandler(handler.NopHandler, variable...))
+}
+
+// ExistsWithHandler performs existential quantifier elimination for a given
+// set of variables and return the resulting BDD.  If the node limit of the
+// kernel is reached, the operation is canceled with the event
+// BddNodeLimitReached.
+func (b *BDD) ExistsWithHandler(hdl handler.Handler, variable ...f.Variable) (*BDD, handler.State) {
+	return b.withLiterals(f.VariablesAsLiterals(variable), hdl, func(vars int32) int32 {
+		return b.Kernel.exists(b.Index, vars)
+	})
 }
 
 // ForAll performs universal quantifier elimination for a given set of
-// variables and returns the resulting BDD.
+// variables and returns the resulting BDD.  Panics if the node limit of the
+// kernel is reached, use ForAllWithHandler to get a cancellation instead.
 func (b *BDD) ForAll(variable ...f.Variable) *BDD {
-	resBdd := CompileLiterals(f.VariablesAsLiterals(variable), b.Kernel)
-	return newBdd(b.Kernel.forAll(b.Index, resBdd.Index), b.Kernel)
+	return b.Kernel.must(b.ForAllWithHandler(handler.NopHandler, variable...))
+}
+
+// ForAllWithHandler performs universal quantifier elimination for a given
+// set of variables and returns the resulting BDD.  If the node limit of the
+// kernel is reached, the operation is canceled with the event
+// BddNodeLimitReached.
+func (b *BDD) ForAllWithHandler(hdl handler.Handler, variable ...f.Variable) (*BDD, handler.State) {
+	return b.withLiterals(f.VariablesAsLiterals(variable), hdl, func(vars int32) int32 {
+		return b.Kernel.forAll(b.Index, vars)
+	})
+}
+
+// AndExists returns a new BDD which is the conjunction of the BDD and the
+// given other BDD where the given variables are existentially quantified.
+// This relational product is computed in a single pass and therefore avoids
+// the construction of the potentially large conjunction.  This method panics
+// if the BDDs were constructed by different kernels or if the node limit of
+// the kernel is reached, use AndExistsWithHandler to get a cancellation
+// instead.
+func (b *BDD) AndExists(other *BDD, variable ...f.Variable) *BDD {
+	return b.Kernel.must(b.AndExistsWithHandler(other, handler.NopHandler, variable...))
+}
+
+// AndExistsWithHandler returns a new BDD which is the conjunction of the BDD
+// and the given other BDD where the given variables are existentially
+// quantified.  If the node limit of the kernel is reached, the operation is
+// canceled with the event BddNodeLimitReached.  This method panics if the
+// BDDs were constructed by different kernels.
+func (b *BDD) AndExistsWithHandler(other *BDD, hdl handler.Handler, variable ...f.Variable) (*BDD, handler.State) {
+	b.checkKernel(other)
+	defer runtime.KeepAlive(other)
+	return b.withLiterals(f.VariablesAsLiterals(variable), hdl, func(vars int32) int32 {
+		return b.Kernel.andExists(b.Index, other.Index, vars)
+	})
+}
+
+// ITE returns a new BDD which is the if-then-else of the BDD as condition and
+// the given then and else BDDs, i.e. (b & then) | (~b & else).  This method
+// panics if the BDDs were constructed by different kernels or if the node
+// limit of the kernel is reached, use ITEWithHandler to get a cancellation
+// instead.
+func (b *BDD) ITE(thenBDD, elseBDD *BDD) *BDD {
+	return b.Kernel.must(b.ITEWithHandler(thenBDD, elseBDD, handler.NopHandler))
+}
+
+// ITEWithHandler returns a new BDD which is the if-then-else of the BDD as
+// condition and the given then and else BDDs.  If the node limit of the
+// kernel is reached, the operation is canceled with the event
+// BddNodeLimitReached.  This method panics if the BDDs were constructed by
+// different kernels.
+func (b *BDD) ITEWithHandler(thenBDD, elseBDD *BDD, hdl handler.Handler) (*BDD, handler.State) {
+	b.checkKernel(thenBDD)
+	b.checkKernel(elseBDD)
+	defer runtime.KeepAlive(thenBDD)
+	defer runtime.KeepAlive(elseBDD)
+	return b.Kernel.result(b.Kernel.ite(b.Index, thenBDD.Index, elseBDD.Index), hdl)
+}
+
+// Compose returns a new BDD where the given variable is substituted by the
+// given BDD.  If the variable is not on the BDD's kernel, the BDD does not
+// depend on it and an equivalent BDD is returned.  This method panics if the
+// BDDs were constructed by different kernels or if the node limit of the
+// kernel is reached.
+func (b *BDD) Compose(variable f.Variable, bdd *BDD) *BDD {
+	return b.VectorCompose(map[f.Variable]*BDD{variable: bdd})
+}
+
+// VectorCompose returns a new BDD where all variables of the substitution
+// are simultaneously substituted by their respective BDDs.  Variables which
+// are not on the BDD's kernel are ignored.  This method panics if the BDDs
+// were constructed by different kernels or if the node limit of the kernel
+// is reached, use VectorComposeWithHandler to get a cancellation instead.
+func (b *BDD) VectorCompose(substitution map[f.Variable]*BDD) *BDD {
+	return b.Kernel.must(b.VectorComposeWithHandler(substitution, handler.NopHandler))
+}
+
+// VectorComposeWithHandler returns a new BDD where all variables of the
+// substitution are simultaneously substituted by their respective BDDs.  If
+// the node limit of the kernel is reached, the operation is canceled with the
+// event BddNodeLimitReached.  This method panics if the BDDs were constructed
+// by different kernels.
+func (b *BDD) VectorComposeWithHandler(
+	substitution map[f.Variable]*BDD, hdl handler.Handler,
+) (*BDD, handler.State) {
+	b.Kernel.restoreVariables()
+	replacement := make(map[int32]int32, len(substitution))
+	for variable, bdd := range substitution {
+		b.checkKernel(bdd)
+		if idx, ok := b.Kernel.var2idx[variable]; ok {
+			replacement[idx] = bdd.Index
+		}
+	}
+	defer runtime.KeepAlive(substitution)
+	return b.Kernel.result(b.Kernel.vectorCompose(b.Index, replacement), hdl)
+}
+
+// Rename returns a new BDD where all variables of the renaming are
+// simultaneously renamed to their respective new variables.  Therefore, two
+// variables can also be swapped.  New variables which are not yet on the
+// BDD's kernel are added to it and the kernel is extended if all of its
+// variables are in use.  Variables to rename which are not on the kernel are
+// ignored.  Panics if the node limit of the kernel is reached, use
+// RenameWithHandler to get a cancellation instead.
+func (b *BDD) Rename(renaming map[f.Variable]f.Variable) *BDD {
+	return b.Kernel.must(b.RenameWithHandler(renaming, handler.NopHandler))
+}
+
+// RenameWithHandler returns a new BDD where all variables of the renaming are
+// simultaneously renamed to their respective new variables.  If the node
+// limit of the kernel is reached, the operation is canceled with the event
+// BddNodeLimitReached.
+func (b *BDD) RenameWithHandler(renaming map[f.Variable]f.Variable, hdl handler.Handler) (*BDD, handler.State) {
+	b.Kernel.restoreVariables()
+	replacement := make(map[int32]int32, len(renaming))
+	for variable, newVariable := range renaming {
+		if idx, ok := b.Kernel.var2idx[variable]; ok {
+			replacement[idx] = b.Kernel.ithVar(b.Kernel.getOrAddVarIndex(newVariable))
+		}
+	}
+	return b.Kernel.result(b.Kernel.vectorCompose(b.Index, replacement), hdl)
+}
+
+// checkKernel panics if the other BDD was constructed by a different kernel.
+func (b *BDD) checkKernel(other *BDD) {
+	if other.Kernel != b.Kernel {
+		panic(errorx.BadInput("other BDD and receiver BDD have different kernels"))
+	}
+}
+
+// withLiterals compiles the conjunction of the given literals, performs the
+// operation on it and returns the result of the operation.
+func (b *BDD) withLiterals(
+	literals []f.Literal, hdl handler.Handler, operation func(lits int32) int32,
+) (*BDD, handler.State) {
+	b.Kernel.restoreVariables()
+	lits, state := compileLiterals(literals, b.Kernel, hdl)
+	if !state.Success {
+		return nil, state
+	}
+	defer b.Kernel.delRef(lits)
+	return b.Kernel.result(operation(lits), hdl)
 }
 
 // Model returns an arbitrary model of the BDD.  An error is returned if the
 // BDD is a contradiction and therefore has no model.
 func (b *BDD) Model() (*model.Model, error) {
+	b.Kernel.restoreVariables()
 	return b.createModel(b.Kernel.satOne(b.Index))
 }
 
@@ -365,6 +581,7 @@ func (b *BDD) Model() (*model.Model, error) {
 // be assigned with the given defaultValue.  An error is returned if the BDD is
 // a contradiction and therefore has no model.
 func (b *BDD) ModelWithVariables(defaultValue bool, variable ...f.Variable) (*model.Model, error) {
+	b.Kernel.restoreVariables()
 	bdd := CompileLiterals(f.VariablesAsLiterals(variable), b.Kernel)
 	var pol int32
 	if defaultValue {
@@ -373,27 +590,33 @@ func (b *BDD) ModelWithVariables(defaultValue bool, variable ...f.Variable) (*mo
 		pol = bddFalse
 	}
 	modelBdd := b.Kernel.satOneSet(b.Index, bdd.Index, pol)
-	return b.createModel(modelBdd)
+	mdl, err := b.createModel(modelBdd)
+	bdd.Free()
+	return mdl, err
 }
 
 // FullModel returns a model over all variables of the BDD.  An error is
 // returned if the BDD is a contradiction and therefore has no model.
 func (b *BDD) FullModel() (*model.Model, error) {
+	b.Kernel.restoreVariables()
 	return b.createModel(b.Kernel.fullSatOne(b.Index))
 }
 
 // PathCountOne returns the number of paths leading to the terminal 1 node.
 func (b *BDD) PathCountOne() *big.Int {
+	b.Kernel.restoreVariables()
 	return b.Kernel.pathCountOne(b.Index)
 }
 
 // PathCountZero returns the number of paths leading to the terminal 0 node.
 func (b *BDD) PathCountZero() *big.Int {
+	b.Kernel.restoreVariables()
 	return b.Kernel.pathCountZero(b.Index)
 }
 
 // Support returns all the variables the BDD depends on.
 func (b *BDD) Support() []f.Variable {
+	b.Kernel.restoreVariables()
 	supportBdd := b.Kernel.support(b.Index)
 	mdl, err := b.createModel(supportBdd)
 	if err != nil {
@@ -409,6 +632,7 @@ func (b *BDD) NodeCount() int {
 
 // VariableProfile returns how often each variable occurs in the BDD.
 func (b *BDD) VariableProfile() map[f.Variable]int {
+	b.Kernel.restoreVariables()
 	varProfile := b.Kernel.varProfile(b.Index)
 	profile := make(map[f.Variable]int, len(varProfile))
 	for i := range varProfile {
@@ -420,6 +644,7 @@ func (b *BDD) VariableProfile() map[f.Variable]int {
 
 // VariableOrder returns the variable order of the BDD.
 func (b *BDD) VariableOrder() []f.Variable {
+	b.Kernel.restoreVariables()
 	order := make([]f.Variable, len(b.Kernel.level2var)-1)
 	for i := range order {
 		variable, _ := b.Kernel.getVariableForIndex(b.Kernel.level2var[i])
@@ -430,6 +655,7 @@ func (b *BDD) VariableOrder() []f.Variable {
 
 // NodeRepresentation returns a graph-like representation of the BDD as nodes.
 func (b *BDD) NodeRepresentation() Node {
+	b.Kernel.restoreVariables()
 	kernel := b.Kernel
 	index := b.Index
 	kernelNodeMap := make(map[int32][]int32)
@@ -0,0 +1,150 @@
+package bdd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// WriteBuDDy writes the given BDD to a file with the given filename in the
+// format of the function bdd_save of the BuDDy library.  The variable numbers
+// of the file are the indices of the variables on the kernel.  Returns an
+// error if there was a problem writing the file.
+func WriteBuDDy(filename string, bdd *BDD) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteBuDDyToWriter(file, bdd)
+}
+
+// WriteBuDDyToWriter writes the given BDD to the given writer in the format
+// of the function bdd_save of the BuDDy library.  Returns an error if there
+// was a problem writing to the writer.
+func WriteBuDDyToWriter(writer io.Writer, bdd *BDD) error {
+	kernel := bdd.Kernel
+	kernel.restoreVariables()
+	w := bufio.NewWriter(writer)
+	if isConst(bdd.Index) {
+		fmt.Fprintf(w, "0 0 %d\n", bdd.Index)
+		return w.Flush()
+	}
+	nodes, _ := kernel.serializedNodes([]*BDD{bdd})
+	fmt.Fprintf(w, "%d %d\n", len(nodes), kernel.varnum)
+	for i := range kernel.varnum {
+		fmt.Fprintf(w, "%d ", kernel.var2level[i])
+	}
+	fmt.Fprintln(w)
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%d %d %d %d\n",
+			node, kernel.level2var[kernel.level(node)], kernel.low(node), kernel.high(node))
+	}
+	return w.Flush()
+}
+
+// ReadBuDDy reads a BDD from a file with the given filename in the format of
+// the function bdd_save of the BuDDy library.  Since the format contains no
+// variable names, the variable with number i is the i-th of the given
+// variables.  If no variables are given, the variable with number i is the
+// variable with index i on the kernel.  The variable order of the file is
+// ignored, the BDD is constructed with the order of the kernel.  Returns an
+// error if there was a problem reading the file or a variable number cannot
+// be mapped to a variable.
+func ReadBuDDy(filename string, kernel *Kernel, variables ...f.Variable) (*BDD, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadBuDDyFromReader(file, kernel, variables...)
+}
+
+// ReadBuDDyFromReader reads a BDD from the given reader in the format of the
+// function bdd_save of the BuDDy library like ReadBuDDy.
+func ReadBuDDyFromReader(reader io.Reader, kernel *Kernel, variables ...f.Variable) (*BDD, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(bufio.ScanWords)
+	next := func() (int, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, errorx.BadInput("unexpected end of BuDDy file")
+		}
+		value, err := strconv.Atoi(scanner.Text())
+		if err != nil {
+			return 0, errorx.BadInput("illegal number in BuDDy file: %s", scanner.Text())
+		}
+		return value, nil
+	}
+	numNodes, err := next()
+	if err != nil {
+		return nil, err
+	}
+	numVars, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if numNodes == 0 && numVars == 0 {
+		constant, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if !isConst(int32(constant)) {
+			return nil, errorx.BadInput("illegal constant in BuDDy file: %d", constant)
+		}
+		return newBdd(int32(constant), kernel), nil
+	}
+	for range numVars {
+		if _, err = next(); err != nil {
+			return nil, err
+		}
+	}
+
+	builder := newNodeBuilder(kernel)
+	defer builder.release()
+	nodes := map[int]int32{0: bddFalse, 1: bddTrue}
+	root := bddFalse
+	for range numNodes {
+		var values [4]int
+		for j := range values {
+			if values[j], err = next(); err != nil {
+				return nil, err
+			}
+		}
+		variable, err := buddyVariable(kernel, values[1], variables)
+		if err != nil {
+			return nil, err
+		}
+		low, okLow := nodes[values[2]]
+		high, okHigh := nodes[values[3]]
+		if !okLow || !okHigh {
+			return nil, errorx.BadInput("unknown node in BuDDy file: %d", values[0])
+		}
+		if root, err = builder.add(variable, low, high); err != nil {
+			return nil, err
+		}
+		nodes[values[0]] = root
+	}
+	return builder.bdd(root), nil
+}
+
+func buddyVariable(kernel *Kernel, number int, variables []f.Variable) (f.Variable, error) {
+	if len(variables) > 0 {
+		if number < 0 || number >= len(variables) {
+			return 0, errorx.BadInput("no variable given for variable number %d", number)
+		}
+		return variables[number], nil
+	}
+	variable, ok := kernel.idx2var[int32(number)]
+	if !ok {
+		return 0, errorx.BadInput("no variable with index %d on the kernel", number)
+	}
+	return variable, nil
+}
@@ -0,0 +1,158 @@
+package bdd
+
+import (
+	"math/big"
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/booleworks/logicng-go/transformation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBDDITE(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	kernel := NewKernelWithOrdering(fac, fac.Vars("a", "b", "c", "d"), 100, 100)
+	cond := CompileWithKernel(fac, p.ParseUnsafe("a | b"), kernel)
+	thenBDD := CompileWithKernel(fac, p.ParseUnsafe("c & d"), kernel)
+	elseBDD := CompileWithKernel(fac, p.ParseUnsafe("~c | b"), kernel)
+	exp := CompileWithKernel(fac, p.ParseUnsafe("(a | b) & c & d | ~a & ~b & ~c"), kernel)
+	assert.Equal(exp.Index, cond.ITE(thenBDD, elseBDD).Index)
+
+	verum := CompileWithKernel(fac, fac.Verum(), kernel)
+	falsum := CompileWithKernel(fac, fac.Falsum(), kernel)
+	assert.Equal(thenBDD.Index, verum.ITE(thenBDD, elseBDD).Index)
+	assert.Equal(elseBDD.Index, falsum.ITE(thenBDD, elseBDD).Index)
+	assert.Equal(cond.Index, cond.ITE(verum, falsum).Index)
+	assert.Equal(cond.Negate().Index, cond.ITE(falsum, verum).Index)
+	assert.Equal(thenBDD.Index, cond.ITE(thenBDD, thenBDD).Index)
+}
+
+func TestBDDAndExists(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+	kernel := NewKernelWithOrdering(fac, []f.Variable{a, b, c}, 100, 100)
+	bdd1 := CompileWithKernel(fac, p.ParseUnsafe("a => b"), kernel)
+	bdd2 := CompileWithKernel(fac, p.ParseUnsafe("b => c"), kernel)
+	exp := CompileWithKernel(fac, p.ParseUnsafe("a => c"), kernel)
+	assert.Equal(exp.Index, bdd1.AndExists(bdd2, b).Index)
+	assert.Equal(bdd1.And(bdd2).Index, bdd1.AndExists(bdd2).Index)
+	assert.True(bdd1.AndExists(bdd2, a, b, c).IsTautology())
+	assert.True(bdd1.AndExists(bdd1.Negate(), b).IsContradiction())
+	assert.Equal(bdd1.Exists(a).Index, bdd1.AndExists(bdd1, a).Index)
+}
+
+func TestBDDCompose(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c, x := fac.Var("a"), fac.Var("b"), fac.Var("c"), fac.Var("x")
+	kernel := NewKernelWithOrdering(fac, []f.Variable{a, b, c}, 100, 100)
+	bdd := CompileWithKernel(fac, p.ParseUnsafe("a & b | ~a & c"), kernel)
+	g := CompileWithKernel(fac, p.ParseUnsafe("b | c"), kernel)
+	exp := CompileWithKernel(fac, p.ParseUnsafe("(b | c) & b | ~b & ~c & c"), kernel)
+	assert.Equal(exp.Index, bdd.Compose(a, g).Index)
+	assert.Equal(bdd.Index, bdd.Compose(x, g).Index)
+	verum := CompileWithKernel(fac, fac.Verum(), kernel)
+	assert.Equal(bdd.Restrict(a.AsLiteral()).Index, bdd.Compose(a, verum).Index)
+
+	swapped := bdd.VectorCompose(map[f.Variable]*BDD{
+		b: CompileWithKernel(fac, c.AsFormula(), kernel),
+		c: CompileWithKernel(fac, b.AsFormula(), kernel),
+	})
+	assert.Equal(CompileWithKernel(fac, p.ParseUnsafe("a & c | ~a & b"), kernel).Index, swapped.Index)
+}
+
+func TestBDDRename(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c, x := fac.Var("a"), fac.Var("b"), fac.Var("c"), fac.Var("x")
+	kernel := NewKernel(fac, 4, 100, 100)
+	bdd := CompileWithKernel(fac, p.ParseUnsafe("a & ~b | c"), kernel)
+
+	renamed := bdd.Rename(map[f.Variable]f.Variable{a: b, b: a})
+	assert.Equal(CompileWithKernel(fac, p.ParseUnsafe("b & ~a | c"), kernel).Index, renamed.Index)
+	renamed = bdd.Rename(map[f.Variable]f.Variable{c: x})
+	assert.Equal(CompileWithKernel(fac, p.ParseUnsafe("a & ~b | x"), kernel).Index, renamed.Index)
+	assert.Equal(bdd.Index, renamed.Rename(map[f.Variable]f.Variable{x: c}).Index)
+	assert.Equal(bdd.Index, bdd.Rename(map[f.Variable]f.Variable{}).Index)
+}
+
+func TestBDDRenameExtendsKernel(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, c := fac.Var("a"), fac.Var("c")
+	bdd := Compile(fac, p.ParseUnsafe("a & b"))
+	assert.Equal(int32(2), bdd.Kernel.Statistics().Vars)
+
+	renamed := bdd.Rename(map[f.Variable]f.Variable{a: c})
+	assert.Equal(int32(3), bdd.Kernel.Statistics().Vars)
+	assertEquivalent(assert, fac, p.ParseUnsafe("c & b"), renamed)
+	assertEquivalent(assert, fac, p.ParseUnsafe("a & b"), bdd)
+	assert.Equal(bdd.Index, renamed.Rename(map[f.Variable]f.Variable{c: a}).Index)
+	assert.Equal(big.NewInt(2), renamed.ModelCount())
+}
+
+func TestBDDComposeRandom(t *testing.T) {
+	assert := assert.New(t)
+	for _, reorder := range []bool{false, true} {
+		for i := range 20 {
+			fac := f.NewFactory()
+			config := randomizer.DefaultConfig()
+			config.Seed = int64(i)
+			config.NumVars = 8
+			r := randomizer.New(fac, config)
+			formula1, formula2, formula3 := r.Formula(4), r.Formula(3), r.Formula(2)
+			vars := fac.Vars("v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7")
+			kernel := NewKernelWithOrdering(fac, vars, 20, 20)
+			if reorder {
+				kernel.AddAllVariablesAsBlock()
+				kernel.ActivateReorderDuringBuild(ReorderSift, 1000)
+			}
+			bdd1 := CompileWithKernel(fac, formula1, kernel)
+			bdd2 := CompileWithKernel(fac, formula2, kernel)
+			bdd3 := CompileWithKernel(fac, formula3, kernel)
+
+			ite := bdd1.ITE(bdd2, bdd3)
+			assertEquivalent(assert, fac, fac.Or(fac.And(formula1, formula2), fac.And(formula1.Negate(fac), formula3)), ite)
+
+			quantified := vars[:3]
+			andExists := bdd1.AndExists(bdd2, quantified...)
+			assert.Equal(bdd1.And(bdd2).Exists(quantified...).Index, andExists.Index)
+
+			substitution := transformation.NewSubstitution()
+			substitution.AddVar(vars[0], formula2)
+			substitution.AddVar(vars[1], formula3)
+			substitution.AddVar(vars[2], vars[3].AsFormula())
+			substitution.AddVar(vars[3], vars[2].AsFormula())
+			substituted, _ := transformation.Substitute(fac, formula1, substitution)
+			composed := bdd1.VectorCompose(map[f.Variable]*BDD{
+				vars[0]: bdd2,
+				vars[1]: bdd3,
+				vars[2]: CompileWithKernel(fac, vars[3].AsFormula(), kernel),
+				vars[3]: CompileWithKernel(fac, vars[2].AsFormula(), kernel),
+			})
+			assertEquivalent(assert, fac, substituted, composed)
+
+			renaming := map[f.Variable]f.Variable{vars[4]: vars[5], vars[5]: vars[6], vars[6]: vars[4]}
+			substitution = transformation.NewSubstitution()
+			for variable, newVariable := range renaming {
+				substitution.AddVar(variable, newVariable.AsFormula())
+			}
+			renamed, _ := transformation.Substitute(fac, formula1, substitution)
+			assertEquivalent(assert, fac, renamed, bdd1.Rename(renaming))
+		}
+	}
+}
+
+func assertEquivalent(assert *assert.Assertions, fac f.Factory, formula f.Formula, bdd *BDD) {
+	assert.True(sat.IsEquivalent(fac, formula, bdd.CNF()))
+}
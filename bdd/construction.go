@@ -74,9 +74,15 @@ func (k *Kernel) notRec(r int32) (int32, bool) {
 	if entry.a == r && entry.c == bddNot.v {
 		return entry.res, false
 	}
-	node, _ := k.notRec(k.low(r))
+	node, reorder := k.notRec(k.low(r))
+	if reorder {
+		return -1, true
+	}
 	k.pushRef(node)
-	node, _ = k.notRec(k.high(r))
+	node, reorder = k.notRec(k.high(r))
+	if reorder {
+		return -1, true
+	}
 	k.pushRef(node)
 	res, reorder := k.makeNode(k.level(r), k.readRef(2), k.readRef(1))
 	if reorder {
@@ -93,8 +99,8 @@ func (k *Kernel) restrict(r, variable int32) int32 {
 	if variable < 2 {
 		return r
 	}
-	k.varset2svartable(variable)
-	return k.doWithPotentialReordering(func() (int32, bool) {
+	return k.doWithFixedVariables(func() (int32, bool) {
+		k.varset2svartable(variable)
 		return k.restrictRec(r, (variable<<3)|cacheidRestrict)
 	})
 }
@@ -145,8 +151,8 @@ func (k *Kernel) exists(r, variable int32) int32 {
 	if variable < 2 {
 		return r
 	}
-	k.varset2vartable(variable)
-	return k.doWithPotentialReordering(func() (int32, bool) {
+	return k.doWithFixedVariables(func() (int32, bool) {
+		k.varset2vartable(variable)
 		return k.quantRec(r, bddOr, variable<<3)
 	})
 }
@@ -155,8 +161,8 @@ func (k *Kernel) forAll(r, variable int32) int32 {
 	if variable < 2 {
 		return r
 	}
-	k.varset2vartable(variable)
-	return k.doWithPotentialReordering(func() (int32, bool) {
+	return k.doWithFixedVariables(func() (int32, bool) {
+		k.varset2vartable(variable)
 		return k.quantRec(r, bddAnd, (variable<<3)|cacheidForall)
 	})
 }
@@ -239,3 +245,192 @@ func (k *Kernel) insvarset(a int32) bool {
 func (k *Kernel) invarset(a int32) bool {
 	return k.quantvarset[a] == k.quantvarsetId
 }
+
+func (k *Kernel) ite(f, g, h int32) int32 {
+	return k.doWithPotentialReordering(func() (int32, bool) {
+		return k.iteRec(f, g, h)
+	})
+}
+
+func (k *Kernel) iteRec(f, g, h int32) (int32, bool) {
+	if isOne(f) {
+		return g, false
+	}
+	if isZero(f) {
+		return h, false
+	}
+	if g == h {
+		return g, false
+	}
+	if isOne(g) && isZero(h) {
+		return f, false
+	}
+	if isZero(g) && isOne(h) {
+		return k.notRec(f)
+	}
+	entry := k.itecache.lookup(triple(f, g, h))
+	if entry.a == f && entry.b == g && entry.c == h {
+		return entry.res, false
+	}
+	level := min(k.level(f), k.level(g), k.level(h))
+	node, reorder := k.iteRec(k.cofactor(f, level, false), k.cofactor(g, level, false), k.cofactor(h, level, false))
+	if reorder {
+		return -1, true
+	}
+	k.pushRef(node)
+	node, reorder = k.iteRec(k.cofactor(f, level, true), k.cofactor(g, level, true), k.cofactor(h, level, true))
+	if reorder {
+		return -1, true
+	}
+	k.pushRef(node)
+	res, reorder := k.makeNode(level, k.readRef(2), k.readRef(1))
+	if reorder {
+		return -1, true
+	}
+	k.popref(2)
+	entry.a = f
+	entry.b = g
+	entry.c = h
+	entry.res = res
+	return res, false
+}
+
+// cofactor returns the low or high successor of the node if the node is on
+// the given level and the node itself otherwise.
+func (k *Kernel) cofactor(r, level int32, phase bool) int32 {
+	if k.level(r) != level {
+		return r
+	}
+	if phase {
+		return k.high(r)
+	}
+	return k.low(r)
+}
+
+func (k *Kernel) andExists(l, r, variable int32) int32 {
+	if variable < 2 {
+		return k.and(l, r)
+	}
+	// the variable table is indexed by levels and must be computed again if
+	// the variables are reordered during the computation
+	return k.doWithFixedVariables(func() (int32, bool) {
+		k.varset2vartable(variable)
+		return k.andExistsRec(l, r, variable<<3)
+	})
+}
+
+func (k *Kernel) andExistsRec(l, r, quantid int32) (int32, bool) {
+	if isZero(l) || isZero(r) {
+		return bddFalse, false
+	}
+	if l == r || isOne(r) {
+		return k.quantRec(l, bddOr, quantid)
+	}
+	if isOne(l) {
+		return k.quantRec(r, bddOr, quantid)
+	}
+	level := min(k.level(l), k.level(r))
+	if level > k.quantlast {
+		return k.applyRec(l, r, bddAnd)
+	}
+	entry := k.appexcache.lookup(triple(l, r, quantid))
+	if entry.a == l && entry.b == r && entry.c == quantid {
+		return entry.res, false
+	}
+	node, reorder := k.andExistsRec(k.cofactor(l, level, false), k.cofactor(r, level, false), quantid)
+	if reorder {
+		return -1, true
+	}
+	k.pushRef(node)
+	node, reorder = k.andExistsRec(k.cofactor(l, level, true), k.cofactor(r, level, true), quantid)
+	if reorder {
+		return -1, true
+	}
+	k.pushRef(node)
+	var res int32
+	if k.invarset(level) {
+		res, reorder = k.applyRec(k.readRef(2), k.readRef(1), bddOr)
+	} else {
+		res, reorder = k.makeNode(level, k.readRef(2), k.readRef(1))
+	}
+	if reorder {
+		return -1, true
+	}
+	k.popref(2)
+	entry.a = l
+	entry.b = r
+	entry.c = quantid
+	entry.res = res
+	return res, false
+}
+
+// vectorCompose simultaneously substitutes the variables of the BDD by the
+// BDDs of the given replacement which maps variable indices to BDD nodes.
+// The replacement is indexed by variables instead of levels, such that it
+// remains valid if the variables are reordered during the computation.
+func (k *Kernel) vectorCompose(r int32, replacement map[int32]int32) int32 {
+	if len(replacement) == 0 {
+		return r
+	}
+	k.replacepair = make([]int32, k.varnum)
+	for i := range k.replacepair {
+		k.replacepair[i] = -1
+	}
+	for variable, node := range replacement {
+		k.replacepair[variable] = node
+	}
+	k.replaceid++
+	if k.replaceid == math.MaxInt32 {
+		k.replacecache.reset()
+		k.replaceid = 1
+	}
+	// the substituted BDDs can be on levels above the substituted variable,
+	// so the recursion of ite can be deeper than the one of the other
+	// operations
+	if len(k.refstack) < int(4*k.varnum+4) {
+		k.refstack = make([]int32, 4*k.varnum+4)
+	}
+	return k.doWithFixedVariables(func() (int32, bool) {
+		k.replacelast = 0
+		for variable := range replacement {
+			k.replacelast = max(k.replacelast, k.var2level[variable])
+		}
+		return k.vectorComposeRec(r)
+	})
+}
+
+func (k *Kernel) vectorComposeRec(r int32) (int32, bool) {
+	if isConst(r) || k.level(r) > k.replacelast {
+		return r, false
+	}
+	entry := k.replacecache.lookup(r)
+	if entry.a == r && entry.c == k.replaceid {
+		return entry.res, false
+	}
+	node, reorder := k.vectorComposeRec(k.low(r))
+	if reorder {
+		return -1, true
+	}
+	k.pushRef(node)
+	node, reorder = k.vectorComposeRec(k.high(r))
+	if reorder {
+		return -1, true
+	}
+	k.pushRef(node)
+	// the substituted successors can contain variables on levels above the
+	// node, so the node's variable must be combined with them by ite
+	variable := k.level2var[k.level(r)]
+	replacement := k.replacepair[variable]
+	if replacement < 0 {
+		replacement = k.ithVar(variable)
+	}
+	res, reorder := k.iteRec(replacement, k.readRef(1), k.readRef(2))
+	if reorder {
+		return -1, true
+	}
+	k.popref(2)
+	entry.a = r
+	entry.c = k.replaceid
+	entry.res = res
+	return res, false
+}
@@ -0,0 +1,323 @@
+package bdd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// WriteDDDMP writes the given BDDs to a file with the given filename in the
+// text format of the DDDMP library of CUDD.  Since DDDMP represents BDDs with
+// complement edges, the nodes of the BDDs are converted accordingly.  The
+// variable IDs of the file are the indices of the variables on the kernel and
+// the permutation IDs are their levels.  All BDDs must be constructed by the
+// same kernel.  Returns an error if there was a problem writing the file.
+func WriteDDDMP(filename string, bdds ...*BDD) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteDDDMPToWriter(file, bdds...)
+}
+
+// WriteDDDMPToWriter writes the given BDDs to the given writer in the DDDMP
+// text format.  Returns an error if there was a problem writing to the
+// writer.
+func WriteDDDMPToWriter(writer io.Writer, bdds ...*BDD) error {
+	kernel, err := commonKernel(bdds)
+	if err != nil {
+		return err
+	}
+	kernel.restoreVariables()
+	nodes, _ := kernel.serializedNodes(bdds)
+	support := make([]int32, 0)
+	supportPos := make(map[int32]int)
+	for _, node := range nodes {
+		idx := kernel.level2var[kernel.level(node)]
+		if _, ok := supportPos[idx]; !ok {
+			supportPos[idx] = 0
+			support = append(support, idx)
+		}
+	}
+	slices.Sort(support)
+	for i, idx := range support {
+		supportPos[idx] = i
+	}
+
+	conv := newComplementConverter()
+	lines := []string{"1 T 1 0 0"}
+	edges := map[int32]complementEdge{bddTrue: {1, false}, bddFalse: {1, true}}
+	for _, node := range nodes {
+		idx := kernel.level2var[kernel.level(node)]
+		edge, created := conv.node(idx, edges[kernel.high(node)], edges[kernel.low(node)])
+		if created {
+			key := conv.keys[edge.id-2]
+			lines = append(lines, fmt.Sprintf("%d %d %d %d %d",
+				edge.id, idx, supportPos[idx], key.then, key.elseEdge.signed()))
+		}
+		edges[node] = edge
+	}
+
+	order := kernel.namedVariableOrder()
+	w := bufio.NewWriter(writer)
+	fmt.Fprintln(w, ".ver DDDMP-2.0")
+	fmt.Fprintln(w, ".mode A")
+	fmt.Fprintln(w, ".varinfo 0")
+	fmt.Fprintf(w, ".nnodes %d\n", len(lines))
+	fmt.Fprintf(w, ".nvars %d\n", len(order))
+	fmt.Fprintf(w, ".nsuppvars %d\n", len(support))
+	fmt.Fprintf(w, ".orderedvarnames %s\n", joinNames(kernel, order))
+	supportVars := make([]f.Variable, len(support))
+	ids := make([]string, len(support))
+	permids := make([]string, len(support))
+	for i, idx := range support {
+		supportVars[i] = kernel.idx2var[idx]
+		ids[i] = strconv.Itoa(int(idx))
+		permids[i] = strconv.Itoa(int(kernel.var2level[idx]))
+	}
+	fmt.Fprintf(w, ".suppvarnames %s\n", joinNames(kernel, supportVars))
+	fmt.Fprintf(w, ".ids %s\n", strings.Join(ids, " "))
+	fmt.Fprintf(w, ".permids %s\n", strings.Join(permids, " "))
+	fmt.Fprintf(w, ".nroots %d\n", len(bdds))
+	roots := make([]string, len(bdds))
+	for i, bdd := range bdds {
+		roots[i] = strconv.Itoa(edges[bdd.Index].signed())
+	}
+	fmt.Fprintf(w, ".rootids %s\n", strings.Join(roots, " "))
+	fmt.Fprintln(w, ".nodes")
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, ".end")
+	return w.Flush()
+}
+
+// ReadDDDMP reads BDDs from a file with the given filename in the text
+// format of the DDDMP library of CUDD.  Only files in ASCII mode are
+// supported.  The variables are named by the variable names of the file, if
+// the file contains no names, a variable with ID i is named v<i>.  The BDDs
+// are constructed by the given kernel, the variables of the file are added to
+// the kernel if they are not yet known.  If the kernel is nil, a new kernel
+// with the variable order of the file is created.  Returns the BDDs in the
+// order of the root IDs and an error if there was a problem reading the file
+// or the node limit of the kernel is reached.
+func ReadDDDMP(fac f.Factory, filename string, kernel *Kernel) ([]*BDD, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadDDDMPFromReader(fac, file, kernel)
+}
+
+// ReadDDDMPFromReader reads BDDs from the given reader in the DDDMP text
+// format like ReadDDDMP.
+func ReadDDDMPFromReader(fac f.Factory, reader io.Reader, kernel *Kernel) ([]*BDD, error) {
+	header := make(map[string][]string)
+	var nodeLines [][]string
+	inNodes := false
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+			continue
+		case fields[0] == ".nodes":
+			inNodes = true
+		case fields[0] == ".end":
+			inNodes = false
+		case inNodes:
+			nodeLines = append(nodeLines, fields)
+		case strings.HasPrefix(fields[0], "."):
+			header[fields[0]] = fields[1:]
+		default:
+			return nil, errorx.BadInput("unexpected line in DDDMP file: %s", scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if mode := header[".mode"]; len(mode) > 0 && mode[0] != "A" {
+		return nil, errorx.BadInput("only DDDMP files in ASCII mode are supported")
+	}
+
+	ids, err := parseInts(header[".ids"])
+	if err != nil {
+		return nil, err
+	}
+	permids, err := parseInts(header[".permids"])
+	if err != nil {
+		return nil, err
+	}
+	support := make([]f.Variable, len(ids))
+	for i, id := range ids {
+		support[i] = fac.Var(dddmpVarName(header, i, id))
+	}
+	if kernel == nil {
+		order := slices.Clone(support)
+		if len(permids) == len(ids) {
+			positions := make(map[f.Variable]int, len(ids))
+			for i, variable := range support {
+				positions[variable] = permids[i]
+			}
+			slices.SortStableFunc(order, func(a, b f.Variable) int { return positions[a] - positions[b] })
+		}
+		kernel = NewKernelWithOrdering(fac, order, int32(2*len(nodeLines)+10), int32(len(nodeLines)+10))
+	}
+
+	builder := newNodeBuilder(kernel)
+	defer builder.release()
+	nodes := make(map[int]int32, len(nodeLines))
+	edge := func(value int) (int32, error) {
+		node, ok := nodes[abs(value)]
+		if !ok {
+			return -1, errorx.BadInput("unknown node ID in DDDMP file: %d", abs(value))
+		}
+		if value < 0 {
+			return builder.not(node)
+		}
+		return node, nil
+	}
+	for _, fields := range nodeLines {
+		if len(fields) < 4 {
+			return nil, errorx.BadInput("illegal node in DDDMP file: %s", strings.Join(fields, " "))
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		if fields[1] == "T" {
+			nodes[id] = bddTrue
+			continue
+		}
+		values, err := parseInts(fields[len(fields)-3:])
+		if err != nil {
+			return nil, err
+		}
+		if values[0] < 0 || values[0] >= len(support) {
+			return nil, errorx.BadInput("illegal variable in DDDMP file: %d", values[0])
+		}
+		high, err := edge(values[1])
+		if err != nil {
+			return nil, err
+		}
+		low, err := edge(values[2])
+		if err != nil {
+			return nil, err
+		}
+		if nodes[id], err = builder.add(support[values[0]], low, high); err != nil {
+			return nil, err
+		}
+	}
+
+	rootIDs, err := parseInts(header[".rootids"])
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*BDD, len(rootIDs))
+	for i, rootID := range rootIDs {
+		root, err := edge(rootID)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = builder.bdd(root)
+	}
+	return result, nil
+}
+
+// dddmpVarName returns the name of the i-th support variable with the given
+// ID.
+func dddmpVarName(header map[string][]string, i, id int) string {
+	if names := header[".varnames"]; id < len(names) {
+		return names[id]
+	}
+	if names := header[".suppvarnames"]; i < len(names) {
+		return names[i]
+	}
+	return fmt.Sprintf("v%d", id)
+}
+
+func joinNames(kernel *Kernel, variables []f.Variable) string {
+	names := make([]string, len(variables))
+	for i, variable := range variables {
+		names[i], _ = kernel.fac.VarName(variable)
+	}
+	return strings.Join(names, " ")
+}
+
+func parseInts(fields []string) ([]int, error) {
+	result := make([]int, len(fields))
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, errorx.BadInput("illegal number in DDDMP file: %s", field)
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// A complementEdge references a node of a BDD with complement edges.  The
+// node with ID 1 is the constant true.
+type complementEdge struct {
+	id         int
+	complement bool
+}
+
+func (e complementEdge) signed() int {
+	if e.complement {
+		return -e.id
+	}
+	return e.id
+}
+
+type complementKey struct {
+	variable int32
+	then     int
+	elseEdge complementEdge
+}
+
+// A complementConverter converts the nodes of a BDD to nodes with complement
+// edges.  In order to keep the representation canonical, the then edges of
+// the nodes are never complemented.
+type complementConverter struct {
+	unique map[complementKey]int
+	keys   []complementKey
+}
+
+func newComplementConverter() *complementConverter {
+	return &complementConverter{unique: make(map[complementKey]int)}
+}
+
+// node returns the edge for the node with the given variable and successors
+// and whether a new node was created for it.
+func (c *complementConverter) node(variable int32, then, elseEdge complementEdge) (complementEdge, bool) {
+	complement := then.complement
+	if complement {
+		elseEdge.complement = !elseEdge.complement
+	}
+	key := complementKey{variable, then.id, elseEdge}
+	if id, ok := c.unique[key]; ok {
+		return complementEdge{id, complement}, false
+	}
+	id := len(c.keys) + 2
+	c.unique[key] = id
+	c.keys = append(c.keys, key)
+	return complementEdge{id, complement}, true
+}
@@ -49,5 +49,56 @@
 //	kernel := bdd.NewKernelWithOrdering(fac, ordering, 10, 100)
 //	bdd := bdd.CompileWithKernel(fac, formula, kernel)
 //
+// Besides the Boolean connectives, BDDs on the same kernel can be combined
+// with ITE, the relational product AndExists, the functional composition
+// Compose and VectorCompose, and the simultaneous variable renaming Rename:
+//
+//	image := transitions.AndExists(states, currentVars...).Rename(nextToCurrent)
+//
+// Random models of a BDD can be drawn uniformly or according to literal
+// weights with Sample and SampleWeighted.  TopK returns the models with the
+// smallest cost under a linear objective over the literals.
+//
+// BDDs can be stored and restored without compiling them again.  WriteBinary
+// and ReadBinary use a compact binary format which keeps the names and the
+// order of the variables.  For the exchange with other BDD libraries, the text
+// format of CUDD's DDDMP library is supported by WriteDDDMP and ReadDDDMP and
+// the format of BuDDy's bdd_save by WriteBuDDy and ReadBuDDy.  When reading
+// into an existing kernel, the BDDs are rebuilt with the kernel's order.
+//
+// Each BDD holds a reference on its nodes in the kernel.  When many BDDs are
+// compiled on the same kernel, BDDs which are no longer needed should be
+// released with Free, such that their nodes can be reused.  Alternatively,
+// ActivateAutomaticFree lets Go's garbage collector free unreachable BDDs.
+// SetNodeLimit bounds the size of the node table and Statistics reports the
+// numbers of live and dead nodes.  At the node limit, the compilation and the
+// operations with a handler, e.g. AndWithHandler, are canceled with the event
+// BddNodeLimitReached.
+//
+//	kernel.SetNodeLimit(1_000_000)
+//	for _, formula := range formulas {
+//	    bdd := bdd.CompileWithKernel(fac, formula, kernel)
+//	    count := bdd.ModelCount()
+//	    bdd.Free()
+//	}
+//
+// The variables of a kernel can be reordered with Reorder, respecting the
+// variable blocks defined by AddVariableBlock.  Besides window permutation and
+// sifting, there are symmetric sifting and group sifting, which move groups
+// of symmetric or strongly connected blocks together, and converging variants
+// of both.  Simulated annealing and a genetic algorithm search more
+// thoroughly and are meant for the offline optimization of an order which is
+// later reused with NewKernelWithOrdering.  Linear sifting additionally
+// replaces adjacent variables x and y by x xor y and y if this reduces the
+// number of nodes.  The BDDs still represent the same functions, the
+// transformations are undone automatically before an operation which depends
+// on the variables on the levels of the kernel, e.g. Exists or ToFormula.
+// ReorderWithHandler reports the
+// progress of a reordering to a handler which can bound it, e.g. by time:
+//
+//	kernel.AddAllVariablesAsBlock()
+//	kernel.ReorderWithHandler(bdd.ReorderAnnealing, handler.NewTimeoutWithDuration(time.Minute))
+//	order := b.VariableOrder()
+//
 // The BDD kernel implementation is not thread-safe.
 package bdd
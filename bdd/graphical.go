@@ -31,6 +31,7 @@ type GraphicalGenerator struct {
 // with the configuration of the generator.  The resulting representation can
 // then be exported as mermaid or graphviz graph.
 func GenerateGraphical(bdd *BDD, generator *GraphicalGenerator) *graphical.Representation {
+	bdd.Kernel.restoreVariables()
 	representation := graphical.NewGraphicalRepresentation(generator.AlignTerminals, true, generator.BackgroundColor)
 	bddGenerator := bddGenerator{
 		GraphicalGenerator: generator,
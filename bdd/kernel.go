@@ -2,6 +2,7 @@ package bdd
 
 import (
 	"math"
+	"sync"
 
 	"github.com/booleworks/logicng-go/errorx"
 	"github.com/booleworks/logicng-go/event"
@@ -63,6 +64,8 @@ type Kernel struct {
 	cachesize       int32   // Size of the operator caches
 	nodesize        int32   // Number of allocated nodes
 	maxnodeincrease int32   // Max. # of nodes used to inc. table
+	maxnodesize     int32   // Max. # of nodes in the table, 0 if unlimited
+	limitReached    bool    // Whether the current operation was aborted at the node limit
 	freepos         int32   // First free node
 	freenum         int32   // Number of free nodes
 	produced        int     // Number of newBdd nodes ever produced
@@ -76,12 +79,20 @@ type Kernel struct {
 	quantvarsetId int32   // Current id used in quantvarset
 	quantlast     int32   // Current last variable to be quant.
 
+	replacepair []int32 // Current replacement BDD for each variable, -1 if none
+	replaceid   int32   // Current id used in the replace cache
+	replacelast int32   // Current last level to be replaced
+
 	applycache   *cache // Cache for apply results
 	itecache     *cache // Cache for ITE results
 	quantcache   *cache // Cache for exist/forall results
 	appexcache   *cache // Cache for appex/appall results
 	replacecache *cache // Cache for replace results
 	misccache    *cache // Cache for other results
+
+	automaticFree bool       // Whether BDDs are freed by finalizers
+	releaseMutex  sync.Mutex // Guards the released roots
+	released      []int32    // Roots of finalized BDDs which are not yet dereferenced
 }
 
 // NewKernel constructs a newBdd BDD kernel with numVars the number of variables
@@ -160,8 +171,25 @@ func (k *Kernel) SwapVariables(first, second f.Variable) error {
 //
 // If the reordering should be performed without any restrictions,
 // AddVariableBlockAll can be called before this method.
+//
+// After a reordering with ReorderLinearSift, the levels of the kernel can
+// decide over the exclusive or of variables.  The BDDs still represent the
+// same functions and the transformations are undone before an operation
+// which depends on the single variables of the levels.
 func (k *Kernel) Reorder(method ReorderingMethod) {
-	k.reordering.reorder(method)
+	k.reordering.reorder(method, handler.NopHandler)
+}
+
+// ReorderWithHandler reorders the variables on the kernel with the given
+// reordering method and handler.  The reordering emits an
+// EventReorderingProgress with the current number of nodes after each step.
+// If the handler cancels the reordering, it stops with the order reached so
+// far, which is always a valid order of the variable blocks.  For the
+// annealing and genetic reorderings, which are meant for the offline
+// optimization of an order, the handler is the way to bound their running
+// time, e.g. by a timeout or by a target number of nodes.
+func (k *Kernel) ReorderWithHandler(method ReorderingMethod, hdl handler.Handler) handler.State {
+	return k.reordering.reorder(method, hdl)
 }
 
 // ActivateReorderDuringBuild activates automatic reordering during the BDD
@@ -256,21 +284,28 @@ func (k *Kernel) IndexForVariable(variable f.Variable) (int32, error) {
 	return index, nil
 }
 
+// setNumberOfVars sets the number of variables of the kernel.  The number
+// can only be increased, the new variables are placed on the lowest levels
+// and all existing nodes remain valid.
 func (k *Kernel) setNumberOfVars(num int32) {
-	if num < 0 || num > maxvar {
+	if num < k.varnum || num > maxvar {
 		panic(errorx.IllegalState("illegal variable number: %d", num))
 	}
 	k.reordering.disableReorder()
-	k.vars = make([]int32, num*2)
-	k.level2var = make([]int32, num+1)
-	k.var2level = make([]int32, num+1)
+	vars := make([]int32, num*2)
+	copy(vars, k.vars[:k.varnum*2])
+	k.vars = vars
+	level2var := make([]int32, num+1)
+	copy(level2var, k.level2var[:k.varnum])
+	k.level2var = level2var
+	var2level := make([]int32, num+1)
+	copy(var2level, k.var2level[:k.varnum])
+	k.var2level = var2level
 	k.refstack = make([]int32, num*2+4)
 	k.refstacktop = 0
 	for k.varnum < num {
-		node, _ := k.makeNode(k.varnum, 0, 1)
-		k.vars[k.varnum*2] = k.pushRef(node)
-		node, _ = k.makeNode(k.varnum, 1, 0)
-		k.vars[k.varnum*2+1] = node
+		k.vars[k.varnum*2] = k.pushRef(k.makeNodeUnsafe(k.varnum, 0, 1))
+		k.vars[k.varnum*2+1] = k.makeNodeUnsafe(k.varnum, 1, 0)
 		k.popref(1)
 		k.setRefcou(k.vars[k.varnum*2], maxref)
 		k.setRefcou(k.vars[k.varnum*2+1], maxref)
@@ -286,11 +321,14 @@ func (k *Kernel) setNumberOfVars(num int32) {
 	k.reordering.enableReorder()
 }
 
+// getOrAddVarIndex returns the index of the given variable.  If the variable
+// is not yet known and all variables of the kernel are in use, the kernel is
+// extended by a new variable on the lowest level.
 func (k *Kernel) getOrAddVarIndex(variable f.Variable) int32 {
 	index, ok := k.var2idx[variable]
 	if !ok {
 		if len(k.var2idx) >= int(k.varnum) {
-			panic(errorx.IllegalState("no free variables left"))
+			k.setNumberOfVars(k.varnum + 1)
 		}
 		index = int32(len(k.var2idx))
 		k.var2idx[variable] = index
@@ -312,23 +350,40 @@ func (k *Kernel) getLevel(variable f.Variable) int32 {
 	return -1
 }
 
+// doWithPotentialReordering performs the given operation and repeats it after
+// an automatic reordering if the operation requested one.  If the operation
+// was aborted because the node limit was reached, -1 is returned and the
+// abort is reported by the next call to addRef.  As long as the abort is not
+// reported, all further operations are skipped.
 func (k *Kernel) doWithPotentialReordering(operation func() (int32, bool)) int32 {
+	if k.limitReached {
+		return -1
+	}
 	k.initRef()
 	res, reorder := operation()
-	if !reorder {
+	if !reorder || k.limitReached {
 		return res
 	}
 	k.reordering.checkReorder()
 	k.initRef()
 	k.reordering.disableReorder()
 	res, reorder = operation()
-	if reorder {
+	k.reordering.enableReorder()
+	if reorder && !k.limitReached {
 		panic(errorx.IllegalState("must never happen"))
 	}
-	k.reordering.enableReorder()
 	return res
 }
 
+// doWithFixedVariables performs an operation which depends on the single
+// variables on the levels of the kernel like doWithPotentialReordering, but a
+// reordering during the operation performs no linear transformations.
+func (k *Kernel) doWithFixedVariables(operation func() (int32, bool)) int32 {
+	k.reordering.keepVariables = true
+	defer func() { k.reordering.keepVariables = false }()
+	return k.doWithPotentialReordering(operation)
+}
+
 func (k *Kernel) apply(l, r int32, op operand) int32 {
 	return k.doWithPotentialReordering(func() (int32, bool) {
 		return k.applyRec(l, r, op)
@@ -437,7 +492,14 @@ func (k *Kernel) applyRec(l, r int32, op operand) (int32, bool) {
 	return res, false
 }
 
+// addRef adds a reference to the given root node.  If the operation which
+// computed the root was aborted at the node limit, the node limit is reported
+// as cancellation instead.
 func (k *Kernel) addRef(root int32, hdl handler.Handler) (int32, handler.State) {
+	if k.limitReached {
+		k.limitReached = false
+		return -1, handler.Cancelation(event.BddNodeLimitReached)
+	}
 	if e := event.BddNewRefAdded; !hdl.ShouldResume(e) {
 		return -1, handler.Cancelation(e)
 	}
@@ -454,6 +516,35 @@ func (k *Kernel) addRef(root int32, hdl handler.Handler) (int32, handler.State)
 	return root, succ
 }
 
+// result adds a reference to the given root node and returns it as new BDD.
+func (k *Kernel) result(root int32, hdl handler.Handler) (*BDD, handler.State) {
+	bdd, state := k.addRef(root, hdl)
+	if !state.Success {
+		return nil, state
+	}
+	return newBdd(bdd, k), succ
+}
+
+// must returns the given BDD and panics if the operation which computed it
+// was canceled.  With the NopHandler this is only the case if the node limit
+// of the kernel was reached.
+func (k *Kernel) must(bdd *BDD, state handler.State) *BDD {
+	if !state.Success {
+		panic(errorx.IllegalState("node limit of %d nodes reached", k.maxnodesize))
+	}
+	return bdd
+}
+
+// literal returns the BDD node of the given literal and adds its variable to
+// the kernel if it is not yet known.
+func (k *Kernel) literal(lit f.Literal) int32 {
+	idx := k.getOrAddVarIndex(lit.Variable())
+	if lit.IsPos() {
+		return k.ithVar(idx)
+	}
+	return k.nithVar(idx)
+}
+
 func (k *Kernel) delRef(root int32) {
 	if root < 2 {
 		return
@@ -482,8 +573,14 @@ func (k *Kernel) incRef(n int32) {
 	}
 }
 
+// makeNodeUnsafe creates a node for operations which cannot be aborted and
+// panics if the node limit is reached.
 func (k *Kernel) makeNodeUnsafe(level, low, high int32) int32 {
 	node, _ := k.makeNode(level, low, high)
+	if k.limitReached {
+		k.limitReached = false
+		panic(errorx.IllegalState("node limit of %d nodes reached", k.maxnodesize))
+	}
 	return node
 }
 
@@ -504,11 +601,14 @@ func (k *Kernel) makeNode(level, low, high int32) (int32, bool) {
 		if (k.nodesize-k.freenum) >= k.reordering.usedNodesNextReorder && k.reordering.reorderReady() {
 			return -1, true
 		}
-		if (k.freenum*100)/k.nodesize <= k.minfreenodes {
+		if (k.freenum*100)/k.nodesize <= k.minfreenodes && k.canResize() {
 			k.nodeResize(true)
 			hash = nodehash(level, low, high, k.nodesize)
 		}
-		if k.freepos == 0 {
+		if k.freepos == 0 && k.maxnodesize > 0 {
+			k.limitReached = true
+			return -1, true
+		} else if k.freepos == 0 {
 			panic(errorx.IllegalState("cannot allocate more space for more nodes"))
 		}
 	}
@@ -539,6 +639,7 @@ func (k *Kernel) markCount(i int32) int {
 }
 
 func (k *Kernel) gbc() {
+	k.processReleased()
 	for r := 0; r < int(k.refstacktop); r++ {
 		k.mark(k.refstack[r])
 	}
@@ -598,7 +699,14 @@ func (k *Kernel) mark(i int32) {
 func (k *Kernel) nodeResize(doRehash bool) {
 	oldsize := k.nodesize
 	k.nodesize = min(k.nodesize<<1, oldsize+k.maxnodeincrease)
+	if k.maxnodesize > 0 {
+		k.nodesize = min(k.nodesize, k.maxnodesize)
+	}
 	k.nodesize = int32(primeLte(int(k.nodesize)))
+	if k.nodesize <= oldsize {
+		k.nodesize = oldsize
+		return
+	}
 	newnodes := make([]node, k.nodesize)
 	copy(newnodes, k.nodes)
 	k.nodes = newnodes
@@ -762,27 +870,41 @@ type node struct {
 	next   int32
 }
 
-// Statistics holds fields with internal kernel statistics.
+// Statistics holds fields with internal kernel statistics.  The used nodes
+// are split into live nodes, which are reachable from a referenced node, and
+// dead nodes, which are removed by the next garbage collection.
 type Statistics struct {
-	Produced int   // number of produced nodes
-	Nodes    int32 // number of allocated nodes in the node table
-	Free     int32 // number of free nodes in the node table
-	Vars     int32 // number of variables
-	Cache    int32 // cache size
-	GC       int32 // number of performed garbage collections
-	Used     int32 // number of used nodes
-}
-
-// Statistics returns the statistics for the kernel.
+	Produced  int   // number of produced nodes
+	Nodes     int32 // number of allocated nodes in the node table
+	Free      int32 // number of free nodes in the node table
+	Vars      int32 // number of variables
+	Cache     int32 // cache size
+	GC        int32 // number of performed garbage collections
+	Used      int32 // number of used nodes
+	Live      int32 // number of live nodes
+	Dead      int32 // number of dead nodes
+	Roots     int32 // number of referenced nodes
+	NodeLimit int32 // maximum number of nodes in the node table, 0 if unlimited
+}
+
+// Statistics returns the statistics for the kernel.  The references of BDDs
+// which were freed automatically are released before the nodes are counted.
 func (k *Kernel) Statistics() Statistics {
+	k.processReleased()
+	used := k.nodesize - k.freenum - 2
+	live, roots := k.countLiveNodes()
 	return Statistics{
-		Produced: k.produced,
-		Nodes:    k.nodesize,
-		Free:     k.freenum,
-		Vars:     k.varnum,
-		Cache:    k.cachesize,
-		GC:       k.gbcollectnum,
-		Used:     k.nodesize - k.freenum,
+		Produced:  k.produced,
+		Nodes:     k.nodesize,
+		Free:      k.freenum,
+		Vars:      k.varnum,
+		Cache:     k.cachesize,
+		GC:        k.gbcollectnum,
+		Used:      k.nodesize - k.freenum,
+		Live:      live,
+		Dead:      used - live,
+		Roots:     roots,
+		NodeLimit: k.maxnodesize,
 	}
 }
 
@@ -79,8 +79,7 @@ func (k *Kernel) fullSatOne(r int32) int32 {
 	k.initRef()
 	res := k.fullSatOneRec(r)
 	for v := k.level(r) - 1; v >= 0; v-- {
-		node, _ := k.makeNode(v, res, 0)
-		res = k.pushRef(node)
+		res = k.pushRef(k.makeNodeUnsafe(v, res, 0))
 	}
 	k.reordering.enableReorder()
 	return res
@@ -0,0 +1,98 @@
+package bdd
+
+import (
+	"runtime"
+)
+
+// Free releases the BDD's reference on the nodes of its kernel.  Nodes which
+// are not referenced by any other BDD are removed by the next garbage
+// collection of the kernel and their space is reused for new nodes.
+// Therefore, the BDD must not be used anymore after it was freed.  Freeing a
+// BDD more than once has no effect.
+//
+// Every BDD returned by the functions and methods of this package holds its
+// own reference, i.e. a BDD must be freed even if another BDD with the same
+// node index exists.
+func (b *BDD) Free() {
+	if b.freed {
+		return
+	}
+	b.freed = true
+	runtime.SetFinalizer(b, nil)
+	b.Kernel.delRef(b.Index)
+}
+
+// finalize is the finalizer of BDDs on kernels with automatic freeing.  Since
+// the kernel is not thread-safe and finalizers run on their own goroutine,
+// the reference is not released here, but only recorded and released at the
+// next garbage collection of the kernel.
+func (b *BDD) finalize() {
+	k := b.Kernel
+	k.releaseMutex.Lock()
+	k.released = append(k.released, b.Index)
+	k.releaseMutex.Unlock()
+}
+
+// ActivateAutomaticFree activates the automatic freeing of BDDs on the
+// kernel.  All BDDs which are created afterward are freed when they are no
+// longer reachable and are reclaimed by Go's garbage collector.  Explicitly
+// freeing such BDDs with Free is still possible and releases their nodes
+// earlier.
+func (k *Kernel) ActivateAutomaticFree() {
+	k.automaticFree = true
+}
+
+// SetNodeLimit sets the maximum number of nodes of the kernel's node table.
+// The node table is not extended beyond this limit and if a new node is
+// required when the limit is reached and no free nodes are left after a
+// garbage collection, the current operation is aborted.
+// CompileWithKernelAndHandler and the operations with a handler like
+// AndWithHandler or ExistsWithHandler report this as cancellation with the
+// event BddNodeLimitReached, the operations without a handler panic.  Since a
+// reordering cannot be aborted, the limit can be exceeded during a
+// reordering.  A limit of 0 removes the limit.
+func (k *Kernel) SetNodeLimit(limit int32) {
+	k.maxnodesize = max(limit, 0)
+}
+
+// GarbageCollect removes all nodes from the kernel which are not referenced
+// by a BDD anymore.  The garbage collection is also performed automatically
+// when the node table of the kernel is full.
+func (k *Kernel) GarbageCollect() {
+	k.initRef()
+	k.gbc()
+}
+
+func (k *Kernel) canResize() bool {
+	return k.maxnodesize == 0 || k.nodesize < k.maxnodesize
+}
+
+// processReleased releases the references of all BDDs which were finalized
+// since the last garbage collection.
+func (k *Kernel) processReleased() {
+	k.releaseMutex.Lock()
+	released := k.released
+	k.released = nil
+	k.releaseMutex.Unlock()
+	for _, root := range released {
+		k.delRef(root)
+	}
+}
+
+// countLiveNodes returns the number of nodes which are reachable from a
+// referenced node and the number of referenced nodes.
+func (k *Kernel) countLiveNodes() (live, roots int32) {
+	for n := int32(2); n < k.nodesize; n++ {
+		if k.low(n) != -1 && k.refcou(n) > 0 {
+			roots++
+			k.mark(n)
+		}
+	}
+	for n := int32(2); n < k.nodesize; n++ {
+		if k.marked(n) {
+			live++
+			k.unmarkNode(n)
+		}
+	}
+	return live, roots
+}
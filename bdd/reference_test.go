@@ -0,0 +1,282 @@
+package bdd
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFree(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	kernel := NewKernelWithOrdering(fac, fac.Vars("a", "b", "c", "d"), 100, 100)
+	varNodes := int32(8)
+
+	bdd1 := CompileWithKernel(fac, p.ParseUnsafe("(a | b) & (c | d)"), kernel)
+	bdd2 := CompileWithKernel(fac, p.ParseUnsafe("a => (b <=> d)"), kernel)
+	stats := kernel.Statistics()
+	assert.Equal(stats.Used-2, stats.Live+stats.Dead)
+	live := stats.Live
+
+	bdd1.Free()
+	bdd1.Free()
+	stats = kernel.Statistics()
+	assert.Less(stats.Live, live)
+	assert.Equal(bdd2.NodeCount(), int(stats.Live-varNodes)+countVarNodes(kernel, bdd2))
+	kernel.GarbageCollect()
+	stats = kernel.Statistics()
+	assert.Equal(int32(0), stats.Dead)
+
+	bdd2.Free()
+	kernel.GarbageCollect()
+	stats = kernel.Statistics()
+	assert.Equal(varNodes, stats.Live)
+	assert.Equal(varNodes, stats.Roots)
+	assert.Equal(int32(0), stats.Dead)
+}
+
+func TestFreeDerivedBDDs(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c, d := fac.Var("a"), fac.Var("b"), fac.Var("c"), fac.Var("d")
+	kernel := NewKernelWithOrdering(fac, []f.Variable{a, b, c, d}, 100, 100)
+	bdd := CompileWithKernel(fac, p.ParseUnsafe("(a | b) & (c | d) | a & ~d"), kernel)
+	other := CompileWithKernel(fac, p.ParseUnsafe("b | ~c"), kernel)
+	negated := bdd.Negate()
+
+	derived := []*BDD{
+		negated, bdd.And(other), bdd.Or(other), bdd.Implies(other), bdd.ImpliedBy(other),
+		bdd.Equivalence(other), bdd.Restrict(a.Negate(fac), c.AsLiteral()), bdd.Exists(a, b),
+		bdd.ForAll(c, d), bdd.AndExists(other, b), bdd.ITE(other, negated), bdd.Compose(a, other),
+		bdd.Rename(map[f.Variable]f.Variable{a: c, c: a}),
+	}
+	_, _ = bdd.ModelWithVariables(true, a, b, c, d)
+	for _, derivedBDD := range derived {
+		derivedBDD.Free()
+	}
+	bdd.Free()
+	other.Free()
+	kernel.GarbageCollect()
+	stats := kernel.Statistics()
+	assert.Equal(int32(8), stats.Live)
+	assert.Equal(int32(0), stats.Dead)
+}
+
+func TestFreeBoundsNodeTable(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 12
+	r := randomizer.New(fac, config)
+	vars := make([]f.Variable, config.NumVars)
+	for i := range vars {
+		vars[i] = fac.Var("v" + string(rune('a'+i)))
+	}
+	formulas := make([]f.Formula, 200)
+	for i := range formulas {
+		formulas[i] = r.Formula(4)
+	}
+
+	kernel := NewKernel(fac, int32(len(vars)), 100, 100)
+	kernel.SetNodeLimit(2000)
+	for _, formula := range formulas {
+		bdd := CompileWithKernel(fac, formula, kernel)
+		assertEquivalent(assert, fac, formula, bdd)
+		bdd.Free()
+	}
+	stats := kernel.Statistics()
+	assert.LessOrEqual(stats.Nodes, int32(2000))
+	assert.Equal(int32(2000), stats.NodeLimit)
+
+	notFreed := NewKernel(fac, int32(len(vars)), 100, 100)
+	notFreed.SetNodeLimit(2000)
+	assert.Panics(func() {
+		for _, formula := range formulas {
+			CompileWithKernel(fac, formula, notFreed)
+		}
+	})
+}
+
+func TestNodeLimitCancelation(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 12
+	r := randomizer.New(fac, config)
+	formulas := make([]f.Formula, 200)
+	for i := range formulas {
+		formulas[i] = r.Formula(4)
+	}
+	vars := f.Variables(fac, formulas...).Content()
+	kernel := NewKernelWithOrdering(fac, vars, 100, 100)
+	kernel.SetNodeLimit(300)
+
+	var kept []*BDD
+	canceled := 0
+	for _, formula := range formulas {
+		bdd, state := CompileWithKernelAndHandler(fac, formula, kernel, handler.NopHandler)
+		if !state.Success {
+			assert.Nil(bdd)
+			assert.Equal(event.BddNodeLimitReached, state.CancelCause)
+			canceled++
+			continue
+		}
+		assertEquivalent(assert, fac, formula, bdd)
+		kept = append(kept, bdd)
+	}
+	assert.Greater(canceled, 0)
+	assert.LessOrEqual(kernel.Statistics().Nodes, int32(300))
+
+	for _, bdd := range kept {
+		bdd.Free()
+	}
+	kernel.GarbageCollect()
+	assert.Equal(int32(2*len(vars)), kernel.Statistics().Live)
+	formula := fac.Or(fac.And(vars[0].AsFormula(), vars[1].AsFormula()), vars[2].AsFormula())
+	bdd, state := CompileWithKernelAndHandler(fac, formula, kernel, handler.NopHandler)
+	assert.True(state.Success)
+	assertEquivalent(assert, fac, formula, bdd)
+	bdd.Free()
+	kernel.GarbageCollect()
+	assert.Equal(int32(2*len(vars)), kernel.Statistics().Live)
+}
+
+func TestOperationNodeLimitCancelation(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 12
+	r := randomizer.New(fac, config)
+	formulas := make([]f.Formula, 100)
+	for i := range formulas {
+		formulas[i] = r.Formula(3)
+	}
+	vars := f.Variables(fac, formulas...).Content()
+	kernel := NewKernelWithOrdering(fac, vars, 100, 100)
+	kernel.SetNodeLimit(500)
+	var kept []f.Formula
+	var bdds []*BDD
+	for _, formula := range formulas {
+		if bdd, state := CompileWithKernelAndHandler(fac, formula, kernel, handler.NopHandler); state.Success {
+			kept = append(kept, formula)
+			bdds = append(bdds, bdd)
+		}
+	}
+
+	canceled := 0
+	for i := 1; i < len(bdds); i++ {
+		conjunction, state := bdds[i-1].AndWithHandler(bdds[i], handler.NopHandler)
+		if !state.Success {
+			assert.Nil(conjunction)
+			assert.Equal(event.BddNodeLimitReached, state.CancelCause)
+			assert.Panics(func() { bdds[i-1].And(bdds[i]) })
+			canceled++
+			continue
+		}
+		assertEquivalent(assert, fac, fac.And(kept[i-1], kept[i]), conjunction)
+		conjunction.Free()
+	}
+	assert.Greater(canceled, 0)
+
+	for _, bdd := range bdds[1:] {
+		bdd.Free()
+	}
+	projected, state := bdds[0].ExistsWithHandler(handler.NopHandler, vars[0])
+	assert.True(state.Success)
+	positive := bdds[0].Restrict(vars[0].AsLiteral())
+	negative := bdds[0].Restrict(vars[0].Negate(fac))
+	expected := positive.Or(negative)
+	assert.Equal(expected.Index, projected.Index)
+	positive.Free()
+	negative.Free()
+	expected.Free()
+	projected.Free()
+	bdds[0].Free()
+	kernel.GarbageCollect()
+	assert.Equal(int32(2*len(vars)), kernel.Statistics().Live)
+}
+
+func TestAutomaticFree(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	kernel := NewKernelWithOrdering(fac, fac.Vars("a", "b", "c", "d"), 100, 100)
+	kernel.ActivateAutomaticFree()
+	kept := CompileWithKernel(fac, p.ParseUnsafe("a & b | c & d"), kernel)
+	for range 100 {
+		bdd := CompileWithKernel(fac, p.ParseUnsafe("(a | ~b) & (c <=> d)"), kernel)
+		bdd.Negate().And(kept).Exists(fac.Var("a"))
+	}
+	explicit := CompileWithKernel(fac, p.ParseUnsafe("a | d"), kernel)
+	explicit.Free()
+
+	expected := int32(8) + int32(kept.NodeCount()) - int32(countVarNodes(kernel, kept))
+	var stats Statistics
+	for range 100 {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+		if stats = kernel.Statistics(); stats.Live == expected {
+			break
+		}
+	}
+	assert.Equal(expected, stats.Live)
+	assertEquivalent(assert, fac, p.ParseUnsafe("a & b | c & d"), kept)
+	runtime.KeepAlive(kept)
+}
+
+func TestFreeAfterReordering(t *testing.T) {
+	assert := assert.New(t)
+	for i := range 10 {
+		fac := f.NewFactory()
+		config := randomizer.DefaultConfig()
+		config.Seed = int64(i)
+		config.NumVars = 8
+		r := randomizer.New(fac, config)
+		kernel := NewKernelWithOrdering(fac, fac.Vars("v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7"), 20, 20)
+		kernel.AddAllVariablesAsBlock()
+		kernel.ActivateReorderDuringBuild(ReorderSift, 1000)
+		formulas := []f.Formula{r.Formula(4), r.Formula(4), r.Formula(3)}
+		bdds := make([]*BDD, len(formulas))
+		for j, formula := range formulas {
+			bdds[j] = CompileWithKernel(fac, formula, kernel)
+		}
+		// the root of the quantified BDD is a child of the conjunction's root
+		quantified := bdds[0].Exists(fac.Var("v0"))
+		conjunction := CompileWithKernel(fac, fac.Variable("v0"), kernel).And(quantified)
+		formulas = append(formulas, quantified.CNF(), fac.And(fac.Variable("v0"), quantified.CNF()))
+		bdds = append(bdds, quantified, conjunction)
+		kernel.Reorder(ReorderWin2)
+		for range 5 {
+			CompileWithKernel(fac, r.Formula(4), kernel).Free()
+		}
+		for j, bdd := range bdds {
+			assertEquivalent(assert, fac, formulas[j], bdd)
+			bdd.Free()
+		}
+		kernel.GarbageCollect()
+		stats := kernel.Statistics()
+		assert.Equal(int32(16), stats.Live)
+		assert.Equal(int32(0), stats.Dead)
+	}
+}
+
+// countVarNodes returns the number of nodes of the BDD which are also
+// variable nodes of the kernel.
+func countVarNodes(kernel *Kernel, bdd *BDD) int {
+	count := 0
+	for _, node := range kernel.allNodes(bdd.Index) {
+		if kernel.refcou(node[0]) == maxref {
+			count++
+		}
+	}
+	return count
+}
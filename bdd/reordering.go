@@ -6,23 +6,44 @@ import (
 	"sort"
 
 	"github.com/booleworks/logicng-go/errorx"
+	"github.com/booleworks/logicng-go/event"
+	"github.com/booleworks/logicng-go/handler"
 )
 
 type ReorderingMethod byte
 
 const (
-	ReorderNone    ReorderingMethod = iota // no reordering
-	ReorderWin2                            // sliding window of size 2
-	ReorderWin2Ite                         // sliding window of size 2 iterative
-	ReorderSift                            // sifting
-	ReorderSiftIte                         // iterative sifting
-	ReorderWin3                            // sliding window of size 3
-	ReorderWin3Ite                         // sliding window of size 3 iterative
-	ReorderRandom                          // random reordering (should only be used for testing)
+	ReorderNone          ReorderingMethod = iota // no reordering
+	ReorderWin2                                  // sliding window of size 2
+	ReorderWin2Ite                               // sliding window of size 2 iterative
+	ReorderSift                                  // sifting
+	ReorderSiftIte                               // iterative sifting
+	ReorderWin3                                  // sliding window of size 3
+	ReorderWin3Ite                               // sliding window of size 3 iterative
+	ReorderRandom                                // random reordering (should only be used for testing)
+	ReorderSymSift                               // symmetric sifting
+	ReorderSymSiftConv                           // symmetric sifting until convergence
+	ReorderGroupSift                             // group sifting
+	ReorderGroupSiftConv                         // group sifting until convergence
+	ReorderAnnealing                             // simulated annealing
+	ReorderGenetic                               // genetic algorithm
+	ReorderLinearSift                            // sifting with linear transformations
 )
 
 //go:generate stringer -type=ReorderingMethod
 
+// EventReorderingProgress is emitted by a reordering after each step.  It
+// holds the reordering method and the number of nodes currently used by the
+// kernel.
+type EventReorderingProgress struct {
+	Method ReorderingMethod
+	Nodes  int
+}
+
+func (EventReorderingProgress) EventType() string {
+	return "BDD Reordering Progress"
+}
+
 type reordering struct {
 	k *Kernel
 
@@ -41,6 +62,12 @@ type reordering struct {
 	usednumAfter         int32
 	resizedInMakenode    bool
 	usedNodesNextReorder int32
+
+	linear        []linearTransformation // linear transformations which are not yet undone
+	keepVariables bool                   // forbids linear transformations during an operation
+
+	hdl   handler.Handler
+	state handler.State
 }
 
 func newReordering(k *Kernel) *reordering {
@@ -51,6 +78,8 @@ func newReordering(k *Kernel) *reordering {
 		usednumBefore:   0,
 		usednumAfter:    0,
 		blockId:         0,
+		hdl:             handler.NopHandler,
+		state:           handler.Success(),
 	}
 	r.clrVarBlocks()
 	r.setReorderDuringConstruction(ReorderNone, 0)
@@ -98,14 +127,30 @@ func (r *reordering) swapVariables(v1, v2 int32) {
 	r.reorderDone()
 }
 
-func (r *reordering) reorder(method ReorderingMethod) {
+func (r *reordering) reorder(method ReorderingMethod, hdl handler.Handler) handler.State {
+	if !hdl.ShouldResume(event.BddReorderingStarted) {
+		return handler.Cancelation(event.BddReorderingStarted)
+	}
 	savemethod := r.reorderMethod
 	savetimes := r.bddreorderTimes
 	r.reorderMethod = method
 	r.bddreorderTimes = 1
+	r.hdl = hdl
+	r.state = handler.Success()
+	if method == ReorderLinearSift && r.keepVariables {
+		method = ReorderSift
+	}
+	if method != ReorderLinearSift {
+		r.removeLinearTransformations()
+	}
+	defer func() {
+		r.reorderMethod = savemethod
+		r.bddreorderTimes = savetimes
+		r.hdl = handler.NopHandler
+	}()
 	top := newBddTree(-1)
 	if r.reorderInit() < 0 {
-		return
+		return r.state
 	}
 	r.usednumBefore = r.k.nodesize - r.k.freenum
 	top.first = 0
@@ -118,8 +163,21 @@ func (r *reordering) reorder(method ReorderingMethod) {
 	r.varTree = top.nextlevel
 	r.usednumAfter = r.k.nodesize - r.k.freenum
 	r.reorderDone()
-	r.reorderMethod = savemethod
-	r.bddreorderTimes = savetimes
+	return r.state
+}
+
+// shouldResume reports the current number of nodes to the handler of the
+// reordering.  Once the handler canceled the reordering, all further calls
+// return false and the algorithms stop with the order reached so far.
+func (r *reordering) shouldResume() bool {
+	if !r.state.Success {
+		return false
+	}
+	e := EventReorderingProgress{r.reorderMethod, int(r.reorderNodenum())}
+	if !r.hdl.ShouldResume(e) {
+		r.state = handler.Cancelation(e)
+	}
+	return r.state.Success
 }
 
 func (r *reordering) setReorderDuringConstruction(method ReorderingMethod, num int32) {
@@ -131,6 +189,7 @@ func (r *reordering) addVariableBlock(first, last int32, fixed bool) {
 	if first < 0 || first >= r.k.varnum || last < 0 || last >= r.k.varnum {
 		panic(errorx.IllegalState("invalid var range from %d to %d", first, last))
 	}
+	r.removeLinearTransformations()
 	t := addRange(r.varTree, first, last, fixed, r.blockId, r.k.level2var)
 	if t == nil {
 		panic(errorx.IllegalState("could not add range to tree"))
@@ -161,7 +220,7 @@ func (r *reordering) reorderBlock(t *bddTree, method ReorderingMethod) {
 	if t == nil {
 		return
 	}
-	if !t.fixed && t.nextlevel != nil {
+	if !t.fixed && t.nextlevel != nil && r.state.Success {
 		switch method {
 		case ReorderWin2:
 			t.nextlevel = r.reorderWin2(t.nextlevel)
@@ -177,6 +236,20 @@ func (r *reordering) reorderBlock(t *bddTree, method ReorderingMethod) {
 			t.nextlevel = r.reorderWin3ite(t.nextlevel)
 		case ReorderRandom:
 			t.nextlevel = r.reorderRandom(t.nextlevel)
+		case ReorderSymSift:
+			t.nextlevel = r.reorderSymSift(t.nextlevel)
+		case ReorderSymSiftConv:
+			t.nextlevel = r.reorderConverge(t.nextlevel, r.reorderSymSift)
+		case ReorderGroupSift:
+			t.nextlevel = r.reorderGroupSift(t.nextlevel)
+		case ReorderGroupSiftConv:
+			t.nextlevel = r.reorderConverge(t.nextlevel, r.reorderGroupSift)
+		case ReorderAnnealing:
+			t.nextlevel = r.reorderAnnealing(t.nextlevel)
+		case ReorderGenetic:
+			t.nextlevel = r.reorderGenetic(t.nextlevel)
+		case ReorderLinearSift:
+			t.nextlevel = r.reorderLinearSift(t.nextlevel)
 		}
 	}
 	for thisTree := t.nextlevel; thisTree != nil; thisTree = thisTree.next {
@@ -195,12 +268,21 @@ func (r *reordering) reorderBlock(t *bddTree, method ReorderingMethod) {
 }
 
 func (r *reordering) reorderDone() {
+	// During the reordering the reference count of a node also contains the
+	// references of its parents, these have to be removed again such that the
+	// external roots are left with their external references.
+	for n := int32(2); n < r.k.nodesize; n++ {
+		if r.k.low(n) != -1 {
+			r.k.decRef(r.k.low(n))
+			r.k.decRef(r.k.high(n))
+		}
+	}
 	for n := int32(0); n < r.extRootSize; n++ {
 		r.k.setMark(r.extRoots[n])
 	}
 	for n := int32(2); n < r.k.nodesize; n++ {
 		if r.k.marked(n) {
-			r.k.unmark(n)
+			r.k.unmarkNode(n)
 		} else {
 			r.k.setRefcou(n, 0)
 		}
@@ -216,7 +298,7 @@ func (r *reordering) reorderWin2(t *bddTree) *bddTree {
 	if t == nil {
 		return nil
 	}
-	for thisTree.next != nil {
+	for thisTree.next != nil && r.shouldResume() {
 		best := r.reorderNodenum()
 		r.blockdown(thisTree)
 		if best < r.reorderNodenum() {
@@ -236,10 +318,10 @@ func (r *reordering) reorderWin2ite(t *bddTree) *bddTree {
 		return nil
 	}
 	var lastsize int32
-	for ok := true; ok; ok = r.reorderNodenum() != lastsize {
+	for ok := true; ok; ok = r.reorderNodenum() != lastsize && r.state.Success {
 		lastsize = r.reorderNodenum()
 		thisTree = t
-		for thisTree.next != nil {
+		for thisTree.next != nil && r.shouldResume() {
 			best := r.reorderNodenum()
 			r.blockdown(thisTree)
 			if best < r.reorderNodenum() {
@@ -260,7 +342,7 @@ func (r *reordering) reorderWin3(t *bddTree) *bddTree {
 		return nil
 	}
 
-	for thisTree.next != nil {
+	for thisTree.next != nil && r.shouldResume() {
 		_1, _2 := r.reorderSwapwin3(thisTree)
 		thisTree = _1
 		if _2 != nil {
@@ -279,10 +361,10 @@ func (r *reordering) reorderWin3ite(t *bddTree) *bddTree {
 		return nil
 	}
 
-	for ok := true; ok; ok = r.reorderNodenum() != lastsize {
+	for ok := true; ok; ok = r.reorderNodenum() != lastsize && r.state.Success {
 		lastsize = r.reorderNodenum()
 		thisTree = first
-		for thisTree.next != nil && thisTree.next.next != nil {
+		for thisTree.next != nil && thisTree.next.next != nil && r.shouldResume() {
 			_1, _2 := r.reorderSwapwin3(thisTree)
 			thisTree = _1
 			if _2 != nil {
@@ -407,7 +489,7 @@ func (r *reordering) reorderSiftite(t *bddTree) *bddTree {
 	if t == nil {
 		return nil
 	}
-	for ok := true; ok; ok = r.reorderNodenum() != lastsize {
+	for ok := true; ok; ok = r.reorderNodenum() != lastsize && r.state.Success {
 		lastsize = r.reorderNodenum()
 		first = r.reorderSift(first)
 	}
@@ -415,6 +497,12 @@ func (r *reordering) reorderSiftite(t *bddTree) *bddTree {
 }
 
 func (r *reordering) reorderSift(t *bddTree) *bddTree {
+	return r.reorderSiftWith(t, r.reorderSiftBestpos)
+}
+
+// reorderSiftWith sifts the blocks in the order of their number of nodes,
+// each block is moved to its best position by the given function.
+func (r *reordering) reorderSiftWith(t *bddTree, bestpos func(blk *bddTree, middlePos int32)) *bddTree {
 	var thisTree *bddTree
 	var seq []*bddTree
 	var p []bddSizePair
@@ -449,18 +537,20 @@ func (r *reordering) reorderSift(t *bddTree) *bddTree {
 	}
 
 	// Do the sifting on this sequence
-	t = r.reorderSiftSeq(t, seq, num)
+	t = r.reorderSiftSeq(t, seq, num, bestpos)
 
 	return t
 }
 
-func (r *reordering) reorderSiftSeq(t *bddTree, seq []*bddTree, num int32) *bddTree {
+func (r *reordering) reorderSiftSeq(
+	t *bddTree, seq []*bddTree, num int32, bestpos func(blk *bddTree, middlePos int32),
+) *bddTree {
 	var thisTree *bddTree
 	if t == nil {
 		return nil
 	}
-	for n := 0; n < int(num); n++ {
-		r.reorderSiftBestpos(seq[n], num/2)
+	for n := 0; n < int(num) && r.shouldResume(); n++ {
+		bestpos(seq[n], num/2)
 	}
 	// Find first block
 	for thisTree = t; thisTree.prev != nil; thisTree = thisTree.prev {
@@ -535,7 +625,7 @@ func (r *reordering) reorderRandom(t *bddTree) *bddTree {
 		num++
 	}
 
-	for n := 0; n < 4*num; n++ {
+	for n := 0; n < 4*num && r.shouldResume(); n++ {
 		blk := rand.Intn(num)
 		if seq[blk].next != nil {
 			r.blockdown(seq[blk])
@@ -738,8 +828,12 @@ func (r *reordering) reorderMakenode(variable, low, high int32) int32 {
 	if r.k.freepos == 0 {
 		// Try to allocate more nodes - call noderesize without enabling
 		// rehashing. Note: if ever rehashing is allowed here, then remember
-		// to update local variable "hash"
+		// to update local variable "hash".  The node limit is not enforced
+		// here, since a reordering cannot be aborted.
+		limit := r.k.maxnodesize
+		r.k.maxnodesize = 0
 		r.k.nodeResize(false)
+		r.k.maxnodesize = limit
 		r.resizedInMakenode = true
 	}
 
@@ -843,7 +937,7 @@ func (r *reordering) reorderAuto() {
 	if !r.reorderReady() {
 		return
 	}
-	r.reorder(r.reorderMethod)
+	r.reorder(r.reorderMethod, handler.NopHandler)
 	r.bddreorderTimes--
 }
 
@@ -0,0 +1,238 @@
+package bdd
+
+import (
+	"slices"
+	"sort"
+)
+
+// Percentage of nodes which may violate the extended symmetry of two
+// variables for them to be aggregated in group sifting.
+const groupSiftTolerance = 10
+
+// A blockGroup is a sequence of adjacent blocks on the same level of the
+// block tree.  During symmetric and group sifting, a group is always moved as
+// a whole.
+type blockGroup struct {
+	blocks []*bddTree
+	merged bool
+}
+
+func (r *reordering) reorderSymSift(t *bddTree) *bddTree {
+	return r.reorderGroupSiftWith(t, r.symmetricGroups)
+}
+
+func (r *reordering) reorderGroupSift(t *bddTree) *bddTree {
+	return r.reorderGroupSiftWith(t, r.attractingGroups)
+}
+
+func (r *reordering) reorderConverge(t *bddTree, method func(*bddTree) *bddTree) *bddTree {
+	first := t
+	var lastsize int32
+	if t == nil {
+		return nil
+	}
+	for ok := true; ok; ok = r.reorderNodenum() < lastsize && r.state.Success {
+		lastsize = r.reorderNodenum()
+		first = method(first)
+	}
+	return first
+}
+
+// reorderGroupSiftWith sifts groups of blocks instead of single blocks.  At
+// first, each block forms its own group.  Adjacent groups are aggregated when
+// the given check holds for them, initially and each time a group was moved
+// to its best position.
+func (r *reordering) reorderGroupSiftWith(t *bddTree, aggregate func(upper, lower *blockGroup) bool) *bddTree {
+	var thisTree *bddTree
+	if t == nil {
+		return nil
+	}
+	var groups []*blockGroup
+	for thisTree = t; thisTree != nil; thisTree = thisTree.next {
+		groups = append(groups, &blockGroup{blocks: []*bddTree{thisTree}})
+	}
+	for pos := len(groups) - 2; pos >= 0; pos-- {
+		groups = r.aggregateGroups(groups, pos, aggregate)
+	}
+
+	// Sort according to the number of nodes of each group
+	seq := slices.Clone(groups)
+	sizes := make(map[*blockGroup]int32, len(seq))
+	for _, g := range seq {
+		sizes[g] = r.groupNodenum(g)
+	}
+	sort.SliceStable(seq, func(i, j int) bool { return sizes[seq[i]] > sizes[seq[j]] })
+
+	for _, g := range seq {
+		if g.merged {
+			continue
+		}
+		if !r.shouldResume() {
+			break
+		}
+		pos := r.siftGroupBestpos(groups, slices.Index(groups, g))
+		groups = r.aggregateGroups(groups, pos, aggregate)
+		if pos > 0 {
+			groups = r.aggregateGroups(groups, pos-1, aggregate)
+		}
+	}
+
+	// Find first block
+	for thisTree = t; thisTree.prev != nil; thisTree = thisTree.prev {
+	}
+	return thisTree
+}
+
+// aggregateGroups merges the group at position pos with its successor if the
+// given check holds for them.
+func (r *reordering) aggregateGroups(
+	groups []*blockGroup,
+	pos int,
+	aggregate func(upper, lower *blockGroup) bool,
+) []*blockGroup {
+	if pos+1 >= len(groups) || !aggregate(groups[pos], groups[pos+1]) {
+		return groups
+	}
+	groups[pos].blocks = append(groups[pos].blocks, groups[pos+1].blocks...)
+	groups[pos+1].merged = true
+	return slices.Delete(groups, pos+1, pos+2)
+}
+
+// siftGroupBestpos moves the group at position pos up and down and finally to
+// the position with the least number of nodes, which is returned.
+func (r *reordering) siftGroupBestpos(groups []*blockGroup, pos int) int {
+	best := r.reorderNodenum()
+	maxAllowed := best/5 + best
+	bestpos := pos
+	dirIsUp := pos <= len(groups)/2
+
+	// Move group back and forth
+	for range 2 {
+		first := true
+
+		if dirIsUp {
+			for pos > 0 && (r.reorderNodenum() <= maxAllowed || first) {
+				first = false
+				r.groupdown(groups, pos-1)
+				pos--
+				if r.reorderNodenum() < best {
+					best = r.reorderNodenum()
+					bestpos = pos
+					maxAllowed = best/5 + best
+				}
+			}
+		} else {
+			for pos < len(groups)-1 && (r.reorderNodenum() <= maxAllowed || first) {
+				first = false
+				r.groupdown(groups, pos)
+				pos++
+				if r.reorderNodenum() < best {
+					best = r.reorderNodenum()
+					bestpos = pos
+					maxAllowed = best/5 + best
+				}
+			}
+		}
+		dirIsUp = !dirIsUp
+	}
+
+	// Move to best pos
+	for pos < bestpos {
+		r.groupdown(groups, pos)
+		pos++
+	}
+	for pos > bestpos {
+		r.groupdown(groups, pos-1)
+		pos--
+	}
+	return pos
+}
+
+// groupdown moves the group at position pos below its successor.
+func (r *reordering) groupdown(groups []*blockGroup, pos int) {
+	upper := groups[pos]
+	lower := groups[pos+1]
+	for i := len(upper.blocks) - 1; i >= 0; i-- {
+		for range lower.blocks {
+			r.blockdown(upper.blocks[i])
+		}
+	}
+	groups[pos], groups[pos+1] = lower, upper
+}
+
+func (r *reordering) groupNodenum(g *blockGroup) int32 {
+	num := int32(0)
+	for _, blk := range g.blocks {
+		for v := blk.first; v <= blk.last; v++ {
+			num += r.levels[v].nodenum
+		}
+	}
+	return num
+}
+
+// symmetricGroups holds if the adjacent groups consist of single variables
+// and the two variables at their border are symmetric.
+func (r *reordering) symmetricGroups(upper, lower *blockGroup) bool {
+	x := upper.blocks[len(upper.blocks)-1]
+	y := lower.blocks[0]
+	if x.first != x.last || y.first != y.last {
+		return false
+	}
+	return r.symmetric(x.first, y.first, false, 0)
+}
+
+// attractingGroups holds if the two variables at the border of the adjacent
+// groups are symmetric or negatively symmetric for nearly all of their nodes.
+func (r *reordering) attractingGroups(upper, lower *blockGroup) bool {
+	x := upper.blocks[len(upper.blocks)-1]
+	y := lower.blocks[0]
+	return r.symmetric(x.seq[len(x.seq)-1], y.seq[0], true, groupSiftTolerance)
+}
+
+// symmetric checks whether the variable x and the variable y directly below
+// it are symmetric in all functions represented by the kernel, i.e. whether
+// swapping the values of x and y does not change any function.  In the
+// extended check, also negative symmetry is accepted, i.e. swapping and
+// negating the values.  The tolerance is the percentage of nodes of x which
+// may violate the symmetry and of references to nodes of y which may come
+// from other nodes than the ones of x.  Nodes with a saturated reference
+// count, like the nodes of the variables themselves, are ignored.
+func (r *reordering) symmetric(x, y int32, extended bool, tolerance int) bool {
+	var xnodes, violations, arcs, yrefs int
+	for n := range r.levels[x].size {
+		for q := r.k.hash(n + r.levels[x].start); q != 0; q = r.k.next(q) {
+			if r.k.refcou(q) == 0 || r.k.refcou(q) == maxref {
+				continue
+			}
+			xnodes++
+			f0 := r.k.low(q)
+			f1 := r.k.high(q)
+			f00, f01, f10, f11 := f0, f0, f1, f1
+			if r.vari(f0) == y {
+				f00 = r.k.low(f0)
+				f01 = r.k.high(f0)
+				if r.k.refcou(f0) != maxref {
+					arcs++
+				}
+			}
+			if r.vari(f1) == y {
+				f10 = r.k.low(f1)
+				f11 = r.k.high(f1)
+				if r.k.refcou(f1) != maxref {
+					arcs++
+				}
+			}
+			if f01 != f10 && (!extended || f00 != f11) {
+				violations++
+			}
+		}
+	}
+	for n := range r.levels[y].size {
+		for q := r.k.hash(n + r.levels[y].start); q != 0; q = r.k.next(q) {
+			if r.k.refcou(q) != maxref {
+				yrefs += int(r.k.refcou(q))
+			}
+		}
+	}
+	return violations*100 <= tolerance*xnodes && (yrefs-arcs)*100 <= tolerance*yrefs
+}
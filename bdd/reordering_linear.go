@@ -0,0 +1,277 @@
+package bdd
+
+// A linearTransformation records that the variable upper, which was directly
+// above the variable lower, was replaced by the exclusive or of both
+// variables.
+type linearTransformation struct {
+	upper int32
+	lower int32
+}
+
+// A linearMove is a move of a block by one position during linear sifting,
+// optionally followed by a linear transformation of the two variables which
+// became adjacent by the move.
+type linearMove struct {
+	down   bool
+	linear bool
+}
+
+// reorderLinearSift performs a sifting where after each move of a block of a
+// single variable, the two adjacent variables are linearly transformed if
+// this reduces the number of nodes.
+//
+// A linear transformation replaces the upper variable x of two adjacent
+// variables x and y by x xor y.  The nodes are transformed in place, such that
+// each node still represents the same function, but the nodes on the level of
+// x now decide over x xor y.  All transformations are recorded and undone by
+// removeLinearTransformations before an operation which depends on the single
+// variables on the levels of the kernel is performed.
+func (r *reordering) reorderLinearSift(t *bddTree) *bddTree {
+	return r.reorderSiftWith(t, r.reorderLinearSiftBestpos)
+}
+
+func (r *reordering) reorderLinearSiftBestpos(blk *bddTree, middlePos int32) {
+	best := r.reorderNodenum()
+	maxAllowed := best/5 + best
+	bestPass, bestLen := 0, 0
+	dirIsUp := blk.pos <= middlePos
+
+	// Move block back and forth.  Since the moves include linear
+	// transformations, the block does not return to its position by moving it
+	// in the other direction, therefore the moves of the first pass are undone
+	// before the second pass.
+	var passes [2][]linearMove
+	for pass := range passes {
+		first := true
+		for (dirIsUp && blk.prev != nil || !dirIsUp && blk.next != nil) &&
+			(r.reorderNodenum() <= maxAllowed || first) {
+			first = false
+			passes[pass] = append(passes[pass], r.linearSiftMove(blk, !dirIsUp))
+			if r.reorderNodenum() < best {
+				best = r.reorderNodenum()
+				bestPass, bestLen = pass, len(passes[pass])
+				maxAllowed = best/5 + best
+			}
+		}
+		if pass == 0 {
+			r.undoLinearMoves(blk, passes[0])
+		}
+		dirIsUp = !dirIsUp
+	}
+
+	// Move to best pos
+	if bestPass == 1 {
+		r.undoLinearMoves(blk, passes[1][bestLen:])
+		return
+	}
+	r.undoLinearMoves(blk, passes[1])
+	for _, move := range passes[0][:bestLen] {
+		r.redoLinearMove(blk, move)
+	}
+}
+
+// linearSiftMove moves the block by one position and linearly transforms the
+// two variables which became adjacent if both are blocks of single variables
+// and the transformation reduces the number of nodes.
+func (r *reordering) linearSiftMove(blk *bddTree, down bool) linearMove {
+	move := linearMove{down: down}
+	upper, lower, ok := r.siftBlock(blk, down)
+	if !ok {
+		return move
+	}
+	size := r.reorderNodenum()
+	r.reorderLinear(upper)
+	if r.reorderNodenum() < size {
+		move.linear = true
+		r.linear = append(r.linear, linearTransformation{upper, lower})
+	} else {
+		r.reorderLinear(upper)
+	}
+	return move
+}
+
+// redoLinearMove performs the given move again after it was undone.
+func (r *reordering) redoLinearMove(blk *bddTree, move linearMove) {
+	upper, lower, _ := r.siftBlock(blk, move.down)
+	if move.linear {
+		r.reorderLinear(upper)
+		r.linear = append(r.linear, linearTransformation{upper, lower})
+	}
+}
+
+// undoLinearMoves undoes the given moves of the block in reverse order.
+func (r *reordering) undoLinearMoves(blk *bddTree, moves []linearMove) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		upper := blk
+		if moves[i].down {
+			upper = blk.prev
+		}
+		if moves[i].linear {
+			r.reorderLinear(upper.first)
+			r.linear = r.linear[:len(r.linear)-1]
+		}
+		r.blockdown(upper)
+	}
+}
+
+// siftBlock moves the block by one position down or up.  If the block and
+// the block it was swapped with both consist of a single variable, the upper
+// and the lower of these variables are returned.
+func (r *reordering) siftBlock(blk *bddTree, down bool) (upper, lower int32, ok bool) {
+	var upperBlk, lowerBlk *bddTree
+	if down {
+		r.blockdown(blk)
+		upperBlk, lowerBlk = blk.prev, blk
+	} else {
+		r.blockdown(blk.prev)
+		upperBlk, lowerBlk = blk, blk.next
+	}
+	if upperBlk.first != upperBlk.last || lowerBlk.first != lowerBlk.last {
+		return -1, -1, false
+	}
+	return upperBlk.first, lowerBlk.first, true
+}
+
+// reorderLinear replaces the variable var0 by the exclusive or of var0 and
+// the variable var1 directly below it.  Each node of var0 with the cofactors
+// f00, f01, f10, f11 w.r.t. var0 and var1 is rebuilt in place with the low
+// child (var1 ? f11 : f00) and the high child (var1 ? f01 : f10), such that
+// it still represents the same function.  The transformation is its own
+// inverse.
+func (r *reordering) reorderLinear(var0 int32) {
+	level := r.k.var2level[var0]
+	if level >= r.k.varnum-1 {
+		return
+	}
+	var1 := r.k.level2var[level+1]
+	r.resizedInMakenode = false
+
+	// Remove all nodes of var0 from their hash chains
+	toBeProcessed := int32(0)
+	vl0 := r.levels[var0].start
+	size0 := r.levels[var0].size
+	r.levels[var0].nodenum = 0
+	for n := range size0 {
+		q := r.k.hash(n + vl0)
+		r.k.setHash(n+vl0, 0)
+		for q != 0 {
+			next := r.k.next(q)
+			r.k.setNext(q, toBeProcessed)
+			toBeProcessed = q
+			q = next
+		}
+	}
+
+	for toBeProcessed > 0 {
+		next := r.k.next(toBeProcessed)
+		f0 := r.k.low(toBeProcessed)
+		f1 := r.k.high(toBeProcessed)
+		f00, f01, f10, f11 := f0, f0, f1, f1
+		if r.vari(f0) == var1 {
+			f00 = r.k.low(f0)
+			f01 = r.k.high(f0)
+		}
+		if r.vari(f1) == var1 {
+			f10 = r.k.low(f1)
+			f11 = r.k.high(f1)
+		}
+
+		// As in reorderSwap, the old children are dereferenced after the new
+		// ones were built and dead nodes of var1 are removed by the local GBC.
+		low := r.reorderMakenode(var1, f00, f11)
+		high := r.reorderMakenode(var1, f10, f01)
+		r.k.decRef(f0)
+		r.k.decRef(f1)
+
+		r.k.setLow(toBeProcessed, low)
+		r.k.setHigh(toBeProcessed, high)
+		r.levels[var0].nodenum++
+		hash := r.nodehashReorder(var0, low, high)
+		r.k.setNext(toBeProcessed, r.k.hash(hash))
+		r.k.setHash(hash, toBeProcessed)
+		toBeProcessed = next
+	}
+	r.reorderLocalGbc(var0)
+
+	// The nodes of var0 now depend on var1 and the nodes of var1 on all
+	// variables below var0.
+	for n := range r.k.varnum {
+		if r.interactionMatrix.depends(var0, n) > 0 {
+			r.interactionMatrix.set(var1, n)
+			r.interactionMatrix.set(n, var1)
+		}
+	}
+	r.interactionMatrix.set(var0, var1)
+	r.interactionMatrix.set(var1, var0)
+
+	if r.resizedInMakenode {
+		r.reorderRehashAll()
+	}
+}
+
+// removeLinearTransformations undoes all linear transformations of previous
+// linear siftings in reverse order.  Afterwards, each level of the kernel
+// decides over a single variable again.  The variables of a transformation
+// are blocks of single variables with the same parent block, for undoing the
+// transformation the blocks are made adjacent again.
+func (r *reordering) removeLinearTransformations() {
+	if len(r.linear) == 0 {
+		return
+	}
+	top := newBddTree(-1)
+	r.reorderInit()
+	top.first = 0
+	top.last = r.k.varnum - 1
+	top.fixed = false
+	top.nextlevel = r.varTree
+	for i := len(r.linear) - 1; i >= 0; i-- {
+		r.moveAbove(top, r.linear[i].upper, r.linear[i].lower)
+		r.reorderLinear(r.linear[i].upper)
+	}
+	r.linear = nil
+	// Updates the sequences of the blocks without reordering them
+	r.reorderBlock(top, ReorderNone)
+	r.varTree = top.nextlevel
+	r.reorderDone()
+}
+
+// moveAbove moves the block of the variable upper directly above the block of
+// the variable lower.
+func (r *reordering) moveAbove(top *bddTree, upper, lower int32) {
+	parent, upperBlk := findVariableBlock(top, upper)
+	_, lowerBlk := findVariableBlock(top, lower)
+	if r.k.var2level[upper] < r.k.var2level[lower] {
+		for upperBlk.next != lowerBlk {
+			r.blockdown(upperBlk)
+		}
+	} else {
+		for upperBlk.prev != lowerBlk {
+			r.blockdown(upperBlk.prev)
+		}
+		r.blockdown(lowerBlk)
+	}
+	for parent.nextlevel.prev != nil {
+		parent.nextlevel = parent.nextlevel.prev
+	}
+}
+
+// findVariableBlock returns the block of the single given variable and its
+// parent block.
+func findVariableBlock(parent *bddTree, variable int32) (*bddTree, *bddTree) {
+	for t := parent.nextlevel; t != nil; t = t.next {
+		if t.first == variable && t.last == variable {
+			return parent, t
+		}
+		if t.first <= variable && variable <= t.last {
+			return findVariableBlock(t, variable)
+		}
+	}
+	return nil, nil
+}
+
+// restoreVariables undoes the linear transformations of a linear sifting.
+// It must be called before all operations which depend on the single
+// variables on the levels of the kernel.
+func (k *Kernel) restoreVariables() {
+	k.reordering.removeLinearTransformations()
+}
@@ -0,0 +1,217 @@
+package bdd
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+)
+
+const (
+	annealingStartFactor = 0.1 // start temperature relative to the number of nodes
+	annealingStopTemp    = 1.0 // temperature at which the annealing stops
+	annealingCooling     = 0.9 // factor by which the temperature is lowered
+	annealingExchange    = 0.4 // probability of exchanging two blocks instead of a jump
+	geneticMaxPopulation = 30  // upper bound for the population size
+	geneticCrossovers    = 2   // crossovers per individual of the population
+)
+
+// === Simulated annealing ============================================
+
+// reorderAnnealing searches for a good order of the blocks by simulated
+// annealing.  A move either exchanges two blocks or lets one block jump to
+// another position.  Moves which increase the number of nodes are accepted
+// with a probability decreasing with the temperature.  At the end, the best
+// order found is restored.
+func (r *reordering) reorderAnnealing(t *bddTree) *bddTree {
+	blocks := blockSequence(t)
+	num := len(blocks)
+	if num < 2 {
+		return t
+	}
+	current := r.reorderNodenum()
+	bestSize := current
+	best := slices.Clone(blocks)
+	temperature := annealingStartFactor * float64(current)
+
+	for temperature > annealingStopTemp && r.state.Success {
+		for range num {
+			if !r.shouldResume() {
+				break
+			}
+			from := rand.Intn(num)
+			to := rand.Intn(num - 1)
+			if to >= from {
+				to++
+			}
+			exchange := rand.Float64() < annealingExchange
+			r.moveBlock(blocks, from, to)
+			if exchange {
+				r.moveBlock(blocks, to-sign(to-from), from)
+			}
+			size := r.reorderNodenum()
+			if size <= current || rand.Float64() < math.Exp(-float64(size-current)/temperature) {
+				current = size
+				if size < bestSize {
+					bestSize = size
+					best = slices.Clone(blocks)
+				}
+			} else {
+				if exchange {
+					r.moveBlock(blocks, from, to-sign(to-from))
+				}
+				r.moveBlock(blocks, to, from)
+			}
+		}
+		temperature *= annealingCooling
+	}
+	r.arrangeBlocks(blocks, best)
+	return best[0]
+}
+
+// === Genetic algorithm ==============================================
+
+// reorderGenetic searches for a good order of the blocks by a genetic
+// algorithm.  The initial population consists of the current order, its
+// reverse and random orders.  In each step, two random parents are combined
+// by an order crossover and the child replaces the worst individual of the
+// population if it is better.  At the end, the best order found is restored.
+func (r *reordering) reorderGenetic(t *bddTree) *bddTree {
+	blocks := blockSequence(t)
+	num := len(blocks)
+	if num < 2 {
+		return t
+	}
+	pool := slices.Clone(blocks)
+	popSize := min(3*num, geneticMaxPopulation)
+	population := make([][]int, 0, popSize)
+	sizes := make([]int32, 0, popSize)
+
+	identity := make([]int, num)
+	for i := range identity {
+		identity[i] = i
+	}
+	population = append(population, identity)
+	sizes = append(sizes, r.reorderNodenum())
+	for len(population) < popSize && r.shouldResume() {
+		var individual []int
+		if len(population) == 1 {
+			individual = slices.Clone(identity)
+			slices.Reverse(individual)
+		} else {
+			individual = rand.Perm(num)
+		}
+		population = append(population, individual)
+		sizes = append(sizes, r.evaluateOrder(blocks, pool, individual))
+	}
+
+	for range geneticCrossovers * popSize {
+		if len(population) < 2 || !r.shouldResume() {
+			break
+		}
+		p1 := rand.Intn(len(population))
+		p2 := rand.Intn(len(population) - 1)
+		if p2 >= p1 {
+			p2++
+		}
+		child := orderCrossover(population[p1], population[p2])
+		worst := 0
+		for i := range sizes {
+			if sizes[i] > sizes[worst] {
+				worst = i
+			}
+		}
+		size := r.evaluateOrder(blocks, pool, child)
+		if size < sizes[worst] {
+			population[worst] = child
+			sizes[worst] = size
+		}
+	}
+
+	best := 0
+	for i := range sizes {
+		if sizes[i] < sizes[best] {
+			best = i
+		}
+	}
+	target := make([]*bddTree, num)
+	for i, idx := range population[best] {
+		target[i] = pool[idx]
+	}
+	r.arrangeBlocks(blocks, target)
+	return target[0]
+}
+
+// evaluateOrder arranges the blocks in the order given by the indices into
+// the pool of blocks and returns the resulting number of nodes.
+func (r *reordering) evaluateOrder(blocks, pool []*bddTree, order []int) int32 {
+	target := make([]*bddTree, len(order))
+	for i, idx := range order {
+		target[i] = pool[idx]
+	}
+	r.arrangeBlocks(blocks, target)
+	return r.reorderNodenum()
+}
+
+// orderCrossover takes a random segment of the first parent and fills the
+// remaining positions with the missing elements in the order of the second
+// parent.
+func orderCrossover(p1, p2 []int) []int {
+	num := len(p1)
+	start := rand.Intn(num)
+	end := start + rand.Intn(num-start) + 1
+	child := make([]int, num)
+	used := make([]bool, num)
+	for i := start; i < end; i++ {
+		child[i] = p1[i]
+		used[p1[i]] = true
+	}
+	pos := 0
+	for _, elem := range p2 {
+		if used[elem] {
+			continue
+		}
+		if pos == start {
+			pos = end
+		}
+		child[pos] = elem
+		pos++
+	}
+	return child
+}
+
+// === Block moves ====================================================
+
+func blockSequence(t *bddTree) []*bddTree {
+	var seq []*bddTree
+	for thisTree := t; thisTree != nil; thisTree = thisTree.next {
+		seq = append(seq, thisTree)
+	}
+	return seq
+}
+
+// moveBlock moves the block at position from to position to and keeps the
+// slice of blocks in sync with the current order.
+func (r *reordering) moveBlock(blocks []*bddTree, from, to int) {
+	for ; from < to; from++ {
+		r.blockdown(blocks[from])
+		blocks[from], blocks[from+1] = blocks[from+1], blocks[from]
+	}
+	for ; from > to; from-- {
+		r.blockdown(blocks[from-1])
+		blocks[from-1], blocks[from] = blocks[from], blocks[from-1]
+	}
+}
+
+// arrangeBlocks moves the blocks such that they are in the target order.
+func (r *reordering) arrangeBlocks(blocks, target []*bddTree) {
+	for i, blk := range target {
+		r.moveBlock(blocks, slices.Index(blocks, blk), i)
+	}
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
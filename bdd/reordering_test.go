@@ -8,7 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/booleworks/logicng-go/event"
 	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
 	"github.com/booleworks/logicng-go/parser"
 	"github.com/booleworks/logicng-go/randomizer"
 	"github.com/booleworks/logicng-go/sat"
@@ -23,6 +25,16 @@ var reorderMethods = []ReorderingMethod{
 	ReorderSift,
 	ReorderSiftIte,
 	ReorderRandom,
+	ReorderSymSift,
+	ReorderSymSiftConv,
+	ReorderGroupSift,
+	ReorderGroupSiftConv,
+	ReorderLinearSift,
+}
+
+var stochasticReorderMethods = []ReorderingMethod{
+	ReorderAnnealing,
+	ReorderGenetic,
 }
 
 func TestBDDSwapping(t *testing.T) {
@@ -98,6 +110,168 @@ func TestBDDReorderOnBuildLongRunning(t *testing.T) {
 	testReorderOnBuild(t, 25, 47, true, stats)
 }
 
+func TestBDDStochasticReorderingQuick(t *testing.T) {
+	stats := &swapStats{}
+	for vars := 25; vars <= 26; vars++ {
+		for depth := 4; depth <= 6; depth++ {
+			fac := f.NewFactory()
+			formula := randomFormula(fac, vars, depth)
+			for _, method := range stochasticReorderMethods {
+				performReorder(t, fac, formula, method, true, false, stats)
+				performReorder(t, fac, formula, method, false, false, stats)
+			}
+		}
+	}
+}
+
+func TestBDDSymmetricSifting(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("a & b | c & d | e & f")
+	order := fac.Vars("a", "c", "e", "b", "d", "f")
+
+	for _, method := range []ReorderingMethod{ReorderSymSift, ReorderSymSiftConv, ReorderGroupSift, ReorderGroupSiftConv} {
+		kernel := NewKernelWithOrdering(fac, order, 1000, 1000)
+		bdd := CompileWithKernel(fac, formula, kernel)
+		assert.Equal(14, bdd.NodeCount())
+		kernel.AddAllVariablesAsBlock()
+		kernel.Reorder(method)
+		assert.Equal(6, bdd.NodeCount())
+		assert.True(sat.IsEquivalent(fac, formula, bdd.CNF()))
+	}
+}
+
+func TestBDDLinearSifting(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a <=> b) & (c <=> d) & (e | (a <=> c)) & (b | d | f)")
+	kernel := NewKernelWithOrdering(fac, order(fac, formula), 1000, 1000)
+	bdd := CompileWithKernel(fac, formula, kernel)
+	other := CompileWithKernel(fac, p.ParseUnsafe("a & ~e"), kernel)
+	assert.Equal(11, bdd.NodeCount())
+	kernel.AddAllVariablesAsBlock()
+	kernel.Reorder(ReorderLinearSift)
+	assert.Equal(6, bdd.NodeCount())
+	assert.NotEmpty(kernel.reordering.linear)
+
+	// basis independent operations on the transformed BDDs
+	assert.Equal(big.NewInt(10), bdd.ModelCount())
+	conjunction := bdd.And(other)
+	assert.Equal(big.NewInt(2), conjunction.ModelCount())
+	assert.NotEmpty(kernel.reordering.linear)
+
+	// operations on the variables undo the transformations
+	assert.True(sat.IsEquivalent(fac, fac.And(formula, p.ParseUnsafe("a & ~e")), conjunction.CNF()))
+	assert.Empty(kernel.reordering.linear)
+	assert.Positive(verifyTree(kernel, bdd.Index))
+	assert.Positive(verifyTree(kernel, conjunction.Index))
+	assert.Equal(11, bdd.NodeCount())
+
+	kernel.Reorder(ReorderLinearSift)
+	assert.NotEmpty(kernel.reordering.linear)
+	exists := bdd.Exists(fac.Var("a"), fac.Var("f"))
+	assert.Empty(kernel.reordering.linear)
+	assert.True(sat.IsEquivalent(fac, p.ParseUnsafe("(c <=> d) & (e | (b <=> c))"), exists.CNF()))
+	assert.True(sat.IsEquivalent(fac, formula, bdd.CNF()))
+}
+
+func TestBDDSymmetryCheck(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	kernel := NewKernelWithOrdering(fac, fac.Vars("a", "b", "c", "d"), 1000, 1000)
+	CompileWithKernel(fac, p.ParseUnsafe("(a | b) & (c | ~d)"), kernel)
+	r := kernel.reordering
+	r.reorderInit()
+	assert.True(r.symmetric(0, 1, false, 0))
+	assert.False(r.symmetric(1, 2, false, 0))
+	assert.False(r.symmetric(2, 3, false, 0))
+	assert.True(r.symmetric(2, 3, true, 0))
+	r.reorderDone()
+}
+
+func TestBDDReorderingHandler(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	formula := randomFormula(fac, 25, 5)
+	modelCount := Compile(fac, formula).ModelCount()
+
+	for _, method := range append(slices.Clone(reorderMethods), stochasticReorderMethods...) {
+		kernel := NewKernelWithOrdering(fac, order(fac, formula), 1000, 10000)
+		bdd := CompileWithKernel(fac, formula, kernel)
+		kernel.AddAllVariablesAsBlock()
+		before := bdd.VariableOrder()
+		state := kernel.ReorderWithHandler(method, &reorderingHandler{bound: 0})
+		assert.False(state.Success)
+		assert.Equal(event.BddReorderingStarted, state.CancelCause)
+		assert.Equal(before, bdd.VariableOrder())
+
+		hdl := &reorderingHandler{bound: 3}
+		state = kernel.ReorderWithHandler(method, hdl)
+		assert.False(state.Success)
+		assert.Equal(EventReorderingProgress{method, hdl.nodes}, state.CancelCause)
+		assert.Equal(4, hdl.steps)
+		assert.Equal(modelCount, bdd.ModelCount())
+		assert.True(sat.IsEquivalent(fac, formula, bdd.CNF()))
+	}
+}
+
+func TestBDDReorderingNodeBound(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	formula := randomFormula(fac, 25, 5)
+	kernel := NewKernelWithOrdering(fac, order(fac, formula), 1000, 10000)
+	bdd := CompileWithKernel(fac, formula, kernel)
+	kernel.AddAllVariablesAsBlock()
+	before := bdd.NodeCount()
+	state := kernel.ReorderWithHandler(ReorderAnnealing, &reorderingTargetHandler{target: before - 1})
+	assert.False(state.Success)
+	assert.Less(bdd.NodeCount(), before)
+	assert.True(sat.IsEquivalent(fac, formula, bdd.CNF()))
+}
+
+func TestBDDReorderingTimeout(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	formula := randomFormula(fac, 30, 6)
+	kernel := NewKernelWithOrdering(fac, order(fac, formula), 1000, 10000)
+	bdd := CompileWithKernel(fac, formula, kernel)
+	kernel.AddAllVariablesAsBlock()
+	before := bdd.NodeCount()
+	state := kernel.ReorderWithHandler(ReorderGenetic, handler.NewTimeoutWithDuration(time.Millisecond))
+	assert.False(state.Success)
+	assert.LessOrEqual(bdd.NodeCount(), before)
+	assert.True(sat.IsEquivalent(fac, formula, bdd.CNF()))
+}
+
+type reorderingHandler struct {
+	bound int
+	steps int
+	nodes int
+}
+
+func (h *reorderingHandler) ShouldResume(e event.Event) bool {
+	if e == event.BddReorderingStarted {
+		return h.bound > 0
+	}
+	h.steps++
+	h.nodes = e.(EventReorderingProgress).Nodes
+	return h.steps <= h.bound
+}
+
+type reorderingTargetHandler struct {
+	target int
+}
+
+func (h *reorderingTargetHandler) ShouldResume(e event.Event) bool {
+	if progress, ok := e.(EventReorderingProgress); ok {
+		return progress.Nodes > h.target
+	}
+	return true
+}
+
 func testRandomReordering(t *testing.T, minVars, maxVars int, verbose bool, stats *swapStats) {
 	for vars := minVars; vars <= maxVars; vars++ {
 		for depth := 4; depth <= 6; depth++ {
@@ -132,7 +306,7 @@ func performReorder(
 	usedBefore := bdd.NodeCount()
 	start := time.Now()
 	addVariableBlocks(f.Variables(fac, formula).Size(), withBlocks, kernel)
-	kernel.reordering.reorder(reorderMethod)
+	kernel.reordering.reorder(reorderMethod, handler.NopHandler)
 	duration := time.Since(start) / 1_000_000
 	usedAfter := bdd.NodeCount()
 	assert.True(verifyBddConsistency(fac, formula, bdd, count, stats))
@@ -249,6 +423,7 @@ func addVariableBlocks(numVars int, withBlocks bool, kernel *Kernel) {
 }
 
 func verifyBddConsistency(fac f.Factory, f1 f.Formula, bdd *BDD, modelCount *big.Int, stats *swapStats) bool {
+	bdd.Kernel.restoreVariables()
 	if !verify(bdd.Kernel, bdd.Index) {
 		return false
 	}
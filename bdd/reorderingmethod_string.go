@@ -16,11 +16,18 @@ func _() {
 	_ = x[ReorderWin3-5]
 	_ = x[ReorderWin3Ite-6]
 	_ = x[ReorderRandom-7]
+	_ = x[ReorderSymSift-8]
+	_ = x[ReorderSymSiftConv-9]
+	_ = x[ReorderGroupSift-10]
+	_ = x[ReorderGroupSiftConv-11]
+	_ = x[ReorderAnnealing-12]
+	_ = x[ReorderGenetic-13]
+	_ = x[ReorderLinearSift-14]
 }
 
-const _ReorderingMethod_name = "ReorderNoneReorderWin2ReorderWin2IteReorderSiftReorderSiftIteReorderWin3ReorderWin3IteReorderRandom"
+const _ReorderingMethod_name = "ReorderNoneReorderWin2ReorderWin2IteReorderSiftReorderSiftIteReorderWin3ReorderWin3IteReorderRandomReorderSymSiftReorderSymSiftConvReorderGroupSiftReorderGroupSiftConvReorderAnnealingReorderGeneticReorderLinearSift"
 
-var _ReorderingMethod_index = [...]uint8{0, 11, 22, 36, 47, 61, 72, 86, 99}
+var _ReorderingMethod_index = [...]uint8{0, 11, 22, 36, 47, 61, 72, 86, 99, 113, 131, 147, 167, 183, 197, 214}
 
 func (i ReorderingMethod) String() string {
 	if i >= ReorderingMethod(len(_ReorderingMethod_index)-1) {
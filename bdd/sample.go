@@ -0,0 +1,138 @@
+package bdd
+
+import (
+	"math"
+	"math/rand"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/weight"
+)
+
+// Sample returns n models of the BDD which are drawn uniformly at random and
+// independently of each other from all models of the BDD projected to the
+// given variables.  If no variables are given, the models over all
+// variables of the BDD's kernel are sampled.  As for the model enumeration,
+// variables which are not in the BDD's kernel are ignored.  The random
+// number generator rng makes the samples reproducible.  Returns an empty
+// slice if the BDD is a contradiction.
+//
+// Each sample is drawn by a single path from the root to the true terminal,
+// where each child is chosen with a probability proportional to its model
+// count.
+func (b *BDD) Sample(n int, rng *rand.Rand, variables ...f.Variable) []*model.Model {
+	return b.SampleWeighted(n, rng, nil, variables...)
+}
+
+// SampleWeighted returns n models of the BDD projected to the given
+// variables like Sample, but each model is drawn with a probability
+// proportional to its weight.  The weight of a model is the product of the
+// weights of its literals, literals which are not in the given weight map
+// have weight 1.  If the weights of the two literals of each variable add up
+// to one, they can be interpreted as the probability of the literal, e.g.
+// the take rate of an option.  Returns an empty slice if the weighted model
+// count of the projected BDD is zero.
+func (b *BDD) SampleWeighted(
+	n int,
+	rng *rand.Rand,
+	weights map[f.Literal]float64,
+	variables ...f.Variable,
+) []*model.Model {
+	k := b.Kernel
+	k.restoreVariables()
+	projected := f.NewMutableVarSet()
+	if len(variables) == 0 {
+		for _, variable := range k.idx2var {
+			projected.Add(variable)
+		}
+	}
+	for _, variable := range variables {
+		if _, ok := k.var2idx[variable]; ok {
+			projected.Add(variable)
+		}
+	}
+	var quantified []f.Variable
+	for _, variable := range k.idx2var {
+		if !projected.Contains(variable) {
+			quantified = append(quantified, variable)
+		}
+	}
+	root := b
+	if len(quantified) > 0 {
+		root = b.Exists(quantified...)
+		defer root.Free()
+	}
+	logWeights := make(map[f.Literal]float64, len(weights))
+	for literal, w := range weights {
+		if projected.Contains(literal.Variable()) {
+			logWeights[literal] = math.Log(w)
+		}
+	}
+
+	samples := make([]*model.Model, 0, n)
+	_, values := nodeValues(k, weight.Log, logWeights, root.Index)
+	total := freeLevels(k, weight.Log, logWeights, -1, k.level(root.Index), values[root.Index], nil)
+	if weight.Log.IsZero(total) {
+		return samples
+	}
+	for i := 0; i < n; i++ {
+		assignment := k.samplePath(rng, root.Index, values, logWeights)
+		literals := make([]f.Literal, 0, projected.Size())
+		for _, variable := range projected.Content() {
+			if assignment[variable] {
+				literals = append(literals, variable.AsLiteral())
+			} else {
+				literals = append(literals, variable.Negate(k.fac))
+			}
+		}
+		samples = append(samples, model.New(literals...))
+	}
+	return samples
+}
+
+// samplePath draws a random path from the root to the true terminal and
+// returns the assignment of the variables on all levels.  The given values
+// are the weighted model counts of the nodes in log-space.
+func (k *Kernel) samplePath(
+	rng *rand.Rand,
+	root int32,
+	values map[int32]float64,
+	weights map[f.Literal]float64,
+) map[f.Variable]bool {
+	assignment := make(map[f.Variable]bool, len(k.idx2var))
+	level := int32(-1)
+	for node := root; ; {
+		k.sampleFreeLevels(rng, weights, level, k.level(node), assignment)
+		if node == bddTrue {
+			return assignment
+		}
+		low, high := childValues(k, weight.Log, weights, values, node)
+		phase := rng.Float64() < math.Exp(high-weight.Log.Add(low, high))
+		if variable, ok := k.levelVariable(k.level(node)); ok {
+			assignment[variable] = phase
+		}
+		level = k.level(node)
+		if phase {
+			node = k.high(node)
+		} else {
+			node = k.low(node)
+		}
+	}
+}
+
+// sampleFreeLevels assigns the variables on the levels strictly between from
+// and to independently with the probability of their weights.
+func (k *Kernel) sampleFreeLevels(
+	rng *rand.Rand,
+	weights map[f.Literal]float64,
+	from, to int32,
+	assignment map[f.Variable]bool,
+) {
+	for level := from + 1; level < to; level++ {
+		if variable, ok := k.levelVariable(level); ok {
+			pos := weight.Of(weight.Log, weights, variable.AsLiteral())
+			neg := weight.Of(weight.Log, weights, variable.Negate(k.fac))
+			assignment[variable] = rng.Float64() < math.Exp(pos-weight.Log.Add(pos, neg))
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package bdd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+)
+
+const (
+	binaryMagic   = "LNGBDD"
+	binaryVersion = byte(1)
+
+	maxBinaryNameLength = 1 << 20           // maximum length of a variable name
+	maxBinaryNodes      = math.MaxInt32 / 2 // maximum number of nodes of a file
+	maxInitialNodeSize  = 1 << 20           // maximum initial node table size of a new kernel
+)
+
+// WriteBinary writes the given BDDs to a file with the given filename in a
+// compact binary format.  The format stores the names and the order of the
+// variables of the BDDs' kernel and the shared node table of the BDDs, such
+// that the BDDs can be restored with ReadBinary without compiling them again.
+// All BDDs must be constructed by the same kernel.  Returns an error if there
+// was a problem writing the file.
+func WriteBinary(filename string, bdds ...*BDD) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteBinaryToWriter(file, bdds...)
+}
+
+// WriteBinaryToWriter writes the given BDDs to the given writer in the
+// compact binary format of WriteBinary.  Returns an error if there was a
+// problem writing to the writer.
+func WriteBinaryToWriter(writer io.Writer, bdds ...*BDD) error {
+	kernel, err := commonKernel(bdds)
+	if err != nil {
+		return err
+	}
+	kernel.restoreVariables()
+	nodes, refs := kernel.serializedNodes(bdds)
+	order := kernel.namedVariableOrder()
+	positions := make(map[int32]uint64, len(order))
+	for i, variable := range order {
+		positions[kernel.var2idx[variable]] = uint64(i)
+	}
+
+	w := bufio.NewWriter(writer)
+	w.WriteString(binaryMagic)
+	w.WriteByte(binaryVersion)
+	writeUvarint(w, uint64(len(order)))
+	for _, variable := range order {
+		name, _ := kernel.fac.VarName(variable)
+		writeUvarint(w, uint64(len(name)))
+		w.WriteString(name)
+	}
+	writeUvarint(w, uint64(len(nodes)))
+	for _, node := range nodes {
+		writeUvarint(w, positions[kernel.level2var[kernel.level(node)]])
+		writeUvarint(w, uint64(refs[kernel.low(node)]))
+		writeUvarint(w, uint64(refs[kernel.high(node)]))
+	}
+	writeUvarint(w, uint64(len(bdds)))
+	for _, bdd := range bdds {
+		writeUvarint(w, uint64(refs[bdd.Index]))
+	}
+	return w.Flush()
+}
+
+// ReadBinary reads BDDs from a file with the given filename in the binary
+// format of WriteBinary.  The BDDs are constructed by the given kernel, the
+// variables of the file are added to the kernel if they are not yet known.
+// If the kernel is nil, a new kernel with the variable order of the file is
+// created.  Returns the BDDs in the order in which they were written and an
+// error if there was a problem reading the file or the node limit of the
+// kernel is reached.
+func ReadBinary(fac f.Factory, filename string, kernel *Kernel) ([]*BDD, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadBinaryFromReader(fac, file, kernel)
+}
+
+// ReadBinaryFromReader reads BDDs from the given reader in the binary format
+// of WriteBinary like ReadBinary.
+func ReadBinaryFromReader(fac f.Factory, reader io.Reader, kernel *Kernel) ([]*BDD, error) {
+	r := bufio.NewReader(reader)
+	header := make([]byte, len(binaryMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return nil, errorx.BadInput("input is not a binary BDD file")
+	}
+	if header[len(binaryMagic)] != binaryVersion {
+		return nil, errorx.BadInput("unsupported binary BDD version: %d", header[len(binaryMagic)])
+	}
+	numVars, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if numVars > uint64(maxvar) {
+		return nil, errorx.BadInput("too many variables in binary BDD file: %d", numVars)
+	}
+	var order []f.Variable
+	for range numVars {
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if length > maxBinaryNameLength {
+			return nil, errorx.BadInput("variable name too long in binary BDD file: %d", length)
+		}
+		var name strings.Builder
+		if _, err = io.CopyN(&name, r, int64(length)); err != nil {
+			return nil, err
+		}
+		order = append(order, fac.Var(name.String()))
+	}
+	numNodes, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if numNodes > maxBinaryNodes {
+		return nil, errorx.BadInput("too many nodes in binary BDD file: %d", numNodes)
+	}
+	if kernel == nil {
+		nodeSize := min(numNodes, maxInitialNodeSize)
+		kernel = NewKernelWithOrdering(fac, order, int32(2*nodeSize+10), int32(nodeSize+10))
+	}
+	builder := newNodeBuilder(kernel)
+	defer builder.release()
+	nodes := []int32{bddFalse, bddTrue}
+	for range numNodes {
+		var values [3]uint64
+		for j := range values {
+			if values[j], err = binary.ReadUvarint(r); err != nil {
+				return nil, err
+			}
+		}
+		if values[0] >= uint64(len(order)) || values[1] >= uint64(len(nodes)) || values[2] >= uint64(len(nodes)) {
+			return nil, errorx.BadInput("illegal node in binary BDD file")
+		}
+		node, err := builder.add(order[values[0]], nodes[values[1]], nodes[values[2]])
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	numRoots, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	var roots []int32
+	for range numRoots {
+		ref, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if ref >= uint64(len(nodes)) {
+			return nil, errorx.BadInput("illegal root in binary BDD file")
+		}
+		roots = append(roots, nodes[ref])
+	}
+	result := make([]*BDD, len(roots))
+	for i, root := range roots {
+		result[i] = builder.bdd(root)
+	}
+	return result, nil
+}
+
+func writeUvarint(w *bufio.Writer, value uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], value)
+	w.Write(buf[:n])
+}
+
+// commonKernel returns the kernel of the given BDDs or an error if the BDDs
+// were constructed by different kernels.
+func commonKernel(bdds []*BDD) (*Kernel, error) {
+	if len(bdds) == 0 {
+		return nil, errorx.BadInput("no BDDs given")
+	}
+	for _, bdd := range bdds[1:] {
+		if bdd.Kernel != bdds[0].Kernel {
+			return nil, errorx.BadInput("BDDs have different kernels")
+		}
+	}
+	return bdds[0].Kernel, nil
+}
+
+// serializedNodes returns the inner nodes of the given BDDs such that each
+// node occurs after its successors.  The references map the constants to 0
+// and 1 and the i-th node to i+2.
+func (k *Kernel) serializedNodes(bdds []*BDD) ([]int32, map[int32]int) {
+	var nodes []int32
+	refs := map[int32]int{bddFalse: 0, bddTrue: 1}
+	var collect func(node int32)
+	collect = func(node int32) {
+		if _, ok := refs[node]; ok {
+			return
+		}
+		collect(k.low(node))
+		collect(k.high(node))
+		refs[node] = len(nodes) + 2
+		nodes = append(nodes, node)
+	}
+	for _, bdd := range bdds {
+		collect(bdd.Index)
+	}
+	return nodes, refs
+}
+
+// namedVariableOrder returns the variables of the kernel ordered by their
+// level.
+func (k *Kernel) namedVariableOrder() []f.Variable {
+	order := make([]f.Variable, 0, len(k.var2idx))
+	for level := range k.varnum {
+		if variable, ok := k.idx2var[k.level2var[level]]; ok {
+			order = append(order, variable)
+		}
+	}
+	return order
+}
+
+// A nodeBuilder constructs the nodes of a deserialized BDD on a kernel.  All
+// constructed nodes are referenced until the builder is released, such that
+// they are not removed by a garbage collection in the meantime.
+type nodeBuilder struct {
+	kernel *Kernel
+	refs   []int32
+}
+
+func newNodeBuilder(kernel *Kernel) *nodeBuilder {
+	return &nodeBuilder{kernel: kernel}
+}
+
+// add returns the node with the given variable and successors.  Since the
+// order of the kernel can differ from the order of the input, the node is
+// constructed by an ite.
+func (b *nodeBuilder) add(variable f.Variable, low, high int32) (int32, error) {
+	k := b.kernel
+	idx := k.getOrAddVarIndex(variable)
+	return b.ref(k.ite(k.ithVar(idx), high, low))
+}
+
+// not returns the negation of the given node.
+func (b *nodeBuilder) not(node int32) (int32, error) {
+	return b.ref(b.kernel.not(node))
+}
+
+// ref adds a reference to the given node and returns an error if the
+// operation which computed the node was aborted at the node limit.
+func (b *nodeBuilder) ref(node int32) (int32, error) {
+	node, state := b.kernel.addRef(node, handler.NopHandler)
+	if !state.Success {
+		return -1, errorx.IllegalState("node limit of %d nodes reached", b.kernel.maxnodesize)
+	}
+	b.refs = append(b.refs, node)
+	return node, nil
+}
+
+// bdd returns a referenced BDD for the given node which remains valid after
+// the builder is released.
+func (b *nodeBuilder) bdd(node int32) *BDD {
+	if node >= 2 {
+		b.kernel.incRef(node)
+	}
+	return newBdd(node, b.kernel)
+}
+
+func (b *nodeBuilder) release() {
+	for _, node := range b.refs {
+		b.kernel.delRef(node)
+	}
+	b.refs = nil
+}
@@ -0,0 +1,276 @@
+package bdd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula1 := p.ParseUnsafe("(a => b) & (c | ~d)")
+	formula2 := p.ParseUnsafe("a <=> ~c")
+	kernel := NewKernelWithOrdering(fac, fac.Vars("d", "c", "b", "a", "x"), 100, 100)
+	bdd1 := CompileWithKernel(fac, formula1, kernel)
+	bdd2 := CompileWithKernel(fac, formula2, kernel)
+	verum := CompileWithKernel(fac, fac.Verum(), kernel)
+
+	var buf bytes.Buffer
+	assert.Nil(WriteBinaryToWriter(&buf, bdd1, bdd2, verum))
+	read, err := ReadBinaryFromReader(fac, bytes.NewReader(buf.Bytes()), nil)
+	assert.Nil(err)
+	assert.Equal(3, len(read))
+	assert.Equal(fac.Vars("d", "c", "b", "a", "x"), read[0].VariableOrder())
+	assert.Equal(bdd1.NodeCount(), read[0].NodeCount())
+	assertEquivalent(assert, fac, formula1, read[0])
+	assertEquivalent(assert, fac, formula2, read[1])
+	assert.True(read[2].IsTautology())
+
+	read, err = ReadBinaryFromReader(fac, bytes.NewReader(buf.Bytes()), kernel)
+	assert.Nil(err)
+	assert.Equal(bdd1.Index, read[0].Index)
+	assert.Equal(bdd2.Index, read[1].Index)
+
+	other := NewKernelWithOrdering(fac, fac.Vars("a", "b", "c", "d"), 100, 100)
+	read, err = ReadBinaryFromReader(fac, bytes.NewReader(buf.Bytes()), other)
+	assert.Nil(err)
+	assert.Equal(CompileWithKernel(fac, formula1, other).Index, read[0].Index)
+	assert.Equal(CompileWithKernel(fac, formula2, other).Index, read[1].Index)
+}
+
+func TestBinaryFile(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	formula := parser.New(fac).ParseUnsafe("a & ~b | c")
+	bdd := Compile(fac, formula)
+	filename := filepath.Join(t.TempDir(), "bdd.bin")
+	assert.Nil(WriteBinary(filename, bdd))
+	read, err := ReadBinary(fac, filename, nil)
+	assert.Nil(err)
+	assertEquivalent(assert, fac, formula, read[0])
+}
+
+func TestBinaryErrors(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	bdd1 := Compile(fac, p.ParseUnsafe("a & b"))
+	bdd2 := Compile(fac, p.ParseUnsafe("a | b"))
+	var buf bytes.Buffer
+	assert.NotNil(WriteBinaryToWriter(&buf))
+	assert.NotNil(WriteBinaryToWriter(&buf, bdd1, bdd2))
+
+	_, err := ReadBinaryFromReader(fac, strings.NewReader("NOTABDDFILE"), nil)
+	assert.NotNil(err)
+	assert.Nil(WriteBinaryToWriter(&buf, bdd1))
+	_, err = ReadBinaryFromReader(fac, bytes.NewReader(buf.Bytes()[:buf.Len()-2]), nil)
+	assert.NotNil(err)
+
+	small := NewKernel(fac, 1, 10, 10)
+	read, err := ReadBinaryFromReader(fac, bytes.NewReader(buf.Bytes()), small)
+	assert.Nil(err)
+	assert.Equal(int32(2), small.Statistics().Vars)
+	assertEquivalent(assert, fac, p.ParseUnsafe("a & b"), read[0])
+}
+
+func TestBinaryIllegalLengths(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	header := binaryMagic + string(binaryVersion)
+	uvarints := func(values ...uint64) string {
+		var buf []byte
+		for _, value := range values {
+			buf = binary.AppendUvarint(buf, value)
+		}
+		return header + string(buf)
+	}
+	for _, input := range []string{
+		uvarints(math.MaxUint64),
+		uvarints(1<<40, 1, 'a'),
+		uvarints(1, math.MaxUint64),
+		uvarints(1, 1<<40),
+		uvarints(1, 1, 'a', math.MaxUint64),
+		uvarints(1, 1, 'a', 1<<40),
+	} {
+		_, err := ReadBinaryFromReader(fac, strings.NewReader(input), nil)
+		assert.True(errors.Is(err, errorx.ErrBadInput), input)
+	}
+
+	_, err := ReadBinaryFromReader(fac, strings.NewReader(uvarints(1, 1, 'a', 1<<16)), nil)
+	assert.NotNil(err)
+	_, err = ReadBinaryFromReader(fac, strings.NewReader(uvarints(1, 1, 'a', 1, 0, 0, 1, 1<<40)), nil)
+	assert.NotNil(err)
+}
+
+func TestDDDMPRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula1 := p.ParseUnsafe("(a => b) & (c | ~d)")
+	formula2 := p.ParseUnsafe("~a & b | c")
+	kernel := NewKernelWithOrdering(fac, fac.Vars("b", "a", "c", "d"), 100, 100)
+	bdd1 := CompileWithKernel(fac, formula1, kernel)
+	bdd2 := CompileWithKernel(fac, formula2, kernel)
+	falsum := CompileWithKernel(fac, fac.Falsum(), kernel)
+
+	var buf bytes.Buffer
+	assert.Nil(WriteDDDMPToWriter(&buf, bdd1, bdd2, falsum, bdd1.Negate()))
+	assert.Contains(buf.String(), ".suppvarnames b a c d\n")
+	assert.Contains(buf.String(), ".permids 0 1 2 3\n")
+	read, err := ReadDDDMPFromReader(fac, bytes.NewReader(buf.Bytes()), nil)
+	assert.Nil(err)
+	assert.Equal(4, len(read))
+	assert.Equal(fac.Vars("b", "a", "c", "d"), read[0].VariableOrder())
+	assertEquivalent(assert, fac, formula1, read[0])
+	assertEquivalent(assert, fac, formula2, read[1])
+	assert.True(read[2].IsContradiction())
+	assertEquivalent(assert, fac, formula1.Negate(fac), read[3])
+
+	read, err = ReadDDDMPFromReader(fac, bytes.NewReader(buf.Bytes()), kernel)
+	assert.Nil(err)
+	assert.Equal(bdd1.Index, read[0].Index)
+	assert.Equal(bdd2.Index, read[1].Index)
+	assert.Equal(bdd1.Negate().Index, read[3].Index)
+}
+
+func TestDDDMPComplementEdges(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	kernel := NewKernelWithOrdering(fac, fac.Vars("a", "b"), 100, 100)
+	bdd := CompileWithKernel(fac, p.ParseUnsafe("a <=> b"), kernel)
+	var buf bytes.Buffer
+	assert.Nil(WriteDDDMPToWriter(&buf, bdd, bdd.Negate()))
+	// with complement edges, a <=> b and its negation share their three nodes
+	assert.Contains(buf.String(), ".nnodes 3\n")
+
+	dddmp := `.ver DDDMP-2.0
+.mode A
+.varinfo 0
+.dd example
+.nnodes 3
+.nvars 3
+.nsuppvars 2
+.suppvarnames x y
+.ids 0 2
+.permids 1 0
+.nroots 2
+.rootids 3 -3
+.nodes
+1 T 1 0 0
+2 2 1 1 -1
+3 0 0 2 -1
+.end
+`
+	read, err := ReadDDDMPFromReader(fac, strings.NewReader(dddmp), nil)
+	assert.Nil(err)
+	assert.Equal(fac.Vars("y", "x"), read[0].VariableOrder())
+	assertEquivalent(assert, fac, p.ParseUnsafe("x & y"), read[0])
+	assertEquivalent(assert, fac, p.ParseUnsafe("~x | ~y"), read[1])
+}
+
+func TestDDDMPErrors(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	_, err := ReadDDDMPFromReader(fac, strings.NewReader(".ver DDDMP-2.0\n.mode B\n"), nil)
+	assert.NotNil(err)
+	_, err = ReadDDDMPFromReader(fac, strings.NewReader("1 T 1 0 0\n"), nil)
+	assert.NotNil(err)
+	_, err = ReadDDDMPFromReader(fac, strings.NewReader(".ids 0\n.rootids 2\n.nodes\n1 T 1 0 0\n2 0 0 1 -3\n.end\n"), nil)
+	assert.NotNil(err)
+}
+
+func TestBuDDyRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a => b) & (c | ~d)")
+	kernel := NewKernelWithOrdering(fac, fac.Vars("a", "b", "c", "d"), 100, 100)
+	bdd := CompileWithKernel(fac, formula, kernel)
+
+	var buf bytes.Buffer
+	assert.Nil(WriteBuDDyToWriter(&buf, bdd))
+	read, err := ReadBuDDyFromReader(bytes.NewReader(buf.Bytes()), kernel)
+	assert.Nil(err)
+	assert.Equal(bdd.Index, read.Index)
+
+	other := NewKernelWithOrdering(fac, fac.Vars("x", "y", "z", "w"), 100, 100)
+	read, err = ReadBuDDyFromReader(bytes.NewReader(buf.Bytes()), other, fac.Vars("x", "y", "z", "w")...)
+	assert.Nil(err)
+	assertEquivalent(assert, fac, p.ParseUnsafe("(x => y) & (z | ~w)"), read)
+
+	buf.Reset()
+	assert.Nil(WriteBuDDyToWriter(&buf, CompileWithKernel(fac, fac.Verum(), kernel)))
+	assert.Equal("0 0 1\n", buf.String())
+	read, err = ReadBuDDyFromReader(bytes.NewReader(buf.Bytes()), kernel)
+	assert.Nil(err)
+	assert.True(read.IsTautology())
+}
+
+func TestBuDDyFormat(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	kernel := NewKernelWithOrdering(fac, fac.Vars("a", "b", "c"), 100, 100)
+	saved := "2 3\n1 0 2\n10 2 1 0\n11 0 0 10\n"
+	read, err := ReadBuDDyFromReader(strings.NewReader(saved), kernel)
+	assert.Nil(err)
+	assertEquivalent(assert, fac, p.ParseUnsafe("a & ~c"), read)
+
+	_, err = ReadBuDDyFromReader(strings.NewReader("2 3\n1 0 2\n10 5 1 0\n11 0 0 10\n"), kernel)
+	assert.NotNil(err)
+	_, err = ReadBuDDyFromReader(strings.NewReader("2 3\n1 0 2\n10 2 1 0\n11 0 0 12\n"), kernel)
+	assert.NotNil(err)
+	_, err = ReadBuDDyFromReader(strings.NewReader("2 3\n1 0 2\n10 2 1 0\n"), kernel)
+	assert.NotNil(err)
+}
+
+func TestSerializationRandom(t *testing.T) {
+	assert := assert.New(t)
+	for i := range 20 {
+		fac := f.NewFactory()
+		config := randomizer.DefaultConfig()
+		config.Seed = int64(i)
+		config.NumVars = 10
+		r := randomizer.New(fac, config)
+		formula1, formula2 := r.Formula(4), r.Formula(4)
+		vars := fac.Vars("v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8", "v9")
+		kernel := NewKernelWithOrdering(fac, vars, 100, 100)
+		bdd1 := CompileWithKernel(fac, formula1, kernel)
+		bdd2 := CompileWithKernel(fac, formula2, kernel)
+		reversed := NewKernelWithOrdering(fac, []f.Variable{
+			vars[9], vars[8], vars[7], vars[6], vars[5], vars[4], vars[3], vars[2], vars[1], vars[0],
+		}, 20, 20)
+
+		var binary, dddmp, buddy bytes.Buffer
+		assert.Nil(WriteBinaryToWriter(&binary, bdd1, bdd2))
+		assert.Nil(WriteDDDMPToWriter(&dddmp, bdd1, bdd2))
+		assert.Nil(WriteBuDDyToWriter(&buddy, bdd1))
+
+		fromBinary, err := ReadBinaryFromReader(fac, &binary, reversed)
+		assert.Nil(err)
+		fromDDDMP, err := ReadDDDMPFromReader(fac, &dddmp, reversed)
+		assert.Nil(err)
+		fromBuDDy, err := ReadBuDDyFromReader(&buddy, reversed, vars...)
+		assert.Nil(err)
+		exp1 := CompileWithKernel(fac, formula1, reversed)
+		exp2 := CompileWithKernel(fac, formula2, reversed)
+		assert.Equal(exp1.Index, fromBinary[0].Index)
+		assert.Equal(exp2.Index, fromBinary[1].Index)
+		assert.Equal(exp1.Index, fromDDDMP[0].Index)
+		assert.Equal(exp2.Index, fromDDDMP[1].Index)
+		assert.Equal(exp1.Index, fromBuDDy.Index)
+	}
+}
@@ -0,0 +1,198 @@
+package bdd
+
+import (
+	"container/heap"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/weight"
+)
+
+// TopK returns the k models of the BDD projected to the given variables with
+// the smallest cost in ascending order of their cost together with the
+// costs.  The cost of a model is the sum of the costs of its literals,
+// literals which are not in the given cost map have cost 0.  If ties is set,
+// all models with the same cost as the k-th model are also returned.  If no
+// variables are given, the models over all variables of the BDD's kernel are
+// returned.  As for the model enumeration, variables which are not in the
+// BDD's kernel are ignored.  If the costs contain literals over variables
+// which are not projected, the cost of a model is the smallest cost of all
+// its extensions to these variables.
+//
+// The models are computed by a best-first search over the paths of the BDD.
+// Since the minimal cost of each node is computed upfront, each search step
+// extends a cheapest partial path and no path is explored in vain.
+func (b *BDD) TopK(k int, ties bool, costs map[f.Literal]int, variables ...f.Variable) ([]*model.Model, []int) {
+	kernel := b.Kernel
+	kernel.restoreVariables()
+	projected := f.NewMutableVarSet()
+	if len(variables) == 0 {
+		for _, variable := range kernel.idx2var {
+			projected.Add(variable)
+		}
+	}
+	for _, variable := range variables {
+		if _, ok := kernel.var2idx[variable]; ok {
+			projected.Add(variable)
+		}
+	}
+	// variables which are not projected are quantified unless they have
+	// costs, then their projected models can occur multiple times
+	var quantified []f.Variable
+	hidden := false
+	for _, variable := range kernel.idx2var {
+		if projected.Contains(variable) {
+			continue
+		}
+		if costs[variable.AsLiteral()] != 0 || costs[variable.Negate(kernel.fac)] != 0 {
+			hidden = true
+		} else {
+			quantified = append(quantified, variable)
+		}
+	}
+	root := b
+	if len(quantified) > 0 {
+		root = b.Exists(quantified...)
+		defer root.Free()
+	}
+
+	search := &topKSearch{kernel: kernel, costs: costs, projected: projected}
+	_, search.values = nodeValues(kernel, weight.MinCost, costs, root.Index)
+	search.push(&topKState{node: root.Index, level: 0})
+	var models []*model.Model
+	var modelCosts []int
+	seen := make(map[string]bool)
+	for search.Len() > 0 {
+		state := heap.Pop(search).(*topKState)
+		if len(models) >= k && (!ties || len(models) == 0 || state.priority > modelCosts[len(models)-1]) {
+			break
+		}
+		if state.level < kernel.varnum {
+			search.expand(state)
+			continue
+		}
+		mdl := state.assignment.model()
+		if hidden {
+			key := mdl.Sprint(kernel.fac)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		models = append(models, mdl)
+		modelCosts = append(modelCosts, state.cost)
+	}
+	return models, modelCosts
+}
+
+// A topKSearch is the priority queue of the best-first search for the top-k
+// models.  The values are the minimal costs of the nodes.
+type topKSearch struct {
+	kernel    *Kernel
+	costs     map[f.Literal]int
+	projected *f.MutableVarSet
+	values    map[int32]int
+	states    []*topKState
+	count     int
+}
+
+// A topKState is a partial path which ends at the node and has assigned all
+// levels before the given level.  The priority is the cost of the path plus
+// the minimal cost of its completion.
+type topKState struct {
+	node       int32
+	level      int32
+	cost       int
+	priority   int
+	order      int
+	assignment *topKAssignment
+}
+
+// A topKAssignment is a persistent list of the projected literals of a path.
+type topKAssignment struct {
+	literal f.Literal
+	parent  *topKAssignment
+}
+
+func (a *topKAssignment) model() *model.Model {
+	var literals []f.Literal
+	for ; a != nil; a = a.parent {
+		literals = append(literals, a.literal)
+	}
+	return model.New(literals...)
+}
+
+// expand pushes the extensions of the state by the next level.  On a level
+// with a node, the path follows the node's edges.  A level skipped by the
+// path is free: if it is projected, both phases are pushed, otherwise only
+// the cheaper phase.
+func (s *topKSearch) expand(state *topKState) {
+	k := s.kernel
+	variable, named := k.levelVariable(state.level)
+	if state.level == k.level(state.node) {
+		for _, phase := range []bool{false, true} {
+			child := k.low(state.node)
+			if phase {
+				child = k.high(state.node)
+			}
+			s.pushChild(state, child, variable, named, phase)
+		}
+	} else if named && s.projected.Contains(variable) {
+		s.pushChild(state, state.node, variable, named, false)
+		s.pushChild(state, state.node, variable, named, true)
+	} else if named {
+		pos := weight.Of(weight.MinCost, s.costs, variable.AsLiteral())
+		neg := weight.Of(weight.MinCost, s.costs, variable.Negate(k.fac))
+		s.pushChild(state, state.node, variable, named, pos < neg)
+	} else {
+		s.pushChild(state, state.node, variable, named, false)
+	}
+}
+
+func (s *topKSearch) pushChild(state *topKState, node int32, variable f.Variable, named, phase bool) {
+	if node == bddFalse {
+		return
+	}
+	child := &topKState{node: node, level: state.level + 1, cost: state.cost, assignment: state.assignment}
+	if named {
+		literal := variable.Negate(s.kernel.fac)
+		if phase {
+			literal = variable.AsLiteral()
+		}
+		child.cost += weight.Of(weight.MinCost, s.costs, literal)
+		if s.projected.Contains(variable) {
+			child.assignment = &topKAssignment{literal, state.assignment}
+		}
+	}
+	s.push(child)
+}
+
+func (s *topKSearch) push(state *topKState) {
+	completion := freeLevels(s.kernel, weight.MinCost, s.costs, state.level-1, s.kernel.level(state.node), s.values[state.node], nil)
+	if weight.MinCost.IsZero(completion) {
+		return
+	}
+	state.priority = state.cost + completion
+	state.order = s.count
+	s.count++
+	heap.Push(s, state)
+}
+
+func (s *topKSearch) Len() int { return len(s.states) }
+
+func (s *topKSearch) Less(i, j int) bool {
+	if s.states[i].priority != s.states[j].priority {
+		return s.states[i].priority < s.states[j].priority
+	}
+	return s.states[i].order < s.states[j].order
+}
+
+func (s *topKSearch) Swap(i, j int) { s.states[i], s.states[j] = s.states[j], s.states[i] }
+
+func (s *topKSearch) Push(x any) { s.states = append(s.states, x.(*topKState)) }
+
+func (s *topKSearch) Pop() any {
+	state := s.states[len(s.states)-1]
+	s.states = s.states[:len(s.states)-1]
+	return state
+}
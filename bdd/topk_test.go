@@ -0,0 +1,36 @@
+package bdd
+
+import (
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBDDTopK(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+	kernel := NewKernelWithOrdering(fac, []f.Variable{a, b, c}, 100, 100)
+	bdd := CompileWithKernel(fac, p.ParseUnsafe("a | b"), kernel)
+	costs := map[f.Literal]int{a.AsLiteral(): 2, b.AsLiteral(): 1, c.AsLiteral(): -1}
+
+	models, modelCosts := bdd.TopK(3, false, costs, a, b)
+	assert.Equal([]int{0, 1, 2}, modelCosts)
+	assert.ElementsMatch([]f.Literal{a.Negate(fac), b.AsLiteral()}, models[0].Literals)
+	assert.ElementsMatch([]f.Literal{a.AsLiteral(), b.Negate(fac)}, models[1].Literals)
+	assert.ElementsMatch([]f.Literal{a.AsLiteral(), b.AsLiteral()}, models[2].Literals)
+
+	models, modelCosts = bdd.TopK(2, false, costs)
+	assert.Equal([]int{0, 1}, modelCosts)
+	assert.ElementsMatch([]f.Literal{a.Negate(fac), b.AsLiteral(), c.AsLiteral()}, models[0].Literals)
+
+	_, modelCosts = bdd.TopK(1, true, map[f.Literal]int{c.AsLiteral(): 1})
+	assert.Equal([]int{0, 0, 0}, modelCosts)
+	models, _ = bdd.TopK(10, false, costs, a, b)
+	assert.Len(models, 3)
+	models, _ = CompileWithKernel(fac, fac.Falsum(), kernel).TopK(10, false, costs)
+	assert.Empty(models)
+}
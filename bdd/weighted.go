@@ -0,0 +1,185 @@
+package bdd
+
+import (
+	"math"
+	"math/big"
+	"slices"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/weight"
+)
+
+// WeightedModelCount returns the sum of the weights of all models of the BDD.
+// As for the model count, the models are over all variables of the BDD's
+// kernel.  The weight of a model is the product of the weights of its
+// literals.  Literals which are not in the given weight map have weight 1.
+// The computation is linear in the number of nodes of the BDD times the
+// number of variables.
+func (b *BDD) WeightedModelCount(weights map[f.Literal]*big.Rat) *big.Rat {
+	return weightedModelCount(b, weight.Rational, weights, nil)
+}
+
+// WeightedModelCountFloat returns the weighted model count of the BDD like
+// WeightedModelCount but computes with float64 weights.
+func (b *BDD) WeightedModelCountFloat(weights map[f.Literal]float64) float64 {
+	return weightedModelCount(b, weight.Float, weights, nil)
+}
+
+// LogWeightedModelCount returns the natural logarithm of the weighted model
+// count of the BDD.  The computation is performed in log-space and therefore
+// does not underflow for small weights over many variables.
+func (b *BDD) LogWeightedModelCount(weights map[f.Literal]float64) float64 {
+	return weightedModelCount(b, weight.Log, weight.ToLog(weights), nil)
+}
+
+// Marginals returns for each literal over the variables of the BDD's kernel
+// its marginal, i.e. the weighted model count of the models in which the
+// literal is true divided by the weighted model count of the BDD.  If the
+// weights of the two literals of each variable add up to one, the marginal
+// of a literal is its probability among the models.  All marginals are
+// computed in one bottom-up and one top-down pass over the BDD.  If the
+// weighted model count is zero, all marginals are zero.
+func (b *BDD) Marginals(weights map[f.Literal]*big.Rat) map[f.Literal]*big.Rat {
+	marginals := make(map[f.Literal]*big.Rat)
+	total := weightedModelCount(b, weight.Rational, weights, marginals)
+	return weight.Normalize(weight.Rational, marginals, total)
+}
+
+// MarginalsFloat returns the marginals of the literals like Marginals but
+// computes with float64 weights.  The computation is performed in log-space.
+func (b *BDD) MarginalsFloat(weights map[f.Literal]float64) map[f.Literal]float64 {
+	marginals := make(map[f.Literal]float64)
+	total := weightedModelCount(b, weight.Log, weight.ToLog(weights), marginals)
+	for literal, marginal := range weight.Normalize(weight.Log, marginals, total) {
+		marginals[literal] = math.Exp(marginal)
+	}
+	return marginals
+}
+
+// weightedModelCount computes the weighted model count of the BDD.  If
+// literalWeights is not nil, the weighted model count of the models of each
+// literal is stored in it.  The bottom-up pass computes the weighted count of
+// each node, the top-down pass the weight of all paths from the root to each
+// node.  The levels skipped by an edge are free variables.
+func weightedModelCount[T any](
+	b *BDD,
+	s weight.Semiring[T],
+	weights map[f.Literal]T,
+	literalWeights map[f.Literal]T,
+) T {
+	k := b.Kernel
+	k.restoreVariables()
+	nodes, values := nodeValues(k, s, weights, b.Index)
+	if literalWeights == nil {
+		return freeLevels(k, s, weights, -1, k.level(b.Index), values[b.Index], nil)
+	}
+
+	for _, variable := range k.idx2var {
+		literalWeights[variable.AsLiteral()] = s.Zero()
+		literalWeights[variable.Negate(k.fac)] = s.Zero()
+	}
+	total := freeLevels(k, s, weights, -1, k.level(b.Index), values[b.Index], literalWeights)
+	paths := map[int32]T{b.Index: freeLevels(k, s, weights, -1, k.level(b.Index), s.One(), nil)}
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		path := paths[node]
+		variable, named := k.levelVariable(k.level(node))
+		for _, phase := range []bool{false, true} {
+			child := k.low(node)
+			if phase {
+				child = k.high(node)
+			}
+			edge := path
+			if named {
+				literal := variable.Negate(k.fac)
+				if phase {
+					literal = variable.AsLiteral()
+				}
+				edge = s.Mul(edge, weight.Of(s, weights, literal))
+				childValue := freeLevels(k, s, weights, k.level(node), k.level(child), values[child], nil)
+				weight.AddTo(s, literalWeights, literal, s.Mul(edge, childValue))
+			}
+			freeLevels(k, s, weights, k.level(node), k.level(child), s.Mul(edge, values[child]), literalWeights)
+			if child > bddTrue {
+				weight.AddTo(s, paths, child, freeLevels(k, s, weights, k.level(node), k.level(child), edge, nil))
+			}
+		}
+	}
+	return total
+}
+
+// nodeValues returns the inner nodes reachable from the root sorted by
+// descending level and the weighted model count of each node over the
+// levels below the node.
+func nodeValues[T any](k *Kernel, s weight.Semiring[T], weights map[f.Literal]T, root int32) ([]int32, map[int32]T) {
+	nodes := k.reachableNodes(root)
+	slices.SortFunc(nodes, func(n1, n2 int32) int { return int(k.level(n2) - k.level(n1)) })
+	values := map[int32]T{bddFalse: s.Zero(), bddTrue: s.One()}
+	for _, node := range nodes {
+		low, high := childValues(k, s, weights, values, node)
+		values[node] = s.Add(low, high)
+	}
+	return nodes, values
+}
+
+// childValues returns the weighted model counts of the low and the high
+// child of the node including the weight of the node's literal and of the
+// levels skipped by the edges.
+func childValues[T any](k *Kernel, s weight.Semiring[T], weights map[f.Literal]T, values map[int32]T, node int32) (T, T) {
+	variable, named := k.levelVariable(k.level(node))
+	low := freeLevels(k, s, weights, k.level(node), k.level(k.low(node)), values[k.low(node)], nil)
+	high := freeLevels(k, s, weights, k.level(node), k.level(k.high(node)), values[k.high(node)], nil)
+	if named {
+		low = s.Mul(low, weight.Of(s, weights, variable.Negate(k.fac)))
+		high = s.Mul(high, weight.Of(s, weights, variable.AsLiteral()))
+	}
+	return low, high
+}
+
+// reachableNodes returns all inner nodes reachable from the given node.
+func (k *Kernel) reachableNodes(root int32) []int32 {
+	var nodes []int32
+	visited := make(map[int32]bool)
+	stack := []int32{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node <= bddTrue || visited[node] {
+			continue
+		}
+		visited[node] = true
+		nodes = append(nodes, node)
+		stack = append(stack, k.low(node), k.high(node))
+	}
+	return nodes
+}
+
+// levelVariable returns the variable on the given level and whether there is
+// a variable assigned to the level's BDD variable.
+func (k *Kernel) levelVariable(level int32) (f.Variable, bool) {
+	variable, ok := k.idx2var[k.level2var[level]]
+	return variable, ok
+}
+
+// freeLevels multiplies base with the factors of the free variables on the
+// levels strictly between from and to.  Levels without a variable have the
+// factor 2.
+func freeLevels[T any](
+	k *Kernel,
+	s weight.Semiring[T],
+	weights map[f.Literal]T,
+	from, to int32,
+	base T,
+	literalWeights map[f.Literal]T,
+) T {
+	var variables []f.Variable
+	two := s.Add(s.One(), s.One())
+	for level := from + 1; level < to; level++ {
+		if variable, ok := k.levelVariable(level); ok {
+			variables = append(variables, variable)
+		} else {
+			base = s.Mul(base, two)
+		}
+	}
+	return weight.FreeVariables(k.fac, s, weights, variables, base, literalWeights)
+}
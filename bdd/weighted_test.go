@@ -0,0 +1,119 @@
+package bdd
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBDDWeightedModelCountSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b := fac.Var("a"), fac.Var("b")
+	bdd := Compile(fac, p.ParseUnsafe("a | b"))
+
+	weights := map[f.Literal]*big.Rat{
+		a.AsLiteral(): big.NewRat(1, 4), a.Negate(fac): big.NewRat(3, 4),
+		b.AsLiteral(): big.NewRat(1, 2), b.Negate(fac): big.NewRat(1, 2),
+	}
+	assert.Equal(big.NewRat(5, 8), bdd.WeightedModelCount(weights))
+	assert.Equal(big.NewRat(3, 1), bdd.WeightedModelCount(nil))
+
+	marginals := bdd.Marginals(weights)
+	assert.Equal(big.NewRat(2, 5), marginals[a.AsLiteral()])
+	assert.Equal(big.NewRat(3, 5), marginals[a.Negate(fac)])
+	assert.Equal(big.NewRat(4, 5), marginals[b.AsLiteral()])
+	assert.Equal(big.NewRat(1, 5), marginals[b.Negate(fac)])
+
+	floatWeights := map[f.Literal]float64{a.AsLiteral(): 0.25, a.Negate(fac): 0.75}
+	assert.InDelta(1.25, bdd.WeightedModelCountFloat(floatWeights), 1e-9)
+	assert.InDelta(math.Log(1.25), bdd.LogWeightedModelCount(floatWeights), 1e-9)
+	floatMarginals := bdd.MarginalsFloat(floatWeights)
+	assert.InDelta(0.4, floatMarginals[a.AsLiteral()], 1e-9)
+	assert.InDelta(0.8, floatMarginals[b.AsLiteral()], 1e-9)
+
+	c := fac.Var("c")
+	kernel := NewKernelWithOrdering(fac, []f.Variable{c, a, fac.Var("x"), b}, 100, 100)
+	withFree := CompileWithKernel(fac, p.ParseUnsafe("a | b"), kernel)
+	weights[c.AsLiteral()], weights[c.Negate(fac)] = big.NewRat(1, 3), big.NewRat(2, 3)
+	assert.Equal(big.NewRat(5, 4), withFree.WeightedModelCount(weights))
+	marginals = withFree.Marginals(weights)
+	assert.Equal(big.NewRat(1, 3), marginals[c.AsLiteral()])
+	assert.Equal(big.NewRat(1, 2), marginals[fac.Lit("x", true)])
+	assert.Equal(big.NewRat(2, 5), marginals[a.AsLiteral()])
+	assert.Equal(big.NewRat(4, 5), marginals[b.AsLiteral()])
+
+	falsum := Compile(fac, fac.Falsum())
+	assert.Equal(0, falsum.WeightedModelCount(weights).Sign())
+	for _, marginal := range falsum.Marginals(weights) {
+		assert.Equal(0, marginal.Sign())
+	}
+}
+
+func TestBDDWeightedModelCountRandom(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 8
+	config.Seed = 31
+	r := randomizer.New(fac, config)
+	for i := 0; i < 50; i++ {
+		formula := r.Formula(4)
+		bdd := Compile(fac, formula)
+		vars := bdd.VariableOrder()
+		weights := make(map[f.Literal]*big.Rat)
+		floatWeights := make(map[f.Literal]float64)
+		for j, variable := range vars {
+			weights[variable.AsLiteral()] = big.NewRat(int64(j+1), 10)
+			weights[variable.Negate(fac)] = big.NewRat(int64(10-j), 7)
+			floatWeights[variable.AsLiteral()], _ = weights[variable.AsLiteral()].Float64()
+			floatWeights[variable.Negate(fac)], _ = weights[variable.Negate(fac)].Float64()
+		}
+		models := bdd.ModelEnumeration(vars...)
+		total, literalTotals := bruteForceWeights(models, weights)
+		assert.Zero(total.Cmp(bdd.WeightedModelCount(weights)))
+		totalFloat, _ := total.Float64()
+		assert.InDelta(totalFloat, bdd.WeightedModelCountFloat(floatWeights), 1e-9)
+		if total.Sign() > 0 {
+			assert.InDelta(math.Log(totalFloat), bdd.LogWeightedModelCount(floatWeights), 1e-9)
+		}
+		marginals := bdd.Marginals(weights)
+		floatMarginals := bdd.MarginalsFloat(floatWeights)
+		assert.Len(marginals, 2*len(vars))
+		for literal, marginal := range marginals {
+			expected := new(big.Rat)
+			if total.Sign() > 0 && literalTotals[literal] != nil {
+				expected.Quo(literalTotals[literal], total)
+			}
+			assert.Zero(expected.Cmp(marginal))
+			expectedFloat, _ := expected.Float64()
+			assert.InDelta(expectedFloat, floatMarginals[literal], 1e-9)
+		}
+	}
+}
+
+func bruteForceWeights(models []*model.Model, weights map[f.Literal]*big.Rat) (*big.Rat, map[f.Literal]*big.Rat) {
+	total := new(big.Rat)
+	literalTotals := make(map[f.Literal]*big.Rat)
+	for _, m := range models {
+		w := big.NewRat(1, 1)
+		for _, literal := range m.Literals {
+			w.Mul(w, weights[literal])
+		}
+		total.Add(total, w)
+		for _, literal := range m.Literals {
+			if literalTotals[literal] == nil {
+				literalTotals[literal] = new(big.Rat)
+			}
+			literalTotals[literal].Add(literalTotals[literal], w)
+		}
+	}
+	return total, literalTotals
+}
@@ -96,3 +96,30 @@ func countWithBDD(fac f.Factory, formula f.Formula) *big.Int {
 	bdd := bdd.CompileWithKernel(fac, formula, kernel)
 	return bdd.ModelCount()
 }
+
+func TestDecomposition(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	cnf := p.ParseUnsafe("(a | b) & (~a | c) & (c | d) & (~d | e)")
+	decomposition, err := ComputeDecomposition(fac, cnf)
+	assert.Nil(err)
+	var clauses []f.Formula
+	var collect func(d *Decomposition)
+	collect = func(d *Decomposition) {
+		if d.IsLeaf() {
+			clauses = append(clauses, d.Clause)
+			return
+		}
+		collect(d.Left)
+		collect(d.Right)
+	}
+	collect(decomposition)
+	assert.ElementsMatch(fac.Operands(cnf), clauses)
+
+	leaf, err := ComputeDecomposition(fac, p.ParseUnsafe("a | b"))
+	assert.Nil(err)
+	assert.True(leaf.IsLeaf())
+	_, err = ComputeDecomposition(fac, p.ParseUnsafe("a => b"))
+	assert.NotNil(err)
+}
@@ -21,7 +21,23 @@
 //	dnnf := dnnf.Compile(fac, formula)
 //
 // The dnnf itself is just a regular formula.  In LogicNG DNNFs are primarily
-// used for model counting.
+// used for model counting.  Besides the model count, a compiled d-DNNF
+// supports consistency, validity and clausal entailment checks, conditioning,
+// weighted model counting, model enumeration, the computation of minimum
+// cardinality models and the number of models per literal:
+//
+//	consistent := dnnf.IsConsistent()
+//	entailed, err := dnnf.Entails(clause)
+//	conditioned, err := dnnf.Condition(fac.Lit("a", true))
+//	frequencies := dnnf.LiteralModelCounts()
+//
+// Models can be drawn uniformly at random or according to literal weights
+// with Sample and SampleWeighted:
+//
+//	samples := dnnf.Sample(100, rand.New(rand.NewSource(42)))
+//
+// TopK returns the models with the smallest cost under a linear objective
+// over the literals.
 //
 // [DNNF]: https://dl.acm.org/doi/10.1145/502090.502091
 package dnnf
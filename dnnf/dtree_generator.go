@@ -216,3 +216,42 @@ func (g *graph) getCopyOfEdgeList() [][]int32 {
 	}
 	return result
 }
+
+// A Decomposition is a decomposition tree (dtree) of a CNF.  The leaves of
+// the tree hold the clauses of the CNF and each inner node splits its clauses
+// into the clauses of its left and right subtree.
+type Decomposition struct {
+	Left   *Decomposition // left subtree of an inner node, nil for a leaf
+	Right  *Decomposition // right subtree of an inner node, nil for a leaf
+	Clause f.Formula      // clause of a leaf
+}
+
+// IsLeaf reports whether the decomposition is a leaf.
+func (d *Decomposition) IsLeaf() bool {
+	return d.Left == nil
+}
+
+// ComputeDecomposition computes a decomposition tree of the given CNF with
+// the min-fill heuristic which is also used by the DNNF compiler.  Returns an
+// error if the formula is not in CNF.
+func ComputeDecomposition(fac f.Factory, cnf f.Formula) (*Decomposition, error) {
+	if !normalform.IsCNF(fac, cnf) {
+		return nil, errorx.BadInput("formula is not in CNF")
+	}
+	if cnf.IsAtomic() || cnf.Sort() != f.SortAnd {
+		return &Decomposition{Clause: cnf}, nil
+	}
+	tree, _ := generateMinFillDtree(fac, cnf, handler.NopHandler)
+	return exportDtree(tree), nil
+}
+
+func exportDtree(tree dtree) *Decomposition {
+	switch t := tree.(type) {
+	case *dtreeLeaf:
+		return &Decomposition{Clause: t.clause}
+	case *dtreeNode:
+		return &Decomposition{Left: exportDtree(t.left), Right: exportDtree(t.right)}
+	default:
+		panic(errorx.IllegalState("unknown dtree type"))
+	}
+}
@@ -0,0 +1,413 @@
+package dnnf
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/booleworks/logicng-go/assignment"
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+)
+
+// IsConsistent reports whether the DNNF has at least one model.  The check is
+// linear in the size of the DNNF.
+func (d *DNNF) IsConsistent() bool {
+	return d.evaluator(nil).consistent(d.Formula)
+}
+
+// IsValid reports whether every assignment of the original variables is a
+// model of the DNNF.  The check is linear in the size of the DNNF.
+func (d *DNNF) IsValid() bool {
+	return d.ModelCount().Cmp(pow2(d.OriginalVars.Size())) == 0
+}
+
+// Entails reports whether the DNNF entails the given clause.  The DNNF
+// entails a clause iff the DNNF conditioned on the negation of the clause is
+// inconsistent.  Returns an error if the given formula is not a clause.
+func (d *DNNF) Entails(clause f.Formula) (bool, error) {
+	var literals []f.Literal
+	switch clause.Sort() {
+	case f.SortTrue:
+		return true, nil
+	case f.SortFalse:
+		return !d.IsConsistent(), nil
+	case f.SortLiteral:
+		literals = []f.Literal{f.Literal(clause)}
+	case f.SortOr:
+		for _, op := range d.Fac.Operands(clause) {
+			if op.Sort() != f.SortLiteral {
+				return false, errorx.BadInput("formula is not a clause")
+			}
+			literals = append(literals, f.Literal(op))
+		}
+	default:
+		return false, errorx.BadInput("formula is not a clause")
+	}
+	negation := make(map[f.Variable]bool, len(literals))
+	for _, literal := range literals {
+		if phase, ok := negation[literal.Variable()]; ok && phase == literal.IsPos() {
+			return true, nil // the clause is a tautology
+		}
+		negation[literal.Variable()] = !literal.IsPos()
+	}
+	return !d.evaluator(negation).consistent(d.Formula), nil
+}
+
+// Condition returns the DNNF conditioned on the given literals.  Each
+// literal of the DNNF is replaced by true if it is one of the given literals
+// and by false if its negation is one of the given literals.  The variables
+// of the literals are removed from the original variables of the result.
+// Returns an error if the literals contain a variable in both phases.
+func (d *DNNF) Condition(literals ...f.Literal) (*DNNF, error) {
+	ass, err := assignment.New(d.Fac, literals...)
+	if err != nil {
+		return nil, err
+	}
+	originalVars := f.NewMutableVarSetCopy(d.OriginalVars)
+	for _, literal := range literals {
+		originalVars.Remove(literal.Variable())
+	}
+	return &DNNF{d.Fac, assignment.Restrict(d.Fac, d.Formula, ass), originalVars.AsImmutable()}, nil
+}
+
+// LiteralModelCount returns the number of models of the DNNF over its
+// original variables in which the given literal is true.  If the variable of
+// the literal is not an original variable of the DNNF, the model count of the
+// DNNF is returned.
+func (d *DNNF) LiteralModelCount(literal f.Literal) *big.Int {
+	if !d.OriginalVars.Contains(literal.Variable()) {
+		return d.ModelCount()
+	}
+	e := d.evaluator(map[f.Variable]bool{literal.Variable(): literal.IsPos()})
+	result := e.count(d.Formula)
+	return result.Mul(result, pow2(e.dontCares()))
+}
+
+// LiteralModelCounts returns for each literal over the original variables of
+// the DNNF the number of models in which the literal is true.  Divided by the
+// model count of the DNNF, this yields the relative frequency of each literal
+// among all models.  The computation requires one pass over the DNNF per
+// original variable.
+func (d *DNNF) LiteralModelCounts() map[f.Literal]*big.Int {
+	total := d.ModelCount()
+	counts := make(map[f.Literal]*big.Int, 2*d.OriginalVars.Size())
+	for _, variable := range d.OriginalVars.Content() {
+		pos := d.LiteralModelCount(variable.AsLiteral())
+		counts[variable.AsLiteral()] = pos
+		counts[variable.Negate(d.Fac)] = new(big.Int).Sub(total, pos)
+	}
+	return counts
+}
+
+// ProjectedModelCount returns the number of assignments of the given
+// variables which can be extended to a model of the DNNF.  In contrast to
+// the other queries, projected model counting is not polytime on d-DNNFs: if
+// the DNNF contains variables which are not projected, the count is computed
+// by a search over the projected variables with a consistency check in each
+// step.
+func (d *DNNF) ProjectedModelCount(variables ...f.Variable) *big.Int {
+	dnnfVariables := f.Variables(d.Fac, d.Formula)
+	projected := f.NewVarSet(variables...)
+	var relevant []f.Variable
+	irrelevant := 0
+	for _, variable := range projected.Content() {
+		if dnnfVariables.Contains(variable) {
+			relevant = append(relevant, variable)
+		} else {
+			irrelevant++
+		}
+	}
+	if projected.ContainsAll(dnnfVariables) {
+		result := d.evaluator(nil).count(d.Formula)
+		return result.Mul(result, pow2(irrelevant))
+	}
+	assignment := make(map[f.Variable]bool, len(relevant))
+	result := d.projectedCount(relevant, assignment)
+	return result.Mul(result, pow2(irrelevant))
+}
+
+func (d *DNNF) projectedCount(variables []f.Variable, assignment map[f.Variable]bool) *big.Int {
+	if !d.evaluator(assignment).consistent(d.Formula) {
+		return big.NewInt(0)
+	}
+	if len(variables) == 0 {
+		return big.NewInt(1)
+	}
+	variable := variables[0]
+	assignment[variable] = true
+	result := d.projectedCount(variables[1:], assignment)
+	assignment[variable] = false
+	result.Add(result, d.projectedCount(variables[1:], assignment))
+	delete(assignment, variable)
+	return result
+}
+
+// ModelEnumeration returns all models of the DNNF projected to the given
+// variables.  If no variables are given, the models over the original
+// variables of the DNNF are returned.  The models are enumerated by a
+// backtracking search over the variables with a consistency check in each
+// step, therefore the running time is polynomial in the number of models.
+func (d *DNNF) ModelEnumeration(variables ...f.Variable) []*model.Model {
+	if len(variables) == 0 {
+		variables = d.OriginalVars.Content()
+	} else {
+		variables = f.NewVarSet(variables...).Content()
+	}
+	models := []*model.Model{}
+	assignment := make(map[f.Variable]bool, len(variables))
+	d.enumerate(variables, 0, assignment, func(map[f.Variable]bool) bool { return true }, &models)
+	return models
+}
+
+// MinimumCardinality returns the minimum number of positive literals in a
+// model of the DNNF over its original variables.  Returns -1 if the DNNF is
+// inconsistent.  The computation is linear in the size of the DNNF.
+func (d *DNNF) MinimumCardinality() int {
+	card := d.evaluator(nil).minCard(d.Formula)
+	if card == math.MaxInt {
+		return -1
+	}
+	return card
+}
+
+// MinimumCardinalityModels returns all models of the DNNF over its original
+// variables with a minimum number of positive literals.
+func (d *DNNF) MinimumCardinalityModels() []*model.Model {
+	models := []*model.Model{}
+	minimum := d.MinimumCardinality()
+	if minimum < 0 {
+		return models
+	}
+	dnnfVariables := f.Variables(d.Fac, d.Formula)
+	isMinimal := func(assignment map[f.Variable]bool) bool {
+		card := d.evaluator(assignment).minCard(d.Formula)
+		for variable, phase := range assignment {
+			if phase && !dnnfVariables.Contains(variable) {
+				card++
+			}
+		}
+		return card == minimum
+	}
+	assignment := make(map[f.Variable]bool, d.OriginalVars.Size())
+	d.enumerate(d.OriginalVars.Content(), 0, assignment, isMinimal, &models)
+	return models
+}
+
+func (d *DNNF) enumerate(
+	variables []f.Variable,
+	index int,
+	assignment map[f.Variable]bool,
+	accept func(map[f.Variable]bool) bool,
+	models *[]*model.Model,
+) {
+	if !d.evaluator(assignment).consistent(d.Formula) || !accept(assignment) {
+		return
+	}
+	if index == len(variables) {
+		*models = append(*models, d.assignmentModel(variables, assignment))
+		return
+	}
+	variable := variables[index]
+	for _, phase := range []bool{false, true} {
+		assignment[variable] = phase
+		d.enumerate(variables, index+1, assignment, accept, models)
+	}
+	delete(assignment, variable)
+}
+
+// evaluator evaluates queries on a DNNF under a partial assignment without
+// constructing the conditioned DNNF.
+type evaluator struct {
+	d           *DNNF
+	assignment  map[f.Variable]bool
+	consistents map[f.Formula]bool
+	counts      map[f.Formula]*big.Int
+	frees       map[f.Formula]int
+	positives   map[f.Formula]int
+	minCards    map[f.Formula]int
+}
+
+func (d *DNNF) evaluator(assignment map[f.Variable]bool) *evaluator {
+	return &evaluator{
+		d:           d,
+		assignment:  assignment,
+		consistents: make(map[f.Formula]bool),
+		counts:      make(map[f.Formula]*big.Int),
+		frees:       make(map[f.Formula]int),
+		positives:   make(map[f.Formula]int),
+		minCards:    make(map[f.Formula]int),
+	}
+}
+
+// literalValue returns whether the literal is satisfied by the assignment and
+// whether its variable is assigned at all.
+func (e *evaluator) literalValue(node f.Formula) (satisfied, assigned bool) {
+	literal := f.Literal(node)
+	phase, ok := e.assignment[literal.Variable()]
+	return ok && phase == literal.IsPos(), ok
+}
+
+func (e *evaluator) consistent(node f.Formula) bool {
+	if cached, ok := e.consistents[node]; ok {
+		return cached
+	}
+	var result bool
+	switch node.Sort() {
+	case f.SortTrue:
+		result = true
+	case f.SortFalse:
+		result = false
+	case f.SortLiteral:
+		satisfied, assigned := e.literalValue(node)
+		result = satisfied || !assigned
+	case f.SortAnd:
+		result = true
+		for _, op := range e.d.Fac.Operands(node) {
+			if !e.consistent(op) {
+				result = false
+				break
+			}
+		}
+	case f.SortOr:
+		result = false
+		for _, op := range e.d.Fac.Operands(node) {
+			if e.consistent(op) {
+				result = true
+				break
+			}
+		}
+	}
+	e.consistents[node] = result
+	return result
+}
+
+// free returns the number of unassigned variables of the node.
+func (e *evaluator) free(node f.Formula) int {
+	if cached, ok := e.frees[node]; ok {
+		return cached
+	}
+	result := 0
+	for _, variable := range f.Variables(e.d.Fac, node).Content() {
+		if _, ok := e.assignment[variable]; !ok {
+			result++
+		}
+	}
+	e.frees[node] = result
+	return result
+}
+
+// positive returns the number of variables of the node which are assigned to
+// true.
+func (e *evaluator) positive(node f.Formula) int {
+	if cached, ok := e.positives[node]; ok {
+		return cached
+	}
+	result := 0
+	for _, variable := range f.Variables(e.d.Fac, node).Content() {
+		if e.assignment[variable] {
+			result++
+		}
+	}
+	e.positives[node] = result
+	return result
+}
+
+// dontCares returns the number of unassigned original variables which do not
+// occur in the DNNF.
+func (e *evaluator) dontCares() int {
+	dnnfVariables := f.Variables(e.d.Fac, e.d.Formula)
+	result := 0
+	for _, variable := range e.d.OriginalVars.Content() {
+		if _, ok := e.assignment[variable]; !ok && !dnnfVariables.Contains(variable) {
+			result++
+		}
+	}
+	return result
+}
+
+// count returns the number of models of the node over its unassigned
+// variables which are consistent with the assignment.
+func (e *evaluator) count(node f.Formula) *big.Int {
+	if cached, ok := e.counts[node]; ok {
+		return new(big.Int).Set(cached)
+	}
+	var result *big.Int
+	switch node.Sort() {
+	case f.SortTrue:
+		result = big.NewInt(1)
+	case f.SortFalse:
+		result = big.NewInt(0)
+	case f.SortLiteral:
+		if satisfied, assigned := e.literalValue(node); satisfied || !assigned {
+			result = big.NewInt(1)
+		} else {
+			result = big.NewInt(0)
+		}
+	case f.SortAnd:
+		result = big.NewInt(1)
+		for _, op := range e.d.Fac.Operands(node) {
+			result.Mul(result, e.count(op))
+		}
+	case f.SortOr:
+		result = big.NewInt(0)
+		free := e.free(node)
+		for _, op := range e.d.Fac.Operands(node) {
+			opCount := e.count(op)
+			result.Add(result, opCount.Mul(opCount, pow2(free-e.free(op))))
+		}
+	}
+	e.counts[node] = new(big.Int).Set(result)
+	return result
+}
+
+// minCard returns the minimum number of positive literals of the node's
+// variables in a model of the node which is consistent with the assignment.
+// Returns math.MaxInt if there is no such model.
+func (e *evaluator) minCard(node f.Formula) int {
+	if cached, ok := e.minCards[node]; ok {
+		return cached
+	}
+	var result int
+	switch node.Sort() {
+	case f.SortTrue:
+		result = 0
+	case f.SortFalse:
+		result = math.MaxInt
+	case f.SortLiteral:
+		satisfied, assigned := e.literalValue(node)
+		switch {
+		case assigned && !satisfied:
+			result = math.MaxInt
+		case node.IsPos():
+			result = 1
+		default:
+			result = 0
+		}
+	case f.SortAnd:
+		result = 0
+		for _, op := range e.d.Fac.Operands(node) {
+			card := e.minCard(op)
+			if card == math.MaxInt {
+				result = math.MaxInt
+				break
+			}
+			result += card
+		}
+	case f.SortOr:
+		// positive assigned variables which are not in an operand add to its cardinality
+		result = math.MaxInt
+		positive := e.positive(node)
+		for _, op := range e.d.Fac.Operands(node) {
+			if card := e.minCard(op); card != math.MaxInt {
+				result = min(result, card+positive-e.positive(op))
+			}
+		}
+	}
+	e.minCards[node] = result
+	return result
+}
+
+func pow2(exponent int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(exponent))
+}
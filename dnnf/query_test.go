@@ -0,0 +1,179 @@
+package dnnf
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/booleworks/logicng-go/bdd"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/normalform"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNNFTrivialQueries(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+
+	verum := Compile(fac, fac.Verum())
+	assert.True(verum.IsConsistent())
+	assert.True(verum.IsValid())
+	assert.Len(verum.ModelEnumeration(), 1)
+	assert.Equal(0, verum.MinimumCardinality())
+	falsum := Compile(fac, fac.Falsum())
+	assert.False(falsum.IsConsistent())
+	assert.False(falsum.IsValid())
+	assert.Empty(falsum.ModelEnumeration())
+	assert.Equal(-1, falsum.MinimumCardinality())
+	assert.Empty(falsum.MinimumCardinalityModels())
+
+	dnnf := Compile(fac, p.ParseUnsafe("(a | b) & (~a | c)"))
+	entailed, err := dnnf.Entails(p.ParseUnsafe("b | c"))
+	assert.Nil(err)
+	assert.True(entailed)
+	entailed, err = dnnf.Entails(p.ParseUnsafe("a | c"))
+	assert.Nil(err)
+	assert.False(entailed)
+	entailed, err = dnnf.Entails(p.ParseUnsafe("a | ~a"))
+	assert.Nil(err)
+	assert.True(entailed)
+	_, err = dnnf.Entails(p.ParseUnsafe("a & b"))
+	assert.NotNil(err)
+
+	assert.Equal(1, dnnf.MinimumCardinality())
+	minModels := dnnf.MinimumCardinalityModels()
+	assert.Len(minModels, 1)
+	assert.Equal([]f.Variable{fac.Var("b")}, minModels[0].PosVars())
+
+	conditioned, err := dnnf.Condition(fac.Lit("a", true))
+	assert.Nil(err)
+	assert.Equal(fac.Variable("c"), conditioned.Formula)
+	assert.Equal(big.NewInt(2), conditioned.ModelCount())
+	_, err = dnnf.Condition(fac.Lit("a", true), fac.Lit("a", false))
+	assert.NotNil(err)
+
+	counts := dnnf.LiteralModelCounts()
+	assert.Equal(big.NewInt(2), counts[fac.Lit("a", true)])
+	assert.Equal(big.NewInt(2), counts[fac.Lit("a", false)])
+	assert.Equal(big.NewInt(3), counts[fac.Lit("c", true)])
+	assert.Equal(big.NewInt(1), counts[fac.Lit("c", false)])
+	assert.Equal(dnnf.ModelCount(), dnnf.LiteralModelCount(fac.Lit("x", true)))
+
+	assert.Equal(big.NewInt(3), dnnf.ProjectedModelCount(fac.Var("a"), fac.Var("b")))
+	assert.Equal(big.NewInt(6), dnnf.ProjectedModelCount(fac.Var("a"), fac.Var("b"), fac.Var("x")))
+	assert.Equal(big.NewInt(1), dnnf.ProjectedModelCount())
+}
+
+func TestDNNFRandomQueries(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 8
+	config.Seed = 23
+	config.WeightPBC, config.WeightCC, config.WeightAMO, config.WeightEXO = 0, 0, 0, 0
+	r := randomizer.New(fac, config)
+	for i := 0; i < 50; i++ {
+		formula := normalform.FactorizedCNF(fac, r.Formula(4))
+		vars := f.Variables(fac, formula).Content()
+		if len(vars) < 4 {
+			continue
+		}
+		dnnf := Compile(fac, formula)
+		kernel := bdd.NewKernelWithOrdering(fac, bdd.ForceOrder(fac, formula), 10000, 10000)
+		b := bdd.CompileWithKernel(fac, formula, kernel)
+
+		assert.Equal(sat.IsSatisfiable(fac, formula), dnnf.IsConsistent())
+		assert.Equal(b.IsTautology(), dnnf.IsValid())
+
+		clause := fac.Clause(vars[0].Negate(fac), vars[2].AsLiteral())
+		entailed, err := dnnf.Entails(clause)
+		assert.Nil(err)
+		assert.Equal(!sat.IsSatisfiable(fac, fac.And(formula, fac.Not(clause))), entailed)
+
+		lits := []f.Literal{vars[1].AsLiteral(), vars[3].Negate(fac)}
+		conditioned, err := dnnf.Condition(lits...)
+		assert.Nil(err)
+		restricted := b.Restrict(lits...)
+		assert.True(sat.IsEquivalent(fac, restricted.ToFormula(fac), conditioned.Formula))
+		assert.Equal(restricted.ModelCount(), new(big.Int).Mul(conditioned.ModelCount(), big.NewInt(4)))
+
+		projection := vars[:3]
+		projected := b.Exists(vars[3:]...).ModelEnumeration(projection...)
+		assert.Equal(big.NewInt(int64(len(projected))), dnnf.ProjectedModelCount(projection...))
+		assert.Len(dnnf.ModelEnumeration(projection...), len(projected))
+
+		models := dnnf.ModelEnumeration()
+		assert.Equal(dnnf.ModelCount().Int64(), int64(len(models)))
+		for _, m := range models {
+			assert.Equal(len(vars), m.Size())
+			assert.True(sat.IsSatisfiable(fac, fac.And(formula, m.Formula(fac))))
+		}
+
+		literalCounts := make(map[f.Literal]int64)
+		for _, m := range models {
+			for _, literal := range m.Literals {
+				literalCounts[literal]++
+			}
+		}
+		for literal, count := range dnnf.LiteralModelCounts() {
+			assert.Equal(literalCounts[literal], count.Int64())
+		}
+
+		weights := make(map[f.Literal]*big.Rat)
+		for j, variable := range vars {
+			weights[variable.AsLiteral()] = big.NewRat(int64(j+1), 10)
+			weights[variable.Negate(fac)] = big.NewRat(int64(10-j), 10)
+		}
+		assert.Equal(bruteForceWeight(models, weights), dnnf.WeightedModelCount(weights))
+		assert.Equal(new(big.Rat).SetInt(dnnf.ModelCount()), dnnf.WeightedModelCount(nil))
+
+		minimum, minModels := bruteForceMinimumCardinality(models)
+		assert.Equal(minimum, dnnf.MinimumCardinality())
+		assert.ElementsMatch(minModels, dnnf.MinimumCardinalityModels())
+	}
+}
+
+func bruteForceWeight(models []*model.Model, weights map[f.Literal]*big.Rat, literals ...f.Literal) *big.Rat {
+	result := big.NewRat(0, 1)
+	for _, m := range models {
+		if !containsAll(m.Literals, literals) {
+			continue
+		}
+		weight := big.NewRat(1, 1)
+		for _, literal := range m.Literals {
+			weight.Mul(weight, weights[literal])
+		}
+		result.Add(result, weight)
+	}
+	return result
+}
+
+func bruteForceMinimumCardinality(models []*model.Model) (int, []*model.Model) {
+	minimum := -1
+	var minModels []*model.Model
+	for _, m := range models {
+		card := len(m.PosVars())
+		if minimum < 0 || card < minimum {
+			minimum = card
+			minModels = nil
+		}
+		if card == minimum {
+			minModels = append(minModels, m)
+		}
+	}
+	return minimum, minModels
+}
+
+func containsAll(literals, required []f.Literal) bool {
+	for _, literal := range required {
+		if !slices.Contains(literals, literal) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package dnnf
+
+import (
+	"math"
+	"math/rand"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/weight"
+)
+
+// Sample returns n models of the DNNF which are drawn uniformly at random and
+// independently of each other from all models of the DNNF projected to the
+// given variables.  If no variables are given, the models over the original
+// variables of the DNNF are sampled.  The random number generator rng makes
+// the samples reproducible.  Returns an empty slice if the DNNF is
+// inconsistent.
+//
+// If all variables of the DNNF are projected, each sample is drawn in one
+// top-down pass over the DNNF, where each operand of a disjunction is chosen
+// with a probability proportional to its model count.  As for the projected
+// model count, sampling is not polytime otherwise: then all projected models
+// are enumerated first.
+func (d *DNNF) Sample(n int, rng *rand.Rand, variables ...f.Variable) []*model.Model {
+	return d.SampleWeighted(n, rng, nil, variables...)
+}
+
+// SampleWeighted returns n models of the DNNF projected to the given
+// variables like Sample, but each model is drawn with a probability
+// proportional to its weight.  The weight of a model is the product of the
+// weights of its literals, literals which are not in the given weight map
+// have weight 1.  If the weights of the two literals of each variable add up
+// to one, they can be interpreted as the probability of the literal, e.g.
+// the take rate of an option.  Returns an empty slice if the weighted model
+// count of the projected DNNF is zero.
+func (d *DNNF) SampleWeighted(
+	n int,
+	rng *rand.Rand,
+	weights map[f.Literal]float64,
+	variables ...f.Variable,
+) []*model.Model {
+	projected := d.OriginalVars
+	if len(variables) > 0 {
+		projected = f.NewVarSet(variables...)
+	}
+	logWeights := make(map[f.Literal]float64, len(weights))
+	for literal, w := range weights {
+		if projected.Contains(literal.Variable()) {
+			logWeights[literal] = math.Log(w)
+		}
+	}
+	if !projected.ContainsAll(f.Variables(d.Fac, d.Formula)) {
+		return d.sampleProjected(n, rng, logWeights, projected.Content())
+	}
+
+	samples := make([]*model.Model, 0, n)
+	dnnfVariables := f.Variables(d.Fac, d.Formula)
+	var missing []f.Variable
+	for _, variable := range projected.Content() {
+		if !dnnfVariables.Contains(variable) {
+			missing = append(missing, variable)
+		}
+	}
+	_, values := nodeValues(d, weight.Log, logWeights)
+	if weight.Log.IsZero(values[d.Formula]) {
+		return samples
+	}
+	for i := 0; i < n; i++ {
+		assignment := make(map[f.Variable]bool, projected.Size())
+		d.sampleNode(rng, d.Formula, values, logWeights, assignment)
+		d.sampleFree(rng, logWeights, missing, assignment)
+		samples = append(samples, d.assignmentModel(projected.Content(), assignment))
+	}
+	return samples
+}
+
+// sampleNode draws a random model of the node and stores it in the
+// assignment.  The given values are the weighted model counts of the nodes
+// in log-space.
+func (d *DNNF) sampleNode(
+	rng *rand.Rand,
+	node f.Formula,
+	values map[f.Formula]float64,
+	weights map[f.Literal]float64,
+	assignment map[f.Variable]bool,
+) {
+	switch node.Sort() {
+	case f.SortLiteral:
+		literal := f.Literal(node)
+		assignment[literal.Variable()] = literal.IsPos()
+	case f.SortAnd:
+		for _, op := range d.Fac.Operands(node) {
+			d.sampleNode(rng, op, values, weights, assignment)
+		}
+	case f.SortOr:
+		ops := d.Fac.Operands(node)
+		var chosen f.Formula
+		var missing []f.Variable
+		r := rng.Float64()
+		for _, op := range ops {
+			opMissing := d.missingVariables(node, op)
+			opValue := weight.FreeVariables(d.Fac, weight.Log, weights, opMissing, values[op], nil)
+			if weight.Log.IsZero(opValue) {
+				continue
+			}
+			// the last operand with a non-zero value is the fallback for rounding errors
+			chosen, missing = op, opMissing
+			r -= math.Exp(opValue - values[node])
+			if r < 0 {
+				break
+			}
+		}
+		d.sampleFree(rng, weights, missing, assignment)
+		d.sampleNode(rng, chosen, values, weights, assignment)
+	}
+}
+
+// sampleFree assigns the given free variables independently with the
+// probability of their weights.
+func (d *DNNF) sampleFree(
+	rng *rand.Rand,
+	weights map[f.Literal]float64,
+	variables []f.Variable,
+	assignment map[f.Variable]bool,
+) {
+	for _, variable := range variables {
+		pos := weight.Of(weight.Log, weights, variable.AsLiteral())
+		neg := weight.Of(weight.Log, weights, variable.Negate(d.Fac))
+		assignment[variable] = rng.Float64() < math.Exp(pos-weight.Log.Add(pos, neg))
+	}
+}
+
+// sampleProjected draws the samples from the enumerated projected models.
+func (d *DNNF) sampleProjected(
+	n int,
+	rng *rand.Rand,
+	weights map[f.Literal]float64,
+	variables []f.Variable,
+) []*model.Model {
+	samples := make([]*model.Model, 0, n)
+	models := d.ModelEnumeration(variables...)
+	modelWeights := make([]float64, len(models))
+	total := weight.Log.Zero()
+	for i, mdl := range models {
+		modelWeights[i] = weight.Log.One()
+		for _, literal := range mdl.Literals {
+			modelWeights[i] = weight.Log.Mul(modelWeights[i], weight.Of(weight.Log, weights, literal))
+		}
+		total = weight.Log.Add(total, modelWeights[i])
+	}
+	if weight.Log.IsZero(total) {
+		return samples
+	}
+	for i := 0; i < n; i++ {
+		var chosen *model.Model
+		r := rng.Float64()
+		for j, mdl := range models {
+			if weight.Log.IsZero(modelWeights[j]) {
+				continue
+			}
+			chosen = mdl
+			r -= math.Exp(modelWeights[j] - total)
+			if r < 0 {
+				break
+			}
+		}
+		samples = append(samples, model.New(chosen.Literals...))
+	}
+	return samples
+}
+
+// assignmentModel returns the model of the assignment over the variables.
+func (d *DNNF) assignmentModel(variables []f.Variable, assignment map[f.Variable]bool) *model.Model {
+	literals := make([]f.Literal, len(variables))
+	for i, variable := range variables {
+		if assignment[variable] {
+			literals[i] = variable.AsLiteral()
+		} else {
+			literals[i] = variable.Negate(d.Fac)
+		}
+	}
+	return model.New(literals...)
+}
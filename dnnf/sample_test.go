@@ -0,0 +1,114 @@
+package dnnf
+
+import (
+	"math/rand"
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/normalform"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNNFSampleSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+	dnnf := Compile(fac, p.ParseUnsafe("a | b"))
+
+	frequencies := sampleFrequencies(fac, dnnf.Sample(3000, rand.New(rand.NewSource(1))))
+	assert.Len(frequencies, 3)
+	assert.InDelta(1.0/3, frequencies["[a, b]"], 0.04)
+	assert.InDelta(1.0/3, frequencies["[a, ~b]"], 0.04)
+	assert.InDelta(1.0/3, frequencies["[~a, b]"], 0.04)
+
+	weights := map[f.Literal]float64{a.AsLiteral(): 0.25, a.Negate(fac): 0.75}
+	frequencies = sampleFrequencies(fac, dnnf.SampleWeighted(3000, rand.New(rand.NewSource(2)), weights))
+	assert.InDelta(0.2, frequencies["[a, b]"], 0.04)
+	assert.InDelta(0.2, frequencies["[a, ~b]"], 0.04)
+	assert.InDelta(0.6, frequencies["[~a, b]"], 0.04)
+
+	frequencies = sampleFrequencies(fac, dnnf.Sample(3000, rand.New(rand.NewSource(3)), a, b, c))
+	assert.Len(frequencies, 6)
+	assert.InDelta(1.0/6, frequencies["[a, b, c]"], 0.03)
+	assert.InDelta(1.0/6, frequencies["[~a, b, ~c]"], 0.03)
+
+	assert.Equal(dnnf.Sample(10, rand.New(rand.NewSource(4))), dnnf.Sample(10, rand.New(rand.NewSource(4))))
+	assert.Empty(Compile(fac, fac.Falsum()).Sample(10, rand.New(rand.NewSource(5))))
+	zero := map[f.Literal]float64{a.AsLiteral(): 0, b.AsLiteral(): 0}
+	assert.Empty(dnnf.SampleWeighted(10, rand.New(rand.NewSource(6)), zero))
+}
+
+func TestDNNFSampleProjected(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b := fac.Var("a"), fac.Var("b")
+	dnnf := Compile(fac, p.ParseUnsafe("(a | b) & (a | c) & (~a | c | d)"))
+
+	frequencies := sampleFrequencies(fac, dnnf.Sample(3000, rand.New(rand.NewSource(1)), a, b))
+	assert.Len(frequencies, 3)
+	assert.InDelta(1.0/3, frequencies["[a, b]"], 0.04)
+	assert.InDelta(1.0/3, frequencies["[a, ~b]"], 0.04)
+	assert.InDelta(1.0/3, frequencies["[~a, b]"], 0.04)
+
+	weights := map[f.Literal]float64{a.AsLiteral(): 0.25, a.Negate(fac): 0.75, fac.Lit("c", true): 0.01}
+	frequencies = sampleFrequencies(fac, dnnf.SampleWeighted(3000, rand.New(rand.NewSource(2)), weights, a, b))
+	assert.InDelta(0.2, frequencies["[a, b]"], 0.04)
+	assert.InDelta(0.2, frequencies["[a, ~b]"], 0.04)
+	assert.InDelta(0.6, frequencies["[~a, b]"], 0.04)
+}
+
+func TestDNNFSampleRandom(t *testing.T) {
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 6
+	config.Seed = 23
+	config.WeightPBC, config.WeightCC, config.WeightAMO, config.WeightEXO = 0, 0, 0, 0
+	r := randomizer.New(fac, config)
+	numTests := 20
+	if testing.Short() {
+		numTests = 5
+	}
+	for i := 0; i < numTests; i++ {
+		dnnf := Compile(fac, normalform.FactorizedCNF(fac, r.Formula(3)))
+		vars := dnnf.OriginalVars.Content()
+		weights := make(map[f.Literal]float64)
+		for j, variable := range vars {
+			weights[variable.AsLiteral()] = float64(j+1) / 10
+		}
+		expected := make(map[string]float64)
+		total := 0.0
+		for _, mdl := range dnnf.ModelEnumeration() {
+			w := 1.0
+			for _, literal := range mdl.Literals {
+				if weight, ok := weights[literal]; ok {
+					w *= weight
+				}
+			}
+			expected[mdl.Sprint(fac)] = w
+			total += w
+		}
+		frequencies := sampleFrequencies(fac, dnnf.SampleWeighted(2000, rand.New(rand.NewSource(int64(i))), weights))
+		distance := 0.0
+		for key, w := range expected {
+			distance += max(w/total-frequencies[key], 0)
+		}
+		assert.Less(t, distance, 0.1)
+		for key := range frequencies {
+			_, ok := expected[key]
+			assert.True(t, ok)
+		}
+	}
+}
+
+func sampleFrequencies(fac f.Factory, samples []*model.Model) map[string]float64 {
+	frequencies := make(map[string]float64)
+	for _, sample := range samples {
+		frequencies[sample.Sprint(fac)] += 1 / float64(len(samples))
+	}
+	return frequencies
+}
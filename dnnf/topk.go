@@ -0,0 +1,175 @@
+package dnnf
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/weight"
+)
+
+// TopK returns the k models of the DNNF projected to the given variables
+// with the smallest cost in ascending order of their cost together with the
+// costs.  The cost of a model is the sum of the costs of its literals,
+// literals which are not in the given cost map have cost 0.  If ties is set,
+// all models with the same cost as the k-th model are also returned.  If no
+// variables are given, the models over the original variables of the DNNF
+// are returned.  If the costs contain literals over variables which are not
+// projected, the cost of a model is the smallest cost of all its extensions
+// to these variables.
+//
+// The models are computed bottom-up by merging the k cheapest assignments of
+// the operands of each node.  If not all variables of the DNNF are
+// projected, a projected model can be the projection of multiple
+// assignments, so the computation is repeated with more assignments per node
+// until k different projected models are found.
+func (d *DNNF) TopK(k int, ties bool, costs map[f.Literal]int, variables ...f.Variable) ([]*model.Model, []int) {
+	projected := d.OriginalVars
+	if len(variables) > 0 {
+		projected = f.NewVarSet(variables...)
+	}
+	dnnfVariables := f.Variables(d.Fac, d.Formula)
+	var free []f.Variable
+	for _, variable := range projected.Content() {
+		if !dnnfVariables.Contains(variable) {
+			free = append(free, variable)
+		}
+	}
+	hidden := !projected.ContainsAll(dnnfVariables)
+	// original variables which are neither projected nor in the DNNF are
+	// assigned their cheaper phase
+	offset := 0
+	for _, variable := range d.OriginalVars.Content() {
+		if !projected.Contains(variable) && !dnnfVariables.Contains(variable) {
+			offset += min(weight.Of(weight.MinCost, costs, variable.AsLiteral()),
+				weight.Of(weight.MinCost, costs, variable.Negate(d.Fac)))
+		}
+	}
+
+	for limit := max(k, 1); ; limit *= 2 {
+		merger := &topKMerger{d: d, costs: costs, limit: limit, ties: ties}
+		assignments := merger.product(merger.nodeAssignments(), merger.freeAssignments(free))
+		var models []*model.Model
+		var modelCosts []int
+		seen := make(map[string]bool)
+		for _, assignment := range assignments {
+			if len(models) >= k && (!ties || len(models) == 0 || assignment.cost+offset > modelCosts[len(models)-1]) {
+				break
+			}
+			literals := assignment.literals
+			if hidden {
+				literals = nil
+				for _, literal := range assignment.literals {
+					if projected.Contains(literal.Variable()) {
+						literals = append(literals, literal)
+					}
+				}
+				slices.Sort(literals)
+				key := fmt.Sprint(literals)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			models = append(models, model.New(literals...))
+			modelCosts = append(modelCosts, assignment.cost+offset)
+		}
+		if !hidden || len(models) >= k || len(assignments) < limit {
+			return models, modelCosts
+		}
+	}
+}
+
+// A rankedAssignment is an assignment of the variables of a node together
+// with its cost.
+type rankedAssignment struct {
+	cost     int
+	literals []f.Literal
+}
+
+// A topKMerger computes the cheapest assignments of the nodes of a DNNF.
+// For each node at most limit assignments are kept, if ties is set
+// additionally all assignments with the same cost as the last one.
+type topKMerger struct {
+	d     *DNNF
+	costs map[f.Literal]int
+	limit int
+	ties  bool
+}
+
+// nodeAssignments returns the cheapest assignments of the variables of the
+// DNNF in ascending order of their cost.
+func (m *topKMerger) nodeAssignments() []rankedAssignment {
+	fac := m.d.Fac
+	assignments := make(map[f.Formula][]rankedAssignment)
+	for _, node := range m.d.postOrder() {
+		switch node.Sort() {
+		case f.SortTrue:
+			assignments[node] = []rankedAssignment{{}}
+		case f.SortFalse:
+			assignments[node] = nil
+		case f.SortLiteral:
+			literal := f.Literal(node)
+			assignments[node] = []rankedAssignment{{weight.Of(weight.MinCost, m.costs, literal), []f.Literal{literal}}}
+		case f.SortAnd:
+			result := []rankedAssignment{{}}
+			for _, op := range fac.Operands(node) {
+				result = m.product(result, assignments[op])
+			}
+			assignments[node] = result
+		case f.SortOr:
+			var result []rankedAssignment
+			for _, op := range fac.Operands(node) {
+				smoothed := m.product(assignments[op], m.freeAssignments(m.d.missingVariables(node, op)))
+				result = append(result, smoothed...)
+			}
+			assignments[node] = m.truncate(result)
+		}
+	}
+	return assignments[m.d.Formula]
+}
+
+// freeAssignments returns the cheapest assignments of the given free
+// variables.
+func (m *topKMerger) freeAssignments(variables []f.Variable) []rankedAssignment {
+	result := []rankedAssignment{{}}
+	for _, variable := range variables {
+		pos, neg := variable.AsLiteral(), variable.Negate(m.d.Fac)
+		result = m.product(result, []rankedAssignment{
+			{weight.Of(weight.MinCost, m.costs, pos), []f.Literal{pos}},
+			{weight.Of(weight.MinCost, m.costs, neg), []f.Literal{neg}},
+		})
+	}
+	return result
+}
+
+// product returns the cheapest combinations of the assignments of two nodes
+// over disjoint variables.
+func (m *topKMerger) product(as1, as2 []rankedAssignment) []rankedAssignment {
+	result := make([]rankedAssignment, 0, len(as1)*len(as2))
+	for _, a1 := range as1 {
+		for _, a2 := range as2 {
+			literals := make([]f.Literal, 0, len(a1.literals)+len(a2.literals))
+			literals = append(literals, a1.literals...)
+			literals = append(literals, a2.literals...)
+			result = append(result, rankedAssignment{a1.cost + a2.cost, literals})
+		}
+	}
+	return m.truncate(result)
+}
+
+// truncate sorts the assignments by their cost and removes all but the
+// cheapest ones.
+func (m *topKMerger) truncate(assignments []rankedAssignment) []rankedAssignment {
+	slices.SortStableFunc(assignments, func(a1, a2 rankedAssignment) int { return cmp.Compare(a1.cost, a2.cost) })
+	if len(assignments) <= m.limit {
+		return assignments
+	}
+	end := m.limit
+	for m.ties && end < len(assignments) && assignments[end].cost == assignments[m.limit-1].cost {
+		end++
+	}
+	return assignments[:end]
+}
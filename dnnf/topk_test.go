@@ -0,0 +1,37 @@
+package dnnf
+
+import (
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNNFTopK(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+	dnnf := Compile(fac, p.ParseUnsafe("a | b"))
+	costs := map[f.Literal]int{a.AsLiteral(): 2, b.AsLiteral(): 1, c.AsLiteral(): -1}
+
+	models, modelCosts := dnnf.TopK(3, false, costs)
+	assert.Equal([]int{1, 2, 3}, modelCosts)
+	assert.ElementsMatch([]f.Literal{a.Negate(fac), b.AsLiteral()}, models[0].Literals)
+	assert.ElementsMatch([]f.Literal{a.AsLiteral(), b.Negate(fac)}, models[1].Literals)
+	assert.ElementsMatch([]f.Literal{a.AsLiteral(), b.AsLiteral()}, models[2].Literals)
+
+	models, modelCosts = dnnf.TopK(2, false, costs, a, b, c)
+	assert.Equal([]int{0, 1}, modelCosts)
+	assert.ElementsMatch([]f.Literal{a.Negate(fac), b.AsLiteral(), c.AsLiteral()}, models[0].Literals)
+
+	models, modelCosts = dnnf.TopK(1, false, costs, a)
+	assert.Equal([]int{1}, modelCosts)
+	assert.Equal([]f.Literal{a.Negate(fac)}, models[0].Literals)
+
+	_, modelCosts = dnnf.TopK(1, true, map[f.Literal]int{c.AsLiteral(): 1}, a, b, c)
+	assert.Equal([]int{0, 0, 0}, modelCosts)
+	models, _ = Compile(fac, fac.Falsum()).TopK(10, false, costs)
+	assert.Empty(models)
+}
@@ -0,0 +1,197 @@
+package dnnf
+
+import (
+	"math"
+	"math/big"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/weight"
+)
+
+// WeightedModelCount returns the sum of the weights of all models of the DNNF
+// over its original variables.  The weight of a model is the product of the
+// weights of its literals.  Literals which are not in the given weight map
+// have weight 1.  The computation is linear in the size of the DNNF.
+func (d *DNNF) WeightedModelCount(weights map[f.Literal]*big.Rat) *big.Rat {
+	return weightedModelCount(d, weight.Rational, weights, nil)
+}
+
+// WeightedModelCountFloat returns the weighted model count of the DNNF like
+// WeightedModelCount but computes with float64 weights.
+func (d *DNNF) WeightedModelCountFloat(weights map[f.Literal]float64) float64 {
+	return weightedModelCount(d, weight.Float, weights, nil)
+}
+
+// LogWeightedModelCount returns the natural logarithm of the weighted model
+// count of the DNNF.  The computation is performed in log-space and
+// therefore does not underflow for small weights over many variables.
+func (d *DNNF) LogWeightedModelCount(weights map[f.Literal]float64) float64 {
+	return weightedModelCount(d, weight.Log, weight.ToLog(weights), nil)
+}
+
+// Marginals returns for each literal over the original variables of the DNNF
+// its marginal, i.e. the weighted model count of the models in which the
+// literal is true divided by the weighted model count of the DNNF.  If the
+// weights of the two literals of each variable add up to one, the marginal
+// of a literal is its probability among the models.  All marginals are
+// computed in one bottom-up and one top-down pass over the DNNF.  If the
+// weighted model count is zero, all marginals are zero.
+func (d *DNNF) Marginals(weights map[f.Literal]*big.Rat) map[f.Literal]*big.Rat {
+	marginals := make(map[f.Literal]*big.Rat)
+	total := weightedModelCount(d, weight.Rational, weights, marginals)
+	return weight.Normalize(weight.Rational, marginals, total)
+}
+
+// MarginalsFloat returns the marginals of the literals like Marginals but
+// computes with float64 weights.  The computation is performed in log-space.
+func (d *DNNF) MarginalsFloat(weights map[f.Literal]float64) map[f.Literal]float64 {
+	marginals := make(map[f.Literal]float64)
+	total := weightedModelCount(d, weight.Log, weight.ToLog(weights), marginals)
+	for literal, marginal := range weight.Normalize(weight.Log, marginals, total) {
+		marginals[literal] = math.Exp(marginal)
+	}
+	return marginals
+}
+
+// weightedModelCount computes the weighted model count of the DNNF.  If
+// literalWeights is not nil, the weighted model count of the models of each
+// literal is stored in it.  In the bottom-up pass the values of the nodes are
+// computed, in the top-down pass the partial derivatives of the root value
+// with respect to each node.  For a deterministic and decomposable NNF the
+// derivative of a literal node times the literal's weight is the weighted
+// model count of the models in which the literal is true.  Since the DNNF is
+// not smooth, the variables missing in an operand of a disjunction (and in
+// the DNNF itself) are handled like free variables.
+func weightedModelCount[T any](
+	d *DNNF,
+	s weight.Semiring[T],
+	weights map[f.Literal]T,
+	literalWeights map[f.Literal]T,
+) T {
+	nodes, values := nodeValues(d, s, weights)
+	missing := d.missingOriginalVariables()
+	rootValue := values[d.Formula]
+	if literalWeights == nil {
+		return weight.FreeVariables(d.Fac, s, weights, missing, rootValue, nil)
+	}
+
+	for _, variable := range d.OriginalVars.Content() {
+		literalWeights[variable.AsLiteral()] = s.Zero()
+		literalWeights[variable.Negate(d.Fac)] = s.Zero()
+	}
+	total := weight.FreeVariables(d.Fac, s, weights, missing, rootValue, literalWeights)
+	derivatives := make(map[f.Formula]T, len(nodes))
+	derivatives[d.Formula] = weight.FreeVariables(d.Fac, s, weights, missing, s.One(), nil)
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		derivative, ok := derivatives[node]
+		if !ok {
+			continue
+		}
+		switch node.Sort() {
+		case f.SortLiteral:
+			literal := f.Literal(node)
+			weight.AddTo(s, literalWeights, literal, s.Mul(derivative, values[node]))
+		case f.SortAnd:
+			ops := d.Fac.Operands(node)
+			// the derivative of an operand is the product of all other operands
+			suffix := make([]T, len(ops)+1)
+			suffix[len(ops)] = s.One()
+			for j := len(ops) - 1; j >= 0; j-- {
+				suffix[j] = s.Mul(values[ops[j]], suffix[j+1])
+			}
+			prefix := derivative
+			for j, op := range ops {
+				weight.AddTo(s, derivatives, op, s.Mul(prefix, suffix[j+1]))
+				prefix = s.Mul(prefix, values[op])
+			}
+		case f.SortOr:
+			for _, op := range d.Fac.Operands(node) {
+				missing := d.missingVariables(node, op)
+				smoothing := weight.FreeVariables(d.Fac, s, weights, missing, s.One(), nil)
+				weight.AddTo(s, derivatives, op, s.Mul(derivative, smoothing))
+				weight.FreeVariables(d.Fac, s, weights, missing, s.Mul(derivative, values[op]), literalWeights)
+			}
+		}
+	}
+	return total
+}
+
+// nodeValues returns all nodes of the DNNF in post-order and the weighted
+// model count of each node over its variables.
+func nodeValues[T any](d *DNNF, s weight.Semiring[T], weights map[f.Literal]T) ([]f.Formula, map[f.Formula]T) {
+	nodes := d.postOrder()
+	values := make(map[f.Formula]T, len(nodes))
+	for _, node := range nodes {
+		switch node.Sort() {
+		case f.SortTrue:
+			values[node] = s.One()
+		case f.SortFalse:
+			values[node] = s.Zero()
+		case f.SortLiteral:
+			values[node] = weight.Of(s, weights, f.Literal(node))
+		case f.SortAnd:
+			value := s.One()
+			for _, op := range d.Fac.Operands(node) {
+				value = s.Mul(value, values[op])
+			}
+			values[node] = value
+		case f.SortOr:
+			value := s.Zero()
+			for _, op := range d.Fac.Operands(node) {
+				missing := d.missingVariables(node, op)
+				value = s.Add(value, weight.FreeVariables(d.Fac, s, weights, missing, values[op], nil))
+			}
+			values[node] = value
+		}
+	}
+	return nodes, values
+}
+
+// postOrder returns all nodes of the DNNF such that each node is after its
+// operands.
+func (d *DNNF) postOrder() []f.Formula {
+	var nodes []f.Formula
+	visited := make(map[f.Formula]bool)
+	var visit func(node f.Formula)
+	visit = func(node f.Formula) {
+		if visited[node] {
+			return
+		}
+		visited[node] = true
+		if node.Sort() == f.SortAnd || node.Sort() == f.SortOr {
+			for _, op := range d.Fac.Operands(node) {
+				visit(op)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	visit(d.Formula)
+	return nodes
+}
+
+// missingVariables returns the variables of the disjunction which are not in
+// the given operand.
+func (d *DNNF) missingVariables(or, op f.Formula) []f.Variable {
+	opVariables := f.Variables(d.Fac, op)
+	var missing []f.Variable
+	for _, variable := range f.Variables(d.Fac, or).Content() {
+		if !opVariables.Contains(variable) {
+			missing = append(missing, variable)
+		}
+	}
+	return missing
+}
+
+// missingOriginalVariables returns the original variables which are not in
+// the DNNF.
+func (d *DNNF) missingOriginalVariables() []f.Variable {
+	dnnfVariables := f.Variables(d.Fac, d.Formula)
+	var missing []f.Variable
+	for _, variable := range d.OriginalVars.Content() {
+		if !dnnfVariables.Contains(variable) {
+			missing = append(missing, variable)
+		}
+	}
+	return missing
+}
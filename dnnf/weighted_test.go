@@ -0,0 +1,86 @@
+package dnnf
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/normalform"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNNFMarginalsSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b := fac.Var("a"), fac.Var("b")
+	dnnf := Compile(fac, p.ParseUnsafe("a | b"))
+
+	weights := map[f.Literal]*big.Rat{
+		a.AsLiteral(): big.NewRat(1, 4), a.Negate(fac): big.NewRat(3, 4),
+		b.AsLiteral(): big.NewRat(1, 2), b.Negate(fac): big.NewRat(1, 2),
+	}
+	assert.Equal(big.NewRat(5, 8), dnnf.WeightedModelCount(weights))
+	marginals := dnnf.Marginals(weights)
+	assert.Equal(big.NewRat(2, 5), marginals[a.AsLiteral()])
+	assert.Equal(big.NewRat(3, 5), marginals[a.Negate(fac)])
+	assert.Equal(big.NewRat(4, 5), marginals[b.AsLiteral()])
+	assert.Equal(big.NewRat(1, 5), marginals[b.Negate(fac)])
+
+	floatWeights := map[f.Literal]float64{a.AsLiteral(): 0.25, a.Negate(fac): 0.75}
+	assert.InDelta(1.25, dnnf.WeightedModelCountFloat(floatWeights), 1e-9)
+	assert.InDelta(math.Log(1.25), dnnf.LogWeightedModelCount(floatWeights), 1e-9)
+	floatMarginals := dnnf.MarginalsFloat(floatWeights)
+	assert.InDelta(0.4, floatMarginals[a.AsLiteral()], 1e-9)
+	assert.InDelta(0.8, floatMarginals[b.AsLiteral()], 1e-9)
+
+	falsum := Compile(fac, fac.Falsum())
+	assert.Equal(0, falsum.WeightedModelCount(weights).Sign())
+	assert.True(math.IsInf(falsum.LogWeightedModelCount(floatWeights), -1))
+}
+
+func TestDNNFMarginalsRandom(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 8
+	config.Seed = 37
+	config.WeightPBC, config.WeightCC, config.WeightAMO, config.WeightEXO = 0, 0, 0, 0
+	r := randomizer.New(fac, config)
+	for i := 0; i < 50; i++ {
+		dnnf := Compile(fac, normalform.FactorizedCNF(fac, r.Formula(4)))
+		vars := dnnf.OriginalVars.Content()
+		weights := make(map[f.Literal]*big.Rat)
+		floatWeights := make(map[f.Literal]float64)
+		for j, variable := range vars {
+			weights[variable.AsLiteral()] = big.NewRat(int64(j+1), 10)
+			weights[variable.Negate(fac)] = big.NewRat(int64(10-j), 7)
+			floatWeights[variable.AsLiteral()], _ = weights[variable.AsLiteral()].Float64()
+			floatWeights[variable.Negate(fac)], _ = weights[variable.Negate(fac)].Float64()
+		}
+		models := dnnf.ModelEnumeration()
+		total := bruteForceWeight(models, weights)
+		assert.Zero(total.Cmp(dnnf.WeightedModelCount(weights)))
+		totalFloat, _ := total.Float64()
+		assert.InDelta(totalFloat, dnnf.WeightedModelCountFloat(floatWeights), 1e-9)
+		if total.Sign() > 0 {
+			assert.InDelta(math.Log(totalFloat), dnnf.LogWeightedModelCount(floatWeights), 1e-9)
+		}
+
+		marginals := dnnf.Marginals(weights)
+		floatMarginals := dnnf.MarginalsFloat(floatWeights)
+		assert.Len(marginals, 2*len(vars))
+		for literal, marginal := range marginals {
+			expected := new(big.Rat)
+			if total.Sign() > 0 {
+				expected.Quo(bruteForceWeight(models, weights, literal), total)
+			}
+			assert.Zero(expected.Cmp(marginal))
+			expectedFloat, _ := expected.Float64()
+			assert.InDelta(expectedFloat, floatMarginals[literal], 1e-9)
+		}
+	}
+}
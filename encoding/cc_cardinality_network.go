@@ -38,7 +38,9 @@ func cnAmkForIncremental(result Result, vars []f.Variable, rhs int) *CCIncrement
 		Result:     result,
 		amkEncoder: AMKCardinalityNetwork,
 		alkEncoder: ALKCardinalityNetwork,
-		currentRhs: rhs,
+		bound:      boundUpper,
+		upperRhs:   rhs,
+		nVars:      len(vars),
 		vector1:    output,
 	}
 }
@@ -79,7 +81,9 @@ func cnAlkForIncremental(result Result, vars []f.Variable, rhs int) *CCIncrement
 		Result:     result,
 		amkEncoder: AMKCardinalityNetwork,
 		alkEncoder: ALKCardinalityNetwork,
-		currentRhs: rhs,
+		bound:      boundLower,
+		upperRhs:   len(vars),
+		lowerRhs:   rhs,
 		nVars:      len(vars),
 		vector1:    output,
 	}
@@ -0,0 +1,547 @@
+package encoding
+
+import (
+	"math"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// The functions in this file compute the number of clauses and auxiliary
+// variables of the cardinality constraint encodings.  They follow the
+// structure of the encoders, but only depend on the number of variables and
+// the right-hand side and do not generate any literals or clauses.
+
+func ccCost(cost *Cost, config *Config, comparator f.CSort, rhs, n int) {
+	switch comparator {
+	case f.LE:
+		if rhs == 1 {
+			amoCost(cost, config, n)
+		} else {
+			amkCost(cost, config, rhs, n)
+		}
+	case f.LT:
+		if rhs == 2 {
+			amoCost(cost, config, n)
+		} else {
+			amkCost(cost, config, rhs-1, n)
+		}
+	case f.GE:
+		alkCost(cost, config, rhs, n)
+	case f.GT:
+		alkCost(cost, config, rhs+1, n)
+	case f.EQ:
+		if rhs == 1 {
+			exoCost(cost, config, n)
+		} else {
+			exkCost(cost, config, rhs, n)
+		}
+	default:
+		panic(errorx.UnknownEnumValue(comparator))
+	}
+}
+
+func amoCost(cost *Cost, config *Config, n int) {
+	if n <= 1 {
+		return
+	}
+	switch config.AMOEncoder {
+	case AMOPure:
+		pureCost(cost, n)
+	case AMOLadder:
+		cost.AuxVars += n - 1
+		cost.Clauses += 3*n - 4
+	case AMOProduct:
+		productCost(cost, config.ProductRecursiveBound, n)
+	case AMONested:
+		nestedCost(cost, config.NestingGroupSize, n)
+	case AMOCommander:
+		commanderCost(cost, config.CommanderGroupSize, n)
+	case AMOBinary:
+		binaryCost(cost, n)
+	case AMOBimander:
+		bimanderCost(cost, computeGroupSize(config.BimanderGroupSize, config.BimanderFixedGroupSize, n), n)
+	case AMOBest:
+		if n <= 10 {
+			pureCost(cost, n)
+		} else {
+			productCost(cost, config.ProductRecursiveBound, n)
+		}
+	default:
+		panic(errorx.UnknownEnumValue(config.AMOEncoder))
+	}
+}
+
+func exoCost(cost *Cost, config *Config, n int) {
+	if n <= 1 {
+		cost.Clauses++
+		return
+	}
+	amoCost(cost, config, n)
+	cost.Clauses++
+}
+
+func amkCost(cost *Cost, config *Config, rhs, n int) {
+	if rhs >= n {
+		return
+	}
+	if rhs == 0 {
+		cost.Clauses += n
+		return
+	}
+	switch config.AMKEncoder {
+	case AMKTotalizer:
+		cost.AuxVars += n
+		totalizerCost(cost, n, rhs, boundUpper)
+		cost.Clauses += n - rhs
+	case AMKModularTotalizer, AMKBest:
+		modtotalizerCost(cost, rhs, n)
+	case AMKCardinalityNetwork:
+		if rhs > n/2 {
+			geq := n - rhs
+			sortCost(cost, geq, n, outputToInput)
+			cost.Clauses += geq
+		} else {
+			sortCost(cost, rhs+1, n, inputToOutput)
+			cost.Clauses++
+		}
+	default:
+		panic(errorx.UnknownEnumValue(config.AMKEncoder))
+	}
+}
+
+func alkCost(cost *Cost, config *Config, rhs, n int) {
+	if rhs > n || rhs == 1 {
+		cost.Clauses++
+		return
+	}
+	if rhs == 0 {
+		return
+	}
+	if rhs == n {
+		cost.Clauses += n
+		return
+	}
+	switch config.ALKEncoder {
+	case ALKTotalizer:
+		cost.AuxVars += n
+		totalizerCost(cost, n, rhs, boundLower)
+		cost.Clauses += rhs
+	case ALKModularTotalizer, ALKBest:
+		modtotalizerCost(cost, n-rhs, n)
+	case ALKCardinalityNetwork:
+		newRhs := n - rhs
+		if newRhs > n/2 {
+			geq := n - newRhs
+			sortCost(cost, geq, n, outputToInput)
+			cost.Clauses += geq
+		} else {
+			sortCost(cost, newRhs+1, n, inputToOutput)
+			cost.Clauses++
+		}
+	default:
+		panic(errorx.UnknownEnumValue(config.ALKEncoder))
+	}
+}
+
+func exkCost(cost *Cost, config *Config, rhs, n int) {
+	if rhs > n {
+		cost.Clauses++
+		return
+	}
+	if rhs == 0 || rhs == n {
+		cost.Clauses += n
+		return
+	}
+	switch config.EXKEncoder {
+	case EXKTotalizer, EXKBest:
+		cost.AuxVars += n
+		totalizerCost(cost, n, rhs, boundBoth)
+		cost.Clauses += n
+	case EXKCardinalityNetwork:
+		sortCost(cost, rhs+1, n, both)
+		cost.Clauses += 2
+	default:
+		panic(errorx.UnknownEnumValue(config.EXKEncoder))
+	}
+}
+
+func pureCost(cost *Cost, n int) {
+	cost.Clauses += n * (n - 1) / 2
+}
+
+func productCost(cost *Cost, recursiveBound, n int) {
+	if recursiveBound == 0 {
+		recursiveBound = 20
+	}
+	p := int(math.Ceil(math.Sqrt(float64(n))))
+	q := int(math.Ceil(float64(n) / float64(p)))
+	cost.AuxVars += p + q
+	for _, size := range []int{p, q} {
+		if size <= recursiveBound {
+			pureCost(cost, size)
+		} else {
+			productCost(cost, recursiveBound, size)
+		}
+	}
+	cost.Clauses += 2 * n
+}
+
+func nestedCost(cost *Cost, groupSize, n int) {
+	if groupSize == 0 {
+		groupSize = 4
+	}
+	if n <= groupSize {
+		pureCost(cost, n)
+		return
+	}
+	cost.AuxVars++
+	nestedCost(cost, groupSize, n/2+1)
+	nestedCost(cost, groupSize, n-n/2+1)
+}
+
+func commanderCost(cost *Cost, groupSize, n int) {
+	if groupSize == 0 {
+		groupSize = 3
+	}
+	isExactlyOne := false
+	for n > groupSize {
+		next := 0
+		for start := 0; start < n; start += groupSize {
+			size := min(groupSize, n-start)
+			pureCost(cost, size)
+			cost.AuxVars++
+			if isExactlyOne {
+				cost.Clauses++
+			}
+			cost.Clauses += size
+			next++
+		}
+		n = next
+		isExactlyOne = true
+	}
+	pureCost(cost, n)
+	if isExactlyOne && n > 0 {
+		cost.Clauses++
+	}
+}
+
+func binaryCost(cost *Cost, n int) {
+	numberOfBits := int(math.Ceil(math.Log(float64(n)) / math.Log(2)))
+	twoPowNBits := int(math.Pow(2, float64(numberOfBits)))
+	k := (twoPowNBits - n) * 2
+	cost.AuxVars += numberOfBits
+	i := 0
+	for i < k {
+		grayCode := i ^ (i >> 1)
+		i++
+		nextGray := i ^ (i >> 1)
+		for j := range numberOfBits {
+			if (grayCode & (1 << j)) == (nextGray & (1 << j)) {
+				cost.Clauses++
+			}
+		}
+		i++
+	}
+	cost.Clauses += (twoPowNBits - i) * numberOfBits
+}
+
+func bimanderCost(cost *Cost, groupSize, n int) {
+	groups := make([]int, groupSize)
+	g := int(math.Ceil(float64(n) / float64(groupSize)))
+	ig := 0
+	for i := 0; i < n; {
+		for i < g {
+			groups[ig]++
+			i++
+		}
+		ig++
+		g = g + int(math.Ceil(float64(n-i)/float64(groupSize-ig)))
+	}
+	for _, size := range groups {
+		pureCost(cost, size)
+	}
+	numberOfBits := int(math.Ceil(math.Log(float64(groupSize)) / math.Log(2)))
+	twoPowNBits := int(math.Pow(2, float64(numberOfBits)))
+	k := (twoPowNBits - groupSize) * 2
+	cost.AuxVars += numberOfBits
+	i := 0
+	index := -1
+	for ; i < k; i++ {
+		index++
+		grayCode := i ^ (i >> 1)
+		i++
+		nextGray := i ^ (i >> 1)
+		for j := range numberOfBits {
+			if (grayCode & (1 << j)) == (nextGray & (1 << j)) {
+				cost.Clauses += groups[index]
+			}
+		}
+	}
+	for ; i < twoPowNBits; i++ {
+		index++
+		cost.Clauses += numberOfBits * groups[index]
+	}
+}
+
+// totalizerCost counts the adders of a totalizer node with the given number
+// of output variables and its children.
+func totalizerCost(cost *Cost, size, rhs int, bound bound) {
+	left := size / 2
+	right := size - left
+	if left > 1 {
+		cost.AuxVars += left
+	}
+	if right > 1 {
+		cost.AuxVars += right
+	}
+	if bound == boundUpper || bound == boundBoth {
+		for i := 0; i <= left; i++ {
+			cost.Clauses += max(0, min(right, rhs+1-i)+1)
+		}
+		cost.Clauses--
+	}
+	if bound == boundLower || bound == boundBoth {
+		cost.Clauses += (left+1)*(right+1) - 1
+	}
+	if left > 1 {
+		totalizerCost(cost, left, rhs, bound)
+	}
+	if right > 1 {
+		totalizerCost(cost, right, rhs, bound)
+	}
+}
+
+// mtVector describes a vector of output literals of the modular totalizer.
+// If h0 is set, the vector only consists of the placeholder literal.
+type mtVector struct {
+	size int
+	h0   bool
+}
+
+func modtotalizerCost(cost *Cost, rhs, n int) {
+	mod := int(math.Ceil(math.Sqrt(float64(rhs) + 1.0)))
+	upper := mtVector{n / mod, false}
+	cost.AuxVars += n/mod + mod - 1
+	if upper.size == 0 {
+		upper = mtVector{1, true}
+	}
+	mtToCNFCost(cost, mod, upper, n, rhs+1)
+
+	ulimit := (rhs + 1) / mod
+	llimit := (rhs + 1) - ulimit*mod
+	cost.Clauses += max(0, upper.size-ulimit)
+	if ulimit == 0 || llimit != 0 {
+		cost.Clauses += max(0, mod-1-(llimit-1))
+	} else {
+		cost.Clauses++
+	}
+}
+
+func mtToCNFCost(cost *Cost, mod int, upper mtVector, n, currentRhs int) {
+	split := n / 2
+	var lupper, rupper mtVector
+	var llower, rlower int
+	if split == 1 {
+		lupper = mtVector{2, true}
+		llower = 1
+	} else {
+		left := split / mod
+		limit := mod - 1
+		if left%mod == 0 && split < mod-1 {
+			limit = split
+		}
+		lupper = mtVector{left, false}
+		llower = limit
+		cost.AuxVars += left + limit
+	}
+	if n-split == 1 {
+		rupper = mtVector{1, true}
+		rlower = 1
+	} else {
+		right := (n - split) / mod
+		limit := mod - 1
+		if right%mod == 0 && n-split < mod-1 {
+			limit = n - split
+		}
+		rupper = mtVector{right, false}
+		rlower = limit
+		cost.AuxVars += right + limit
+	}
+	if lupper.size == 0 {
+		lupper = mtVector{1, true}
+	}
+	if rupper.size == 0 {
+		rupper = mtVector{1, true}
+	}
+	mtAdderCost(cost, mod, upper, llower, rlower, lupper, rupper, currentRhs)
+	if split > 1 {
+		mtToCNFCost(cost, mod, lupper, split, currentRhs)
+	}
+	if n-split > 1 {
+		mtToCNFCost(cost, mod, rupper, n-split, currentRhs)
+	}
+}
+
+func mtAdderCost(cost *Cost, mod int, upper mtVector, llower, rlower int, lupper, rupper mtVector, currentRhs int) {
+	if !upper.h0 {
+		cost.AuxVars++
+	}
+	for i := 0; i <= llower; i++ {
+		for j := 0; j <= rlower; j++ {
+			if i+j > currentRhs+1 && currentRhs+1 < mod || i == 0 && j == 0 {
+				continue
+			}
+			cost.Clauses++
+		}
+	}
+	if upper.h0 {
+		return
+	}
+	closeMod := currentRhs / mod
+	if currentRhs%mod != 0 {
+		closeMod++
+	}
+	for i := 0; i <= lupper.size; i++ {
+		for j := 0; j <= rupper.size; j++ {
+			if i+j > closeMod {
+				continue
+			}
+			a := i != 0 && !lupper.h0
+			b := j != 0 && !rupper.h0
+			c := i+j != 0 && i+j-1 < upper.size
+			d := i+j < upper.size
+			if c && (a || b) {
+				cost.Clauses++
+			}
+			if a || b || d {
+				cost.Clauses++
+			}
+		}
+	}
+}
+
+// sortCost counts the sorting network of the cardinality network encoding
+// for n inputs and returns the number of its outputs.
+func sortCost(cost *Cost, m, n int, direction dir) int {
+	if m == 0 || n == 0 {
+		return 0
+	}
+	m2 := min(m, n)
+	if n == 1 {
+		return 1
+	}
+	if n == 2 {
+		if m2 == 2 {
+			cost.AuxVars += 2
+			comparatorCost(cost, direction, true)
+			return 2
+		}
+		cost.AuxVars++
+		comparatorCost(cost, direction, false)
+		return 1
+	}
+	if direction != inputToOutput {
+		l := n / 2
+		outputsA := sortCost(cost, m2, l, direction)
+		outputsB := sortCost(cost, m2, n-l, direction)
+		return mergeCost(cost, m2, outputsA, outputsB, direction)
+	}
+	if counterSorterValue(m2, n) < directSorterValue(n) {
+		for j := range m2 {
+			cost.AuxVars += n - j
+		}
+		cost.Clauses += 2*n - 1
+		for j := 1; j < m2; j++ {
+			cost.Clauses += 2*(n-j) - 1
+		}
+		return m2
+	}
+	cost.AuxVars += m2
+	binomial := 1
+	for c := 1; c <= m2; c++ {
+		binomial = binomial * (n - c + 1) / c
+		cost.Clauses += binomial
+	}
+	return m2
+}
+
+// mergeCost counts the merging network of the cardinality network encoding
+// for inputs with the given sizes and returns the number of its outputs.
+func mergeCost(cost *Cost, m, a, b int, direction dir) int {
+	if m == 0 {
+		return 0
+	}
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	m2 := min(m, a+b)
+	if direction != inputToOutput {
+		return recursiveMergerCost(cost, m2, a, b, direction)
+	}
+	cost.AuxVars += m2
+	cost.Clauses += min(m2, a) + min(m2, b)
+	for i := range a {
+		cost.Clauses += max(0, min(b, m2-1-i))
+	}
+	return m2
+}
+
+func recursiveMergerCost(cost *Cost, c, a, b int, direction dir) int {
+	a2 := min(a, c)
+	b2 := min(b, c)
+	if c == 1 {
+		cost.AuxVars++
+		comparatorCost(cost, direction, false)
+		return 1
+	}
+	if a2 == 1 && b2 == 1 {
+		cost.AuxVars += 2
+		comparatorCost(cost, direction, true)
+		return 2
+	}
+	oddMerge := mergeCost(cost, c/2+1, (a2+1)/2, (b2+1)/2, direction)
+	evenMerge := mergeCost(cost, c/2, a2/2, b2/2, direction)
+	outputs := 1
+	for i, j := 1, 0; ; i, j = i+1, j+1 {
+		if i < oddMerge && j < evenMerge {
+			if outputs+2 <= c {
+				cost.AuxVars += 2
+				comparatorCost(cost, direction, true)
+				outputs += 2
+				if outputs == c {
+					break
+				}
+			} else if outputs+1 == c {
+				cost.AuxVars++
+				comparatorCost(cost, direction, false)
+				outputs++
+				break
+			}
+		} else if i >= oddMerge && j >= evenMerge {
+			break
+		} else {
+			outputs++
+			break
+		}
+	}
+	return outputs
+}
+
+// comparatorCost counts the clauses of a comparator with one or - if full is
+// set - two outputs.
+func comparatorCost(cost *Cost, direction dir, full bool) {
+	forward, backward := 2, 1
+	if full {
+		forward, backward = 3, 3
+	}
+	if direction == inputToOutput || direction == both {
+		cost.Clauses += forward
+	}
+	if direction == outputToInput || direction == both {
+		cost.Clauses += backward
+	}
+}
@@ -39,7 +39,7 @@ func EncodeCCInResult(
 	if constraint.Sort() != f.SortCC {
 		return errorx.BadFormulaSort(constraint.Sort())
 	}
-	cfg := determineConfig(fac, config)
+	cfg := selectByCost(fac, constraint, determineConfig(fac, config))
 	comparator, rhs, lits, _, found := fac.PBCOps(constraint)
 	if !found {
 		panic(errorx.UnknownFormula(constraint))
@@ -79,27 +79,31 @@ func EncodeCCInResult(
 // result can be either a formula result and a formula is generated or it can
 // be a solver encoding and the cnf is added directly to the solver without
 // first generating formulas on the formula factory.  The returned incremental
-// data can then be used to tighten the bound of the constraint (either as
+// data can then be used to tighten the bounds of the constraint (either as
 // formula or also directly on the solver).
 //
 // Depending on the type of cardinality constraint it uses the encoding
 // algorithm as specified either in the optional given config or if it not
-// present the one configured in the formula factory.
+// present the one configured in the formula factory.  Exactly-k constraints
+// are always encoded with the totalizer.
 //
 // Returns an error if the input constraint cannot be converted to an
-// incremental constraint which is the case if it is an exo-constraint, a
-// tautology or contradiction, or a trivial constraint (all literals are set
-// to true or false)
+// incremental constraint which is the case if it is no cardinality
+// constraint, an exo-constraint, a tautology or contradiction, or a trivial
+// constraint (all literals are set to true or false)
 func EncodeIncremental(
 	fac f.Factory,
 	constraint f.Formula,
 	result Result,
 	config ...*Config,
 ) (*CCIncrementalData, error) {
+	if constraint.Sort() != f.SortCC {
+		return nil, errorx.BadFormulaSort(constraint.Sort())
+	}
 	cfg := determineConfig(fac, config)
 	comparator, rhs, lits, _, found := fac.PBCOps(constraint)
 	if !found {
-		panic(errorx.UnknownFormula(constraint))
+		return nil, errorx.UnknownFormula(constraint)
 	}
 	ops, _ := f.LiteralsAsVariables(lits)
 	switch comparator {
@@ -111,8 +115,10 @@ func EncodeIncremental(
 		return alkIncremental(result, cfg, ops, rhs)
 	case f.GT:
 		return alkIncremental(result, cfg, ops, rhs+1)
+	case f.EQ:
+		return exkIncremental(result, ops, rhs)
 	default:
-		panic(errorx.BadInput("incremental encodings are only supported for at-most-k and at-least k constraints"))
+		return nil, errorx.UnknownEnumValue(comparator)
 	}
 }
 
@@ -218,7 +224,7 @@ func alk(result Result, config *Config, rhs int, vars []f.Variable) {
 	}
 	switch config.ALKEncoder {
 	case ALKTotalizer:
-		totalizerALK(result, vars, rhs)
+		totalizerALK(result, vars, rhs, false)
 	case ALKModularTotalizer:
 		modtotalizerALK(result, f.VariablesAsLiterals(vars), rhs)
 	case ALKCardinalityNetwork:
@@ -282,7 +288,7 @@ func amkIncremental(
 	case AMKCardinalityNetwork:
 		return cnAmkForIncremental(result, vars, rhs), nil
 	default:
-		panic(errorx.BadFormulaSort(config.AMKEncoder))
+		return nil, errorx.UnknownEnumValue(config.AMKEncoder)
 	}
 }
 
@@ -311,12 +317,32 @@ func alkIncremental(
 	}
 	switch config.ALKEncoder {
 	case ALKTotalizer:
-		return totalizerALK(result, vars, rhs), nil
+		return totalizerALK(result, vars, rhs, true), nil
 	case ALKModularTotalizer, ALKBest:
 		return modtotalizerALK(result, f.VariablesAsLiterals(vars), rhs), nil
 	case ALKCardinalityNetwork:
 		return cnAlkForIncremental(result, vars, rhs), nil
 	default:
-		panic(errorx.UnknownEnumValue(config.ALKEncoder))
+		return nil, errorx.UnknownEnumValue(config.ALKEncoder)
+	}
+}
+
+func exkIncremental(result Result, vars []f.Variable, rhs int) (*CCIncrementalData, error) {
+	if rhs > len(vars) {
+		result.AddClause()
+		return nil, errorx.BadInput("contradiction EXK with rhs > len(vars)")
+	}
+	if rhs == 0 {
+		for _, variable := range vars {
+			result.AddClause(variable.Negate(result.Factory()))
+		}
+		return nil, errorx.BadInput("trivial EXK with rhs = 0")
+	}
+	if rhs == len(vars) {
+		for _, variable := range vars {
+			result.AddClause(variable.AsLiteral())
+		}
+		return nil, errorx.BadInput("trivial EXK with rhs = len(vars)")
 	}
+	return totalizerEXK(result, vars, rhs), nil
 }
@@ -5,48 +5,75 @@ import (
 	f "github.com/booleworks/logicng-go/formula"
 )
 
-// CCIncrementalData gathers data for an incremental at-most-k cardinality
-// constraint.  When an at-most-k cardinality constraint is constructed, it is
-// possible to save incremental data with it.  Then one can modify the
-// constraint after it was created by tightening the original bound.
+// CCIncrementalData gathers data for an incremental cardinality constraint.
+// When a cardinality constraint is constructed, it is possible to save
+// incremental data with it.  Then one can modify the constraint after it was
+// created by tightening the original bounds.
+//
+// At-most-k constraints only support tightening the upper bound.  At-least-k
+// constraints support tightening the lower bound and - if they are encoded
+// with the totalizer - also the upper bound.  Exactly-k constraints are
+// always encoded with the totalizer and support tightening both bounds.
 type CCIncrementalData struct {
 	Result     Result // encoding result of the incremental constraint
 	amkEncoder AMKEncoder
 	alkEncoder ALKEncoder
+	bound      bound
 	vector1    []f.Literal
 	vector2    []f.Literal
 	mod        int
 	nVars      int
-	currentRhs int
+	upperRhs   int
+	lowerRhs   int
 }
 
-// NewUpperBound tightens the upper bound of an at-most-k constraint and
-// returns the resulting formula.
-//
-// Usage constraints:
-//   - New right-hand side must be smaller than current right-hand side.
-//   - Cannot be used for at-least-k constraints.
-func (cc *CCIncrementalData) NewUpperBound(rhs int) []f.Formula {
-	cc.computeUbConstraint(rhs)
-	return cc.Result.Formulas()
+// CurrentUpperBound returns the current upper bound of the constraint.  If
+// the upper bound of the constraint was never restricted, this is the number
+// of its variables.
+func (cc *CCIncrementalData) CurrentUpperBound() int {
+	return cc.upperRhs
 }
 
-// NewUpperBoundForSolver tightens the upper bound of an at-most-k constraint
-// and encodes it on the solver of the result.
-//
-// Usage constraints:
-//   - New right-hand side must be smaller than current right-hand side.
-//   - Cannot be used for at-least-k constraints.
-func (cc *CCIncrementalData) NewUpperBoundForSolver(rhs int) {
+// CurrentLowerBound returns the current lower bound of the constraint.  If
+// the lower bound of the constraint was never restricted, this is 0.
+func (cc *CCIncrementalData) CurrentLowerBound() int {
+	return cc.lowerRhs
+}
+
+// NewUpperBound tightens the upper bound of the constraint and returns the
+// resulting formula.  Returns an error if the new right-hand side is not
+// smaller than the current upper bound or if the upper bound of the
+// constraint cannot be tightened.
+func (cc *CCIncrementalData) NewUpperBound(rhs int) ([]f.Formula, error) {
+	if err := cc.NewUpperBoundForSolver(rhs); err != nil {
+		return nil, err
+	}
+	return cc.Result.Formulas(), nil
+}
+
+// NewUpperBoundForSolver tightens the upper bound of the constraint and
+// encodes it on the solver of the result.  Returns an error if the new
+// right-hand side is not smaller than the current upper bound or if the upper
+// bound of the constraint cannot be tightened.
+func (cc *CCIncrementalData) NewUpperBoundForSolver(rhs int) error {
+	if cc.bound == boundLower {
+		return errorx.BadInput("the upper bound of an at-least-k constraint encoded with %s cannot be tightened",
+			cc.alkEncoder)
+	}
+	if rhs >= cc.upperRhs {
+		return errorx.BadInput("new upper bound %d does not tighten the current bound of %d", rhs, cc.upperRhs)
+	}
+	cc.upperRhs = rhs
+	if rhs < 0 {
+		cc.Result.AddClause()
+		return nil
+	}
 	cc.computeUbConstraint(rhs)
+	return nil
 }
 
 func (cc *CCIncrementalData) computeUbConstraint(rhs int) {
 	fac := cc.Result.Factory()
-	if rhs >= cc.currentRhs {
-		panic(errorx.BadInput("new upper bound %d does not tighten the current bound of %d", rhs, cc.currentRhs))
-	}
-	cc.currentRhs = rhs
 	switch cc.amkEncoder {
 	case AMKModularTotalizer:
 		ulimit := (rhs + 1) / cc.mod
@@ -80,33 +107,39 @@ func (cc *CCIncrementalData) computeUbConstraint(rhs int) {
 	}
 }
 
-// NewLowerBound tightens the lower bound of an at-least-k constraint and
-// returns the resulting formula.
-//
-// Usage constraints:
-//   - New right-hand side must be greater than current right-hand side.
-//   - Cannot be used for at-most-k constraints.
-func (cc *CCIncrementalData) NewLowerBound(rhs int) []f.Formula {
-	cc.computeLbConstraint(rhs)
-	return cc.Result.Formulas()
+// NewLowerBound tightens the lower bound of the constraint and returns the
+// resulting formula.  Returns an error if the new right-hand side is not
+// greater than the current lower bound or if the constraint is an at-most-k
+// constraint.
+func (cc *CCIncrementalData) NewLowerBound(rhs int) ([]f.Formula, error) {
+	if err := cc.NewLowerBoundForSolver(rhs); err != nil {
+		return nil, err
+	}
+	return cc.Result.Formulas(), nil
 }
 
-// NewLowerBoundForSolver tightens the lower bound of an at-least-k constraint
-// and encodes it on the solver of the result.
-//
-// Usage constraints:
-//   - New right-hand side must be greater than current right-hand side.
-//   - Cannot be used for at-most-k constraints.
-func (cc *CCIncrementalData) NewLowerBoundForSolver(rhs int) {
+// NewLowerBoundForSolver tightens the lower bound of the constraint and
+// encodes it on the solver of the result.  Returns an error if the new
+// right-hand side is not greater than the current lower bound or if the
+// constraint is an at-most-k constraint.
+func (cc *CCIncrementalData) NewLowerBoundForSolver(rhs int) error {
+	if cc.bound == boundUpper {
+		return errorx.BadInput("the lower bound of an at-most-k constraint cannot be tightened")
+	}
+	if rhs <= cc.lowerRhs {
+		return errorx.BadInput("new lower bound %d does not tighten the current bound of %d", rhs, cc.lowerRhs)
+	}
+	cc.lowerRhs = rhs
+	if rhs > cc.nVars {
+		cc.Result.AddClause()
+		return nil
+	}
 	cc.computeLbConstraint(rhs)
+	return nil
 }
 
 func (cc *CCIncrementalData) computeLbConstraint(rhs int) {
 	fac := cc.Result.Factory()
-	if rhs <= cc.currentRhs {
-		panic(errorx.BadInput("new lower bound %d does not tighten the current bound of %d", rhs, cc.currentRhs))
-	}
-	cc.currentRhs = rhs
 	switch cc.alkEncoder {
 	case ALKTotalizer:
 		for i := range rhs {
@@ -17,8 +17,10 @@ func modtotalizerAMK(result Result, vars []f.Literal, rhs int) *CCIncrementalDat
 		Result:     result,
 		amkEncoder: AMKModularTotalizer,
 		alkEncoder: ALKModularTotalizer,
+		bound:      boundUpper,
 		mod:        mod,
-		currentRhs: rhs,
+		upperRhs:   rhs,
+		nVars:      len(vars),
 		vector1:    state.cardinalityUpOutvars,
 		vector2:    state.cardinalityLwOutvars,
 	}
@@ -38,8 +40,10 @@ func modtotalizerALK(result Result, vars []f.Literal, rhs int) *CCIncrementalDat
 		Result:     result,
 		amkEncoder: AMKModularTotalizer,
 		alkEncoder: ALKModularTotalizer,
+		bound:      boundLower,
 		mod:        mod,
-		currentRhs: rhs,
+		upperRhs:   len(vars),
+		lowerRhs:   rhs,
 		nVars:      len(vars),
 		vector1:    state.cardinalityUpOutvars,
 		vector2:    state.cardinalityLwOutvars,
@@ -21,29 +21,51 @@ func totalizerAMK(result Result, vars []f.Variable, rhs int) *CCIncrementalData
 		Result:     result,
 		amkEncoder: AMKTotalizer,
 		alkEncoder: ALKTotalizer,
-		currentRhs: rhs,
+		bound:      boundUpper,
+		upperRhs:   rhs,
+		nVars:      len(vars),
 		vector1:    outvars,
 	}
 }
 
-func totalizerALK(result Result, vars []f.Variable, rhs int) *CCIncrementalData {
+// totalizerALK encodes an at-least-k constraint.  For an incremental
+// encoding, the upper direction of the totalizer is encoded as well, such
+// that also the upper bound of the constraint can be tightened incrementally.
+func totalizerALK(result Result, vars []f.Variable, rhs int, incremental bool) *CCIncrementalData {
 	tv := initializeConstraint(result, vars)
-	toCNF(result, tv, rhs, boundLower)
+	if incremental {
+		toCNF(result, tv, len(vars), boundBoth)
+	} else {
+		toCNF(result, tv, rhs, boundLower)
+	}
 	for i := range rhs {
 		result.AddClause((*tv.outvars)[i].AsLiteral())
 	}
 	outvars := f.VariablesAsLiterals(*tv.outvars)
+	if !incremental {
+		return &CCIncrementalData{
+			Result:     result,
+			amkEncoder: AMKTotalizer,
+			alkEncoder: ALKTotalizer,
+			bound:      boundLower,
+			lowerRhs:   rhs,
+			nVars:      len(vars),
+			vector1:    outvars,
+		}
+	}
 	return &CCIncrementalData{
 		Result:     result,
 		amkEncoder: AMKTotalizer,
 		alkEncoder: ALKTotalizer,
-		currentRhs: rhs,
+		bound:      boundBoth,
+		upperRhs:   len(vars),
+		lowerRhs:   rhs,
 		nVars:      len(vars),
 		vector1:    outvars,
 	}
 }
 
-func totalizerEXK(result Result, vars []f.Variable, rhs int) {
+func totalizerEXK(result Result, vars []f.Variable, rhs int) *CCIncrementalData {
 	tv := initializeConstraint(result, vars)
 	toCNF(result, tv, rhs, boundBoth)
 	for i := range rhs {
@@ -52,6 +74,17 @@ func totalizerEXK(result Result, vars []f.Variable, rhs int) {
 	for i := rhs; i < len(*tv.outvars); i++ {
 		result.AddClause((*tv.outvars)[i].Negate(result.Factory()))
 	}
+	outvars := f.VariablesAsLiterals(*tv.outvars)
+	return &CCIncrementalData{
+		Result:     result,
+		amkEncoder: AMKTotalizer,
+		alkEncoder: ALKTotalizer,
+		bound:      boundBoth,
+		upperRhs:   rhs,
+		lowerRhs:   rhs,
+		nVars:      len(vars),
+		vector1:    outvars,
+	}
 }
 
 func initializeConstraint(result Result, vars []f.Variable) *totalizerVars {
@@ -72,6 +72,9 @@ const (
 	PBCBinaryMerge
 	PBCAdderNetworks
 	PBCBest
+	PBCGTE
+	PBCBDD
+	PBCLadder
 )
 
 // Config describes the configuration for a cardinality or
@@ -94,6 +97,11 @@ type Config struct {
 	BinaryMergeUseGAC                bool
 	BinaryMergeNoSupportForSingleBit bool
 	BinaryMergeUseWatchDog           bool
+
+	// CostFunction is an optional cost function for the best encoders.  If it
+	// is set, the best encoders do not use their fixed heuristics but choose
+	// the encoder whose encoding has the minimal cost for the constraint.
+	CostFunction CostFunction
 }
 
 // Sort returns the configuration sort (Encoder).
@@ -0,0 +1,209 @@
+package encoding
+
+import (
+	"fmt"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// Cost describes the size and the propagation strength of the CNF encoding
+// of a cardinality or pseudo-Boolean constraint.
+type Cost struct {
+	Clauses       int  // number of clauses of the encoding
+	AuxVars       int  // number of auxiliary variables of the encoding
+	ArcConsistent bool // whether unit propagation on the encoding is arc-consistent
+}
+
+// A CostFunction maps the cost of an encoding to a value which should be
+// minimized.  It can be set in the encoder configuration in order to let the
+// best encoders choose the encoding with the minimal cost.
+type CostFunction func(cost Cost) float64
+
+// EncoderCost is the cost of encoding a constraint with a specific encoder.
+type EncoderCost struct {
+	Encoder fmt.Stringer // the encoder, e.g. AMOProduct or PBCGTE
+	Config  *Config      // a copy of the configuration with the encoder selected
+	Cost    Cost         // the cost of the encoding
+}
+
+// EstimateCosts returns the cost of encoding the given cardinality or
+// pseudo-Boolean constraint for each encoder which is applicable to the
+// constraint.  The best encoders are not part of the result.  The other
+// parameters of the encoders are taken from the optional given config or if
+// it is not present from the one configured in the formula factory.
+//
+// The costs are computed analytically per encoder from the number of
+// literals, the coefficients and the right-hand side of the (normalized)
+// constraint.  No clauses or auxiliary variables are generated, thus the
+// given formula factory is not changed and the estimation is much cheaper
+// than the encoding itself.
+//
+// Returns an error if the input constraint is no valid cardinality or
+// pseudo-Boolean constraint.
+func EstimateCosts(fac f.Factory, constraint f.Formula, config ...*Config) ([]EncoderCost, error) {
+	if constraint.Sort() != f.SortCC && constraint.Sort() != f.SortPBC {
+		return nil, errorx.BadFormulaSort(constraint.Sort())
+	}
+	comparator, rhs, lits, coeffs, found := fac.PBCOps(constraint)
+	if !found {
+		return nil, errorx.UnknownFormula(constraint)
+	}
+	c := costConstraint{constraint.Sort(), comparator, rhs, lits, coeffs}
+	return c.estimateCosts(fac, determineConfig(fac, config))
+}
+
+// selectByCost returns the configuration for encoding the given constraint.
+// If the encoder for the constraint is a best encoder and the configuration
+// has a cost function, the configuration of the encoder with the minimal
+// cost is returned.  Otherwise the given configuration is returned.
+func selectByCost(fac f.Factory, constraint f.Formula, config *Config) *Config {
+	if config.CostFunction == nil {
+		return config
+	}
+	comparator, rhs, lits, coeffs, _ := fac.PBCOps(constraint)
+	c := costConstraint{constraint.Sort(), comparator, rhs, lits, coeffs}
+	return c.selectConfig(fac, config)
+}
+
+// costConstraint holds the operands of a cardinality or pseudo-Boolean
+// constraint whose encoding costs are computed.
+type costConstraint struct {
+	sort       f.FSort
+	comparator f.CSort
+	rhs        int
+	lits       []f.Literal
+	coeffs     []int
+}
+
+func (c costConstraint) estimateCosts(fac f.Factory, config *Config) ([]EncoderCost, error) {
+	candidates := c.encoderCandidates(config)
+	for i := range candidates {
+		if err := c.cost(fac, candidates[i].Config, &candidates[i].Cost); err != nil {
+			return nil, err
+		}
+	}
+	return candidates, nil
+}
+
+func (c costConstraint) selectConfig(fac f.Factory, config *Config) *Config {
+	if config.CostFunction == nil || !c.usesBestEncoder(config) {
+		return config
+	}
+	costs, err := c.estimateCosts(fac, config)
+	if err != nil || len(costs) == 0 {
+		return config
+	}
+	best := costs[0]
+	bestValue := config.CostFunction(best.Cost)
+	for _, candidate := range costs[1:] {
+		if value := config.CostFunction(candidate.Cost); value < bestValue {
+			best, bestValue = candidate, value
+		}
+	}
+	return best.Config
+}
+
+// cost adds the cost of encoding the constraint with the given configuration
+// to the given cost.  It follows the same steps as EncodePBCInResult.
+func (c costConstraint) cost(fac f.Factory, config *Config, cost *Cost) error {
+	cfg := c.selectConfig(fac, config)
+	if c.sort == f.SortCC {
+		ccCost(cost, cfg, c.comparator, c.rhs, len(c.lits))
+		return nil
+	}
+	parts := normalizeOps(fac, c.comparator, c.rhs, c.lits, c.coeffs)
+	if len(parts) == 1 {
+		return normalizedCost(cost, parts[0], cfg)
+	}
+	// the conjunction of the two parts of an EQ constraint is simplified
+	// for trivial parts, otherwise each part is encoded on its own
+	switch {
+	case parts[0].sort == f.SortFalse || parts[1].sort == f.SortFalse:
+		cost.Clauses++
+	case parts[0].sort == f.SortTrue:
+		return normalizedCost(cost, parts[1], cfg)
+	case parts[1].sort == f.SortTrue:
+		return normalizedCost(cost, parts[0], cfg)
+	default:
+		for _, part := range parts {
+			sort := f.SortPBC
+			if isCardinality(part) {
+				sort = f.SortCC
+			}
+			partConstraint := costConstraint{sort, f.LE, part.rhs, part.lits, part.coeffs}
+			if err := partConstraint.cost(fac, cfg, cost); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c costConstraint) usesBestEncoder(config *Config) bool {
+	if c.sort == f.SortPBC {
+		return config.PBCEncoder == PBCBest
+	}
+	switch {
+	case isAMO(c.comparator, c.rhs):
+		return config.AMOEncoder == AMOBest
+	case c.comparator == f.LE || c.comparator == f.LT:
+		return config.AMKEncoder == AMKBest
+	case c.comparator == f.GE || c.comparator == f.GT:
+		return config.ALKEncoder == ALKBest
+	default:
+		return config.EXKEncoder == EXKBest
+	}
+}
+
+func isAMO(comparator f.CSort, rhs int) bool {
+	return comparator == f.LE && rhs == 1 || comparator == f.LT && rhs == 2 || comparator == f.EQ && rhs == 1
+}
+
+// isCardinality reports whether the factory represents the given normalized
+// constraint as cardinality constraint.
+func isCardinality(constraint normalizedLE) bool {
+	for i, lit := range constraint.lits {
+		if !lit.IsPos() || constraint.coeffs[i] != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c costConstraint) encoderCandidates(config *Config) []EncoderCost {
+	var candidates []EncoderCost
+	add := func(encoder fmt.Stringer, arcConsistent bool, set func(*Config)) {
+		cfg := *config
+		set(&cfg)
+		candidates = append(candidates, EncoderCost{encoder, &cfg, Cost{ArcConsistent: arcConsistent}})
+	}
+	if c.sort == f.SortPBC {
+		for _, encoder := range []PBCEncoder{PBCSWC, PBCBinaryMerge, PBCAdderNetworks, PBCGTE, PBCBDD, PBCLadder} {
+			arcConsistent := encoder != PBCAdderNetworks && (encoder != PBCBinaryMerge || config.BinaryMergeUseGAC)
+			add(encoder, arcConsistent, func(c *Config) { c.PBCEncoder = encoder })
+		}
+		return candidates
+	}
+	switch {
+	case isAMO(c.comparator, c.rhs):
+		for _, encoder := range []AMOEncoder{
+			AMOPure, AMOLadder, AMOProduct, AMONested, AMOCommander, AMOBinary, AMOBimander,
+		} {
+			add(encoder, true, func(c *Config) { c.AMOEncoder = encoder })
+		}
+	case c.comparator == f.LE || c.comparator == f.LT:
+		for _, encoder := range []AMKEncoder{AMKTotalizer, AMKModularTotalizer, AMKCardinalityNetwork} {
+			add(encoder, true, func(c *Config) { c.AMKEncoder = encoder })
+		}
+	case c.comparator == f.GE || c.comparator == f.GT:
+		for _, encoder := range []ALKEncoder{ALKTotalizer, ALKModularTotalizer, ALKCardinalityNetwork} {
+			add(encoder, true, func(c *Config) { c.ALKEncoder = encoder })
+		}
+	default:
+		for _, encoder := range []EXKEncoder{EXKTotalizer, EXKCardinalityNetwork} {
+			add(encoder, true, func(c *Config) { c.EXKEncoder = encoder })
+		}
+	}
+	return candidates
+}
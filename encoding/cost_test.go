@@ -0,0 +1,128 @@
+package encoding
+
+import (
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateCosts(t *testing.T) {
+	assert := assert.New(t)
+	gac := DefaultConfig()
+	gac.BinaryMergeUseGAC = true
+	gac.BinaryMergeNoSupportForSingleBit = true
+	watchDog := DefaultConfig()
+	watchDog.BinaryMergeUseGAC = false
+	watchDog.BinaryMergeUseWatchDog = true
+	groups := DefaultConfig()
+	groups.ProductRecursiveBound = 3
+	groups.NestingGroupSize = 3
+	groups.CommanderGroupSize = 4
+	groups.BimanderGroupSize = BimanderHalf
+	for _, input := range []string{
+		"a + b <= 1",
+		"a + b + c + d + e <= 1",
+		"a + b + c + d + e + f + g < 4",
+		"a + b + c + d + e + f >= 2",
+		"a + b + c + d + e + f >= 5",
+		"a + b + c + d + e + f > 3",
+		"a + b + c + d + e + f = 3",
+		"a + b + c + d = 1",
+		"a + b + c + d + e + f + g + h + i + j + k + l + m + n + o + p + q + r + s + t + u + v + w <= 1",
+		"a + b + c + d + e + f + g + h + i + j + k + l + m + n + o + p + q + r + s + t + u + v + w <= 9",
+		"a + b + c + d + e + f + g + h + i + j + k + l + m + n + o + p + q + r + s + t + u + v + w >= 14",
+		"a + b + c + d + e + f + g + h + i + j + k + l + m + n + o + p + q + r + s + t + u + v + w = 7",
+		"3*a + 2*b + 2*c + 4*d + e + 5*f <= 8",
+		"3*a + 2*b + 2*c + 4*d + e + 5*f >= 6",
+		"3*a + 2*~b + 2*c + 4*d + 9*e + 5*f < 12",
+		"3*a + 2*b + 2*c + 4*d + e + 5*f = 7",
+		"a + b + c + d + 2*e = 3",
+		"2*a + 2*b + 2*c + 4*d + 6*e + 8*f + 10*g + 3*h + 3*i + 3*j + 17*k <= 23",
+		"7*a + 7*b + 7*c + 7*d + 3*e + 3*f + 3*g + 2*h + 5*i + -4*j + 12*k >= 19",
+		"2*a + 2*b + 4*c <= 5",
+		"5*a + 5*b + 5*c + 5*d <= 4",
+		"2*a + 2*b + 2*c + 2*d = 2",
+	} {
+		for _, config := range []*Config{DefaultConfig(), gac, watchDog, groups} {
+			fac := f.NewFactory()
+			constraint := parser.New(fac).ParseUnsafe(input)
+			costs, err := EstimateCosts(fac, constraint, config)
+			assert.Nil(err)
+			assert.NotEmpty(costs)
+			for _, cost := range costs {
+				cnf, err := EncodePBC(fac, constraint, cost.Config)
+				assert.Nil(err)
+				assert.Equal(len(cnf), cost.Cost.Clauses, "%s with %s", input, cost.Encoder)
+				result := &countingResult{Result: ResultForFormula(fac)}
+				assert.Nil(EncodePBCInResult(fac, constraint, result, cost.Config))
+				assert.Equal(result.auxVars, cost.Cost.AuxVars, "%s with %s", input, cost.Encoder)
+			}
+		}
+	}
+}
+
+func TestEstimateCostsFactoryUnchanged(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	constraint := parser.New(fac).ParseUnsafe("3*a + 2*b + 2*c + 4*d + e + 5*f <= 8")
+	_, err := EstimateCosts(fac, constraint)
+	assert.Nil(err)
+	name, _ := fac.VarName(fac.NewAuxVar(f.AuxPBC))
+	assert.Equal(f.AuxPBC+"0", name)
+
+	_, err = EstimateCosts(fac, fac.Var("a").AsFormula())
+	assert.NotNil(err)
+}
+
+func TestEstimateCostsArcConsistency(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	constraint := parser.New(fac).ParseUnsafe("3*a + 2*b + 2*c + 4*d + e + 5*f <= 8")
+	config := DefaultConfig()
+	config.BinaryMergeUseGAC = false
+	costs, _ := EstimateCosts(fac, constraint, config)
+	for _, cost := range costs {
+		switch cost.Encoder {
+		case PBCAdderNetworks, PBCBinaryMerge:
+			assert.False(cost.Cost.ArcConsistent)
+		default:
+			assert.True(cost.Cost.ArcConsistent)
+		}
+	}
+}
+
+func TestCostFunction(t *testing.T) {
+	assert := assert.New(t)
+	for _, input := range []string{
+		"a + b + c + d + e + f + g + h <= 1",
+		"a + b + c + d + e + f + g < 4",
+		"3*a + 2*b + 2*c + 4*d + e + 5*f <= 8",
+		"2*a + 2*b + 2*c + 2*d + 2*e + 2*f = 4",
+	} {
+		fac := f.NewFactory()
+		constraint := parser.New(fac).ParseUnsafe(input)
+		config := DefaultConfig()
+		config.CostFunction = func(cost Cost) float64 { return float64(cost.Clauses) }
+		costs, _ := EstimateCosts(fac, constraint, config)
+		minClauses := costs[0].Cost.Clauses
+		for _, cost := range costs {
+			minClauses = min(minClauses, cost.Cost.Clauses)
+		}
+		cnf, err := EncodePBC(fac, constraint, config)
+		assert.Nil(err)
+		assert.Equal(minClauses, len(cnf), input)
+	}
+}
+
+// countingResult counts the auxiliary variables of an encoding.
+type countingResult struct {
+	Result
+	auxVars int
+}
+
+func (r *countingResult) NewAuxVar(sort f.AuxVarSort) f.Variable {
+	r.auxVars++
+	return r.Result.NewAuxVar(sort)
+}
@@ -0,0 +1,639 @@
+package encoding
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// DetectionConfig describes the configuration for the detection of
+// cardinality constraints in a set of clauses.
+type DetectionConfig struct {
+	// AuxVars are variables which are known to be auxiliary variables of AMO
+	// encodings like the ladder, commander, product, or binary encoding.
+	// These variables are eliminated if the clauses they occur in encode an
+	// at-most-one constraint over the other variables.
+	AuxVars *f.VarSet
+
+	// OriginalVars are the variables of the original problem.  If they are
+	// given, all other variables are treated as auxiliary variables.
+	OriginalVars *f.VarSet
+
+	// GuessAuxVars treats every variable as a candidate auxiliary variable
+	// which occurs positively and negatively, and only in binary clauses or
+	// clauses with at most one positive literal.  Note that this can also
+	// eliminate original variables of the input if they only occur in
+	// patterns which can be lifted to an at-most-one constraint.
+	GuessAuxVars bool
+
+	// MaxAMK is the maximal right-hand side of at-most-k constraints which
+	// are detected from their binomial (naive) encoding.  With a value < 2 no
+	// at-most-k constraints are detected.
+	MaxAMK int
+}
+
+// DefaultDetectionConfig returns the default configuration for the
+// detection of cardinality constraints.
+func DefaultDetectionConfig() *DetectionConfig {
+	return &DetectionConfig{
+		MaxAMK: 3,
+	}
+}
+
+// Detection is the result of the detection of cardinality constraints in a
+// set of clauses.  The conjunction of the constraints and the remaining
+// clauses is equivalent to the input clauses where the eliminated auxiliary
+// variables are existentially quantified.
+type Detection struct {
+	Constraints []f.Formula   // the detected cardinality constraints
+	Absorbed    [][]f.Formula // the input clauses absorbed by each detected constraint
+	Remaining   []f.Formula   // the input clauses which were not absorbed
+	AuxVars     []f.Variable  // the eliminated auxiliary variables
+}
+
+// DetectCardinalityConstraints detects hand-encoded at-most-one and
+// at-most-k constraints in the given clauses and lifts them back to
+// cardinality constraints.
+//
+// At-most-one constraints are detected in the pairwise encoding by searching
+// cliques of negative binary clauses.  Encodings with auxiliary variables
+// (e.g. ladder, commander, product, or binary encoding) are detected by
+// eliminating the auxiliary variables with resolution, if the auxiliary
+// variables are given in the config or should be guessed.  At-most-k
+// constraints are detected in the binomial encoding where all subsets of k+1
+// variables are forbidden by a negative clause.  Other encodings like the
+// totalizer are not detected.
+//
+// An optional config can be given, otherwise the default config is used.
+// Returns an error if one of the given formulas is not a clause.
+func DetectCardinalityConstraints(
+	fac f.Factory,
+	clauses []f.Formula,
+	config ...*DetectionConfig,
+) (*Detection, error) {
+	cfg := DefaultDetectionConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	d := &detector{
+		fac:       fac,
+		config:    cfg,
+		formulas:  clauses,
+		clauses:   make([][]f.Literal, len(clauses)),
+		absorbed:  make([]int, len(clauses)),
+		result:    &Detection{},
+		varIndex:  make(map[f.Variable]int),
+		clauseIDs: make(map[string]int),
+	}
+	for i, clause := range clauses {
+		lits, err := clauseLiterals(fac, clause)
+		if err != nil {
+			return nil, err
+		}
+		d.clauses[i] = lits
+		d.absorbed[i] = -1
+		if _, ok := d.clauseIDs[clauseKey(lits)]; !ok && lits != nil {
+			d.clauseIDs[clauseKey(lits)] = i
+		}
+		for _, lit := range lits {
+			if _, ok := d.varIndex[lit.Variable()]; !ok {
+				d.varIndex[lit.Variable()] = len(d.varIndex)
+			}
+		}
+	}
+	d.detectAuxAMOs()
+	d.detectPairwiseAMOs()
+	for k := 2; k <= cfg.MaxAMK; k++ {
+		d.detectBinomialAMKs(k)
+	}
+	for i, clause := range clauses {
+		if d.absorbed[i] == -1 {
+			d.result.Remaining = append(d.result.Remaining, clause)
+		}
+	}
+	return d.result, nil
+}
+
+type detector struct {
+	fac       f.Factory
+	config    *DetectionConfig
+	formulas  []f.Formula
+	clauses   [][]f.Literal
+	absorbed  []int
+	result    *Detection
+	varIndex  map[f.Variable]int
+	clauseIDs map[string]int
+	amoVars   []map[f.Variable]bool
+}
+
+// clauseLiterals returns the literals of the given clause or nil if the
+// clause is a constant.
+func clauseLiterals(fac f.Factory, clause f.Formula) ([]f.Literal, error) {
+	switch clause.Sort() {
+	case f.SortTrue, f.SortFalse:
+		return nil, nil
+	case f.SortLiteral:
+		lit, _ := clause.AsLiteral()
+		return []f.Literal{lit}, nil
+	case f.SortOr:
+		ops := fac.Operands(clause)
+		lits := make([]f.Literal, len(ops))
+		for i, op := range ops {
+			lit, err := op.AsLiteral()
+			if err != nil {
+				return nil, errorx.BadInput("formula is not a clause: %s", clause.Sprint(fac))
+			}
+			lits[i] = lit
+		}
+		return lits, nil
+	default:
+		return nil, errorx.BadInput("formula is not a clause: %s", clause.Sprint(fac))
+	}
+}
+
+func clauseKey(lits []f.Literal) string {
+	sorted := slices.Clone(lits)
+	slices.Sort(sorted)
+	key := make([]byte, 0, 4*len(sorted))
+	for _, lit := range sorted {
+		key = append(key, byte(lit>>24), byte(lit>>16), byte(lit>>8), byte(lit))
+	}
+	return string(key)
+}
+
+func isNegative(lits []f.Literal) bool {
+	for _, lit := range lits {
+		if lit.IsPos() {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *detector) addConstraint(constraint f.Formula, vars []f.Variable, clauses []int) {
+	index := len(d.result.Constraints)
+	d.result.Constraints = append(d.result.Constraints, constraint)
+	absorbed := make([]f.Formula, 0, len(clauses))
+	clauses = slices.Clone(clauses)
+	slices.Sort(clauses)
+	for _, i := range clauses {
+		if d.absorbed[i] == -1 {
+			d.absorbed[i] = index
+			absorbed = append(absorbed, d.formulas[i])
+		}
+	}
+	d.result.Absorbed = append(d.result.Absorbed, absorbed)
+	if constraint.Sort() == f.SortCC {
+		if comparator, rhs, _, _, _ := d.fac.PBCOps(constraint); comparator == f.LE && rhs == 1 {
+			varSet := make(map[f.Variable]bool, len(vars))
+			for _, v := range vars {
+				varSet[v] = true
+			}
+			d.amoVars = append(d.amoVars, varSet)
+		}
+	}
+}
+
+func (d *detector) sortVars(vars []f.Variable) {
+	slices.SortFunc(vars, func(v1, v2 f.Variable) int { return cmp.Compare(d.varIndex[v1], d.varIndex[v2]) })
+}
+
+// detectAuxAMOs eliminates connected groups of candidate auxiliary variables
+// by resolution.  If the result of the elimination consists of negative
+// binary clauses which - together with the negative binary clauses of the
+// input - form cliques, the group's clauses are replaced by at-most-one
+// constraints over these cliques.
+func (d *detector) detectAuxAMOs() {
+	candidates := d.auxCandidates()
+	if len(candidates) == 0 {
+		return
+	}
+	occurrences := make(map[f.Variable][]int)
+	for i, lits := range d.clauses {
+		if !slices.ContainsFunc(lits, func(lit f.Literal) bool { return candidates[lit.Variable()] }) {
+			continue
+		}
+		for _, lit := range lits {
+			occurrences[lit.Variable()] = append(occurrences[lit.Variable()], i)
+		}
+	}
+	visited := make(map[f.Variable]bool)
+	for _, lits := range d.clauses {
+		for _, lit := range lits {
+			start := lit.Variable()
+			if !candidates[start] || visited[start] {
+				continue
+			}
+			group, clauses := d.auxGroup(start, candidates, occurrences, visited)
+			d.eliminateAuxGroup(group, clauses)
+		}
+	}
+}
+
+func (d *detector) auxCandidates() map[f.Variable]bool {
+	candidates := make(map[f.Variable]bool)
+	if d.config.AuxVars != nil {
+		for _, v := range d.config.AuxVars.Content() {
+			candidates[v] = true
+		}
+	}
+	if d.config.OriginalVars != nil {
+		for v := range d.varIndex {
+			if !d.config.OriginalVars.Contains(v) {
+				candidates[v] = true
+			}
+		}
+	}
+	if !d.config.GuessAuxVars {
+		return candidates
+	}
+	pos := make(map[f.Variable]bool)
+	neg := make(map[f.Variable]bool)
+	excluded := make(map[f.Variable]bool)
+	for _, lits := range d.clauses {
+		positives := 0
+		for _, lit := range lits {
+			if lit.IsPos() {
+				positives++
+			}
+		}
+		for _, lit := range lits {
+			if lit.IsPos() {
+				pos[lit.Variable()] = true
+			} else {
+				neg[lit.Variable()] = true
+			}
+			if len(lits) != 2 && positives > 1 {
+				excluded[lit.Variable()] = true
+			}
+		}
+	}
+	for v := range pos {
+		if neg[v] && !excluded[v] {
+			candidates[v] = true
+		}
+	}
+	return candidates
+}
+
+// auxGroup collects the auxiliary variables which are connected with the
+// given start variable by clauses with auxiliary variables.  Two variables
+// are connected if they occur in a common clause.
+func (d *detector) auxGroup(
+	start f.Variable,
+	candidates map[f.Variable]bool,
+	occurrences map[f.Variable][]int,
+	visited map[f.Variable]bool,
+) ([]f.Variable, []int) {
+	group := []f.Variable{start}
+	queue := []f.Variable{start}
+	visited[start] = true
+	clauseSet := make(map[int]bool)
+	var clauses []int
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, c := range occurrences[v] {
+			if clauseSet[c] {
+				continue
+			}
+			clauseSet[c] = true
+			clauses = append(clauses, c)
+			for _, lit := range d.clauses[c] {
+				if other := lit.Variable(); !visited[other] {
+					visited[other] = true
+					queue = append(queue, other)
+					if candidates[other] {
+						group = append(group, other)
+					}
+				}
+			}
+		}
+	}
+	slices.Sort(clauses)
+	return group, clauses
+}
+
+func (d *detector) eliminateAuxGroup(group []f.Variable, clauses []int) {
+	inGroup := make(map[f.Variable]bool, len(group))
+	for _, v := range group {
+		inGroup[v] = true
+	}
+	var vars []f.Variable
+	varSet := make(map[f.Variable]bool)
+	local := make(map[string][]f.Literal, len(clauses))
+	for _, c := range clauses {
+		local[clauseKey(d.clauses[c])] = d.clauses[c]
+		for _, lit := range d.clauses[c] {
+			if v := lit.Variable(); !inGroup[v] && !varSet[v] {
+				varSet[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	if len(vars) < 3 {
+		return
+	}
+	limit := 4*len(local) + len(vars)*len(vars)
+	remaining := slices.Clone(group)
+	for len(remaining) > 0 {
+		index := d.nextElimination(remaining, local)
+		if !d.resolve(remaining[index], local, limit) {
+			return
+		}
+		remaining = slices.Delete(remaining, index, index+1)
+	}
+	adjacency := make(map[f.Variable]map[f.Variable]int)
+	var derived [][2]f.Variable
+	for _, lits := range local {
+		if len(lits) != 2 || !isNegative(lits) {
+			return
+		}
+		addEdge(adjacency, lits[0].Variable(), lits[1].Variable(), -1)
+		derived = append(derived, [2]f.Variable{lits[0].Variable(), lits[1].Variable()})
+	}
+	for i, lits := range d.clauses {
+		if d.absorbed[i] == -1 && len(lits) == 2 && isNegative(lits) &&
+			varSet[lits[0].Variable()] && varSet[lits[1].Variable()] {
+			addEdge(adjacency, lits[0].Variable(), lits[1].Variable(), i)
+		}
+	}
+	slices.SortFunc(derived, func(e1, e2 [2]f.Variable) int {
+		if c := cmp.Compare(d.varIndex[e1[0]], d.varIndex[e2[0]]); c != 0 {
+			return c
+		}
+		return cmp.Compare(d.varIndex[e1[1]], d.varIndex[e2[1]])
+	})
+	covered := make(map[[2]f.Variable]bool)
+	var cliques [][]f.Variable
+	for _, edge := range derived {
+		if covered[edge] {
+			continue
+		}
+		clique := d.growClique(edge[0], edge[1], adjacency)
+		if len(clique) < 3 {
+			return
+		}
+		for i := range clique {
+			for j := range clique {
+				covered[[2]f.Variable{clique[i], clique[j]}] = true
+			}
+		}
+		cliques = append(cliques, clique)
+	}
+	for i, clique := range cliques {
+		d.sortVars(clique)
+		if i == 0 {
+			d.addConstraint(d.fac.AMO(clique...), clique, clauses)
+		} else {
+			d.addConstraint(d.fac.AMO(clique...), clique, nil)
+		}
+	}
+	d.result.AuxVars = append(d.result.AuxVars, group...)
+}
+
+func addEdge(adjacency map[f.Variable]map[f.Variable]int, v1, v2 f.Variable, clause int) {
+	for _, vs := range [][2]f.Variable{{v1, v2}, {v2, v1}} {
+		if adjacency[vs[0]] == nil {
+			adjacency[vs[0]] = make(map[f.Variable]int)
+		}
+		if _, ok := adjacency[vs[0]][vs[1]]; !ok {
+			adjacency[vs[0]][vs[1]] = clause
+		}
+	}
+}
+
+// growClique greedily extends the edge between the two given variables to a
+// maximal clique.  Variables with a higher degree are preferred.
+func (d *detector) growClique(v1, v2 f.Variable, adjacency map[f.Variable]map[f.Variable]int) []f.Variable {
+	clique := []f.Variable{v1, v2}
+	var candidates []f.Variable
+	for v := range adjacency[v1] {
+		if _, ok := adjacency[v2][v]; ok {
+			candidates = append(candidates, v)
+		}
+	}
+	slices.SortFunc(candidates, func(c1, c2 f.Variable) int {
+		if c := cmp.Compare(len(adjacency[c2]), len(adjacency[c1])); c != 0 {
+			return c
+		}
+		return cmp.Compare(d.varIndex[c1], d.varIndex[c2])
+	})
+	for _, candidate := range candidates {
+		adjacent := true
+		for _, v := range clique[2:] {
+			if _, ok := adjacency[candidate][v]; !ok {
+				adjacent = false
+				break
+			}
+		}
+		if adjacent {
+			clique = append(clique, candidate)
+		}
+	}
+	return clique
+}
+
+// nextElimination returns the index of the variable with the least number
+// of possible resolvents.
+func (d *detector) nextElimination(vars []f.Variable, local map[string][]f.Literal) int {
+	best, bestCount := 0, -1
+	for i, v := range vars {
+		pos, neg := 0, 0
+		for _, lits := range local {
+			for _, lit := range lits {
+				if lit.Variable() == v {
+					if lit.IsPos() {
+						pos++
+					} else {
+						neg++
+					}
+				}
+			}
+		}
+		if bestCount == -1 || pos*neg < bestCount {
+			best, bestCount = i, pos*neg
+		}
+	}
+	return best
+}
+
+// resolve eliminates the given variable from the local clauses by
+// resolution.  Returns false if a resolvent is not binary or the number of
+// clauses exceeds the limit.
+func (d *detector) resolve(v f.Variable, local map[string][]f.Literal, limit int) bool {
+	var pos, neg [][]f.Literal
+	for key, lits := range local {
+		for _, lit := range lits {
+			if lit.Variable() == v {
+				if lit.IsPos() {
+					pos = append(pos, lits)
+				} else {
+					neg = append(neg, lits)
+				}
+				delete(local, key)
+				break
+			}
+		}
+	}
+	for _, p := range pos {
+		for _, n := range neg {
+			resolvent, tautology := d.resolvent(v, p, n)
+			if tautology {
+				continue
+			}
+			if len(resolvent) != 2 {
+				return false
+			}
+			local[clauseKey(resolvent)] = resolvent
+			if len(local) > limit {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (d *detector) resolvent(v f.Variable, c1, c2 []f.Literal) ([]f.Literal, bool) {
+	resolvent := make([]f.Literal, 0, len(c1)+len(c2)-2)
+	for _, lit := range slices.Concat(c1, c2) {
+		if lit.Variable() == v || slices.Contains(resolvent, lit) {
+			continue
+		}
+		if slices.Contains(resolvent, lit.Negate(d.fac)) {
+			return nil, true
+		}
+		resolvent = append(resolvent, lit)
+	}
+	return resolvent, false
+}
+
+// detectPairwiseAMOs searches cliques of negative binary clauses.  Binary
+// clauses which are already covered by a detected at-most-one constraint are
+// absorbed by it.
+func (d *detector) detectPairwiseAMOs() {
+	adjacency := make(map[f.Variable]map[f.Variable]int)
+	var edges []int
+	for i, lits := range d.clauses {
+		if d.absorbed[i] != -1 || len(lits) != 2 || !isNegative(lits) {
+			continue
+		}
+		v1, v2 := lits[0].Variable(), lits[1].Variable()
+		if d.absorbByAMO(i, v1, v2) {
+			continue
+		}
+		addEdge(adjacency, v1, v2, i)
+		edges = append(edges, i)
+	}
+	for _, edge := range edges {
+		if d.absorbed[edge] != -1 {
+			continue
+		}
+		clique := d.growClique(d.clauses[edge][0].Variable(), d.clauses[edge][1].Variable(), adjacency)
+		if len(clique) < 3 {
+			continue
+		}
+		var absorbed []int
+		for i := range clique {
+			for j := i + 1; j < len(clique); j++ {
+				absorbed = append(absorbed, adjacency[clique[i]][clique[j]])
+			}
+		}
+		d.sortVars(clique)
+		d.addConstraint(d.fac.AMO(clique...), clique, absorbed)
+	}
+}
+
+func (d *detector) absorbByAMO(clause int, v1, v2 f.Variable) bool {
+	for i, vars := range d.amoVars {
+		if vars[v1] && vars[v2] {
+			d.absorbed[clause] = i
+			d.result.Absorbed[i] = append(d.result.Absorbed[i], d.formulas[clause])
+			return true
+		}
+	}
+	return false
+}
+
+// detectBinomialAMKs detects at-most-k constraints whose binomial encoding
+// consists of a negative clause for each subset of k+1 variables.
+func (d *detector) detectBinomialAMKs(k int) {
+	size := k + 1
+	neighbours := make(map[f.Variable][]f.Variable)
+	var seeds []int
+	for i, lits := range d.clauses {
+		if d.absorbed[i] != -1 || len(lits) != size || !isNegative(lits) {
+			continue
+		}
+		seeds = append(seeds, i)
+		for _, lit := range lits {
+			for _, other := range lits {
+				if other != lit && !slices.Contains(neighbours[lit.Variable()], other.Variable()) {
+					neighbours[lit.Variable()] = append(neighbours[lit.Variable()], other.Variable())
+				}
+			}
+		}
+	}
+	for _, seed := range seeds {
+		if d.absorbed[seed] != -1 {
+			continue
+		}
+		vars := make([]f.Variable, size)
+		for i, lit := range d.clauses[seed] {
+			vars[i] = lit.Variable()
+		}
+		for _, candidate := range neighbours[vars[0]] {
+			if slices.Contains(vars, candidate) {
+				continue
+			}
+			if _, ok := d.subsetClauses(vars, candidate, k); ok {
+				vars = append(vars, candidate)
+			}
+		}
+		if len(vars) == size {
+			continue
+		}
+		absorbed := []int{seed}
+		for i := size; i < len(vars); i++ {
+			clauses, _ := d.subsetClauses(vars[:i], vars[i], k)
+			absorbed = append(absorbed, clauses...)
+		}
+		d.sortVars(vars)
+		d.addConstraint(d.fac.CC(f.LE, uint32(k), vars...), vars, absorbed)
+	}
+}
+
+// subsetClauses returns the clauses which forbid the given candidate
+// together with each subset of k of the given variables.
+func (d *detector) subsetClauses(vars []f.Variable, candidate f.Variable, k int) ([]int, bool) {
+	var clauses []int
+	subset := make([]int, k)
+	for i := range subset {
+		subset[i] = i
+	}
+	lits := make([]f.Literal, k+1)
+	for {
+		for i, index := range subset {
+			lits[i] = vars[index].Negate(d.fac)
+		}
+		lits[k] = candidate.Negate(d.fac)
+		clause, ok := d.clauseIDs[clauseKey(lits)]
+		if !ok || d.absorbed[clause] != -1 {
+			return nil, false
+		}
+		clauses = append(clauses, clause)
+		i := k - 1
+		for i >= 0 && subset[i] == len(vars)-k+i {
+			i--
+		}
+		if i < 0 {
+			return clauses, true
+		}
+		subset[i]++
+		for j := i + 1; j < k; j++ {
+			subset[j] = subset[j-1] + 1
+		}
+	}
+}
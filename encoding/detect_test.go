@@ -0,0 +1,97 @@
+package encoding
+
+import (
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectPairwiseAMO(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	clauses := []f.Formula{
+		p.ParseUnsafe("~a | ~b"),
+		p.ParseUnsafe("a | b | c"),
+		p.ParseUnsafe("~b | ~c"),
+		p.ParseUnsafe("~a | ~c"),
+		p.ParseUnsafe("~c | ~d"),
+		p.ParseUnsafe("~a | d"),
+	}
+	detection, err := DetectCardinalityConstraints(fac, clauses)
+	assert.Nil(err)
+	assert.Equal([]f.Formula{fac.AMO(fac.Vars("a", "b", "c")...)}, detection.Constraints)
+	assert.Equal([][]f.Formula{{clauses[0], clauses[2], clauses[3]}}, detection.Absorbed)
+	assert.Equal([]f.Formula{clauses[1], clauses[4], clauses[5]}, detection.Remaining)
+	assert.Empty(detection.AuxVars)
+
+	_, err = DetectCardinalityConstraints(fac, []f.Formula{p.ParseUnsafe("a & b")})
+	assert.NotNil(err)
+}
+
+func TestDetectEncodedAMO(t *testing.T) {
+	assert := assert.New(t)
+	for _, config := range configs {
+		for _, n := range []int{3, 5, 12, 30} {
+			fac := f.NewFactory()
+			vars := make([]f.Variable, n)
+			for i := range vars {
+				vars[i] = fac.Var("x" + string(rune('a'+i/26)) + string(rune('a'+i%26)))
+			}
+			cnf, err := EncodeCC(fac, fac.AMO(vars...), &config)
+			assert.Nil(err)
+			aux := f.NewMutableVarSetCopy(f.Variables(fac, cnf...))
+			aux.RemoveAll(f.NewVarSet(vars...))
+
+			for _, detectConfig := range []*DetectionConfig{
+				{AuxVars: aux.AsImmutable()},
+				{GuessAuxVars: true},
+			} {
+				detection, err := DetectCardinalityConstraints(fac, cnf, detectConfig)
+				assert.Nil(err)
+				assert.Equal([]f.Formula{fac.AMO(vars...)}, detection.Constraints, "%s %d", config.AMOEncoder, n)
+				assert.Empty(detection.Remaining, "%s %d", config.AMOEncoder, n)
+				assert.ElementsMatch(aux.AsImmutable().Content(), detection.AuxVars)
+			}
+		}
+	}
+}
+
+func TestDetectNoAuxElimination(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	clauses := []f.Formula{
+		p.ParseUnsafe("~a | s"),
+		p.ParseUnsafe("~b | s"),
+		p.ParseUnsafe("~s | ~c"),
+	}
+	detection, err := DetectCardinalityConstraints(fac, clauses, &DetectionConfig{GuessAuxVars: true})
+	assert.Nil(err)
+	assert.Empty(detection.Constraints)
+	assert.Equal(clauses, detection.Remaining)
+}
+
+func TestDetectBinomialAMK(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	clauses := []f.Formula{
+		p.ParseUnsafe("~a | ~b | ~c"),
+		p.ParseUnsafe("~a | ~b | ~d"),
+		p.ParseUnsafe("~a | ~c | ~d"),
+		p.ParseUnsafe("~b | ~c | ~d"),
+		p.ParseUnsafe("~a | ~b | ~e"),
+		p.ParseUnsafe("e | f"),
+	}
+	detection, err := DetectCardinalityConstraints(fac, clauses)
+	assert.Nil(err)
+	assert.Equal([]f.Formula{fac.CC(f.LE, 2, fac.Vars("a", "b", "c", "d")...)}, detection.Constraints)
+	assert.ElementsMatch(clauses[:4], detection.Absorbed[0])
+	assert.Equal(clauses[4:], detection.Remaining)
+
+	detection, _ = DetectCardinalityConstraints(fac, clauses, &DetectionConfig{MaxAMK: 1})
+	assert.Empty(detection.Constraints)
+}
@@ -24,13 +24,47 @@
 // Incremental cardinality constraints are a special variant of encodings,
 // where the upper/lower bound of the constraint can be tightened by adding
 // additional clauses to the resulting CNF.  All AMK and ALK encodings
-// support incremental encodings.
+// support incremental encodings.  ALK constraints encoded with the totalizer
+// and EXK constraints (which are always encoded with the totalizer) can be
+// tightened in both directions.
 //
-// For pseudo-Boolean constraints there are three different encodings:
+// For pseudo-Boolean constraints there are the following encodings:
 //   - PBAdderNetworks: Adder networks encoding
 //   - PBSWC: A sequential weight counter for the encoding of pseudo-Boolean
 //     constraints in CNF
 //   - PBBinaryMerge: Binary merge due to Manthey, Philipp, and Steinke
+//   - PBCGTE: Generalized totalizer due to Joshi, Martins, and Manquinho
+//   - PBCBDD: Reduced BDD with interval-based node sharing due to Abío,
+//     Nieuwenhuis, Oliveras, and Rodríguez-Carbonell
+//   - PBCLadder: Ladder (order) encodings for groups of literals with equal
+//     coefficients combined by a reduced MDD, which is compact for
+//     constraints with few distinct coefficients
+//
+// The generalized totalizer is also used for incremental pseudo-Boolean
+// constraints (see EncodeIncrementalPBC).
+//
+// Constraints can also be encoded half-reified (selector => constraint) with
+// EncodeHalfReified or fully reified (selector <=> constraint) with
+// EncodeReified.  Then the constraint can be switched on and off by assuming
+// the selector literal in a SAT solver call.
+//
+// The number of clauses and auxiliary variables of each applicable encoding
+// of a constraint and whether it is arc-consistent can be computed with
+// EstimateCosts.  If a CostFunction is set in the configuration, the best
+// encoders choose the encoding with the minimal cost:
+//
+//	config := encoding.DefaultConfig()
+//	config.CostFunction = func(c encoding.Cost) float64 {
+//	    return float64(c.Clauses + 2*c.AuxVars)
+//	}
+//	encoding, err := encoding.EncodePBC(fac, pbc, config)
+//
+// The inverse direction is provided by DetectCardinalityConstraints which
+// detects hand-encoded AMO and AMK constraints in a set of clauses and lifts
+// them back to cardinality constraints.
+//
+// XOR (parity) constraints, which are not formulas in LogicNG, can be encoded
+// to clauses with EncodeXOR.
 //
 // To encode a constraint explicitly (and not implicitly within e.g. the CNF
 // methods) you can use the following code:
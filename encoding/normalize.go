@@ -15,7 +15,39 @@ func Normalize(fac f.Factory, constraint f.Formula) f.Formula {
 	if !found {
 		panic(errorx.UnknownFormula(constraint))
 	}
+	parts := normalizeOps(fac, comparator, rhs, literals, coefficients)
+	if len(parts) == 2 {
+		return fac.And(parts[0].formula(fac), parts[1].formula(fac))
+	}
+	return parts[0].formula(fac)
+}
+
+// normalizedLE is a normalized constraint sum(coeffs[i] * lits[i]) <= rhs.
+// If its sort is SortTrue or SortFalse, the constraint is trivial and has no
+// literals.
+type normalizedLE struct {
+	sort   f.FSort
+	lits   []f.Literal
+	coeffs []int
+	rhs    int
+}
+
+func (n normalizedLE) formula(fac f.Factory) f.Formula {
+	switch n.sort {
+	case f.SortFalse:
+		return fac.Falsum()
+	case f.SortTrue:
+		return fac.Verum()
+	default:
+		return fac.PBC(f.LE, n.rhs, n.lits, n.coeffs)
+	}
+}
 
+// normalizeOps returns the normalized <= constraints of the given constraint
+// operands.  For an EQ constraint these are two constraints, otherwise one.
+func normalizeOps(
+	fac f.Factory, comparator f.CSort, rhs int, literals []f.Literal, coefficients []int,
+) []normalizedLE {
 	normPs := make([]f.Literal, len(literals))
 	copy(normPs, literals)
 	normCs := make([]int, len(literals))
@@ -31,7 +63,7 @@ func Normalize(fac f.Factory, constraint f.Formula) f.Formula {
 		}
 		normRhs = -rhs
 		f2 := normalizeLE(fac, normPs, normCs, normRhs)
-		return fac.And(f1, f2)
+		return []normalizedLE{f1, f2}
 	case f.LT, f.LE:
 		copy(normCs, coefficients)
 		if csort == f.LE {
@@ -39,7 +71,7 @@ func Normalize(fac f.Factory, constraint f.Formula) f.Formula {
 		} else {
 			normRhs = rhs - 1
 		}
-		return normalizeLE(fac, normPs, normCs, normRhs)
+		return []normalizedLE{normalizeLE(fac, normPs, normCs, normRhs)}
 	case f.GT, f.GE:
 		for i := range literals {
 			normCs[i] = -coefficients[i]
@@ -49,7 +81,7 @@ func Normalize(fac f.Factory, constraint f.Formula) f.Formula {
 		} else {
 			normRhs = -rhs - 1
 		}
-		return normalizeLE(fac, normPs, normCs, normRhs)
+		return []normalizedLE{normalizeLE(fac, normPs, normCs, normRhs)}
 	default:
 		panic(errorx.UnknownEnumValue(csort))
 	}
@@ -83,7 +115,7 @@ func NegatePBC(fac f.Factory, formula f.Formula) f.Formula {
 	}
 }
 
-func normalizeLE(fac f.Factory, ps []f.Literal, cs []int, rhs int) f.Formula {
+func normalizeLE(fac f.Factory, ps []f.Literal, cs []int, rhs int) normalizedLE {
 	c := rhs
 	newSize := 0
 	for i := 0; i < len(ps); i++ {
@@ -140,10 +172,10 @@ func normalizeLE(fac f.Factory, ps []f.Literal, cs []int, rhs int) f.Formula {
 	for ok := true; ok; ok = changed {
 		changed = false
 		if c < 0 {
-			return fac.Falsum()
+			return normalizedLE{sort: f.SortFalse}
 		}
 		if sum <= c {
-			return fac.Verum()
+			return normalizedLE{sort: f.SortTrue}
 		}
 		div := c
 		for i := 0; i < len(cs); i++ {
@@ -159,7 +191,7 @@ func normalizeLE(fac f.Factory, ps []f.Literal, cs []int, rhs int) f.Formula {
 			changed = true
 		}
 	}
-	return fac.PBC(f.LE, c, ps, cs)
+	return normalizedLE{f.SortPBC, ps, cs, c}
 }
 
 func gcd(small, big int) int {
@@ -0,0 +1,345 @@
+package encoding
+
+import (
+	"math"
+	"slices"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// The functions in this file compute the number of clauses and auxiliary
+// variables of the pseudo-Boolean constraint encodings.  They follow the
+// structure of the encoders, but only depend on the coefficients and the
+// right-hand side of the normalized constraint and do not generate any
+// literals or clauses.
+
+func normalizedCost(cost *Cost, constraint normalizedLE, config *Config) error {
+	switch constraint.sort {
+	case f.SortFalse:
+		cost.Clauses++
+	case f.SortTrue:
+	default:
+		return encodePBCCost(cost, constraint.coeffs, constraint.rhs, config)
+	}
+	return nil
+}
+
+func encodePBCCost(cost *Cost, coeffs []int, rhs int, config *Config) error {
+	if rhs == math.MaxInt {
+		return errorx.BadInput("overflow in the encoding")
+	}
+	if rhs < 0 {
+		cost.Clauses++
+	}
+	if rhs == 0 {
+		cost.Clauses += len(coeffs)
+		return nil
+	}
+	simplifiedCoeffs := make([]int, 0, len(coeffs))
+	for _, coeff := range coeffs {
+		if coeff <= rhs {
+			simplifiedCoeffs = append(simplifiedCoeffs, coeff)
+		} else {
+			cost.Clauses++
+		}
+	}
+	if len(simplifiedCoeffs) <= 1 {
+		return nil
+	}
+	switch config.PBCEncoder {
+	case PBCSWC, PBCBest:
+		swcCost(cost, simplifiedCoeffs, rhs)
+	case PBCBinaryMerge:
+		binaryMergeEncodingCost(cost, simplifiedCoeffs, rhs, config)
+	case PBCAdderNetworks:
+		adderCost(cost, simplifiedCoeffs, rhs)
+	case PBCGTE:
+		gteCost(cost, simplifiedCoeffs, rhs)
+	case PBCBDD:
+		bddCost(cost, simplifiedCoeffs, rhs)
+	case PBCLadder:
+		ladderCost(cost, simplifiedCoeffs, rhs)
+	default:
+		panic(errorx.UnknownEnumValue(config.PBCEncoder))
+	}
+	return nil
+}
+
+func swcCost(cost *Cost, coeffs []int, rhs int) {
+	n := len(coeffs)
+	cost.AuxVars += n * rhs
+	cost.Clauses += (n-1)*rhs + n - 1
+	for i, coeff := range coeffs {
+		cost.Clauses += coeff
+		if i > 0 {
+			cost.Clauses += rhs - coeff
+		}
+	}
+}
+
+func binaryMergeEncodingCost(cost *Cost, cffs []int, rhs int, config *Config) {
+	coeffs := slices.Clone(cffs)
+	maxWeight := slices.Max(cffs)
+	if !config.BinaryMergeUseGAC {
+		binaryMergeCost(cost, cffs, rhs, maxWeight, len(coeffs), false, config)
+		return
+	}
+	encodeCompleteConstraint := false
+	for i := 0; i < len(coeffs); i++ {
+		f1 := math.Floor(math.Log(float64(coeffs[i]) / math.Log(2)))
+		f2 := math.Log(float64(coeffs[i])) / math.Log(2)
+		if config.BinaryMergeNoSupportForSingleBit && math.Abs(f1-f2) <= 1e-9 {
+			encodeCompleteConstraint = true
+			continue
+		}
+		tmpCoeff := coeffs[i]
+		coeffs[i] = coeffs[len(coeffs)-1]
+		coeffs = coeffs[:len(coeffs)-1]
+		weight := maxWeight
+		if maxWeight == tmpCoeff {
+			weight = slices.Max(coeffs)
+		}
+		if rhs-tmpCoeff <= 0 {
+			cost.Clauses += len(coeffs)
+		}
+		if maxWeight != tmpCoeff || rhs-tmpCoeff > 0 {
+			binaryMergeCost(cost, coeffs, rhs-tmpCoeff, weight, len(coeffs), true, config)
+		}
+		if i < len(coeffs) {
+			coeffs = append(coeffs, coeffs[i])
+			coeffs[i] = tmpCoeff
+		}
+	}
+	if config.BinaryMergeNoSupportForSingleBit && encodeCompleteConstraint {
+		binaryMergeCost(cost, cffs, rhs, maxWeight, len(coeffs), false, config)
+	}
+}
+
+func binaryMergeCost(cost *Cost, coefficients []int, leq, maxWeight, n int, gac bool, config *Config) {
+	lessThen := leq + 1
+	p := int(math.Floor(math.Log(float64(maxWeight)) / math.Log(2)))
+	m := int(math.Ceil(float64(lessThen) / math.Pow(2, float64(p))))
+	newLessThen := int(float64(m) * math.Pow(2, float64(p)))
+	t := int(float64(m)*math.Pow(2, float64(p)) - float64(lessThen))
+
+	cost.AuxVars++
+	cost.Clauses++
+	buckets := make([]int, p+1)
+	bit := 1
+	for i := 0; i <= p; i++ {
+		if (t & bit) != 0 {
+			buckets[i]++
+		}
+		for j := range n {
+			if (coefficients[j] & bit) != 0 {
+				if gac && coefficients[j] >= lessThen {
+					cost.Clauses++
+				} else {
+					buckets[i]++
+				}
+			}
+		}
+		bit = bit << 1
+	}
+	carries := 0
+	for i, bucket := range buckets {
+		k := int(math.Ceil(float64(newLessThen) / math.Pow(2, float64(i))))
+		var card int
+		if config.BinaryMergeUseWatchDog {
+			card = unaryTotalizerCost(cost, bucket)
+		} else {
+			card = sortCost(cost, k, bucket, inputToOutput)
+		}
+		if k <= bucket {
+			cost.Clauses++
+		}
+		merged := card
+		if i > 0 && carries > 0 {
+			if card == 0 {
+				merged = carries
+			} else {
+				if config.BinaryMergeUseWatchDog {
+					merged = unaryAdderCost(cost, card, carries)
+				} else {
+					merged = mergeCost(cost, k, card, carries, inputToOutput)
+				}
+				if k == merged || config.BinaryMergeUseWatchDog && k <= merged {
+					cost.Clauses++
+				}
+			}
+		}
+		carries = merged / 2
+	}
+}
+
+func unaryTotalizerCost(cost *Cost, n int) int {
+	if n <= 1 {
+		return n
+	}
+	cost.AuxVars += n
+	return unaryAdderCost(cost, unaryTotalizerCost(cost, n/2), unaryTotalizerCost(cost, n-n/2))
+}
+
+func unaryAdderCost(cost *Cost, u, v int) int {
+	if u == 0 || v == 0 {
+		return u + v
+	}
+	cost.AuxVars += u + v
+	cost.Clauses += u*v + u + v
+	return u + v
+}
+
+func adderCost(cost *Cost, coeffs []int, rhs int) {
+	nb := ldInt(rhs)
+	buckets := make([]int, nb)
+	for iBit := range nb {
+		for _, coeff := range coeffs {
+			if ((1 << iBit) & coeff) != 0 {
+				buckets[iBit]++
+			}
+		}
+	}
+	used := make([]bool, nb)
+	for i := 0; i < len(buckets); i++ {
+		if buckets[i] == 0 {
+			continue
+		}
+		if i == len(buckets)-1 && buckets[i] >= 2 {
+			buckets = append(buckets, 0)
+			used = append(used, false)
+		}
+		for buckets[i] >= 3 {
+			cost.AuxVars += 2
+			cost.Clauses += 20
+			buckets[i] -= 2
+			buckets[i+1]++
+		}
+		if buckets[i] == 2 {
+			cost.AuxVars += 2
+			cost.Clauses += 7
+			buckets[i]--
+			buckets[i+1]++
+		}
+		buckets[i]--
+		used[i] = true
+	}
+	kBits := numToBits(len(buckets), rhs)
+	for i := range used {
+		if kBits[i] || !used[i] {
+			continue
+		}
+		skip := false
+		for j := i + 1; j < len(used); j++ {
+			if kBits[j] && !used[j] {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			cost.Clauses++
+		}
+	}
+}
+
+func gteCost(cost *Cost, coeffs []int, rhs int) {
+	for _, value := range gteTreeCost(cost, coeffs, rhs+1) {
+		if value > rhs {
+			cost.Clauses++
+		}
+	}
+}
+
+// gteTreeCost counts the generalized totalizer for the given coefficients and
+// returns the values of its root node.
+func gteTreeCost(cost *Cost, coeffs []int, limit int) []int {
+	if len(coeffs) == 1 {
+		return []int{min(coeffs[0], limit)}
+	}
+	mid := len(coeffs) / 2
+	left := gteTreeCost(cost, coeffs[:mid], limit)
+	right := gteTreeCost(cost, coeffs[mid:], limit)
+	sums := make(map[int]present)
+	for _, a := range left {
+		sums[a] = present{}
+	}
+	for _, b := range right {
+		sums[b] = present{}
+		for _, a := range left {
+			sums[min(a+b, limit)] = present{}
+		}
+	}
+	values := make([]int, 0, len(sums))
+	for v := range sums {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	cost.AuxVars += len(values)
+	cost.Clauses += len(left) + len(right)*(len(left)+1)
+	return values
+}
+
+func bddCost(cost *Cost, coeffs []int, rhs int) {
+	indices := sortedByCoeffs(coeffs)
+	groups := make([]mddGroup, len(coeffs))
+	for i, index := range indices {
+		groups[i] = mddGroup{coeffs[index], make([]f.Literal, 1)}
+	}
+	mddCost(cost, groups, rhs)
+}
+
+func ladderCost(cost *Cost, coeffs []int, rhs int) {
+	indices := sortedByCoeffs(coeffs)
+	var groups []mddGroup
+	for i := 0; i < len(indices); {
+		coeff := coeffs[indices[i]]
+		groupSize := 0
+		for ; i < len(indices) && coeffs[indices[i]] == coeff; i++ {
+			groupSize++
+		}
+		size := min(groupSize, rhs/coeff+1)
+		current := 1
+		for k := 1; k < groupSize; k++ {
+			next := min(k+1, size)
+			cost.AuxVars += next
+			cost.Clauses += next + min(next, current)
+			current = next
+		}
+		groups = append(groups, mddGroup{coeff, make([]f.Literal, current)})
+	}
+	mddCost(cost, groups, rhs)
+}
+
+// mddCost counts the nodes of the reduced MDD for the given groups.  Only the
+// number of selectors of the groups is relevant, not the selectors itself.
+func mddCost(cost *Cost, groups []mddGroup, rhs int) {
+	b := newMDDBuilder(groups)
+	_, _, root := b.build(0, rhs)
+	switch root {
+	case b.trueNode:
+	case b.falseNode:
+		cost.Clauses++
+	default:
+		cost.Clauses++
+		b.nodeCost(cost, root, make(map[*mddNode]present))
+	}
+}
+
+func (b *mddBuilder) nodeCost(cost *Cost, node *mddNode, counted map[*mddNode]present) {
+	if _, ok := counted[node]; ok {
+		return
+	}
+	counted[node] = present{}
+	cost.AuxVars++
+	for c, child := range node.children {
+		if c > 0 && child == node.children[c-1] || child == b.trueNode {
+			continue
+		}
+		cost.Clauses++
+		if child != b.falseNode {
+			b.nodeCost(cost, child, counted)
+		}
+	}
+}
+
+type present struct{}
@@ -31,12 +31,12 @@ func EncodePBC(fac f.Factory, pbc f.Formula, config ...*Config) ([]f.Formula, er
 // Returns an error if the input constraint is no valid pseudo-Boolean constraint.
 func EncodePBCInResult(fac f.Factory, pbc f.Formula, result Result, config ...*Config) error {
 	if pbc.Sort() == f.SortCC {
-		return EncodeCCInResult(fac, pbc, result)
+		return EncodeCCInResult(fac, pbc, result, config...)
 	}
 	if pbc.Sort() != f.SortPBC {
 		return errorx.BadFormulaSort(pbc.Sort())
 	}
-	cfg := determineConfig(fac, config)
+	cfg := selectByCost(fac, pbc, determineConfig(fac, config))
 	normalized := Normalize(fac, pbc)
 	var err error
 	switch normalized.Sort() {
@@ -89,6 +89,12 @@ func encodePBC(result Result, lits []f.Literal, coeffs []int, rhs int, config *C
 		encodePBCBinaryMerge(result, simplifiedLits, simplifiedCoeffs, rhs, config)
 	case PBCAdderNetworks:
 		encodePBCAdder(result, simplifiedLits, simplifiedCoeffs, rhs)
+	case PBCGTE:
+		encodePBCGTE(result, simplifiedLits, simplifiedCoeffs, rhs)
+	case PBCBDD:
+		encodePBCBDD(result, simplifiedLits, simplifiedCoeffs, rhs)
+	case PBCLadder:
+		encodePBCLadder(result, simplifiedLits, simplifiedCoeffs, rhs)
 	default:
 		panic(errorx.UnknownEnumValue(config.PBCEncoder))
 	}
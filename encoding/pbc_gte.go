@@ -0,0 +1,75 @@
+package encoding
+
+import (
+	"slices"
+
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// encodePBCGTE encodes the constraint with a generalized totalizer due to
+// Joshi, Martins, and Manquinho.  All sums greater than the right-hand side
+// are merged into one output of the root which is then forbidden.
+func encodePBCGTE(result Result, lits []f.Literal, coeffs []int, rhs int) {
+	fac := result.Factory()
+	root := gteTree(result, lits, coeffs, rhs+1)
+	for i, value := range root.values {
+		if value > rhs {
+			result.AddClause(root.outputs[i].Negate(fac))
+		}
+	}
+}
+
+// gteNode is a node of a generalized totalizer.  The output literal at
+// position i is true if the weighted sum of the literals below the node is at
+// least values[i].  All values are positive and sorted in ascending order.
+type gteNode struct {
+	values  []int
+	outputs []f.Literal
+}
+
+// gteTree encodes a generalized totalizer for the given literals and
+// coefficients and returns its root node.  All sums greater than or equal to
+// the limit are merged into the value limit.
+func gteTree(result Result, lits []f.Literal, coeffs []int, limit int) *gteNode {
+	if len(lits) == 1 {
+		return &gteNode{[]int{min(coeffs[0], limit)}, []f.Literal{lits[0]}}
+	}
+	mid := len(lits) / 2
+	left := gteTree(result, lits[:mid], coeffs[:mid], limit)
+	right := gteTree(result, lits[mid:], coeffs[mid:], limit)
+	return gteMerge(result, left, right, limit)
+}
+
+func gteMerge(result Result, left, right *gteNode, limit int) *gteNode {
+	fac := result.Factory()
+	sums := make(map[int]f.Literal)
+	for _, a := range left.values {
+		sums[a] = 0
+	}
+	for _, b := range right.values {
+		sums[b] = 0
+		for _, a := range left.values {
+			sums[min(a+b, limit)] = 0
+		}
+	}
+	values := make([]int, 0, len(sums))
+	for v := range sums {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	outputs := make([]f.Literal, len(values))
+	for i, v := range values {
+		outputs[i] = result.NewAuxVar(f.AuxPBC).AsLiteral()
+		sums[v] = outputs[i]
+	}
+	for i, a := range left.values {
+		result.AddClause(left.outputs[i].Negate(fac), sums[a])
+	}
+	for j, b := range right.values {
+		result.AddClause(right.outputs[j].Negate(fac), sums[b])
+		for i, a := range left.values {
+			result.AddClause(left.outputs[i].Negate(fac), right.outputs[j].Negate(fac), sums[min(a+b, limit)])
+		}
+	}
+	return &gteNode{values, outputs}
+}
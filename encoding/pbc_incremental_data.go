@@ -0,0 +1,190 @@
+package encoding
+
+import (
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// PBCIncrementalData gathers data for an incremental pseudo-Boolean
+// constraint of the form c_1*l_1 + ... + c_n*l_n <= k.  When such a
+// constraint is encoded incrementally, one can modify the constraint after it
+// was created by tightening the original bound or test a tighter bound by
+// assumptions without changing the encoding.
+//
+// The constraint is encoded as a generalized totalizer whose root outputs
+// represent the possible values of the weighted sum.  Negative coefficients
+// and a common divisor of the coefficients are handled internally, therefore
+// all bounds are given with respect to the original constraint.
+type PBCIncrementalData struct {
+	Result     Result // encoding result of the incremental constraint
+	values     []int
+	outputs    []f.Literal
+	divisor    int
+	offset     int
+	currentRhs int
+	nbAllowed  int
+}
+
+// EncodeIncrementalPBC encodes an incremental pseudo-Boolean constraint into
+// an encoding result and returns the incremental data with this result.  This
+// result can be either a formula result and a formula is generated or it can
+// be a solver encoding and the cnf is added directly to the solver without
+// first generating formulas on the formula factory.  The returned incremental
+// data can then be used to tighten the upper bound of the constraint (either
+// as formula or also directly on the solver).  Also cardinality constraints
+// can be encoded with this function.
+//
+// Returns an error if the input constraint is no pseudo-Boolean or
+// cardinality constraint with a <= or < comparator, or if the constraint has
+// no literals or is a contradiction.
+func EncodeIncrementalPBC(fac f.Factory, constraint f.Formula, result Result) (*PBCIncrementalData, error) {
+	if constraint.Sort() != f.SortPBC && constraint.Sort() != f.SortCC {
+		return nil, errorx.BadFormulaSort(constraint.Sort())
+	}
+	comparator, rhs, lits, coeffs, found := fac.PBCOps(constraint)
+	if !found {
+		return nil, errorx.UnknownFormula(constraint)
+	}
+	switch comparator {
+	case f.LE:
+	case f.LT:
+		rhs--
+	default:
+		return nil, errorx.BadInput("incremental pseudo-Boolean constraints are only supported for <= and <")
+	}
+	return pbcIncremental(result, lits, coeffs, rhs)
+}
+
+func pbcIncremental(result Result, lits []f.Literal, coeffs []int, rhs int) (*PBCIncrementalData, error) {
+	fac := result.Factory()
+	normLits, normCoeffs, offset := normalizeIncremental(fac, lits, coeffs)
+	if len(normLits) == 0 {
+		return nil, errorx.BadInput("trivial pseudo-Boolean constraint without literals")
+	}
+	divisor := normCoeffs[0]
+	sum := 0
+	for _, c := range normCoeffs {
+		divisor = gcd(divisor, c)
+		sum += c
+	}
+	for i := range normCoeffs {
+		normCoeffs[i] /= divisor
+	}
+	sum /= divisor
+	data := &PBCIncrementalData{Result: result, divisor: divisor, offset: offset, currentRhs: rhs}
+	internalRhs := data.internalRhs(rhs)
+	if internalRhs < 0 {
+		result.AddClause()
+		return nil, errorx.BadInput("contradiction pseudo-Boolean constraint")
+	}
+	root := gteTree(result, normLits, normCoeffs, min(internalRhs, sum)+1)
+	data.values = root.values
+	data.outputs = root.outputs
+	data.nbAllowed = len(root.values)
+	data.addBound(internalRhs)
+	return data, nil
+}
+
+// normalizeIncremental transforms the given weighted sum into an equivalent
+// sum with positive coefficients over distinct variables plus a constant
+// offset.
+func normalizeIncremental(fac f.Factory, lits []f.Literal, coeffs []int) ([]f.Literal, []int, int) {
+	vars := make([]f.Variable, 0, len(lits))
+	var2consts := make(map[f.Variable][2]int)
+	for i, lit := range lits {
+		variable := lit.Variable()
+		consts, ok := var2consts[variable]
+		if !ok {
+			vars = append(vars, variable)
+		}
+		if lit.IsPos() {
+			consts[1] += coeffs[i]
+		} else {
+			consts[0] += coeffs[i]
+		}
+		var2consts[variable] = consts
+	}
+	offset := 0
+	normLits := make([]f.Literal, 0, len(vars))
+	normCoeffs := make([]int, 0, len(vars))
+	for _, variable := range vars {
+		consts := var2consts[variable]
+		if consts[1] > consts[0] {
+			offset += consts[0]
+			normLits = append(normLits, variable.AsLiteral())
+			normCoeffs = append(normCoeffs, consts[1]-consts[0])
+		} else {
+			offset += consts[1]
+			if consts[0] > consts[1] {
+				normLits = append(normLits, variable.Negate(fac))
+				normCoeffs = append(normCoeffs, consts[0]-consts[1])
+			}
+		}
+	}
+	return normLits, normCoeffs, offset
+}
+
+// CurrentRhs returns the current right-hand side of the constraint.
+func (pb *PBCIncrementalData) CurrentRhs() int {
+	return pb.currentRhs
+}
+
+// NewUpperBound tightens the upper bound of the constraint and returns the
+// resulting formula.  Returns an error if the new right-hand side is not
+// smaller than the current right-hand side.
+func (pb *PBCIncrementalData) NewUpperBound(rhs int) ([]f.Formula, error) {
+	if err := pb.NewUpperBoundForSolver(rhs); err != nil {
+		return nil, err
+	}
+	return pb.Result.Formulas(), nil
+}
+
+// NewUpperBoundForSolver tightens the upper bound of the constraint and
+// encodes it on the solver of the result.  Returns an error if the new
+// right-hand side is not smaller than the current right-hand side.
+func (pb *PBCIncrementalData) NewUpperBoundForSolver(rhs int) error {
+	if rhs >= pb.currentRhs {
+		return errorx.BadInput("new upper bound %d does not tighten the current bound of %d", rhs, pb.currentRhs)
+	}
+	pb.currentRhs = rhs
+	internalRhs := pb.internalRhs(rhs)
+	if internalRhs < 0 {
+		pb.Result.AddClause()
+		return nil
+	}
+	pb.addBound(internalRhs)
+	return nil
+}
+
+// UpperBoundAssumptions returns a list of literals which enforce the given
+// upper bound on the constraint when they are used as assumptions of a SAT
+// solver call.  The encoding itself is not changed.  Returns an error if the
+// bound is below the minimum value of the weighted sum.
+func (pb *PBCIncrementalData) UpperBoundAssumptions(rhs int) ([]f.Literal, error) {
+	internalRhs := pb.internalRhs(rhs)
+	if internalRhs < 0 {
+		return nil, errorx.BadInput("upper bound %d is below the minimum value %d", rhs, pb.offset)
+	}
+	fac := pb.Result.Factory()
+	assumptions := make([]f.Literal, 0)
+	for i := pb.nbAllowed - 1; i >= 0 && pb.values[i] > internalRhs; i-- {
+		assumptions = append(assumptions, pb.outputs[i].Negate(fac))
+	}
+	return assumptions, nil
+}
+
+func (pb *PBCIncrementalData) internalRhs(rhs int) int {
+	shifted := rhs - pb.offset
+	if shifted < 0 {
+		return -1
+	}
+	return shifted / pb.divisor
+}
+
+func (pb *PBCIncrementalData) addBound(internalRhs int) {
+	fac := pb.Result.Factory()
+	for pb.nbAllowed > 0 && pb.values[pb.nbAllowed-1] > internalRhs {
+		pb.nbAllowed--
+		pb.Result.AddClause(pb.outputs[pb.nbAllowed].Negate(fac))
+	}
+}
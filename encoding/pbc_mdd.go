@@ -0,0 +1,202 @@
+package encoding
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// mddNode is an inner node of a multi-valued decision diagram for a
+// pseudo-Boolean constraint.  The child at position c is the remaining
+// constraint if exactly c literals of the node's group are true.
+type mddNode struct {
+	level    int
+	children []*mddNode
+	lit      f.Literal
+	encoded  bool
+}
+
+// mddGroup is a group of literals with the same coefficient.  The selector at
+// position c-1 is true if at least c literals of the group are true.
+type mddGroup struct {
+	coeff     int
+	selectors []f.Literal
+}
+
+type mddInterval struct {
+	lower, upper int
+	node         *mddNode
+}
+
+// mddBuilder constructs a reduced decision diagram for the constraint
+// sum(groups) <= rhs.  Nodes are shared with the interval technique of Abío,
+// Nieuwenhuis, Oliveras, and Rodríguez-Carbonell: each node is stored with
+// the interval of right-hand sides for which it represents the remaining
+// constraint.
+type mddBuilder struct {
+	groups    []mddGroup
+	rest      []int
+	memo      [][]mddInterval
+	trueNode  *mddNode
+	falseNode *mddNode
+}
+
+// encodePBCBDD encodes the constraint with a reduced BDD with the literals
+// sorted by descending coefficients.
+func encodePBCBDD(result Result, lits []f.Literal, coeffs []int, rhs int) {
+	indices := sortedByCoeffs(coeffs)
+	groups := make([]mddGroup, len(lits))
+	for i, index := range indices {
+		groups[i] = mddGroup{coeffs[index], []f.Literal{lits[index]}}
+	}
+	encodeMDD(result, groups, rhs)
+}
+
+// encodePBCLadder encodes the constraint with a ladder (order encoding) for
+// each group of literals with the same coefficient.  The outputs of the
+// ladders are then combined by a reduced MDD.  This is especially compact for
+// constraints with only few distinct coefficients.
+func encodePBCLadder(result Result, lits []f.Literal, coeffs []int, rhs int) {
+	indices := sortedByCoeffs(coeffs)
+	var groups []mddGroup
+	for i := 0; i < len(indices); {
+		coeff := coeffs[indices[i]]
+		var groupLits []f.Literal
+		for ; i < len(indices) && coeffs[indices[i]] == coeff; i++ {
+			groupLits = append(groupLits, lits[indices[i]])
+		}
+		size := min(len(groupLits), rhs/coeff+1)
+		groups = append(groups, mddGroup{coeff, ladder(result, groupLits, size)})
+	}
+	encodeMDD(result, groups, rhs)
+}
+
+// ladder returns literals l_1, ..., l_size such that l_c is forced to true if
+// at least c of the given literals are true.
+func ladder(result Result, lits []f.Literal, size int) []f.Literal {
+	fac := result.Factory()
+	current := []f.Literal{lits[0]}
+	for k := 1; k < len(lits); k++ {
+		next := make([]f.Literal, min(k+1, size))
+		for j := range next {
+			next[j] = result.NewAuxVar(f.AuxPBC).AsLiteral()
+			if j < len(current) {
+				result.AddClause(current[j].Negate(fac), next[j])
+			}
+			if j == 0 {
+				result.AddClause(lits[k].Negate(fac), next[j])
+			} else {
+				result.AddClause(lits[k].Negate(fac), current[j-1].Negate(fac), next[j])
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func sortedByCoeffs(coeffs []int) []int {
+	indices := make([]int, len(coeffs))
+	for i := range indices {
+		indices[i] = i
+	}
+	slices.SortStableFunc(indices, func(i, j int) int { return cmp.Compare(coeffs[j], coeffs[i]) })
+	return indices
+}
+
+func encodeMDD(result Result, groups []mddGroup, rhs int) {
+	b := newMDDBuilder(groups)
+	_, _, root := b.build(0, rhs)
+	switch root {
+	case b.trueNode:
+	case b.falseNode:
+		result.AddClause()
+	default:
+		result.AddClause(b.encode(result, root))
+	}
+}
+
+func newMDDBuilder(groups []mddGroup) *mddBuilder {
+	b := &mddBuilder{
+		groups:    groups,
+		rest:      make([]int, len(groups)+1),
+		memo:      make([][]mddInterval, len(groups)),
+		trueNode:  &mddNode{},
+		falseNode: &mddNode{},
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		b.rest[i] = b.rest[i+1] + len(groups[i].selectors)*groups[i].coeff
+	}
+	return b
+}
+
+// build returns the node for the constraint on the groups starting at the
+// given level with right-hand side k and the interval of right-hand sides
+// for which the node represents the same constraint.
+func (b *mddBuilder) build(level, k int) (int, int, *mddNode) {
+	if k < 0 {
+		return math.MinInt, -1, b.falseNode
+	}
+	if k >= b.rest[level] {
+		return b.rest[level], math.MaxInt, b.trueNode
+	}
+	memo := b.memo[level]
+	pos, _ := slices.BinarySearchFunc(memo, k, func(interval mddInterval, k int) int {
+		return cmp.Compare(interval.upper, k)
+	})
+	if pos < len(memo) && memo[pos].lower <= k {
+		return memo[pos].lower, memo[pos].upper, memo[pos].node
+	}
+	group := b.groups[level]
+	lower, upper := math.MinInt, math.MaxInt
+	children := make([]*mddNode, len(group.selectors)+1)
+	reducible := true
+	for c := range children {
+		weight := c * group.coeff
+		l, u, child := b.build(level+1, k-weight)
+		lower = max(lower, saturatedAdd(l, weight))
+		upper = min(upper, saturatedAdd(u, weight))
+		children[c] = child
+		reducible = reducible && child == children[0]
+	}
+	node := children[0]
+	if !reducible {
+		node = &mddNode{level: level, children: children}
+	}
+	b.memo[level] = slices.Insert(b.memo[level], pos, mddInterval{lower, upper, node})
+	return lower, upper, node
+}
+
+// encode returns the literal of the given node.  The literal implies that the
+// constraint represented by the node is satisfied.
+func (b *mddBuilder) encode(result Result, node *mddNode) f.Literal {
+	if node.encoded {
+		return node.lit
+	}
+	fac := result.Factory()
+	node.lit = result.NewAuxVar(f.AuxPBC).AsLiteral()
+	node.encoded = true
+	selectors := b.groups[node.level].selectors
+	for c, child := range node.children {
+		if c > 0 && child == node.children[c-1] || child == b.trueNode {
+			continue
+		}
+		clause := []f.Literal{node.lit.Negate(fac)}
+		if c > 0 {
+			clause = append(clause, selectors[c-1].Negate(fac))
+		}
+		if child != b.falseNode {
+			clause = append(clause, b.encode(result, child))
+		}
+		result.AddClause(clause...)
+	}
+	return node.lit
+}
+
+func saturatedAdd(x, y int) int {
+	if x == math.MinInt || x == math.MaxInt {
+		return x
+	}
+	return x + y
+}
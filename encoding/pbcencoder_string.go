@@ -12,11 +12,14 @@ func _() {
 	_ = x[PBCBinaryMerge-1]
 	_ = x[PBCAdderNetworks-2]
 	_ = x[PBCBest-3]
+	_ = x[PBCGTE-4]
+	_ = x[PBCBDD-5]
+	_ = x[PBCLadder-6]
 }
 
-const _PBCEncoder_name = "PBCSWCPBCBinaryMergePBCAdderNetworksPBCBest"
+const _PBCEncoder_name = "PBCSWCPBCBinaryMergePBCAdderNetworksPBCBestPBCGTEPBCBDDPBCLadder"
 
-var _PBCEncoder_index = [...]uint8{0, 6, 20, 36, 43}
+var _PBCEncoder_index = [...]uint8{0, 6, 20, 36, 43, 49, 55, 64}
 
 func (i PBCEncoder) String() string {
 	if i >= PBCEncoder(len(_PBCEncoder_index)-1) {
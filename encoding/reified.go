@@ -0,0 +1,144 @@
+package encoding
+
+import (
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// EncodeHalfReified encodes the half-reified cardinality or pseudo-Boolean
+// constraint selector => constraint to a CNF formula as a list of clauses.
+// Every clause of the encoding contains the negated selector, therefore the
+// constraint can be activated by assuming the selector to be true and is
+// ignored otherwise.
+//
+// It uses the encoding algorithm as specified either in the optional given
+// config or if it not present the one configured in the formula factory.
+//
+// Returns an error if the input constraint is no valid cardinality or
+// pseudo-Boolean constraint.
+func EncodeHalfReified(
+	fac f.Factory,
+	constraint f.Formula,
+	selector f.Literal,
+	config ...*Config,
+) ([]f.Formula, error) {
+	result := ResultForFormula(fac)
+	err := EncodeHalfReifiedInResult(fac, constraint, selector, result, config...)
+	return result.result, err
+}
+
+// EncodeHalfReifiedInResult encodes the half-reified cardinality or
+// pseudo-Boolean constraint selector => constraint into an encoding result.
+// This result can be either a formula result and a formula is generated or
+// it can be a solver encoding and the cnf is added directly to the solver
+// without first generating formulas on the formula factory.
+//
+// It uses the encoding algorithm as specified either in the optional given
+// config or if it not present the one configured in the formula factory.
+//
+// Returns an error if the input constraint is no valid cardinality or
+// pseudo-Boolean constraint.
+func EncodeHalfReifiedInResult(
+	fac f.Factory,
+	constraint f.Formula,
+	selector f.Literal,
+	result Result,
+	config ...*Config,
+) error {
+	if constraint.Sort() != f.SortCC && constraint.Sort() != f.SortPBC {
+		return errorx.BadFormulaSort(constraint.Sort())
+	}
+	cfg := determineConfig(fac, config)
+	return encodeGuarded(fac, constraint, result, selector.Negate(fac), cfg)
+}
+
+// EncodeReified encodes the fully reified cardinality or pseudo-Boolean
+// constraint selector <=> constraint to a CNF formula as a list of clauses.
+// The clauses of the constraint contain the negated selector and the clauses
+// of its negation contain the selector, therefore the selector is true if and
+// only if the constraint is satisfied.
+//
+// It uses the encoding algorithm as specified either in the optional given
+// config or if it not present the one configured in the formula factory.
+//
+// Returns an error if the input constraint is no valid cardinality or
+// pseudo-Boolean constraint.
+func EncodeReified(
+	fac f.Factory,
+	constraint f.Formula,
+	selector f.Literal,
+	config ...*Config,
+) ([]f.Formula, error) {
+	result := ResultForFormula(fac)
+	err := EncodeReifiedInResult(fac, constraint, selector, result, config...)
+	return result.result, err
+}
+
+// EncodeReifiedInResult encodes the fully reified cardinality or
+// pseudo-Boolean constraint selector <=> constraint into an encoding result.
+// This result can be either a formula result and a formula is generated or
+// it can be a solver encoding and the cnf is added directly to the solver
+// without first generating formulas on the formula factory.
+//
+// It uses the encoding algorithm as specified either in the optional given
+// config or if it not present the one configured in the formula factory.
+//
+// Returns an error if the input constraint is no valid cardinality or
+// pseudo-Boolean constraint.
+func EncodeReifiedInResult(
+	fac f.Factory,
+	constraint f.Formula,
+	selector f.Literal,
+	result Result,
+	config ...*Config,
+) error {
+	if err := EncodeHalfReifiedInResult(fac, constraint, selector, result, config...); err != nil {
+		return err
+	}
+	cfg := determineConfig(fac, config)
+	return encodeGuarded(fac, NegatePBC(fac, constraint), result, selector, cfg)
+}
+
+// encodeGuarded encodes the given formula such that every clause contains
+// the guard literal.  The formula is either a constant, a cardinality or
+// pseudo-Boolean constraint, or a disjunction of them as it is generated by
+// the negation of an equality constraint.
+func encodeGuarded(fac f.Factory, formula f.Formula, result Result, guard f.Literal, config *Config) error {
+	switch formula.Sort() {
+	case f.SortTrue:
+		return nil
+	case f.SortFalse:
+		result.AddClause(guard)
+		return nil
+	case f.SortCC, f.SortPBC:
+		return EncodePBCInResult(fac, formula, &guardedResult{result, guard}, config)
+	case f.SortOr:
+		ops := fac.Operands(formula)
+		clause := make([]f.Literal, 0, len(ops)+1)
+		clause = append(clause, guard)
+		for _, op := range ops {
+			opSelector := result.NewAuxVar(f.AuxPBC)
+			clause = append(clause, opSelector.AsLiteral())
+			if err := encodeGuarded(fac, op, result, opSelector.Negate(fac), config); err != nil {
+				return err
+			}
+		}
+		result.AddClause(clause...)
+		return nil
+	default:
+		return errorx.BadFormulaSort(formula.Sort())
+	}
+}
+
+// guardedResult is an encoding result which adds a guard literal to every
+// clause before passing it to the underlying result.
+type guardedResult struct {
+	Result
+	guard f.Literal
+}
+
+func (r *guardedResult) AddClause(literals ...f.Literal) {
+	clause := make([]f.Literal, 0, len(literals)+1)
+	clause = append(clause, r.guard)
+	r.Result.AddClause(append(clause, literals...)...)
+}
@@ -0,0 +1,53 @@
+package encoding
+
+import (
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// EncodeXOR encodes the XOR constraint over the given variables to a CNF
+// formula as a list of clauses.  If parity is true, an odd number of the
+// variables must be true, otherwise an even number.  An XOR constraint over
+// no variables is false for parity true and true otherwise.
+//
+// Long XOR constraints are cut into chunks of three variables which are
+// connected by auxiliary variables, therefore the size of the encoding is
+// linear in the number of variables.
+func EncodeXOR(fac f.Factory, variables []f.Variable, parity bool) []f.Formula {
+	if len(variables) == 0 {
+		if parity {
+			return []f.Formula{fac.Falsum()}
+		}
+		return []f.Formula{}
+	}
+	var clauses []f.Formula
+	for len(variables) > 3 {
+		aux := fac.NewAuxVar(f.AuxCNF)
+		chunk := []f.Variable{variables[0], variables[1], variables[2], aux}
+		clauses = append(clauses, encodeXORChunk(fac, chunk, false)...)
+		variables = append([]f.Variable{aux}, variables[3:]...)
+	}
+	return append(clauses, encodeXORChunk(fac, variables, parity)...)
+}
+
+// encodeXORChunk encodes a short XOR constraint by forbidding each
+// assignment with the wrong parity.
+func encodeXORChunk(fac f.Factory, variables []f.Variable, parity bool) []f.Formula {
+	var clauses []f.Formula
+	for assignment := 0; assignment < 1<<len(variables); assignment++ {
+		odd := false
+		literals := make([]f.Literal, len(variables))
+		for i, variable := range variables {
+			value := assignment&(1<<i) != 0
+			odd = odd != value
+			if value {
+				literals[i] = variable.Negate(fac)
+			} else {
+				literals[i] = variable.AsLiteral()
+			}
+		}
+		if odd != parity {
+			clauses = append(clauses, fac.Clause(literals...))
+		}
+	}
+	return clauses
+}
@@ -16,6 +16,7 @@ var (
 	FactorizationStarted          = event{"Factorization Started"}
 	BddComputationStarted         = event{"BDD Computation Started"}
 	DnnfComputationStarted        = event{"DNNF Computation Started"}
+	SddComputationStarted         = event{"SDD Computation Started"}
 	SatCallStarted                = event{"SAT Call Started"}
 	MaxSATCallStarted             = event{"Max-SAT Call Started"}
 	BackboneComputationStarted    = event{"Backbone Computation Started"}
@@ -27,20 +28,27 @@ var (
 	SmusComputationStarted        = event{"SMUS Computation Started"}
 	OptimizationFunctionStarted   = event{"Optimization Function Started"}
 	ModelEnumerationStarted       = event{"Model Enumeration Started"}
+	ModelCountingStarted          = event{"Model Counting Started"}
+	ModelSamplingStarted          = event{"Model Sampling Started"}
+	BddReorderingStarted          = event{"BDD Reordering Started"}
 
 	SatCallFinished    = event{"SAT Call Finished"}
 	MaxSatCallFinished = event{"Max-SAT Call Finished"}
 
 	ModelEnumerationCommit              = event{"Model Enumeration Commit"}
 	ModelEnumerationRollback            = event{"Model Enumeration Rollback"}
+	ModelEnumerationStreamStopped       = event{"Model Enumeration Stream Stopped"}
 	FactorizationCreatedClause          = event{"Factorization Created Clause"}
 	DistributionPerformed               = event{"Distribution Performed"}
 	BddNewRefAdded                      = event{"BDD New Ref Added"}
+	BddNodeLimitReached                 = event{"BDD Node Limit Reached"}
 	DnnfShannonExpansion                = event{"DNNF Shannon Expansion"}
+	SddApplyPerformed                   = event{"SDD Apply Performed"}
 	DnnfDtreeMinFillGraphInitialized    = event{"DNNF DTree MinFill Graph initialized"}
 	DnnfDtreeMinFillNewIteration        = event{"DNNF DTree MinFill new iteration"}
 	DnnfDtreeProcessingNextOrderVar     = event{"DNNF DTree processing next order variable"}
 	SatConflictDetected                 = event{"SAT Conflict Detected"}
+	ModelCountingDecision               = event{"Model Counting Decision"}
 	SubsumptionStartingUbTreeGeneration = event{"Subsumption Starting UB Tree Generation"}
 	SubsumptionAddedNewSet              = event{"Subsumption Added New Set"}
 
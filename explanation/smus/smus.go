@@ -112,7 +112,10 @@ func ComputeWithHandler(
 }
 
 func minimumHs(hSolver *sat.Solver, variables []f.Variable, hdl handler.Handler) ([]f.Variable, handler.State) {
-	minimumHsModel, state := hSolver.MinimizeWithHandler(f.VariablesAsLiterals(variables), hdl)
+	minimumHsModel, state, err := hSolver.MinimizeWithHandler(f.VariablesAsLiterals(variables), hdl)
+	if err != nil {
+		panic(err)
+	}
 	if !state.Success {
 		return nil, state
 	}
@@ -122,13 +125,16 @@ func minimumHs(hSolver *sat.Solver, variables []f.Variable, hdl handler.Handler)
 func grow(growSolver *sat.Solver, h, variables []f.Variable, hdl handler.Handler) ([]f.Variable, handler.State) {
 	solverState := growSolver.SaveState()
 	growSolver.Add(f.VariablesAsFormulas(h)...)
-	maxModel, state := growSolver.MaximizeWithHandler(f.VariablesAsLiterals(variables), hdl)
+	maxModel, state, err := growSolver.MaximizeWithHandler(f.VariablesAsLiterals(variables), hdl)
+	if err != nil {
+		panic(err)
+	}
 	if !state.Success {
 		return nil, state
 	} else if maxModel == nil {
 		return nil, handler.Success()
 	}
-	err := growSolver.LoadState(solverState)
+	err = growSolver.LoadState(solverState)
 	if err != nil {
 		panic(err)
 	}
@@ -2,7 +2,10 @@ package formula
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/booleworks/logicng-go/configuration"
 	"github.com/booleworks/logicng-go/errorx"
@@ -105,6 +108,7 @@ type CachingFactory struct {
 	functionCache       map[FunctionCacheSort]map[Formula]any
 
 	auxVarCounters map[AuxVarSort]int
+	copyID         uint32
 
 	configurations map[configuration.Sort]configuration.Config
 	symbols        *PrintSymbols
@@ -952,10 +956,18 @@ func (fac *CachingFactory) getPBCUnsafe(formula Formula) pbc {
 }
 
 // NewAuxVar generates and returns a new auxiliary variable of the given sort.
+// On a copy of a factory, the name of the variable contains the id of the
+// copy, such that it differs from the auxiliary variables of the original
+// factory and of other copies.
 func (fac *CachingFactory) NewAuxVar(sort AuxVarSort) Variable {
-	variable := fac.Var(fmt.Sprintf("%s%d", sort, fac.auxVarCounters[sort]))
+	var name string
+	if fac.copyID == 0 {
+		name = fmt.Sprintf("%s%d", sort, fac.auxVarCounters[sort])
+	} else {
+		name = fmt.Sprintf("%sC%d_%d", sort, fac.copyID, fac.auxVarCounters[sort])
+	}
 	fac.auxVarCounters[sort]++
-	return variable
+	return fac.Var(name)
 }
 
 func (fac *CachingFactory) transformationCacheEntry(entry TransformationCacheSort) *map[Formula]Formula {
@@ -1061,3 +1073,66 @@ type fpair struct {
 }
 
 type present struct{}
+
+// Copy returns a deep copy of the factory.  All formulas of this factory are
+// also valid formulas with the same content on the copy and vice versa, but
+// formulas which are created after the copy are only valid on the factory
+// they were created on.  Since factories are not thread-safe, a copy can be
+// used to process formulas of a factory in another goroutine, e.g. in a
+// parallel model enumeration.  The copy gets its own id, which is part of
+// the names of the auxiliary variables created on it.  The function cache is
+// not copied, since its values, e.g. variable sets, are shared data which is
+// extended lazily.
+func (fac *CachingFactory) Copy() *CachingFactory {
+	return &CachingFactory{
+		cFalse:              fac.cFalse,
+		cTrue:               fac.cTrue,
+		id:                  fac.id,
+		literals:            maps.Clone(fac.literals),
+		nots:                maps.Clone(fac.nots),
+		implications:        maps.Clone(fac.implications),
+		equivalences:        maps.Clone(fac.equivalences),
+		ands:                maps.Clone(fac.ands),
+		ors:                 maps.Clone(fac.ors),
+		ccs:                 maps.Clone(fac.ccs),
+		pbcs:                maps.Clone(fac.pbcs),
+		posLitCache:         maps.Clone(fac.posLitCache),
+		negLitCache:         maps.Clone(fac.negLitCache),
+		notCache:            maps.Clone(fac.notCache),
+		implCache:           maps.Clone(fac.implCache),
+		equivCache:          maps.Clone(fac.equivCache),
+		andCache:            cloneBuckets(fac.andCache),
+		orCache:             cloneBuckets(fac.orCache),
+		ccCache:             cloneBuckets(fac.ccCache),
+		pbcCache:            cloneBuckets(fac.pbcCache),
+		transformationCache: cloneCaches(fac.transformationCache),
+		predicateCache:      cloneCaches(fac.predicateCache),
+		functionCache:       make(map[FunctionCacheSort]map[Formula]any),
+		auxVarCounters:      maps.Clone(fac.auxVarCounters),
+		copyID:              copyIDs.Add(1),
+		configurations:      maps.Clone(fac.configurations),
+		symbols:             fac.symbols,
+		conserveVars:        fac.conserveVars,
+	}
+}
+
+// copyIDs generates the ids of factory copies.
+var copyIDs atomic.Uint32
+
+// cloneBuckets clones the hash buckets of a cache, since new formulas are
+// appended to the buckets.
+func cloneBuckets(cache map[uint64][]Formula) map[uint64][]Formula {
+	result := make(map[uint64][]Formula, len(cache))
+	for hash, bucket := range cache {
+		result[hash] = slices.Clone(bucket)
+	}
+	return result
+}
+
+func cloneCaches[S comparable, V any](caches map[S]map[Formula]V) map[S]map[Formula]V {
+	result := make(map[S]map[Formula]V, len(caches))
+	for sort, cache := range caches {
+		result[sort] = maps.Clone(cache)
+	}
+	return result
+}
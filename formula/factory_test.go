@@ -1,6 +1,7 @@
 package formula
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,34 @@ func TestOperandsHashFunction(t *testing.T) {
 	assert.NotEqual(h6, h8)
 	assert.NotEqual(h7, h8)
 }
+
+func TestFactoryCopy(t *testing.T) {
+	assert := assert.New(t)
+	fac := NewFactory()
+	formula := fac.And(fac.Variable("a"), fac.Or(fac.Literal("b", false), fac.Variable("c")))
+	Variables(fac, formula).Content()
+	fac.NewAuxVar(AuxCNF)
+	cp := fac.(*CachingFactory).Copy()
+	assert.Empty(cp.functionCache)
+	assert.Equal(Variables(fac, formula).Content(), Variables(cp, formula).Content())
+	assert.Equal(formula, cp.And(cp.Variable("a"), cp.Or(cp.Literal("b", false), cp.Variable("c"))))
+	assert.Equal(fac.Operands(formula), cp.Operands(formula))
+	assert.Equal(fac.Statistics(), cp.Statistics())
+
+	fac.And(fac.Variable("a"), fac.Variable("d"))
+	cp.And(cp.Variable("e"), cp.Variable("a"))
+	_, ok := fac.(*CachingFactory).posLitCache["e"]
+	assert.False(ok)
+	_, ok = cp.posLitCache["d"]
+	assert.False(ok)
+	assert.Equal(len(fac.(*CachingFactory).andCache), len(cp.andCache))
+
+	assert.Equal(fac.Var("@RESERVED_CNF_1"), fac.NewAuxVar(AuxCNF))
+	auxVar := cp.NewAuxVar(AuxCNF)
+	name, _ := cp.VarName(auxVar)
+	assert.Equal(fmt.Sprintf("@RESERVED_CNF_C%d_1", cp.copyID), name)
+	cp2 := fac.(*CachingFactory).Copy()
+	assert.NotEqual(cp.copyID, cp2.copyID)
+	name2, _ := cp2.VarName(cp2.NewAuxVar(AuxCNF))
+	assert.NotEqual(name, name2)
+}
@@ -8,4 +8,10 @@
 // To read the file again, simply use
 //
 //	read, err := io.ReadFormula(fac, "filename")
+//
+// Pseudo-Boolean problems in the OPB or WBO format of the pseudo-Boolean
+// competitions can be read and written with
+//
+//	problem, err := io.ReadOPB(fac, "filename.opb")
+//	var2id, err := io.WriteOPB(fac, "filename", problem)
 package io
@@ -0,0 +1,207 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// ReadOPB reads a pseudo-Boolean problem from the given filename in the OPB
+// format or its WBO extension for weighted soft constraints.  The variable
+// names of the file are used as variable names for the formulas.  Only linear
+// constraints and objective functions are supported.  Besides the standard
+// "min:" objective, a "max:" objective is tolerated as an extension and read
+// as a maximization problem.  A "min:" objective preceded by the comment line
+// "* maximize", as written by WriteOPB, is read as the maximization of its
+// negation.  Returns the problem and an optional error if
+// there was a problem reading the file.
+func ReadOPB(fac f.Factory, filename string) (*PBProblem, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadOPBFromReader(fac, file)
+}
+
+// ReadOPBFromReader reads a pseudo-Boolean problem from the given reader in
+// the OPB format or its WBO extension for weighted soft constraints.  The
+// variable names of the input are used as variable names for the formulas.
+// Only linear constraints and objective functions are supported and a "max:"
+// objective is tolerated as an extension.  Returns the problem and an optional
+// error if there was a problem reading the input.
+func ReadOPBFromReader(fac f.Factory, reader io.Reader) (*PBProblem, error) {
+	problem := &PBProblem{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	var statement []string
+	negatedMax := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == opbMaximizeMarker {
+			negatedMax = true
+		}
+		if line == "" || strings.HasPrefix(line, "*") {
+			continue
+		}
+		for _, token := range strings.Fields(strings.ReplaceAll(line, ";", " ; ")) {
+			if token != ";" {
+				statement = append(statement, token)
+				continue
+			}
+			if err := parseOPBStatement(fac, problem, statement); err != nil {
+				return nil, err
+			}
+			statement = statement[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(statement) > 0 {
+		return nil, errorx.BadInput("statement '%s' is not terminated by ';'", strings.Join(statement, " "))
+	}
+	if negatedMax && !problem.Maximize && len(problem.ObjectiveLiterals) > 0 {
+		problem.Maximize = true
+		for i, weight := range problem.ObjectiveWeights {
+			problem.ObjectiveWeights[i] = -weight
+		}
+	}
+	return problem, nil
+}
+
+func parseOPBStatement(fac f.Factory, problem *PBProblem, tokens []string) error {
+	switch {
+	case len(tokens) == 0:
+		return nil
+	case tokens[0] == "min:" || tokens[0] == "max:":
+		lits, coeffs, rest, err := parseOPBTerms(fac, tokens[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return errorx.BadInput("unexpected tokens in objective function: %s", strings.Join(rest, " "))
+		}
+		problem.ObjectiveLiterals = lits
+		problem.ObjectiveWeights = coeffs
+		problem.Maximize = tokens[0] == "max:"
+		return nil
+	case tokens[0] == "soft:":
+		if len(tokens) > 1 {
+			top, err := strconv.Atoi(tokens[1])
+			if err != nil {
+				return err
+			}
+			problem.TopCost = top
+		}
+		return nil
+	case strings.HasPrefix(tokens[0], "["):
+		weightToken := strings.TrimSuffix(strings.TrimPrefix(tokens[0], "["), "]")
+		weight, err := strconv.Atoi(weightToken)
+		if err != nil {
+			return errorx.BadInput("illegal weight of soft constraint: %s", tokens[0])
+		}
+		constraint, err := parseOPBConstraint(fac, tokens[1:])
+		if err != nil {
+			return err
+		}
+		problem.SoftConstraints = append(problem.SoftConstraints, constraint)
+		problem.SoftWeights = append(problem.SoftWeights, weight)
+		return nil
+	default:
+		constraint, err := parseOPBConstraint(fac, tokens)
+		if err != nil {
+			return err
+		}
+		problem.Constraints = append(problem.Constraints, constraint)
+		return nil
+	}
+}
+
+func parseOPBConstraint(fac f.Factory, tokens []string) (f.Formula, error) {
+	lits, coeffs, rest, err := parseOPBTerms(fac, tokens)
+	if err != nil {
+		return 0, err
+	}
+	if len(rest) != 2 {
+		return 0, errorx.BadInput("illegal constraint: %s", strings.Join(tokens, " "))
+	}
+	var comparator f.CSort
+	switch rest[0] {
+	case ">=":
+		comparator = f.GE
+	case "=":
+		comparator = f.EQ
+	case "<=":
+		comparator = f.LE
+	case ">":
+		comparator = f.GT
+	case "<":
+		comparator = f.LT
+	default:
+		return 0, errorx.BadInput("illegal comparator: %s", rest[0])
+	}
+	rhs, err := strconv.Atoi(rest[1])
+	if err != nil {
+		return 0, err
+	}
+	return fac.PBC(comparator, rhs, lits, coeffs), nil
+}
+
+// parseOPBTerms parses the linear terms at the beginning of the given tokens
+// and returns the literals, coefficients, and the remaining tokens.
+func parseOPBTerms(fac f.Factory, tokens []string) ([]f.Literal, []int, []string, error) {
+	var lits []f.Literal
+	var coeffs []int
+	i := 0
+	for i < len(tokens) && !isOPBComparator(tokens[i]) {
+		coeff := 1
+		if c, err := strconv.Atoi(tokens[i]); err == nil {
+			coeff = c
+			i++
+		} else if tokens[i] == "+" || tokens[i] == "-" {
+			return nil, nil, nil, errorx.BadInput("detached sign in term: %s", strings.Join(tokens, " "))
+		}
+		if i >= len(tokens) || isOPBComparator(tokens[i]) {
+			return nil, nil, nil, errorx.BadInput("coefficient without literal: %s", strings.Join(tokens, " "))
+		}
+		lit, err := parseOPBLiteral(fac, tokens[i])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		i++
+		if i < len(tokens) && !isOPBComparator(tokens[i]) && !isOPBNumber(tokens[i]) {
+			return nil, nil, nil, errorx.BadInput("non-linear terms are not supported: %s", strings.Join(tokens, " "))
+		}
+		lits = append(lits, lit)
+		coeffs = append(coeffs, coeff)
+	}
+	return lits, coeffs, tokens[i:], nil
+}
+
+func parseOPBLiteral(fac f.Factory, token string) (f.Literal, error) {
+	phase := true
+	name := token
+	if strings.HasPrefix(token, "~") {
+		phase = false
+		name = token[1:]
+	}
+	if name == "" || !(name[0] == '_' || name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z') {
+		return 0, errorx.BadInput("illegal literal: %s", token)
+	}
+	return fac.Lit(name, phase), nil
+}
+
+func isOPBComparator(token string) bool {
+	return token == ">=" || token == "=" || token == "<=" || token == ">" || token == "<"
+}
+
+func isOPBNumber(token string) bool {
+	_, err := strconv.Atoi(token)
+	return err == nil
+}
@@ -0,0 +1,197 @@
+package io
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+const opbExample = `* #variable= 4 #constraint= 3
+* a comment
+min: +2 x1 -3 x2 +1 ~x3 ;
++1 x1 +1 x2 >= 1 ;
++1 x1 +1 x2 +1 x3
+  +1 x4 <= 2 ;
++3 x2 -2 ~x4 = 1;
+`
+
+func TestReadOPB(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	problem, err := ReadOPBFromReader(fac, strings.NewReader(opbExample))
+	assert.Nil(err)
+	assert.Equal(3, len(problem.Constraints))
+	assert.True(problem.HasObjective())
+	assert.False(problem.Maximize)
+	x1, x2, x3, x4 := fac.Lit("x1", true), fac.Lit("x2", true), fac.Lit("x3", true), fac.Lit("x4", true)
+	assert.Equal([]f.Literal{x1, x2, x3.Negate(fac)}, problem.ObjectiveLiterals)
+	assert.Equal([]int{2, -3, 1}, problem.ObjectiveWeights)
+	assert.Equal(fac.CC(f.GE, 1, x1.Variable(), x2.Variable()), problem.Constraints[0])
+	assert.Equal(fac.CC(f.LE, 2, x1.Variable(), x2.Variable(), x3.Variable(), x4.Variable()), problem.Constraints[1])
+	assert.Equal(fac.PBC(f.EQ, 1, []f.Literal{x2, x4.Negate(fac)}, []int{3, -2}), problem.Constraints[2])
+
+	solver := sat.NewSolver(fac)
+	solver.Add(problem.Constraints...)
+	res, err := solver.MinimizeWeighted(problem.ObjectiveLiterals, problem.ObjectiveWeights)
+	assert.Nil(err)
+	assert.Equal(-3, res.Optimum)
+
+	formulas, weights, offset := problem.SoftFormulas(fac)
+	assert.Equal([]f.Formula{x1.Negate(fac).AsFormula(), x2.AsFormula(), x3.AsFormula()}, formulas)
+	assert.Equal([]int{2, 3, 1}, weights)
+	assert.Equal(-3, offset)
+}
+
+func TestReadOPBMaximize(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	problem, err := ReadOPBFromReader(fac, strings.NewReader("max: +2 x1 -1 x2 ;\n+1 x1 +1 x2 >= 1 ;\n"))
+	assert.Nil(err)
+	assert.True(problem.Maximize)
+	assert.Equal([]int{2, -1}, problem.ObjectiveWeights)
+}
+
+func TestReadWBO(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	input := `* #variable= 2 #constraint= 3 #soft= 2 mincost= 2 maxcost= 3 sumcost= 5
+soft: 4 ;
+[2] +1 x1 >= 1 ;
+[3] +1 x2 >= 1 ;
+-1 x1 -1 x2 >= -1 ;
+`
+	problem, err := ReadOPBFromReader(fac, strings.NewReader(input))
+	assert.Nil(err)
+	assert.Equal(4, problem.TopCost)
+	assert.Equal(1, len(problem.Constraints))
+	assert.Equal([]f.Formula{fac.CC(f.GE, 1, fac.Var("x1")), fac.CC(f.GE, 1, fac.Var("x2"))}, problem.SoftConstraints)
+	assert.Equal([]int{2, 3}, problem.SoftWeights)
+	assert.False(problem.HasObjective())
+}
+
+func TestReadOPBIllegal(t *testing.T) {
+	fac := f.NewFactory()
+	for _, input := range []string{
+		"+1 x1 +1 x2 >= 1",
+		"+1 x1 x2 >= 1 ;",
+		"+1 x1 +1 x2 >> 1 ;",
+		"+1 x1 +1 >= 1 ;",
+		"+1 1x >= 1 ;",
+		"[a] +1 x1 >= 1 ;",
+	} {
+		_, err := ReadOPBFromReader(fac, strings.NewReader(input))
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestWriteOPB(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+	problem := &PBProblem{
+		Constraints: []f.Formula{
+			fac.PBC(f.LE, 3, []f.Literal{a.AsLiteral(), b.Negate(fac)}, []int{2, 2}),
+			fac.Or(a.AsFormula(), fac.And(b.AsFormula(), c.AsFormula())),
+		},
+		ObjectiveLiterals: []f.Literal{a.AsLiteral(), c.AsLiteral()},
+		ObjectiveWeights:  []int{-1, 3},
+		Maximize:          true,
+	}
+	var buf bytes.Buffer
+	var2id, err := WriteOPBToWriter(fac, &buf, problem)
+	assert.Nil(err)
+	assert.Equal(map[f.Variable]int{a: 1, c: 2, b: 3}, var2id)
+	assert.Equal(`* #variable= 3 #constraint= 3
+* maximize
+min: +1 x1 -3 x2 ;
+-2 x1 -2 ~x3 >= -3 ;
++1 x1 +1 x3 >= 1 ;
++1 x1 +1 x2 >= 1 ;
+`, buf.String())
+
+	reread, err := ReadOPBFromReader(fac, strings.NewReader(buf.String()))
+	assert.Nil(err)
+	assert.True(reread.Maximize)
+	assert.Equal([]int{-1, 3}, reread.ObjectiveWeights)
+	assert.Equal(3, len(reread.Constraints))
+}
+
+func TestWriteOPBContradiction(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	a := fac.Var("a")
+	problem := &PBProblem{Constraints: []f.Formula{fac.Falsum()}}
+	var buf bytes.Buffer
+	_, err := WriteOPBToWriter(fac, &buf, problem)
+	assert.Nil(err)
+	assert.Equal(`* #variable= 1 #constraint= 2
++1 x1 >= 1 ;
+-1 x1 >= 0 ;
+`, buf.String())
+
+	problem = &PBProblem{
+		Constraints:     []f.Formula{a.AsFormula()},
+		SoftConstraints: []f.Formula{fac.Falsum()},
+		SoftWeights:     []int{2},
+	}
+	buf.Reset()
+	_, err = WriteOPBToWriter(fac, &buf, problem)
+	assert.Nil(err)
+	assert.Equal(`* #variable= 2 #constraint= 4 #soft= 1 mincost= 2 maxcost= 2 sumcost= 2
+soft: ;
++1 x1 >= 1 ;
++1 ~x2 +1 x1 >= 1 ;
++1 ~x2 -1 x1 >= 0 ;
+[2] +1 x2 >= 1 ;
+`, buf.String())
+	reread, err := ReadOPBFromReader(fac, strings.NewReader(buf.String()))
+	assert.Nil(err)
+	assert.Equal(3, len(reread.Constraints))
+}
+
+func TestWriteOPBSoftWithObjective(t *testing.T) {
+	fac := f.NewFactory()
+	a := fac.Var("a")
+	problem := &PBProblem{
+		SoftConstraints:   []f.Formula{a.AsFormula()},
+		SoftWeights:       []int{1},
+		ObjectiveLiterals: []f.Literal{a.AsLiteral()},
+		ObjectiveWeights:  []int{1},
+	}
+	_, err := WriteOPBToWriter(fac, &bytes.Buffer{}, problem)
+	assert.NotNil(t, err)
+}
+
+func TestWriteWBO(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	a, b := fac.Var("a"), fac.Var("b")
+	problem := &PBProblem{
+		Constraints:     []f.Formula{fac.AMO(a, b)},
+		SoftConstraints: []f.Formula{a.AsFormula(), b.AsFormula(), fac.And(a.AsFormula(), b.AsFormula())},
+		SoftWeights:     []int{2, 3, 4},
+		TopCost:         10,
+	}
+	var buf bytes.Buffer
+	_, err := WriteOPBToWriter(fac, &buf, problem)
+	assert.Nil(err)
+	assert.Equal(`* #variable= 3 #constraint= 6 #soft= 3 mincost= 2 maxcost= 4 sumcost= 9
+soft: 10 ;
+-1 x1 -1 x2 >= -1 ;
++1 ~x3 +1 x1 >= 1 ;
++1 ~x3 +1 x2 >= 1 ;
+[2] +1 x1 >= 1 ;
+[3] +1 x2 >= 1 ;
+[4] +1 x3 >= 1 ;
+`, buf.String())
+
+	reread, err := ReadOPBFromReader(fac, strings.NewReader(buf.String()))
+	assert.Nil(err)
+	assert.Equal(10, reread.TopCost)
+	assert.Equal(3, len(reread.SoftConstraints))
+	assert.Equal([]int{2, 3, 4}, reread.SoftWeights)
+}
@@ -0,0 +1,235 @@
+package io
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/normalform"
+)
+
+const (
+	opbExtension = ".opb"
+	wboExtension = ".wbo"
+
+	opbMaximizeMarker = "* maximize"
+)
+
+// WriteOPB writes the given pseudo-Boolean problem to a file with the given
+// filename in the OPB format.  If the problem has soft constraints, the WBO
+// format is used instead.  The variables are renamed to x1, x2, ... .
+// Constraints which are no pseudo-Boolean or cardinality constraints are
+// converted to CNF and written as clauses.  A soft constraint which cannot be
+// represented by a single linear constraint is relaxed by a fresh variable.
+// Since the OPB format only knows minimization, the objective function of a
+// maximization problem is written as the minimization of its negation, marked
+// by the comment line "* maximize" which is recognized by ReadOPB.  A problem
+// with soft constraints must not have an objective function, since the WBO
+// format does not support it.
+// Returns a mapping from each variable of the original problem to its index
+// in the file and an optional error if there was a problem writing the file.
+func WriteOPB(fac f.Factory, filename string, problem *PBProblem) (map[f.Variable]int, error) {
+	extension := opbExtension
+	if len(problem.SoftConstraints) > 0 {
+		extension = wboExtension
+	}
+	name := filename
+	if !strings.HasSuffix(filename, extension) {
+		name = filename + extension
+	}
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return WriteOPBToWriter(fac, file, problem)
+}
+
+// WriteOPBToWriter writes the given pseudo-Boolean problem to the given writer
+// in the OPB format or the WBO format if the problem has soft constraints.
+// See WriteOPB for details.  Returns a mapping from each variable of the
+// original problem to its index in the output and an optional error if there
+// was a problem writing to the writer.
+func WriteOPBToWriter(fac f.Factory, writer io.Writer, problem *PBProblem) (map[f.Variable]int, error) {
+	if len(problem.ObjectiveLiterals) != len(problem.ObjectiveWeights) {
+		return nil, errorx.BadInput("number of objective literals and weights differ")
+	}
+	if len(problem.SoftConstraints) != len(problem.SoftWeights) {
+		return nil, errorx.BadInput("number of soft constraints and weights differ")
+	}
+	if len(problem.SoftConstraints) > 0 && len(problem.ObjectiveLiterals) > 0 {
+		return nil, errorx.BadInput("a problem with soft constraints cannot have an objective function")
+	}
+	ow := &opbWriter{fac: fac, var2id: make(map[f.Variable]int)}
+	for _, lit := range problem.ObjectiveLiterals {
+		ow.index(lit.Variable())
+	}
+	for _, formula := range append(append([]f.Formula{}, problem.Constraints...), problem.SoftConstraints...) {
+		for _, variable := range f.Variables(fac, formula).Content() {
+			ow.index(variable)
+		}
+	}
+	ow.nbVars = len(ow.var2id)
+
+	hard := make([]string, 0, len(problem.Constraints))
+	for _, formula := range problem.Constraints {
+		hard = append(hard, ow.constraints(formula)...)
+	}
+	soft := make([]string, 0, len(problem.SoftConstraints))
+	sumCost, minCost, maxCost := 0, 0, 0
+	for i, formula := range problem.SoftConstraints {
+		weight := problem.SoftWeights[i]
+		if weight < 1 {
+			return nil, errorx.BadInput("the weight of a soft constraint must be > 0")
+		}
+		sumCost += weight
+		if i == 0 || weight < minCost {
+			minCost = weight
+		}
+		maxCost = max(maxCost, weight)
+		constraints := ow.constraints(formula)
+		if len(constraints) == 1 {
+			soft = append(soft, fmt.Sprintf("[%d] %s", weight, constraints[0]))
+			continue
+		}
+		ow.nbVars++
+		selector := "x" + strconv.Itoa(ow.nbVars)
+		for _, c := range constraints {
+			hard = append(hard, fmt.Sprintf("+1 ~%s %s", selector, c))
+		}
+		soft = append(soft, fmt.Sprintf("[%d] +1 %s >= 1", weight, selector))
+	}
+
+	w := bufio.NewWriter(writer)
+	if len(problem.SoftConstraints) > 0 {
+		fmt.Fprintf(w, "* #variable= %d #constraint= %d #soft= %d mincost= %d maxcost= %d sumcost= %d\n",
+			ow.nbVars, len(hard)+len(soft), len(soft), minCost, maxCost, sumCost)
+		if problem.TopCost > 0 {
+			fmt.Fprintf(w, "soft: %d ;\n", problem.TopCost)
+		} else {
+			fmt.Fprintln(w, "soft: ;")
+		}
+	} else {
+		fmt.Fprintf(w, "* #variable= %d #constraint= %d\n", ow.nbVars, len(hard))
+	}
+	if len(problem.ObjectiveLiterals) > 0 {
+		weights := problem.ObjectiveWeights
+		if problem.Maximize {
+			fmt.Fprintln(w, opbMaximizeMarker)
+			weights = make([]int, len(problem.ObjectiveWeights))
+			for i, weight := range problem.ObjectiveWeights {
+				weights[i] = -weight
+			}
+		}
+		fmt.Fprintf(w, "min: %s ;\n", ow.terms(problem.ObjectiveLiterals, weights))
+	}
+	for _, c := range hard {
+		fmt.Fprintf(w, "%s ;\n", c)
+	}
+	for _, c := range soft {
+		fmt.Fprintf(w, "%s ;\n", c)
+	}
+	return ow.var2id, w.Flush()
+}
+
+type opbWriter struct {
+	fac    f.Factory
+	var2id map[f.Variable]int
+	nbVars int
+}
+
+func (ow *opbWriter) index(variable f.Variable) {
+	if _, ok := ow.var2id[variable]; !ok {
+		ow.var2id[variable] = len(ow.var2id) + 1
+	}
+}
+
+// constraints returns the given formula as a list of linear constraints with
+// a >= or = comparator.
+func (ow *opbWriter) constraints(formula f.Formula) []string {
+	fac := ow.fac
+	switch formula.Sort() {
+	case f.SortTrue:
+		return nil
+	case f.SortFalse:
+		return ow.contradiction()
+	case f.SortCC, f.SortPBC:
+		comparator, rhs, lits, coeffs, _ := fac.PBCOps(formula)
+		switch comparator {
+		case f.LE, f.LT:
+			negCoeffs := make([]int, len(coeffs))
+			for i, c := range coeffs {
+				negCoeffs[i] = -c
+			}
+			if comparator == f.LT {
+				rhs--
+			}
+			return []string{fmt.Sprintf("%s >= %d", ow.terms(lits, negCoeffs), -rhs)}
+		case f.GT:
+			return []string{fmt.Sprintf("%s >= %d", ow.terms(lits, coeffs), rhs+1)}
+		case f.EQ:
+			return []string{fmt.Sprintf("%s = %d", ow.terms(lits, coeffs), rhs)}
+		default:
+			return []string{fmt.Sprintf("%s >= %d", ow.terms(lits, coeffs), rhs)}
+		}
+	default:
+		cnf := normalform.CNF(fac, formula)
+		var clauses []f.Formula
+		switch cnf.Sort() {
+		case f.SortTrue:
+			return nil
+		case f.SortAnd:
+			clauses = fac.Operands(cnf)
+		default:
+			clauses = []f.Formula{cnf}
+		}
+		result := make([]string, 0, len(clauses))
+		for _, clause := range clauses {
+			if clause.Sort() == f.SortFalse {
+				result = append(result, ow.contradiction()...)
+				continue
+			}
+			lits := f.Literals(fac, clause).Content()
+			coeffs := make([]int, len(lits))
+			for j := range coeffs {
+				coeffs[j] = 1
+			}
+			result = append(result, fmt.Sprintf("%s >= 1", ow.terms(lits, coeffs)))
+		}
+		return result
+	}
+}
+
+// contradiction returns two constraints x1 >= 1 and -x1 >= 0 which cannot be
+// satisfied together.  If the problem has no variables, x1 is introduced.
+func (ow *opbWriter) contradiction() []string {
+	if ow.nbVars == 0 {
+		ow.nbVars = 1
+	}
+	return []string{"+1 x1 >= 1", "-1 x1 >= 0"}
+}
+
+func (ow *opbWriter) terms(lits []f.Literal, coeffs []int) string {
+	var sb strings.Builder
+	for i, lit := range lits {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		if coeffs[i] >= 0 {
+			sb.WriteString("+")
+		}
+		sb.WriteString(strconv.Itoa(coeffs[i]))
+		sb.WriteString(" ")
+		if lit.IsNeg() {
+			sb.WriteString("~")
+		}
+		sb.WriteString("x")
+		sb.WriteString(strconv.Itoa(ow.var2id[lit.Variable()]))
+	}
+	return sb.String()
+}
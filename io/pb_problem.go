@@ -0,0 +1,70 @@
+package io
+
+import (
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// PBProblem represents a pseudo-Boolean problem as it is read from or written
+// to a file in the OPB or WBO format of the pseudo-Boolean competitions.
+//
+// The hard constraints are stored as formulas, usually pseudo-Boolean or
+// cardinality constraints.  An OPB problem can have a linear objective
+// function which should be minimized (or maximized).  A WBO problem has no
+// objective function but weighted soft constraints and optionally a top cost.
+// Solutions with a cost of the violated soft constraints greater or equal to
+// the top cost are considered infeasible.  A top cost of 0 means that there
+// is no top cost.
+//
+// The hard constraints and the objective function can directly be used for a
+// weighted optimization with the SAT solver, e.g.
+//
+//	solver.Add(problem.Constraints...)
+//	solver.MinimizeWeighted(problem.ObjectiveLiterals, problem.ObjectiveWeights)
+//
+// For a MAX-SAT solver the objective function can be translated to soft
+// formulas with SoftFormulas.
+type PBProblem struct {
+	Constraints       []f.Formula // hard constraints
+	ObjectiveLiterals []f.Literal // literals of the objective function
+	ObjectiveWeights  []int       // weights of the objective function
+	Maximize          bool        // whether the objective function should be maximized
+	SoftConstraints   []f.Formula // soft constraints of a WBO problem
+	SoftWeights       []int       // weights of the soft constraints of a WBO problem
+	TopCost           int         // top cost of a WBO problem or 0
+}
+
+// HasObjective reports whether the problem has a non-empty objective
+// function.
+func (p *PBProblem) HasObjective() bool {
+	return len(p.ObjectiveLiterals) > 0
+}
+
+// SoftFormulas translates the objective function and the soft constraints of
+// the problem to soft formulas with positive weights as they are used by a
+// MAX-SAT solver.  Each term c*l of the objective function is translated to
+// the soft formula ~l with weight c (or l with weight -c for negative
+// coefficients).  The returned offset must be added to the optimum of the
+// MAX-SAT solver in order to get the optimum of the objective function.  For
+// a maximization problem the negated sum is the optimum of the objective
+// function.
+func (p *PBProblem) SoftFormulas(fac f.Factory) (formulas []f.Formula, weights []int, offset int) {
+	formulas = make([]f.Formula, 0, len(p.ObjectiveLiterals)+len(p.SoftConstraints))
+	weights = make([]int, 0, len(p.ObjectiveLiterals)+len(p.SoftConstraints))
+	for i, lit := range p.ObjectiveLiterals {
+		coeff := p.ObjectiveWeights[i]
+		if p.Maximize {
+			coeff = -coeff
+		}
+		if coeff > 0 {
+			formulas = append(formulas, lit.Negate(fac).AsFormula())
+			weights = append(weights, coeff)
+		} else if coeff < 0 {
+			formulas = append(formulas, lit.AsFormula())
+			weights = append(weights, -coeff)
+			offset += coeff
+		}
+	}
+	formulas = append(formulas, p.SoftConstraints...)
+	weights = append(weights, p.SoftWeights...)
+	return formulas, weights, offset
+}
@@ -104,14 +104,14 @@ func TestMaxsatDecrementalityPartial(t *testing.T) {
 		assert.True(res.Satisfiable)
 		assert.Equal(0, res.Optimum)
 		state0 := solver.SaveState()
-		assert.Equal(SolverState{int32(1), 0, 0, 0, 0, 1, []int{}}, *state0)
+		assert.Equal(SolverState{int32(1), 0, 0, 0, 0, 1, []int{}, 0, 0}, *state0)
 
 		solver.AddHardFormula(p.ParseUnsafe("(~a | ~b) & (~b | ~c) & ~d"))
 		res = solver.Solve()
 		assert.True(res.Satisfiable)
 		assert.Equal(0, res.Optimum)
 		state1 := solver.SaveState()
-		assert.Equal(SolverState{int32(3), 4, 3, 0, 0, 1, []int{}}, *state1)
+		assert.Equal(SolverState{int32(3), 4, 3, 0, 0, 1, []int{}, 1, 0}, *state1)
 
 		solver.AddSoftFormula(p.ParseUnsafe("a"), 1)
 		solver.AddSoftFormula(p.ParseUnsafe("b"), 1)
@@ -122,7 +122,7 @@ func TestMaxsatDecrementalityPartial(t *testing.T) {
 		assert.True(
 			slices.Contains(res.Model.Literals, fac.Lit("a", true)) && !slices.Contains(res.Model.Literals, fac.Lit("b", true)) ||
 				slices.Contains(res.Model.Literals, fac.Lit("b", true)) && !slices.Contains(res.Model.Literals, fac.Lit("a", true)))
-		assert.Equal(SolverState{int32(5), 6, 7, 2, 2, 1, []int{1, 1}}, *state2)
+		assert.Equal(SolverState{int32(5), 6, 7, 2, 2, 1, []int{1, 1}, 1, 2}, *state2)
 
 		solver.LoadState(state1)
 		res = solver.Solve()
@@ -194,13 +194,13 @@ func TestMaxsatDecrementalityWeighted(t *testing.T) {
 		assert.Equal(0, res.Optimum)
 		state0 := solver.SaveState()
 
-		assert.Equal(SolverState{int32(1), 2, 2, 1, 2, 2, []int{2}}, *state0)
+		assert.Equal(SolverState{int32(1), 2, 2, 1, 2, 2, []int{2}, 0, 1}, *state0)
 		solver.AddHardFormula(p.ParseUnsafe("(~a | ~b) & (~b | ~c) & ~d"))
 		res = solver.Solve()
 		assert.True(res.Satisfiable)
 		assert.Equal(0, res.Optimum)
 		state1 := solver.SaveState()
-		assert.Equal(SolverState{int32(3), 6, 5, 1, 2, 2, []int{2}}, *state1)
+		assert.Equal(SolverState{int32(3), 6, 5, 1, 2, 2, []int{2}, 1, 1}, *state1)
 
 		solver.AddSoftFormula(p.ParseUnsafe("a"), 1)
 		solver.AddSoftFormula(p.ParseUnsafe("b"), 2)
@@ -208,7 +208,7 @@ func TestMaxsatDecrementalityWeighted(t *testing.T) {
 		assert.True(res.Satisfiable)
 		assert.Equal(1, res.Optimum)
 		state2 := solver.SaveState()
-		assert.Equal(SolverState{int32(5), 8, 9, 3, 5, 2, []int{2, 1, 2}}, *state2)
+		assert.Equal(SolverState{int32(5), 8, 9, 3, 5, 2, []int{2, 1, 2}, 1, 3}, *state2)
 
 		solver.LoadState(state1)
 		res = solver.Solve()
@@ -2,70 +2,110 @@ package maxsat
 
 import (
 	"bufio"
-	"fmt"
-	"math"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/booleworks/logicng-go/errorx"
 	f "github.com/booleworks/logicng-go/formula"
 )
 
 // ReadDimacsToSolver reads a Dimacs file for weighted MAX-SAT problems from
-// the given filename and loads it directly to the given solver.  Returns
-// an error if the file could not be read.
-func ReadDimacsToSolver(fac f.Factory, solver *Solver, filename string) error {
+// the given filename and loads it directly to the given solver.  Both the
+// classic format with a `p wcnf` or `p cnf` header and the header-less format
+// of the MaxSAT Evaluations 2022+ (hard clauses prefixed by `h`) are
+// supported.  The optional prefix parameter is used to generate the variable
+// names.  The default value is `v` therefore variable v1, v2, ... will be
+// generated from the input problem.  Returns an error if the file could not
+// be read.
+func ReadDimacsToSolver(fac f.Factory, solver *Solver, filename string, prefix ...string) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	return ReadDimacsToSolverFromReader(fac, solver, file, prefix...)
+}
+
+// ReadDimacsToSolverFromReader reads a weighted MAX-SAT problem in Dimacs
+// format from the given reader and loads it directly to the given solver.
+// The format is detected automatically, see ReadDimacsToSolver for details.
+// The optional prefix parameter is used to generate the variable names.
+// Returns an error if the input could not be read or is malformed.
+func ReadDimacsToSolverFromReader(fac f.Factory, solver *Solver, reader io.Reader, prefix ...string) error {
+	pfx := "v"
+	if len(prefix) > 0 {
+		pfx = prefix[0]
+	}
 	pureMaxSat := false
 	hardWeight := -1
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "p wcnf") {
-			header := strings.Split(strings.TrimSpace(line), " ")
-			if len(header) > 4 {
-				hardWeight, _ = strconv.Atoi(header[4])
+		tokens := strings.Fields(scanner.Text())
+		if len(tokens) == 0 || strings.HasPrefix(tokens[0], "c") {
+			continue
+		}
+		if tokens[0] == "p" {
+			if len(tokens) < 2 || (tokens[1] != "wcnf" && tokens[1] != "cnf") {
+				return errorx.BadInput("illegal header: %s", scanner.Text())
+			}
+			pureMaxSat = tokens[1] == "cnf"
+			if !pureMaxSat && len(tokens) > 4 {
+				var err error
+				if hardWeight, err = strconv.Atoi(tokens[4]); err != nil {
+					return err
+				}
 			}
-			break
-		} else if strings.HasPrefix(line, "p cnf") {
-			pureMaxSat = true
-			break
+			continue
 		}
-	}
-	for scanner.Scan() {
-		tokens := strings.Split(strings.TrimSpace(scanner.Text()), " ")
-		weight, err := strconv.Atoi(tokens[0])
-		if err != nil {
-			return err
+		if tokens[len(tokens)-1] != "0" {
+			return errorx.BadInput("line %s did not end with 0", scanner.Text())
 		}
-		var start int
-		if pureMaxSat {
-			start = 0
-		} else {
+		hard := false
+		weight := 1
+		start := 0
+		if !pureMaxSat {
 			start = 1
-		}
-		literals := make([]f.Formula, len(tokens)-start-1)
-		for i := start; i < len(tokens)-1; i++ {
-			if len(tokens[i]) > 0 {
-				parsedLit, err := strconv.Atoi(tokens[i])
-				if err != nil {
+			if tokens[0] == "h" {
+				hard = true
+			} else {
+				var err error
+				if weight, err = strconv.Atoi(tokens[0]); err != nil {
 					return err
 				}
-				variable := fmt.Sprintf("v%d", int(math.Abs(float64(parsedLit))))
-				literals[i-start] = fac.Literal(variable, parsedLit > 0)
+				hard = weight == hardWeight
 			}
 		}
-		if pureMaxSat {
-			solver.AddSoftFormula(fac.Or(literals...), 1)
-		} else if weight == hardWeight {
-			solver.AddHardFormula(fac.Or(literals...))
+		clause, err := parseClause(fac, tokens[start:len(tokens)-1], pfx)
+		if err != nil {
+			return err
+		}
+		if hard {
+			solver.AddHardFormula(clause)
+		} else if err = solver.AddSoftFormula(clause, weight); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func parseClause(fac f.Factory, tokens []string, prefix string) (f.Formula, error) {
+	literals := make([]f.Formula, len(tokens))
+	for i, token := range tokens {
+		parsedLit, err := strconv.Atoi(token)
+		if err != nil {
+			return 0, err
+		}
+		if parsedLit == 0 {
+			return 0, errorx.BadInput("unexpected 0 inside a clause")
+		}
+		if parsedLit > 0 {
+			literals[i] = fac.Literal(prefix+strconv.Itoa(parsedLit), true)
 		} else {
-			solver.AddSoftFormula(fac.Or(literals...), weight)
+			literals[i] = fac.Literal(prefix+strconv.Itoa(-parsedLit), false)
 		}
 	}
-	return nil
+	return fac.Or(literals...), nil
 }
@@ -37,10 +37,19 @@ type SolverState struct {
 	ubCost        int
 	currentWeight int
 	softWeights   []int
+	nbHardForms   int
+	nbSoftForms   int
 }
 
 const selPrefix = "@SEL_SOFT_"
 
+// A WeightedFormula is a soft formula of a MAX-SAT problem together with its
+// weight.
+type WeightedFormula struct {
+	Formula f.Formula
+	Weight  int
+}
+
 // A Solver can be used to solve the MAX-SAT problem.  Depending on the
 // underlying solving algorithm it supports also partial and/or weighted
 // MAX-SAT problems.
@@ -53,6 +62,8 @@ type Solver struct {
 	solver           algorithm
 	pgTransformation *pgOnSolver
 	selectorCounter  int
+	hardFormulas     []f.Formula
+	softFormulas     []WeightedFormula
 }
 
 func newSolver(fac f.Factory, algorithm Algorithm, config ...*Config) *Solver {
@@ -168,6 +179,7 @@ func OLL(fac f.Factory, config ...*Config) *Solver {
 // must always be satisfied.
 func (m *Solver) AddHardFormula(formula ...f.Formula) {
 	for _, formula := range formula {
+		m.hardFormulas = append(m.hardFormulas, formula)
 		m.addFormulaAsCNF(formula, -1)
 	}
 }
@@ -178,6 +190,7 @@ func (m *Solver) AddSoftFormula(formula f.Formula, weight int) error {
 	if weight < 1 {
 		return errorx.BadInput("the weight of a formula must be > 0")
 	}
+	m.softFormulas = append(m.softFormulas, WeightedFormula{formula, weight})
 	selVar := m.fac.Var(fmt.Sprintf("%s%d", selPrefix, m.selectorCounter))
 	m.selectorCounter++
 	m.addFormulaAsCNF(m.fac.Or(selVar.Negate(m.fac).AsFormula(), formula), -1)
@@ -188,7 +201,22 @@ func (m *Solver) AddSoftFormula(formula f.Formula, weight int) error {
 
 // SaveState saves and returns the current solver state.
 func (m *Solver) SaveState() *SolverState {
-	return m.solver.saveState()
+	state := m.solver.saveState()
+	state.nbHardForms = len(m.hardFormulas)
+	state.nbSoftForms = len(m.softFormulas)
+	return state
+}
+
+// HardFormulas returns the hard formulas which were added to the solver in
+// the order they were added.
+func (m *Solver) HardFormulas() []f.Formula {
+	return m.hardFormulas
+}
+
+// SoftFormulas returns the soft formulas and their weights which were added
+// to the solver in the order they were added.
+func (m *Solver) SoftFormulas() []WeightedFormula {
+	return m.softFormulas
 }
 
 // LoadState loads the given state to the solver. ATTENTION: You can only load
@@ -207,6 +235,8 @@ func (m *Solver) LoadState(state *SolverState) error {
 	if m.pgTransformation != nil {
 		m.pgTransformation.clearCache()
 	}
+	shrinkTo(&m.hardFormulas, state.nbHardForms)
+	shrinkTo(&m.softFormulas, state.nbSoftForms)
 	return nil
 }
 
@@ -0,0 +1,177 @@
+package maxsat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/normalform"
+)
+
+const wcnfExtension = ".wcnf"
+
+// WriteWCNF writes the problem of the given MAX-SAT solver to a file with the
+// given filename in the WCNF format of the MaxSAT Evaluations 2022+.  Hard
+// and soft formulas are converted to CNF.  A soft formula whose CNF consists
+// of more than one clause is relaxed by a fresh selector variable.  Returns a
+// mapping from each variable of the original problem to its index in the
+// WCNF file and an optional error if there was a problem writing the file.
+func WriteWCNF(fac f.Factory, filename string, solver *Solver) (map[f.Variable]int, error) {
+	name := filename
+	if !strings.HasSuffix(filename, wcnfExtension) {
+		name = filename + wcnfExtension
+	}
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return WriteWCNFToWriter(fac, file, solver)
+}
+
+// WriteWCNFToWriter writes the problem of the given MAX-SAT solver to the
+// given writer in the WCNF format of the MaxSAT Evaluations 2022+.  See
+// WriteWCNF for details.  Returns a mapping from each variable of the
+// original problem to its index in the WCNF output and an optional error if
+// there was a problem writing to the writer.
+func WriteWCNFToWriter(fac f.Factory, writer io.Writer, solver *Solver) (map[f.Variable]int, error) {
+	hard := make([]f.Formula, 0, len(solver.hardFormulas))
+	for _, formula := range solver.hardFormulas {
+		hard = append(hard, normalform.CNF(fac, formula))
+	}
+	soft := make([]f.Formula, 0, len(solver.softFormulas))
+	for _, wf := range solver.softFormulas {
+		soft = append(soft, normalform.CNF(fac, wf.Formula))
+	}
+	var2id := make(map[f.Variable]int)
+	for _, formula := range append(append([]f.Formula{}, hard...), soft...) {
+		for _, variable := range f.Variables(fac, formula).Content() {
+			if _, ok := var2id[variable]; !ok {
+				var2id[variable] = len(var2id) + 1
+			}
+		}
+	}
+	nextVar := len(var2id) + 1
+
+	w := bufio.NewWriter(writer)
+	for _, cnf := range hard {
+		for _, clause := range clauses(fac, cnf) {
+			if err := writeClause(fac, w, "h", clause, var2id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, cnf := range soft {
+		weight := strconv.Itoa(solver.softFormulas[i].Weight)
+		cls := clauses(fac, cnf)
+		switch len(cls) {
+		case 0:
+			continue
+		case 1:
+			if err := writeClause(fac, w, weight, cls[0], var2id); err != nil {
+				return nil, err
+			}
+		default:
+			selector := nextVar
+			nextVar++
+			for _, clause := range cls {
+				if err := writeClause(fac, w, "h", clause, var2id, -selector); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "%s %d 0\n", weight, selector); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return var2id, w.Flush()
+}
+
+// WriteSolution writes the given MAX-SAT result and the handler state of its
+// computation to the given writer in the output format of the MaxSAT
+// Evaluations 2022+.  The cost is written in an `o` line, the status in an
+// `s` line and the model in a `v` line as a string of 0s and 1s.  The status
+// is OPTIMUM FOUND or UNSATISFIABLE for a completed computation.  If the
+// computation was canceled, the status is SATISFIABLE if the result holds a
+// model and UNKNOWN otherwise.
+//
+// The given mapping is usually the result of WriteWCNF and must map the
+// variables to the indices 1 to n for n variables, the `v` line has one entry
+// for each of these indices.  Selector variables which were introduced by
+// WriteWCNF for soft formulas with more than one clause have larger indices
+// and are not part of the `v` line.  Returns an error if an index of the
+// mapping is out of this range.
+func WriteSolution(writer io.Writer, result Result, state handler.State, var2id map[f.Variable]int) error {
+	values := make([]byte, len(var2id))
+	for i := range values {
+		values[i] = '0'
+	}
+	for _, index := range var2id {
+		if index < 1 || index > len(var2id) {
+			return errorx.BadInput("variable index %d is not in the range 1 to %d", index, len(var2id))
+		}
+	}
+	hasModel := result.Satisfiable && result.Model != nil
+	var status string
+	switch {
+	case state.Success && !result.Satisfiable:
+		status = "UNSATISFIABLE"
+	case state.Success:
+		status = "OPTIMUM FOUND"
+	case hasModel:
+		status = "SATISFIABLE"
+	default:
+		status = "UNKNOWN"
+	}
+	if !hasModel {
+		_, err := fmt.Fprintf(writer, "s %s\n", status)
+		return err
+	}
+	for _, variable := range result.Model.PosVars() {
+		if index, ok := var2id[variable]; ok {
+			values[index-1] = '1'
+		}
+	}
+	_, err := fmt.Fprintf(writer, "o %d\ns %s\nv %s\n", result.Optimum, status, values)
+	return err
+}
+
+func clauses(fac f.Factory, cnf f.Formula) []f.Formula {
+	switch cnf.Sort() {
+	case f.SortTrue:
+		return nil
+	case f.SortAnd:
+		return fac.Operands(cnf)
+	default:
+		return []f.Formula{cnf}
+	}
+}
+
+func writeClause(
+	fac f.Factory, w io.Writer, prefix string, clause f.Formula, var2id map[f.Variable]int, additional ...int,
+) error {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for _, lit := range additional {
+		sb.WriteString(" ")
+		sb.WriteString(strconv.Itoa(lit))
+	}
+	if clause.Sort() != f.SortFalse {
+		for _, lit := range f.Literals(fac, clause).Content() {
+			sb.WriteString(" ")
+			if lit.IsNeg() {
+				sb.WriteString("-")
+			}
+			sb.WriteString(strconv.Itoa(var2id[lit.Variable()]))
+		}
+	}
+	sb.WriteString(" 0\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
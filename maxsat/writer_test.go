@@ -0,0 +1,131 @@
+package maxsat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadWCNF2022(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	input := `c a small example
+h 1 2 0
+h -1 -2 0
+3 1 0
+c another comment
+5 2 0
+2 -3 1 0
+`
+	solver := OLL(fac)
+	assert.Nil(ReadDimacsToSolverFromReader(fac, solver, strings.NewReader(input), "x"))
+	assert.Equal(2, len(solver.HardFormulas()))
+	assert.Equal(3, len(solver.SoftFormulas()))
+	assert.Equal(fac.Or(fac.Variable("x1"), fac.Variable("x2")), solver.HardFormulas()[0])
+	assert.Equal(5, solver.SoftFormulas()[1].Weight)
+	result := solver.Solve()
+	assert.True(result.Satisfiable)
+	assert.Equal(3, result.Optimum)
+}
+
+func TestReadWCNFOldFormat(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	input := `p wcnf 2 3 10
+10 1 2 0
+c comment after header
+10 -1 -2 0
+3 1 0
+5 2 0
+`
+	solver := OLL(fac)
+	assert.Nil(ReadDimacsToSolverFromReader(fac, solver, strings.NewReader(input)))
+	assert.Equal(2, len(solver.HardFormulas()))
+	assert.Equal(2, len(solver.SoftFormulas()))
+	assert.Equal(3, solver.Solve().Optimum)
+}
+
+func TestReadWCNFIllegal(t *testing.T) {
+	fac := f.NewFactory()
+	assert.NotNil(t, ReadDimacsToSolverFromReader(fac, OLL(fac), strings.NewReader("h 1 2\n")))
+	assert.NotNil(t, ReadDimacsToSolverFromReader(fac, OLL(fac), strings.NewReader("h 1 a 0\n")))
+	assert.NotNil(t, ReadDimacsToSolverFromReader(fac, OLL(fac), strings.NewReader("0 1 0\n")))
+}
+
+func TestWriteWCNF(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := OLL(fac)
+	solver.AddHardFormula(p.ParseUnsafe("A & B & (C | D)"))
+	solver.AddSoftFormula(p.ParseUnsafe("A => ~B"), 2)
+	solver.AddSoftFormula(p.ParseUnsafe("~C"), 4)
+	solver.AddSoftFormula(p.ParseUnsafe("~D & E"), 8)
+
+	var buf bytes.Buffer
+	var2id, err := WriteWCNFToWriter(fac, &buf, solver)
+	assert.Nil(err)
+	assert.Equal(5, len(var2id))
+	assert.Equal(`h 1 0
+h 2 0
+h 3 4 0
+2 -1 -2 0
+4 -3 0
+h -6 -4 0
+h -6 5 0
+8 6 0
+`, buf.String())
+
+	reread := OLL(fac)
+	assert.Nil(ReadDimacsToSolverFromReader(fac, reread, strings.NewReader(buf.String())))
+	assert.Equal(solver.Solve().Optimum, reread.Solve().Optimum)
+}
+
+func TestWriteSolution(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := OLL(fac)
+	solver.AddHardFormula(p.ParseUnsafe("A & B & (C | D)"))
+	solver.AddSoftFormula(p.ParseUnsafe("~C"), 4)
+	solver.AddSoftFormula(p.ParseUnsafe("~D"), 8)
+
+	var buf bytes.Buffer
+	var2id, _ := WriteWCNFToWriter(fac, &buf, solver)
+	buf.Reset()
+	result := solver.Solve()
+	assert.Nil(WriteSolution(&buf, result, succ, var2id))
+	assert.Equal("o 4\ns OPTIMUM FOUND\nv 1110\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(WriteSolution(&buf, unsat(), succ, var2id))
+	assert.Equal("s UNSATISFIABLE\n", buf.String())
+
+	canceled := handler.Cancelation(event.MaxSATCallStarted)
+	buf.Reset()
+	assert.Nil(WriteSolution(&buf, result, canceled, var2id))
+	assert.Equal("o 4\ns SATISFIABLE\nv 1110\n", buf.String())
+	buf.Reset()
+	assert.Nil(WriteSolution(&buf, Result{}, canceled, var2id))
+	assert.Equal("s UNKNOWN\n", buf.String())
+
+	canceledResult, state := OLL(fac).SolveWithHandler(&cancelHandler{})
+	buf.Reset()
+	assert.Nil(WriteSolution(&buf, canceledResult, state, var2id))
+	assert.Equal("s UNKNOWN\n", buf.String())
+
+	var2id[fac.Var("X")] = 7
+	assert.NotNil(WriteSolution(&buf, result, succ, var2id))
+}
+
+type cancelHandler struct{}
+
+func (cancelHandler) ShouldResume(event.Event) bool {
+	return false
+}
@@ -0,0 +1,204 @@
+package count
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"slices"
+
+	"github.com/booleworks/logicng-go/encoding"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/sat"
+)
+
+// ApproxConfig describes the configuration of the approximate model counter.
+// The estimated count c of an approximate count for the real count n
+// satisfies n/(1+Epsilon) <= c <= n*(1+Epsilon) with a probability of at
+// least 1-Delta.
+type ApproxConfig struct {
+	Epsilon float64 // tolerance of the estimate, must be > 0
+	Delta   float64 // 1-Delta is the confidence of the estimate, must be in (0,1)
+	Seed    int64   // seed for the random hash functions
+}
+
+// DefaultApproxConfig returns the default configuration of the approximate
+// model counter with a tolerance of 0.8 and a confidence of 0.8.
+func DefaultApproxConfig() *ApproxConfig {
+	return &ApproxConfig{
+		Epsilon: 0.8,
+		Delta:   0.2,
+		Seed:    42,
+	}
+}
+
+// ApproxOnFormula returns an estimate of the number of models of the formula
+// projected to the given variables.  See ApproxOnSolver for details.
+func ApproxOnFormula(fac f.Factory, formula f.Formula, variables []f.Variable, config ...*ApproxConfig) *big.Int {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	count, _ := ApproxOnSolverWithHandler(solver, variables, handler.NopHandler, config...)
+	return count
+}
+
+// ApproxOnSolver returns an estimate of the number of models of the formulas
+// on the solver projected to the given variables.  The counter follows the
+// ApproxMC algorithm: random XOR constraints over the projected variables
+// partition the models into cells and the models of a cell are enumerated
+// up to a threshold.  The number of XOR constraints for which a cell is small
+// enough yields an estimate, the median of several estimates is returned.
+// If the number of models is below the threshold, the exact count is
+// returned.  The XOR constraints are encoded as clauses with auxiliary
+// variables.  The state of the solver is restored after the computation.
+func ApproxOnSolver(solver *sat.Solver, variables []f.Variable, config ...*ApproxConfig) *big.Int {
+	count, _ := ApproxOnSolverWithHandler(solver, variables, handler.NopHandler, config...)
+	return count
+}
+
+// ApproxOnSolverWithHandler returns an estimate of the number of models of
+// the formulas on the solver projected to the given variables like
+// ApproxOnSolver.  The handler can be used to cancel the computation, it is
+// also passed to the model enumerations of the cells.
+func ApproxOnSolverWithHandler(
+	solver *sat.Solver,
+	variables []f.Variable,
+	hdl handler.Handler,
+	config ...*ApproxConfig,
+) (*big.Int, handler.State) {
+	cfg := DefaultApproxConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if e := event.ModelCountingStarted; !hdl.ShouldResume(e) {
+		return nil, handler.Cancelation(e)
+	}
+	vars := f.NewVarSet(variables...).Content()
+	counter := &approxCounter{
+		solver:    solver,
+		vars:      vars,
+		hdl:       hdl,
+		threshold: approxThreshold(cfg.Epsilon),
+		random:    rand.New(rand.NewSource(cfg.Seed)),
+	}
+	count, state := counter.cellCount(nil)
+	if !state.Success {
+		return nil, state
+	}
+	if count < counter.threshold {
+		return big.NewInt(int64(count)), succ
+	}
+	iterations := int(math.Ceil(17 * math.Log2(3/cfg.Delta)))
+	var estimates []*big.Int
+	for i := 0; i < iterations; i++ {
+		estimate, state := counter.estimate()
+		if !state.Success {
+			return nil, state
+		}
+		if estimate != nil {
+			estimates = append(estimates, estimate)
+		}
+	}
+	if len(estimates) == 0 {
+		return big.NewInt(0), succ
+	}
+	slices.SortFunc(estimates, func(a, b *big.Int) int { return a.Cmp(b) })
+	return estimates[len(estimates)/2], succ
+}
+
+// approxThreshold returns the maximum size of a cell for the given
+// tolerance.
+func approxThreshold(epsilon float64) int {
+	return int(math.Ceil(1 + 9.84*(1+epsilon/(1+epsilon))*(1+1/epsilon)*(1+1/epsilon)))
+}
+
+type approxCounter struct {
+	solver    *sat.Solver
+	vars      []f.Variable
+	hdl       handler.Handler
+	threshold int
+	random    *rand.Rand
+}
+
+type xorConstraint struct {
+	vars   []f.Variable
+	parity bool
+}
+
+// estimate computes one estimate of the count with a new random hash.  It
+// searches the smallest number m of XOR constraints for which the cell is
+// smaller than the threshold, first by doubling m and then by binary search.
+// Since the hash with m constraints consists of the first m constraints of
+// the hash, the cell sizes decrease with m.  An empty cell yields an estimate
+// of 0, which must be part of the median.  Returns nil if no such m exists.
+func (a *approxCounter) estimate() (*big.Int, handler.State) {
+	xors := make([]xorConstraint, len(a.vars))
+	for i := range xors {
+		for _, variable := range a.vars {
+			if a.random.Intn(2) == 0 {
+				xors[i].vars = append(xors[i].vars, variable)
+			}
+		}
+		xors[i].parity = a.random.Intn(2) == 0
+	}
+	counts := make(map[int]int)
+	count := func(m int) (int, handler.State) {
+		if c, ok := counts[m]; ok {
+			return c, succ
+		}
+		c, state := a.cellCount(xors[:m])
+		counts[m] = c
+		return c, state
+	}
+	low, high := 0, -1 // count(low) >= threshold > count(high)
+	for m := 1; high < 0; m = min(2*m, len(xors)) {
+		c, state := count(m)
+		if !state.Success {
+			return nil, state
+		}
+		if c < a.threshold {
+			high = m
+		} else if m == len(xors) {
+			return nil, succ
+		} else {
+			low = m
+		}
+	}
+	for high-low > 1 {
+		mid := (low + high) / 2
+		c, state := count(mid)
+		if !state.Success {
+			return nil, state
+		}
+		if c < a.threshold {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	estimate := new(big.Int).Lsh(big.NewInt(int64(counts[high])), uint(high))
+	return estimate, succ
+}
+
+// cellCount returns the number of models in the cell of the given XOR
+// constraints up to the threshold.
+func (a *approxCounter) cellCount(xors []xorConstraint) (int, handler.State) {
+	solverState := a.solver.SaveState()
+	defer func() {
+		if err := a.solver.LoadState(solverState); err != nil {
+			panic(err)
+		}
+	}()
+	fac := a.solver.Factory()
+	for _, xor := range xors {
+		a.solver.Add(encoding.EncodeXOR(fac, xor.vars, xor.parity)...)
+	}
+	hdl := iter.HandlerWithBound(a.hdl, a.threshold)
+	config := &iter.Config{Handler: hdl, Strategy: iter.NewNoSplitMEStrategy()}
+	count, _ := OnSolverWithConfig(a.solver, a.vars, config)
+	if state := hdl.State(); !state.Success {
+		return 0, state
+	}
+	return int(count.Int64()), succ
+}
@@ -0,0 +1,131 @@
+package count
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApproxCountExact(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+
+	assert.Equal(big.NewInt(0), ApproxOnFormula(fac, fac.Falsum(), []f.Variable{a}))
+	assert.Equal(big.NewInt(1), ApproxOnFormula(fac, fac.Verum(), nil))
+	assert.Equal(big.NewInt(4), ApproxOnFormula(fac, fac.Verum(), []f.Variable{a, b}))
+
+	formula := p.ParseUnsafe("(a | b) & (~a | c)")
+	assert.Equal(big.NewInt(4), ApproxOnFormula(fac, formula, []f.Variable{a, b, c}))
+	assert.Equal(big.NewInt(3), ApproxOnFormula(fac, formula, []f.Variable{a, b}))
+	assert.Equal(big.NewInt(2), ApproxOnFormula(fac, formula, []f.Variable{a}))
+
+	queens := sat.GenerateNQueens(fac, 6)
+	assert.Equal(big.NewInt(4), ApproxOnFormula(fac, queens, f.Variables(fac, queens).Content()))
+}
+
+func TestApproxCountLarge(t *testing.T) {
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	cc := p.ParseUnsafe("a + b + c + d + e + f + g + h + i + j + k + l + m + n + o + p <= 5")
+	vars := f.Variables(fac, cc).Content()
+	exact := ProjectedCount(fac, vars, cc)
+
+	epsilons := []float64{0.8, 0.5}
+	if testing.Short() {
+		epsilons = epsilons[:1]
+	}
+	for _, epsilon := range epsilons {
+		config := DefaultApproxConfig()
+		config.Epsilon = epsilon
+		solver := sat.NewSolver(fac)
+		solver.Add(cc)
+		estimate := ApproxOnSolver(solver, vars, config)
+		assertWithinTolerance(t, exact, estimate, epsilon)
+		assert.Equal(t, 0, exact.Cmp(OnSolver(solver, vars)))
+	}
+}
+
+func TestApproxCountProjected(t *testing.T) {
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a + b + c + d + e + f + g + h + i + j + k + l <= 4) & (x => a | b) & (y | z)")
+	vars := f.Variables(fac, p.ParseUnsafe("a & b & c & d & e & f & g & h & i & j & k & x")).Content()
+	exact := ProjectedCount(fac, vars, formula)
+
+	estimate := ApproxOnFormula(fac, formula, vars)
+	assertWithinTolerance(t, exact, estimate, DefaultApproxConfig().Epsilon)
+}
+
+func TestApproxEstimateEmptyCell(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := sat.NewSolver(fac)
+	solver.Add(p.ParseUnsafe("(a | b | c | d | e | x) & x & y & z & u & v & w"))
+	vars := fac.Vars("a", "b", "c", "d", "e", "x", "y", "z", "u", "v", "w")
+	zeros := 0
+	for seed := range int64(200) {
+		counter := &approxCounter{solver, vars, handler.NopHandler, approxThreshold(10), rand.New(rand.NewSource(seed))}
+		estimate, state := counter.estimate()
+		assert.True(state.Success)
+		assert.NotNil(estimate)
+		if estimate != nil && estimate.Sign() == 0 {
+			zeros++
+		}
+	}
+	assert.Greater(zeros, 0)
+}
+
+func TestApproxCountHandler(t *testing.T) {
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	cc := p.ParseUnsafe("a + b + c + d + e + f + g + h + i + j <= 5")
+	vars := f.Variables(fac, cc).Content()
+	solver := sat.NewSolver(fac)
+	solver.Add(cc)
+
+	count, state := ApproxOnSolverWithHandler(solver, vars, handler.NopHandler)
+	assert.True(t, state.Success)
+	assertWithinTolerance(t, ProjectedCount(fac, vars, cc), count, DefaultApproxConfig().Epsilon)
+
+	hdl := &eventLimitHandler{event: event.ModelCountingStarted}
+	count, state = ApproxOnSolverWithHandler(solver, vars, hdl)
+	assert.Nil(t, count)
+	assert.False(t, state.Success)
+	assert.Equal(t, event.ModelCountingStarted, state.CancelCause)
+
+	hdl = &eventLimitHandler{event: event.ModelEnumerationStarted, limit: 5}
+	count, state = ApproxOnSolverWithHandler(solver, vars, hdl)
+	assert.Nil(t, count)
+	assert.False(t, state.Success)
+	assert.Equal(t, event.ModelEnumerationStarted, state.CancelCause)
+	assert.True(t, solver.Sat())
+}
+
+func assertWithinTolerance(t *testing.T, exact, estimate *big.Int, epsilon float64) {
+	ratio, _ := new(big.Rat).SetFrac(estimate, exact).Float64()
+	assert.GreaterOrEqual(t, ratio, 1/(1+epsilon))
+	assert.LessOrEqual(t, ratio, 1+epsilon)
+}
+
+type eventLimitHandler struct {
+	event event.Event
+	limit int
+	count int
+}
+
+func (h *eventLimitHandler) ShouldResume(e event.Event) bool {
+	if e == h.event {
+		h.count++
+	}
+	return h.count <= h.limit
+}
@@ -1,3 +1,18 @@
 // Package count provides algorithms for counting models on formulas in LogicNG.
+//
+// Count computes the model count by compiling the formulas to DNNFs,
+// OnFormula and OnSolver count by model enumeration with a SAT solver.  For
+// projected model counting, especially on formulas with many auxiliary
+// variables, ProjectedCount uses a dedicated #SAT solver with component
+// caching:
+//
+//	count := count.ProjectedCount(fac, relevantVariables, formula)
+//
+// If an exact count is too expensive, ApproxOnFormula and ApproxOnSolver
+// compute an estimate with a user-defined tolerance and confidence by hashing
+// the models with random XOR constraints:
+//
+//	config := count.DefaultApproxConfig()
+//	config.Epsilon = 0.5
+//	estimate := count.ApproxOnFormula(fac, formula, relevantVariables, config)
 package count
-
@@ -0,0 +1,490 @@
+package count
+
+import (
+	"container/list"
+	"encoding/binary"
+	"math/big"
+	"slices"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/normalform"
+)
+
+// ProjectedCount returns the number of assignments of the given variables
+// which can be extended to a model of the given formulas (interpreted as
+// conjunction).  Variables of the formulas which are not in the given
+// variables are existentially quantified, variables which are not in the
+// formulas are don't cares.
+//
+// In contrast to Count, the formulas may contain arbitrary cardinality and
+// pseudo-Boolean constraints: the formulas are converted to CNF with
+// auxiliary variables, which do not change the projected count.  The count is
+// computed by an exact #SAT solver with component decomposition, a component
+// cache which evicts the least recently used components if it grows too
+// large, unit propagation with watched literals and failed literal detection
+// (implicit BCP).
+func ProjectedCount(fac f.Factory, variables []f.Variable, formulas ...f.Formula) *big.Int {
+	count, _ := ProjectedCountWithHandler(fac, variables, handler.NopHandler, formulas...)
+	return count
+}
+
+// ProjectedCountWithHandler returns the number of assignments of the given
+// variables which can be extended to a model of the given formulas like
+// ProjectedCount.  The handler can be used to cancel the computation, it is
+// called on each decision of the #SAT solver.
+func ProjectedCountWithHandler(
+	fac f.Factory,
+	variables []f.Variable,
+	hdl handler.Handler,
+	formulas ...f.Formula,
+) (*big.Int, handler.State) {
+	if e := event.ModelCountingStarted; !hdl.ShouldResume(e) {
+		return nil, handler.Cancelation(e)
+	}
+	cnf := normalform.CNF(fac, fac.And(formulas...))
+	counter := newProjectedCounter(fac, cnf, variables, hdl)
+	return counter.count()
+}
+
+// maxCacheBytes is the approximate number of bytes of the component cache.
+// If the cache grows beyond it, the least recently used components are
+// evicted.
+const maxCacheBytes = 256 << 20
+
+// projectedCounter is an exact projected #SAT solver on a CNF.  A literal of
+// the variable with index v is encoded as 2v for the positive and 2v+1 for the
+// negative literal.  The first two literals of each clause with at least two
+// literals are watched for unit propagation.
+type projectedCounter struct {
+	hdl         handler.Handler
+	state       handler.State
+	projected   []bool
+	clauses     [][]int32
+	occurrences [][]int32
+	watches     [][]int32
+	values      []int8 // 1 = true, -1 = false, 0 = unassigned
+	trail       []int32
+	cache       *componentCache
+	contradict  bool
+
+	stamp        int32
+	varStamps    []int32
+	clauseStamps []int32
+}
+
+// component is a set of unassigned variables together with the unsatisfied
+// clauses over them which share no variables with other components.
+type component struct {
+	vars      []int32
+	clauses   []int32
+	projected int
+}
+
+func newProjectedCounter(fac f.Factory, cnf f.Formula, variables []f.Variable, hdl handler.Handler) *projectedCounter {
+	c := &projectedCounter{hdl: hdl, state: succ, cache: newComponentCache(maxCacheBytes)}
+	indices := make(map[f.Variable]int32)
+	index := func(variable f.Variable) int32 {
+		if i, ok := indices[variable]; ok {
+			return i
+		}
+		i := int32(len(c.projected))
+		indices[variable] = i
+		c.projected = append(c.projected, false)
+		return i
+	}
+	for _, variable := range variables {
+		c.projected[index(variable)] = true
+	}
+	var clauses []f.Formula
+	switch cnf.Sort() {
+	case f.SortTrue:
+	case f.SortFalse:
+		c.contradict = true
+	case f.SortAnd:
+		clauses = fac.Operands(cnf)
+	default:
+		clauses = []f.Formula{cnf}
+	}
+	for _, clause := range clauses {
+		var lits []int32
+		tautology := false
+		for _, literal := range f.Literals(fac, clause).Content() {
+			lit := 2 * index(literal.Variable())
+			if !literal.IsPos() {
+				lit++
+			}
+			if slices.Contains(lits, lit^1) {
+				tautology = true
+				break
+			}
+			lits = append(lits, lit)
+		}
+		if !tautology {
+			c.clauses = append(c.clauses, lits)
+		}
+	}
+	numVars := len(c.projected)
+	c.values = make([]int8, numVars)
+	c.varStamps = make([]int32, numVars)
+	c.clauseStamps = make([]int32, len(c.clauses))
+	c.occurrences = make([][]int32, 2*numVars)
+	c.watches = make([][]int32, 2*numVars)
+	for i, clause := range c.clauses {
+		for _, lit := range clause {
+			c.occurrences[lit] = append(c.occurrences[lit], int32(i))
+		}
+		if len(clause) > 1 {
+			c.watches[clause[0]] = append(c.watches[clause[0]], int32(i))
+			c.watches[clause[1]] = append(c.watches[clause[1]], int32(i))
+		}
+	}
+	return c
+}
+
+func (c *projectedCounter) count() (*big.Int, handler.State) {
+	if c.contradict {
+		return big.NewInt(0), succ
+	}
+	for _, clause := range c.clauses {
+		if len(clause) == 1 && !c.assign(clause[0]) {
+			return big.NewInt(0), succ
+		}
+	}
+	if !c.propagate(0) {
+		return big.NewInt(0), succ
+	}
+	vars := make([]int32, len(c.values))
+	for i := range vars {
+		vars[i] = int32(i)
+	}
+	result := c.countVariables(vars)
+	if result == nil {
+		return nil, c.state
+	}
+	return result, succ
+}
+
+// countVariables counts the projected models over the given variables under
+// the current assignment.  Returns nil if the computation was canceled.
+func (c *projectedCounter) countVariables(vars []int32) *big.Int {
+	components, free := c.components(vars)
+	result := new(big.Int).Lsh(big.NewInt(1), uint(free))
+	slices.SortFunc(components, func(c1, c2 *component) int { return len(c1.vars) - len(c2.vars) })
+	for _, comp := range components {
+		count := c.countComponent(comp)
+		if count == nil || count.Sign() == 0 {
+			return count
+		}
+		result.Mul(result, count)
+	}
+	return result
+}
+
+// countComponent counts the projected models of the component.  If the
+// component has no projected variables, its count is 1 if it is satisfiable
+// and 0 otherwise.  Returns nil if the computation was canceled.
+func (c *projectedCounter) countComponent(comp *component) *big.Int {
+	key := comp.key()
+	if cached, ok := c.cache.get(key); ok {
+		return new(big.Int).Set(cached)
+	}
+	if e := event.ModelCountingDecision; !c.hdl.ShouldResume(e) {
+		c.state = handler.Cancelation(e)
+		return nil
+	}
+	decision := c.decisionVariable(comp)
+	result := big.NewInt(0)
+	for _, lit := range []int32{2 * decision, 2*decision + 1} {
+		mark := len(c.trail)
+		if c.assign(lit) && c.propagate(mark) && c.probe(comp.vars) {
+			count := c.countVariables(comp.vars)
+			if count == nil {
+				c.undo(mark)
+				return nil
+			}
+			result.Add(result, count)
+		}
+		c.undo(mark)
+		if comp.projected == 0 && result.Sign() > 0 {
+			break
+		}
+	}
+	c.cache.put(key, new(big.Int).Set(result))
+	return result
+}
+
+// decisionVariable returns the variable of the component with the most
+// occurrences in its clauses.  As long as the component contains projected
+// variables, only those are considered, since the solver may only branch on
+// a non-projected variable if all projected variables are assigned.
+func (c *projectedCounter) decisionVariable(comp *component) int32 {
+	occurrences := make(map[int32]int, len(comp.vars))
+	for _, cid := range comp.clauses {
+		for _, lit := range c.clauses[cid] {
+			v := lit >> 1
+			if c.values[v] == 0 && (comp.projected == 0 || c.projected[v]) {
+				occurrences[v]++
+			}
+		}
+	}
+	best, bestOccurrences := int32(-1), -1
+	for _, v := range comp.vars {
+		if occ, ok := occurrences[v]; ok && occ > bestOccurrences {
+			best, bestOccurrences = v, occ
+		}
+	}
+	return best
+}
+
+// components splits the unassigned variables of the given variables into
+// components.  It returns the components and the number of projected
+// variables which do not occur in an unsatisfied clause.
+func (c *projectedCounter) components(vars []int32) ([]*component, int) {
+	c.stamp++
+	var components []*component
+	free := 0
+	for _, start := range vars {
+		if c.values[start] != 0 || c.varStamps[start] == c.stamp {
+			continue
+		}
+		comp := &component{}
+		c.varStamps[start] = c.stamp
+		queue := []int32{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			comp.vars = append(comp.vars, v)
+			if c.projected[v] {
+				comp.projected++
+			}
+			for _, lit := range []int32{2 * v, 2*v + 1} {
+				for _, cid := range c.occurrences[lit] {
+					if c.clauseStamps[cid] == c.stamp {
+						continue
+					}
+					c.clauseStamps[cid] = c.stamp
+					if c.isSatisfied(cid) {
+						continue
+					}
+					comp.clauses = append(comp.clauses, cid)
+					for _, other := range c.clauses[cid] {
+						w := other >> 1
+						if c.values[w] == 0 && c.varStamps[w] != c.stamp {
+							c.varStamps[w] = c.stamp
+							queue = append(queue, w)
+						}
+					}
+				}
+			}
+		}
+		if len(comp.clauses) == 0 {
+			if comp.projected > 0 {
+				free++
+			}
+			continue
+		}
+		components = append(components, comp)
+	}
+	return components, free
+}
+
+// probe performs failed literal detection on the variables which occur in a
+// binary clause: if a literal leads to a conflict by unit propagation, its
+// negation is implied and assigned.  Returns false if a conflict is found.
+func (c *projectedCounter) probe(vars []int32) bool {
+	for changed := true; changed; {
+		changed = false
+		for _, v := range vars {
+			if c.values[v] != 0 || !c.inBinaryClause(v) {
+				continue
+			}
+			for _, lit := range []int32{2 * v, 2*v + 1} {
+				mark := len(c.trail)
+				consistent := c.assign(lit) && c.propagate(mark)
+				c.undo(mark)
+				if !consistent {
+					if !c.assign(lit^1) || !c.propagate(mark) {
+						return false
+					}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (c *projectedCounter) inBinaryClause(v int32) bool {
+	for _, lit := range []int32{2 * v, 2*v + 1} {
+		for _, cid := range c.occurrences[lit] {
+			if c.unassignedIfUnsatisfied(cid) == 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *projectedCounter) value(lit int32) int8 {
+	if lit&1 == 1 {
+		return -c.values[lit>>1]
+	}
+	return c.values[lit>>1]
+}
+
+func (c *projectedCounter) assign(lit int32) bool {
+	switch c.value(lit) {
+	case 1:
+		return true
+	case -1:
+		return false
+	}
+	if lit&1 == 1 {
+		c.values[lit>>1] = -1
+	} else {
+		c.values[lit>>1] = 1
+	}
+	c.trail = append(c.trail, lit)
+	return true
+}
+
+func (c *projectedCounter) undo(mark int) {
+	for _, lit := range c.trail[mark:] {
+		c.values[lit>>1] = 0
+	}
+	c.trail = c.trail[:mark]
+}
+
+// propagate performs unit propagation for the literals on the trail from the
+// given position on.  Only the clauses which watch the negation of a literal
+// are visited, a clause is moved to the watch list of another literal which
+// is not false if possible.  Since the watched literals of a clause remain
+// valid if assignments are undone, backtracking does not change the watches.
+// Returns false if a conflict is found.
+func (c *projectedCounter) propagate(from int) bool {
+	for i := from; i < len(c.trail); i++ {
+		falseLit := c.trail[i] ^ 1
+		watches := c.watches[falseLit]
+		kept := 0
+		for j, cid := range watches {
+			clause := c.clauses[cid]
+			if clause[0] == falseLit {
+				clause[0], clause[1] = clause[1], clause[0]
+			}
+			if c.value(clause[0]) == 1 {
+				watches[kept] = cid
+				kept++
+				continue
+			}
+			moved := false
+			for k := 2; k < len(clause); k++ {
+				if c.value(clause[k]) != -1 {
+					clause[1], clause[k] = clause[k], clause[1]
+					c.watches[clause[1]] = append(c.watches[clause[1]], cid)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+			watches[kept] = cid
+			kept++
+			if !c.assign(clause[0]) {
+				kept += copy(watches[kept:], watches[j+1:])
+				c.watches[falseLit] = watches[:kept]
+				return false
+			}
+		}
+		c.watches[falseLit] = watches[:kept]
+	}
+	return true
+}
+
+func (c *projectedCounter) isSatisfied(cid int32) bool {
+	return c.unassignedIfUnsatisfied(cid) < 0
+}
+
+// unassignedIfUnsatisfied returns the number of unassigned literals of the
+// clause or -1 if the clause is satisfied.
+func (c *projectedCounter) unassignedIfUnsatisfied(cid int32) int {
+	unassigned := 0
+	for _, lit := range c.clauses[cid] {
+		switch c.value(lit) {
+		case 1:
+			return -1
+		case 0:
+			unassigned++
+		}
+	}
+	return unassigned
+}
+
+// key returns the cache key of the component.  The variables and clauses of
+// a component determine its residual formula: the literals of its clauses
+// which are not over its variables are false.
+func (comp *component) key() string {
+	slices.Sort(comp.vars)
+	slices.Sort(comp.clauses)
+	key := make([]byte, 0, 4*(len(comp.vars)+len(comp.clauses)+1))
+	key = binary.AppendUvarint(key, uint64(len(comp.vars)))
+	for _, v := range comp.vars {
+		key = binary.AppendUvarint(key, uint64(v))
+	}
+	for _, cid := range comp.clauses {
+		key = binary.AppendUvarint(key, uint64(cid))
+	}
+	return string(key)
+}
+
+// componentCache caches the counts of components.  Its size is bounded by an
+// approximate number of bytes, if it is exceeded, the least recently used
+// components are evicted.
+type componentCache struct {
+	limit   int
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type cacheEntry struct {
+	key   string
+	count *big.Int
+}
+
+func newComponentCache(limit int) *componentCache {
+	return &componentCache{limit: limit, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *componentCache) get(key string) (*big.Int, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return element.Value.(*cacheEntry).count, true
+}
+
+func (c *componentCache) put(key string, count *big.Int) {
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	entry := &cacheEntry{key, count}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += entry.bytes()
+	for c.size > c.limit && c.lru.Len() > 1 {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.size -= oldest.Value.(*cacheEntry).bytes()
+	}
+}
+
+// bytes returns the approximate number of bytes of the entry including the
+// overhead of the map and the list.
+func (e *cacheEntry) bytes() int {
+	return len(e.key) + 8*len(e.count.Bits()) + 128
+}
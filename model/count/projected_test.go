@@ -0,0 +1,126 @@
+package count
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/normalform"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectedCountSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+
+	assert.Equal(big.NewInt(0), ProjectedCount(fac, []f.Variable{a}, fac.Falsum()))
+	assert.Equal(big.NewInt(1), ProjectedCount(fac, nil, fac.Verum()))
+	assert.Equal(big.NewInt(4), ProjectedCount(fac, []f.Variable{a, b}, fac.Verum()))
+	assert.Equal(big.NewInt(0), ProjectedCount(fac, []f.Variable{a}, p.ParseUnsafe("a & ~a")))
+
+	formula := p.ParseUnsafe("(a | b) & (~a | c)")
+	assert.Equal(big.NewInt(4), ProjectedCount(fac, []f.Variable{a, b, c}, formula))
+	assert.Equal(big.NewInt(3), ProjectedCount(fac, []f.Variable{a, b}, formula))
+	assert.Equal(big.NewInt(2), ProjectedCount(fac, []f.Variable{a}, formula))
+	assert.Equal(big.NewInt(1), ProjectedCount(fac, nil, formula))
+	assert.Equal(big.NewInt(6), ProjectedCount(fac, []f.Variable{a, b, fac.Var("x")}, formula))
+
+	cc := p.ParseUnsafe("a + b + c + d + e <= 2")
+	vars := f.Variables(fac, cc).Content()
+	assert.Equal(big.NewInt(16), ProjectedCount(fac, vars, cc))
+	assert.Equal(big.NewInt(0), ProjectedCount(fac, nil, sat.GeneratePigeonHole(fac, 5)))
+}
+
+func TestProjectedCountQueens(t *testing.T) {
+	fac := f.NewFactory()
+	for size, models := range map[int]int64{4: 2, 5: 10, 6: 4, 7: 40, 8: 92} {
+		queens := sat.GenerateNQueens(fac, size)
+		count := ProjectedCount(fac, f.Variables(fac, queens).Content(), queens)
+		assert.Equal(t, big.NewInt(models), count)
+	}
+}
+
+func TestProjectedCountRandom(t *testing.T) {
+	fac := f.NewFactory()
+	numTests := 300
+	if testing.Short() {
+		numTests = 50
+	}
+	for i := 0; i < numTests; i++ {
+		config := randomizer.DefaultConfig()
+		config.NumVars = 10
+		config.Seed = int64(i * 17)
+		formula := randomizer.New(fac, config).Formula(4)
+		vars := f.Variables(fac, formula).Content()
+		projection := vars[:len(vars)/2]
+		expected := OnFormula(fac, formula, projection)
+		assert.Equal(t, expected, ProjectedCount(fac, projection, formula))
+		assert.Equal(t, OnFormula(fac, formula, vars), ProjectedCount(fac, vars, formula))
+	}
+}
+
+func TestProjectedCountTseitin(t *testing.T) {
+	fac := f.NewFactory()
+	config := normalform.DefaultCNFConfig()
+	config.Algorithm = normalform.CNFTseitin
+	fac.PutConfiguration(config)
+	randomizerConfig := randomizer.DefaultConfig()
+	randomizerConfig.NumVars = 12
+	randomizerConfig.Seed = 4711
+	r := randomizer.New(fac, randomizerConfig)
+	for i := 0; i < 20; i++ {
+		formula := r.Formula(5)
+		vars := f.Variables(fac, formula).Content()
+		assert.Equal(t, OnFormula(fac, formula, vars), ProjectedCount(fac, vars, formula))
+	}
+}
+
+func TestProjectedCountBoundedCache(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 7)
+	vars := f.Variables(fac, queens).Content()
+	expected := OnFormula(fac, queens, vars[:20])
+	for _, limit := range []int{0, 2000, 20000} {
+		counter := newProjectedCounter(fac, normalform.CNF(fac, queens), vars[:20], handler.NopHandler)
+		counter.cache = newComponentCache(limit)
+		count, state := counter.count()
+		assert.True(state.Success)
+		assert.Equal(expected, count)
+		assert.Equal(counter.cache.lru.Len(), len(counter.cache.entries))
+		if limit == 0 {
+			assert.LessOrEqual(counter.cache.lru.Len(), 1)
+		} else {
+			assert.LessOrEqual(counter.cache.size, limit)
+		}
+	}
+}
+
+func TestProjectedCountHandler(t *testing.T) {
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 8)
+	hdl := &decisionLimitHandler{limit: 10}
+	count, state := ProjectedCountWithHandler(fac, f.Variables(fac, queens).Content(), hdl, queens)
+	assert.Nil(t, count)
+	assert.False(t, state.Success)
+	assert.Equal(t, event.ModelCountingDecision, state.CancelCause)
+}
+
+type decisionLimitHandler struct {
+	limit     int
+	decisions int
+}
+
+func (h *decisionLimitHandler) ShouldResume(e event.Event) bool {
+	if e == event.ModelCountingDecision {
+		h.decisions++
+	}
+	return h.decisions <= h.limit
+}
@@ -48,6 +48,26 @@ func OnSolverWithConfig(solver *sat.Solver, variables []f.Variable, config *iter
 	return me.Iterate(solver, newModelCountCollector, big.NewInt(0))
 }
 
+// OnFormulaParallel counts all models of a formula over the given variables
+// like OnFormulaWithConfig, but distributes the split assignments of the
+// config's strategy over the given number of workers which count their
+// models in parallel.  Each worker uses its own copy of the factory and its
+// own SAT solver, the handler of the config is shared by all workers.
+func OnFormulaParallel(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	workers int,
+	config *iter.Config,
+) (*big.Int, handler.State) {
+	if config == nil {
+		config = iter.DefaultConfig()
+	}
+	me := iter.New[*big.Int](f.NewVarSet(variables...), nil, config)
+	merge := func(count, other *big.Int) *big.Int { return count.Add(count, other) }
+	return me.IterateParallel(fac, []f.Formula{formula}, workers, newModelCountCollector, merge, big.NewInt(0))
+}
+
 type modelCountCollector struct {
 	committedCount     *big.Int
 	uncommittedModels  [][]bool
@@ -156,3 +156,23 @@ func TestMERandom(t *testing.T) {
 		}
 	}
 }
+
+func TestMCParallel(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	for _, cfg := range cfgs {
+		for i := range 20 {
+			config := randomizer.DefaultConfig()
+			config.Seed = int64(i)
+			config.NumVars = 20
+			randomizer := randomizer.New(fac, config)
+			formula := normalform.CNF(fac, randomizer.Formula(2))
+			vars := f.Variables(fac, formula).Content()
+
+			count, state := OnFormulaParallel(fac, formula, vars, 4, cfg)
+			exp, _ := Count(fac, vars, formula)
+			assert.True(state.Success)
+			assert.Equal(exp, count)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package enum
+
+import (
+	"math/big"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/primeimplicant"
+	"github.com/booleworks/logicng-go/sat"
+)
+
+// A Cube is a partial model of an enumeration.  It assigns the variables of
+// its literals and leaves its don't care variables unassigned.  Therefore,
+// it represents all 2^k full models over the enumerated variables which
+// extend its literals, where k is the number of don't care variables.
+type Cube struct {
+	Literals  []f.Literal
+	DontCares []f.Variable
+}
+
+// ModelCount returns the number of full models covered by the cube.
+func (c *Cube) ModelCount() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(len(c.DontCares)))
+}
+
+// Models returns all full models covered by the cube.
+func (c *Cube) Models(fac f.Factory) []*model.Model {
+	combinations := getCartesianProduct(fac, f.NewVarSet(c.DontCares...))
+	models := make([]*model.Model, len(combinations))
+	for i, dontCares := range combinations {
+		literals := make([]f.Literal, 0, len(c.Literals)+len(dontCares))
+		literals = append(literals, c.Literals...)
+		literals = append(literals, dontCares...)
+		models[i] = model.New(literals...)
+	}
+	return models
+}
+
+// Formula returns the cube as a conjunction of its literals.
+func (c *Cube) Formula(fac f.Factory) f.Formula {
+	return fac.Minterm(c.Literals...)
+}
+
+// CubesOnFormula enumerates all models of a formula over the given
+// variables as cubes.  Variables which do not occur in the formula are not
+// expanded but returned as don't care variables of the cubes.  The cubes are
+// pairwise disjoint.
+func CubesOnFormula(fac f.Factory, formula f.Formula, variables []f.Variable) []*Cube {
+	cubes, _ := CubesOnFormulaWithConfig(fac, formula, variables, iter.DefaultConfig())
+	return cubes
+}
+
+// CubesOnFormulaWithConfig enumerates all models of a formula over the given
+// variables as cubes like CubesOnFormula.  The config can be used to
+// influence the model iteration process by setting a handler and/or an
+// iteration strategy.
+func CubesOnFormulaWithConfig(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	config *iter.Config,
+) ([]*Cube, handler.State) {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	return CubesOnSolverWithConfig(solver, variables, config)
+}
+
+// CubesOnSolver enumerates all models on the given SAT solver over the given
+// variables as cubes.  Variables which are not known to the solver are not
+// expanded but returned as don't care variables of the cubes.  The cubes are
+// pairwise disjoint.
+func CubesOnSolver(solver *sat.Solver, variables []f.Variable) []*Cube {
+	cubes, _ := CubesOnSolverWithConfig(solver, variables, iter.DefaultConfig())
+	return cubes
+}
+
+// CubesOnSolverWithConfig enumerates all models on the given SAT solver over
+// the given variables as cubes like CubesOnSolver.  The config can be used to
+// influence the model iteration process by setting a handler and/or an
+// iteration strategy.
+func CubesOnSolverWithConfig(
+	solver *sat.Solver,
+	variables []f.Variable,
+	config *iter.Config,
+) ([]*Cube, handler.State) {
+	if config == nil {
+		config = iter.DefaultConfig()
+	}
+	me := iter.New[[]*Cube](f.NewVarSet(variables...), nil, config)
+	return me.Iterate(solver, newCubeCollector, make([]*Cube, 0))
+}
+
+// PrimeCubesOnFormula computes a cube cover of all models of a formula over
+// the given variables.  Each model found on the SAT solver is enlarged to a
+// prime implicant of the formula, which is then projected to the given
+// variables and blocked as a whole.  Therefore, the result is usually much
+// smaller than the result of CubesOnFormula, but the cubes may overlap.
+// Their union is exactly the set of models of the formula over the given
+// variables.
+func PrimeCubesOnFormula(fac f.Factory, formula f.Formula, variables []f.Variable) []*Cube {
+	cubes, _ := PrimeCubesOnFormulaWithHandler(fac, formula, variables, handler.NopHandler)
+	return cubes
+}
+
+// PrimeCubesOnFormulaWithHandler computes a cube cover of all models of a
+// formula over the given variables like PrimeCubesOnFormula.  The given
+// handler can be used to cancel the computation, a handler which limits the
+// number of models limits the number of cubes.
+//
+// In contrast to the other enumeration functions, no split strategy can be
+// configured: a prime cube usually does not contain the literals of a split
+// assignment, so the models are never split.
+func PrimeCubesOnFormulaWithHandler(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	hdl handler.Handler,
+) ([]*Cube, handler.State) {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	enumVars := f.NewVarSet(variables...)
+	newCollector := func(fac f.Factory, knownVars, dontCaresNotOnSolver, _ *f.VarSet) iter.Collector[[]*Cube] {
+		collector := newCubeCollector(fac, knownVars, dontCaresNotOnSolver, nil).(*cubeCollector)
+		collector.variables = enumVars
+		collector.formulaVars = f.Variables(fac, formula)
+		collector.reduction = primeimplicant.NewImplicantReduction(fac, formula)
+		return collector
+	}
+	config := &iter.Config{Handler: hdl, Strategy: iter.NewNoSplitMEStrategy()}
+	me := iter.New[[]*Cube](enumVars, nil, config)
+	return me.Iterate(solver, newCollector, make([]*Cube, 0))
+}
+
+type cubeCollector struct {
+	committedCubes       []*Cube
+	uncommittedCubes     []*Cube
+	dontCaresNotOnSolver []f.Variable
+
+	// only set for prime cubes
+	variables      *f.VarSet
+	formulaVars    *f.VarSet
+	formulaIndices []int32
+	reduction      *primeimplicant.ImplicantReduction
+}
+
+func newCubeCollector(_ f.Factory, _, dontCaresNotOnSolver, _ *f.VarSet) iter.Collector[[]*Cube] {
+	return &cubeCollector{
+		committedCubes:       []*Cube{},
+		uncommittedCubes:     []*Cube{},
+		dontCaresNotOnSolver: dontCaresNotOnSolver.Content(),
+	}
+}
+
+func (c *cubeCollector) AddModel(
+	modelFromSolver []bool, solver *sat.Solver, relevantAllIndices []int32, hdl handler.Handler,
+) handler.State {
+	e := iter.EventIteratorFoundModels{NumberOfModels: 1}
+	var cube *Cube
+	if c.reduction == nil {
+		mdl := solver.CoreSolver().CreateModel(solver.Factory(), modelFromSolver, relevantAllIndices)
+		cube = &Cube{mdl.Literals, c.dontCaresNotOnSolver}
+	} else {
+		cube = c.primeCube(modelFromSolver, solver, relevantAllIndices)
+	}
+	c.uncommittedCubes = append(c.uncommittedCubes, cube)
+	if !hdl.ShouldResume(e) {
+		return handler.Cancelation(e)
+	}
+	return succ
+}
+
+// primeCube enlarges the model to a prime implicant of the formula, projects
+// it to the relevant variables and blocks the resulting cube on the solver.
+// The iteration removes the blocking clause with the next rollback of the
+// solver's state.
+func (c *cubeCollector) primeCube(modelFromSolver []bool, solver *sat.Solver, relevantAllIndices []int32) *Cube {
+	fac := solver.Factory()
+	if c.formulaIndices == nil {
+		c.formulaIndices = make([]int32, 0, c.formulaVars.Size())
+		for _, variable := range c.formulaVars.Content() {
+			name, _ := fac.VarName(variable)
+			c.formulaIndices = append(c.formulaIndices, solver.CoreSolver().IdxForName(name))
+		}
+	}
+	relevantVars := f.NewMutableVarSet()
+	for _, literal := range solver.CoreSolver().CreateModel(fac, modelFromSolver, relevantAllIndices).Literals {
+		relevantVars.Add(literal.Variable())
+	}
+	fullModel := solver.CoreSolver().CreateModel(fac, modelFromSolver, c.formulaIndices)
+	prime := c.reduction.Reduce(fullModel.Literals)
+
+	cube := &Cube{}
+	cubeVars := f.NewMutableVarSet()
+	blockingClause := make([]int32, 0, len(prime))
+	for _, literal := range prime {
+		if relevantVars.Contains(literal.Variable()) {
+			cube.Literals = append(cube.Literals, literal)
+			cubeVars.Add(literal.Variable())
+			name, _ := fac.VarName(literal.Variable())
+			index := solver.CoreSolver().IdxForName(name)
+			if literal.IsPos() {
+				blockingClause = append(blockingClause, (index*2)^1)
+			} else {
+				blockingClause = append(blockingClause, index*2)
+			}
+		}
+	}
+	for _, variable := range c.variables.Content() {
+		if !cubeVars.Contains(variable) {
+			cube.DontCares = append(cube.DontCares, variable)
+		}
+	}
+	solver.CoreSolver().AddClause(blockingClause, nil)
+	return cube
+}
+
+func (c *cubeCollector) Commit(hdl handler.Handler) handler.State {
+	c.committedCubes = append(c.committedCubes, c.uncommittedCubes...)
+	c.uncommittedCubes = make([]*Cube, 0, 4)
+	if e := event.ModelEnumerationCommit; !hdl.ShouldResume(e) {
+		return handler.Cancelation(e)
+	}
+	return succ
+}
+
+func (c *cubeCollector) Rollback(hdl handler.Handler) handler.State {
+	c.uncommittedCubes = make([]*Cube, 0, 4)
+	if e := event.ModelEnumerationRollback; !hdl.ShouldResume(e) {
+		return handler.Cancelation(e)
+	}
+	return succ
+}
+
+func (c *cubeCollector) RollbackAndReturnModels(_ *sat.Solver, hdl handler.Handler) ([]*model.Model, handler.State) {
+	modelsToReturn := make([]*model.Model, len(c.uncommittedCubes))
+	for i, cube := range c.uncommittedCubes {
+		modelsToReturn[i] = model.New(cube.Literals...)
+	}
+	state := c.Rollback(hdl)
+	return modelsToReturn, state
+}
+
+func (c *cubeCollector) Result() []*Cube {
+	return c.committedCubes
+}
@@ -0,0 +1,136 @@
+package enum
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/normalform"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCubesDontCares(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("A & (B | C)")
+	vars := fac.Vars("A", "B", "C", "X", "Y")
+	for _, cfg := range cfgs {
+		cubes, state := CubesOnFormulaWithConfig(fac, formula, vars, cfg)
+		assert.True(state.Success)
+		assert.Equal(3, len(cubes))
+		for _, cube := range cubes {
+			assert.Equal(3, len(cube.Literals))
+			assert.Equal(fac.Vars("X", "Y"), cube.DontCares)
+			assert.Equal(big.NewInt(4), cube.ModelCount())
+		}
+		assert.ElementsMatch(modelStrings(fac, OnFormula(fac, formula, vars)), cubeModelStrings(fac, cubes))
+	}
+}
+
+func TestCubesContradictionTautology(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	vars := fac.Vars("A", "B")
+	assert.Empty(CubesOnFormula(fac, fac.Falsum(), vars))
+	assert.Empty(PrimeCubesOnFormula(fac, fac.Falsum(), vars))
+
+	cubes := PrimeCubesOnFormula(fac, fac.Verum(), vars)
+	assert.Equal(1, len(cubes))
+	assert.Empty(cubes[0].Literals)
+	assert.Equal(big.NewInt(4), cubes[0].ModelCount())
+}
+
+func TestPrimeCubesSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(A | B) & (C | D | E)")
+	vars := f.Variables(fac, formula).Content()
+	cubes := PrimeCubesOnFormula(fac, formula, vars)
+	assert.LessOrEqual(len(cubes), 6)
+	for _, cube := range cubes {
+		assert.Equal(2, len(cube.Literals))
+		assert.Equal(3, len(cube.DontCares))
+	}
+	assert.ElementsMatch(modelStrings(fac, OnFormula(fac, formula, vars)), cubeModelStrings(fac, cubes))
+}
+
+func TestPrimeCubesRandom(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	for _, cfg := range cfgs {
+		for i := range 50 {
+			config := randomizer.DefaultConfig()
+			config.Seed = int64(i)
+			config.NumVars = 12
+			randomizer := randomizer.New(fac, config)
+			formula := randomizer.Formula(3)
+			varsFormula := f.Variables(fac, formula).Content()
+			pmeVars := varsFormula[:len(varsFormula)*2/3]
+
+			models := OnFormula(fac, formula, pmeVars)
+			cubes := PrimeCubesOnFormula(fac, formula, pmeVars)
+			assert.LessOrEqual(len(cubes), len(models))
+			assert.ElementsMatch(modelStrings(fac, models), cubeModelStrings(fac, cubes))
+
+			disjointCubes, _ := CubesOnFormulaWithConfig(fac, formula, pmeVars, cfg)
+			count := big.NewInt(0)
+			for _, cube := range disjointCubes {
+				count.Add(count, cube.ModelCount())
+			}
+			assert.Equal(int64(len(models)), count.Int64())
+		}
+	}
+}
+
+func TestPrimeCubeDNF(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	assert.Equal(fac.Falsum(), PrimeCubeDNF(fac, fac.Falsum()))
+	assert.Equal(fac.Verum(), PrimeCubeDNF(fac, fac.Verum()))
+	assert.Equal(p.ParseUnsafe("a & b"), PrimeCubeDNF(fac, p.ParseUnsafe("a & b")))
+	assert.ElementsMatch(fac.Vars("a", "b"), f.Variables(fac, PrimeCubeDNF(fac, p.ParseUnsafe("a | b"))).Content())
+	assert.Equal(2, len(fac.Operands(PrimeCubeDNF(fac, p.ParseUnsafe("a | b")))))
+	assert.Equal(2, len(fac.Operands(PrimeCubeDNF(fac, p.ParseUnsafe("(a | b) & (a | c) & (b | ~c)")))))
+
+	for i := range 50 {
+		config := randomizer.DefaultConfig()
+		config.Seed = int64(i)
+		config.NumVars = 10
+		formula := randomizer.New(fac, config).Formula(4)
+		dnf := PrimeCubeDNF(fac, formula)
+		assert.True(normalform.IsDNF(fac, dnf))
+		solver := sat.NewSolver(fac)
+		solver.Add(fac.Not(fac.Equivalence(formula, dnf)))
+		assert.False(solver.Sat())
+	}
+}
+
+func TestPrimeCubesWithLimit(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(A | B) & (C | D | E)")
+	cubes, state := PrimeCubesOnFormulaWithHandler(fac, formula, f.Variables(fac, formula).Content(), iter.HandlerWithLimit(2))
+	assert.False(state.Success)
+	assert.NotEqual(event.Nothing, state.CancelCause)
+	assert.Equal(2, len(cubes))
+}
+
+func cubeModelStrings(fac f.Factory, cubes []*Cube) []string {
+	var result []string
+	for _, cube := range cubes {
+		result = append(result, modelStrings(fac, cube.Models(fac))...)
+	}
+	// overlapping cubes cover some models multiple times
+	slices.Sort(result)
+	return slices.Compact(result)
+}
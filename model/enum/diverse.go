@@ -0,0 +1,161 @@
+package enum
+
+import (
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/maxsat"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/iter"
+)
+
+// DiverseConfig describes the configuration of a diverse model enumeration.
+// Each model has at least the Hamming distance MinDistance to all previously
+// found models, a minimum distance smaller than 1 is treated as 1.  The
+// Algorithm and the MaxSatConfig are used for the MAX-SAT solvers of the
+// enumeration, the algorithm must support unweighted problems, i.e. it must
+// not be AlgWMSU3.  The handler can be used to cancel the enumeration.
+type DiverseConfig struct {
+	MinDistance  int
+	Algorithm    maxsat.Algorithm
+	MaxSatConfig *maxsat.Config
+	Handler      handler.Handler
+}
+
+// DefaultDiverseConfig returns the default configuration for a diverse model
+// enumeration: the models must differ in at least one variable and are
+// computed with the OLL algorithm.
+func DefaultDiverseConfig() *DiverseConfig {
+	return &DiverseConfig{
+		MinDistance:  1,
+		Algorithm:    maxsat.AlgOLL,
+		MaxSatConfig: maxsat.DefaultConfig(),
+		Handler:      handler.NopHandler,
+	}
+}
+
+// DiverseOnFormula enumerates up to n models of a formula over the given
+// variables which differ as much as possible.  The difference of two models
+// is their Hamming distance, i.e. the number of variables they assign
+// differently.  Variables which do not occur in the formula are treated like
+// all other variables.
+//
+// The models are computed greedily: the first model is an arbitrary model of
+// the formula, each further model maximizes the sum of its distances to all
+// previously found models under the restriction that its distance to each of
+// them is at least the configured minimum distance.  Each step solves a
+// MAX-SAT problem, therefore the result is not necessarily the set of n
+// models with the largest pairwise distances, but a good approximation of it.
+// Fewer than n models are returned if no further model with the minimum
+// distance exists.
+//
+// If the handler cancels the computation, the models found so far are
+// returned together with the cancellation.  Returns an error if n is
+// negative or if the configured algorithm does not support unweighted
+// problems.
+func DiverseOnFormula(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	n int,
+	config ...*DiverseConfig,
+) ([]*model.Model, handler.State, error) {
+	cfg := determineDiverseConfig(config)
+	if n < 0 {
+		return nil, succ, errorx.BadInput("number of models must not be negative: %d", n)
+	}
+	minDistance := max(cfg.MinDistance, 1)
+	result := make([]*model.Model, 0, n)
+	if formula.Sort() == f.SortFalse {
+		return result, succ, nil
+	}
+	for len(result) < n {
+		solver, err := newMaxSatSolver(fac, cfg)
+		if err != nil {
+			return nil, succ, err
+		}
+		solver.AddHardFormula(formula)
+		for _, previous := range result {
+			differentLiterals := make([]f.Literal, len(previous.Literals))
+			for i, literal := range previous.Literals {
+				differentLiterals[i] = literal.Negate(fac)
+				if err := solver.AddSoftFormula(differentLiterals[i].AsFormula(), 1); err != nil {
+					return nil, succ, err
+				}
+			}
+			solver.AddHardFormula(fac.PBC(f.GE, minDistance, differentLiterals, ones(len(differentLiterals))))
+		}
+		maxSatResult, state := solver.SolveWithHandler(cfg.Handler)
+		if !state.Success {
+			return result, state, nil
+		} else if !maxSatResult.Satisfiable {
+			break
+		}
+		result = append(result, projectModel(fac, maxSatResult.Model, variables))
+		if e := (iter.EventIteratorFoundModels{NumberOfModels: 1}); !cfg.Handler.ShouldResume(e) {
+			return result, handler.Cancelation(e), nil
+		}
+	}
+	return result, succ, nil
+}
+
+func determineDiverseConfig(config []*DiverseConfig) *DiverseConfig {
+	if len(config) > 0 && config[0] != nil {
+		return config[0]
+	}
+	return DefaultDiverseConfig()
+}
+
+// newMaxSatSolver returns a new MAX-SAT solver for the configured algorithm.
+// AlgWMSU3 is rejected since it does not support unweighted problems.
+func newMaxSatSolver(fac f.Factory, cfg *DiverseConfig) (*maxsat.Solver, error) {
+	maxSatConfig := cfg.MaxSatConfig
+	if maxSatConfig == nil {
+		maxSatConfig = maxsat.DefaultConfig()
+	}
+	switch cfg.Algorithm {
+	case maxsat.AlgWBO:
+		return maxsat.WBO(fac, maxSatConfig), nil
+	case maxsat.AlgIncWBO:
+		return maxsat.IncWBO(fac, maxSatConfig), nil
+	case maxsat.AlgLinearSU:
+		return maxsat.LinearSU(fac, maxSatConfig), nil
+	case maxsat.AlgLinearUS:
+		return maxsat.LinearUS(fac, maxSatConfig), nil
+	case maxsat.AlgMSU3:
+		return maxsat.MSU3(fac, maxSatConfig), nil
+	case maxsat.AlgWMSU3:
+		return nil, errorx.BadInput("algorithm %s does not support unweighted problems", cfg.Algorithm)
+	case maxsat.AlgOLL:
+		return maxsat.OLL(fac, maxSatConfig), nil
+	default:
+		return nil, errorx.UnknownEnumValue(cfg.Algorithm)
+	}
+}
+
+// projectModel projects the model to the given variables.  Variables which
+// are not assigned by the model do not occur on the solver and are assigned
+// false.
+func projectModel(fac f.Factory, mdl *model.Model, variables []f.Variable) *model.Model {
+	assignment := make(map[f.Variable]f.Literal, len(mdl.Literals))
+	for _, literal := range mdl.Literals {
+		assignment[literal.Variable()] = literal
+	}
+	literals := make([]f.Literal, len(variables))
+	for i, variable := range variables {
+		if literal, ok := assignment[variable]; ok {
+			literals[i] = literal
+		} else {
+			literals[i] = variable.Negate(fac)
+		}
+	}
+	return model.New(literals...)
+}
+
+func ones(n int) []int {
+	result := make([]int, n)
+	for i := range result {
+		result[i] = 1
+	}
+	return result
+}
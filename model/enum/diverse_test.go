@@ -0,0 +1,137 @@
+package enum
+
+import (
+	"testing"
+
+	"github.com/booleworks/logicng-go/assignment"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/maxsat"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiverseSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	vars := fac.Vars("a", "b", "c", "d")
+
+	models, state, err := DiverseOnFormula(fac, fac.Verum(), vars, 2)
+	assert.Nil(err)
+	assert.True(state.Success)
+	assert.Len(models, 2)
+	assert.Equal(4, hammingDistance(models[0], models[1]))
+
+	config := DefaultDiverseConfig()
+	config.MinDistance = 3
+	models, _, _ = DiverseOnFormula(fac, fac.Verum(), vars, 5, config)
+	assert.Len(models, 2)
+	config.MinDistance = 2
+	models, _, _ = DiverseOnFormula(fac, fac.Verum(), vars, 10, config)
+	assertDiverse(assert, fac, fac.Verum(), vars, models, 2)
+	assert.Len(models, 8)
+
+	models, _, _ = DiverseOnFormula(fac, fac.Falsum(), vars, 5)
+	assert.Empty(models)
+	models, _, _ = DiverseOnFormula(fac, fac.Verum(), vars, 0)
+	assert.Empty(models)
+	_, _, err = DiverseOnFormula(fac, fac.Verum(), vars, -1)
+	assert.NotNil(err)
+}
+
+func TestDiverseAllModels(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b | c) & (~a | ~b) & (x => d)")
+	vars := fac.Vars("a", "b", "c", "d", "x", "y")
+	models, state, _ := DiverseOnFormula(fac, formula, vars, 100)
+	assert.True(state.Success)
+	assert.Equal(len(OnFormula(fac, formula, vars)), len(models))
+	assertDiverse(assert, fac, formula, vars, models, 1)
+}
+
+func TestDiverseAlgorithms(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b | c) & (~a | ~b | ~d) & (c => e)")
+	vars := fac.Vars("a", "b", "c", "d", "e")
+	algorithms := []maxsat.Algorithm{
+		maxsat.AlgWBO, maxsat.AlgIncWBO, maxsat.AlgLinearSU, maxsat.AlgLinearUS,
+		maxsat.AlgMSU3, maxsat.AlgOLL,
+	}
+	for _, algorithm := range algorithms {
+		config := DefaultDiverseConfig()
+		config.Algorithm = algorithm
+		config.MinDistance = 2
+		models, _, _ := DiverseOnFormula(fac, formula, vars, 4, config)
+		assert.Len(models, 4)
+		assertDiverse(assert, fac, formula, vars, models, 2)
+		assert.Equal(5, hammingDistance(models[0], models[1]))
+	}
+
+	config := DefaultDiverseConfig()
+	config.Algorithm = maxsat.AlgWMSU3
+	models, _, err := DiverseOnFormula(fac, formula, vars, 4, config)
+	assert.NotNil(err)
+	assert.Nil(models)
+}
+
+func TestDiverseRandom(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	for i := range 20 {
+		config := randomizer.DefaultConfig()
+		config.Seed = int64(i)
+		config.NumVars = 8
+		formula := randomizer.New(fac, config).Formula(3)
+		vars := fac.Vars("v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7")
+		diverseConfig := DefaultDiverseConfig()
+		diverseConfig.MinDistance = 3
+		models, _, _ := DiverseOnFormula(fac, formula, vars, 5, diverseConfig)
+		assertDiverse(assert, fac, formula, vars, models, 3)
+		if len(OnFormula(fac, formula, vars)) > 0 {
+			assert.NotEmpty(models)
+		}
+	}
+}
+
+func TestDiverseHandler(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := DefaultDiverseConfig()
+	config.Handler = iter.HandlerWithLimit(2)
+	models, state, _ := DiverseOnFormula(fac, fac.Verum(), fac.Vars("a", "b", "c"), 5, config)
+	assert.False(state.Success)
+	assert.Len(models, 2)
+}
+
+func assertDiverse(
+	assert *assert.Assertions, fac f.Factory, formula f.Formula, vars []f.Variable, models []*model.Model, minDistance int,
+) {
+	for i, mdl := range models {
+		assert.Equal(len(vars), mdl.Size())
+		ass, _ := mdl.Assignment(fac)
+		assert.True(assignment.Evaluate(fac, formula, ass))
+		for _, other := range models[:i] {
+			assert.GreaterOrEqual(hammingDistance(mdl, other), minDistance)
+		}
+	}
+}
+
+func hammingDistance(m1, m2 *model.Model) int {
+	literals := make(map[f.Literal]bool, len(m1.Literals))
+	for _, literal := range m1.Literals {
+		literals[literal] = true
+	}
+	distance := 0
+	for _, literal := range m2.Literals {
+		if !literals[literal] {
+			distance++
+		}
+	}
+	return distance
+}
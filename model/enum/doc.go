@@ -14,6 +14,51 @@
 //	solver := sat.NewMiniSatSolver(fac)
 //	models = enum.OnSolver(solver, fac.Vars("A", "B", "C")) // will produce 3 models
 //
+// For large numbers of models, the models can be streamed instead of being
+// collected: SeqOnSolver returns an iterator which yields the models lazily
+// and stops the enumeration when the consumer breaks out of the loop, and
+// ChanOnSolverWithConfig produces the models on a channel:
+//
+//	for model := range enum.SeqOnSolver(solver, fac.Vars("A", "B", "C")) {
+//	    if process(model) {
+//	        break
+//	    }
+//	}
+//
+// SeqOnSolverWithState additionally reports whether the handler of the
+// configuration canceled the iteration.
+//
+// Instead of full models, the models can also be enumerated as cubes, i.e.
+// partial models with don't care variables.  CubesOnFormula keeps variables
+// which do not occur in the formula as don't cares, while PrimeCubesOnFormula
+// enlarges each found model to a prime implicant and therefore usually yields
+// a much smaller cover of the models:
+//
+//	cubes := enum.PrimeCubesOnFormula(fac, formula, variables)
+//	count := cubes[0].ModelCount() // number of models covered by the first cube
+//
+// For large enumerations, OnFormulaParallel distributes the split
+// assignments of the iteration strategy over multiple workers:
+//
+//	models, state := enum.OnFormulaParallel(fac, formula, variables, 4, iter.DefaultConfig())
+//
+// The models with the smallest cost under a linear objective, i.e. a weight
+// for each literal, can be enumerated in ascending order of their cost with
+// TopKOnFormula on a SAT solver or with TopKOnBDD and TopKOnDNNF on compiled
+// representations:
+//
+//	weights := map[f.Literal]int{fac.Lit("A", true): 3, fac.Lit("B", true): 1}
+//	ranked, state, err := enum.TopKOnFormula(fac, formula, variables, weights, 5)
+//
+// To present alternatives, DiverseOnFormula computes models which differ as
+// much as possible from each other.  Each model is computed with a MAX-SAT
+// solver and has at least a configurable Hamming distance to all previous
+// models:
+//
+//	config := enum.DefaultDiverseConfig()
+//	config.MinDistance = 3
+//	models, state, err := enum.DiverseOnFormula(fac, formula, variables, 5, config)
+//
 // Model enumeration is one use case of the model iteration in the [iter]
 // package and can be configured as described there.
 package enum
@@ -69,6 +69,32 @@ func OnSolverWithConfig(
 	return me.Iterate(solver, newModelEnumCollector, make([]*model.Model, 0))
 }
 
+// OnFormulaParallel enumerates all models of a formula over the given
+// variables like OnFormulaWithConfig, but distributes the split assignments
+// of the config's strategy over the given number of workers which enumerate
+// their models in parallel.  Each worker uses its own copy of the factory and
+// its own SAT solver, the handler of the config is shared by all workers.
+// The models are returned in no particular order.
+func OnFormulaParallel(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	workers int,
+	config *iter.Config,
+	additionalVariables ...f.Variable,
+) ([]*model.Model, handler.State) {
+	var add *f.VarSet
+	if additionalVariables != nil {
+		add = f.NewVarSet(additionalVariables...)
+	}
+	if config == nil {
+		config = iter.DefaultConfig()
+	}
+	me := iter.New[[]*model.Model](f.NewVarSet(variables...), add, config)
+	merge := func(models, other []*model.Model) []*model.Model { return append(models, other...) }
+	return me.IterateParallel(fac, []f.Formula{formula}, workers, newModelEnumCollector, merge, make([]*model.Model, 0))
+}
+
 type modelEnumCollector struct {
 	committedModels                []*model.Model
 	uncommittedModels              [][]f.Literal
@@ -108,7 +108,10 @@ func (c *modelEnumBddCollector) Commit(hdl handler.Handler) handler.State {
 	for _, uncommittedModel := range c.uncommittedModels {
 		modelFormula := uncommittedModel.Formula(c.kernel.Factory())
 		modelBdd := bdd.CompileWithKernel(c.kernel.Factory(), modelFormula, c.kernel)
-		c.committedModels = c.committedModels.Or(modelBdd)
+		previous := c.committedModels
+		c.committedModels = previous.Or(modelBdd)
+		previous.Free()
+		modelBdd.Free()
 	}
 	c.uncommittedModels = make([]*model.Model, 0)
 	if e := event.ModelEnumerationCommit; !hdl.ShouldResume(e) {
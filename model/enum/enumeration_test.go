@@ -330,3 +330,68 @@ func TestMERandomAdditionalVars(t *testing.T) {
 		}
 	}
 }
+
+func TestMEParallel(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	parallelCfgs := []*iter.Config{
+		iter.DefaultConfig(),
+		{Handler: handler.NopHandler, Strategy: iter.NewNoSplitMEStrategy()},
+		{Handler: handler.NopHandler, Strategy: iter.NewBasicStrategy(iter.DefaultMostCommonVarProvider(), 5)},
+	}
+	for _, cfg := range parallelCfgs {
+		for i := range 20 {
+			config := randomizer.DefaultConfig()
+			config.Seed = int64(i)
+			config.NumVars = 15
+			randomizer := randomizer.New(fac, config)
+			formula := randomizer.Formula(3)
+			varsFormula := f.Variables(fac, formula).Content()
+			pmeVars := varsFormula[:len(varsFormula)/2]
+			additionalVars := varsFormula[len(varsFormula)/2:]
+
+			exp, _ := OnFormulaWithConfig(fac, formula, pmeVars, cfg, additionalVars...)
+			models, state := OnFormulaParallel(fac, formula, pmeVars, 4, cfg, additionalVars...)
+			assert.True(state.Success)
+			assert.ElementsMatch(modelStrings(fac, exp, pmeVars...), modelStrings(fac, models, pmeVars...))
+			solver := sat.NewSolver(fac)
+			solver.Add(formula)
+			for _, m := range models {
+				assert.True(f.Variables(fac, m.Formula(fac)).ContainsAll(f.NewVarSet(additionalVars...)))
+				assert.True(solver.Call(sat.WithAssumptions(m.Literals)).Sat())
+			}
+		}
+	}
+}
+
+func TestMEParallelDontCares(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(A | B | C) & (~A | D) & (E | F)")
+	vars := fac.Vars("A", "B", "C", "D", "E", "F", "X", "Y")
+	cfg := &iter.Config{
+		Handler:  handler.NopHandler,
+		Strategy: iter.NewBasicStrategy(iter.DefaultMostCommonVarProvider(), 3),
+	}
+	exp := OnFormula(fac, formula, vars)
+	models, state := OnFormulaParallel(fac, formula, vars, 3, cfg)
+	assert.True(state.Success)
+	assert.Equal(120, len(exp))
+	assert.ElementsMatch(modelStrings(fac, exp), modelStrings(fac, models))
+}
+
+func TestMEParallelWithLimit(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(A | B | C) & (~A | D) & (E | F | G | H)")
+	cfg := &iter.Config{
+		Handler:  iter.HandlerWithLimit(10),
+		Strategy: iter.NewBasicStrategy(iter.DefaultMostCommonVarProvider(), 3),
+	}
+	models, state := OnFormulaParallel(fac, formula, f.Variables(fac, formula).Content(), 4, cfg)
+	assert.False(state.Success)
+	assert.NotEqual(event.Nothing, state.CancelCause)
+	assert.Less(len(models), 90)
+}
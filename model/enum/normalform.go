@@ -69,3 +69,26 @@ func canonicalEnumeration(
 	}
 	return fac.Or(ops...), succ
 }
+
+// PrimeCubeDNF returns a DNF of the given formula whose terms are prime
+// implicants of the formula.  In contrast to the canonical DNF, its terms are
+// not minterms and may overlap, so it is usually much smaller.
+func PrimeCubeDNF(fac f.Factory, formula f.Formula) f.Formula {
+	dnf, _ := PrimeCubeDNFWithHandler(fac, formula, handler.NopHandler)
+	return dnf
+}
+
+// PrimeCubeDNFWithHandler returns a DNF of the given formula whose terms are
+// prime implicants of the formula.  The given handler can be used to cancel
+// the computation.
+func PrimeCubeDNFWithHandler(fac f.Factory, formula f.Formula, hdl handler.Handler) (f.Formula, handler.State) {
+	cubes, state := PrimeCubesOnFormulaWithHandler(fac, formula, f.Variables(fac, formula).Content(), hdl)
+	if !state.Success {
+		return 0, state
+	}
+	ops := make([]f.Formula, len(cubes))
+	for i, cube := range cubes {
+		ops[i] = cube.Formula(fac)
+	}
+	return fac.Or(ops...), succ
+}
@@ -0,0 +1,195 @@
+package enum
+
+import (
+	goiter "iter"
+	"math"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/sat"
+)
+
+// SeqOnFormula returns an iterator over all models of a formula over the
+// given variables.  See SeqOnSolverWithConfig for details.
+func SeqOnFormula(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	additionalVariables ...f.Variable,
+) goiter.Seq[*model.Model] {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	return SeqOnSolverWithConfig(solver, variables, iter.DefaultConfig(), additionalVariables...)
+}
+
+// SeqOnSolver returns an iterator over all models on the given SAT solver
+// over the given variables.  See SeqOnSolverWithConfig for details.
+func SeqOnSolver(solver *sat.Solver, variables []f.Variable, additionalVariables ...f.Variable) goiter.Seq[*model.Model] {
+	return SeqOnSolverWithConfig(solver, variables, iter.DefaultConfig(), additionalVariables...)
+}
+
+// SeqOnSolverWithConfig returns an iterator over all models on the given SAT
+// solver over the given variables.  The additionalVariables will be included
+// in each model, but are not iterated over.  The config can be used to
+// influence the model iteration process by setting a handler and/or an
+// iteration strategy.
+//
+// In contrast to OnSolverWithConfig, the models are not collected but
+// yielded as soon as they are committed by the model iteration.  With a
+// split strategy, at most the maximum number of models of the strategy are
+// kept in memory at once.  Without splits, each model is yielded directly
+// after it was found.  The iteration stops when the consumer stops the
+// iteration (e.g. by breaking out of a range loop) or when the handler
+// cancels it.  The solver must not be used while the iteration is running,
+// its state is restored afterward.  Each call of the iterator performs a new
+// model iteration on the solver.  Use SeqOnSolverWithState to find out
+// whether the handler canceled the iteration.
+func SeqOnSolverWithConfig(
+	solver *sat.Solver,
+	variables []f.Variable,
+	config *iter.Config,
+	additionalVariables ...f.Variable,
+) goiter.Seq[*model.Model] {
+	seq, _ := SeqOnSolverWithState(solver, variables, config, additionalVariables...)
+	return seq
+}
+
+// SeqOnSolverWithState returns an iterator over all models on the given SAT
+// solver over the given variables like SeqOnSolverWithConfig.  Additionally,
+// it returns a function which reports the state of the last iteration: the
+// cancellation if the handler canceled it and success otherwise, also if the
+// consumer stopped the iteration.
+func SeqOnSolverWithState(
+	solver *sat.Solver,
+	variables []f.Variable,
+	config *iter.Config,
+	additionalVariables ...f.Variable,
+) (goiter.Seq[*model.Model], func() handler.State) {
+	var add *f.VarSet
+	if additionalVariables != nil {
+		add = f.NewVarSet(additionalVariables...)
+	}
+	if config == nil {
+		config = iter.DefaultConfig()
+	}
+	state := succ
+	seq := func(yield func(*model.Model) bool) {
+		immediate := config.Strategy == nil || config.Strategy.MaxModelsForIter(0) == math.MaxInt
+		newCollector := func(fac f.Factory, knownVars, dontCaresNotOnSolver, additionalVarsNotOnSolver *f.VarSet) iter.Collector[struct{}] {
+			enumCollector := newModelEnumCollector(fac, knownVars, dontCaresNotOnSolver, additionalVarsNotOnSolver)
+			return &modelStreamCollector{enumCollector.(*modelEnumCollector), yield, immediate, false}
+		}
+		me := iter.New[struct{}](f.NewVarSet(variables...), add, config)
+		_, state = me.Iterate(solver, newCollector, struct{}{})
+		if !state.Success && state.CancelCause == event.ModelEnumerationStreamStopped {
+			state = succ
+		}
+	}
+	return seq, func() handler.State { return state }
+}
+
+// ChanOnSolverWithConfig returns a channel with all models on the given SAT
+// solver over the given variables like SeqOnSolverWithConfig.  The models
+// are produced by a new goroutine and the channel is closed after the last
+// model.  Closing the done channel stops the producer early.  The solver must
+// not be used until the returned channel is closed.  Use
+// ChanOnSolverWithState to find out whether the handler canceled the
+// iteration.
+func ChanOnSolverWithConfig(
+	solver *sat.Solver,
+	variables []f.Variable,
+	config *iter.Config,
+	done <-chan struct{},
+	additionalVariables ...f.Variable,
+) <-chan *model.Model {
+	models, _ := ChanOnSolverWithState(solver, variables, config, done, additionalVariables...)
+	return models
+}
+
+// ChanOnSolverWithState returns a channel with all models on the given SAT
+// solver over the given variables like ChanOnSolverWithConfig.
+// Additionally, it returns a function which reports the state of the
+// iteration once the channel is closed: the cancellation if the handler
+// canceled it and success otherwise, also if the done channel was closed.
+func ChanOnSolverWithState(
+	solver *sat.Solver,
+	variables []f.Variable,
+	config *iter.Config,
+	done <-chan struct{},
+	additionalVariables ...f.Variable,
+) (<-chan *model.Model, func() handler.State) {
+	models := make(chan *model.Model)
+	seq, state := SeqOnSolverWithState(solver, variables, config, additionalVariables...)
+	go func() {
+		defer close(models)
+		for mdl := range seq {
+			select {
+			case models <- mdl:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return models, state
+}
+
+// modelStreamCollector yields the models of the model enumeration collector
+// instead of collecting them.  If immediate is set, the models are never
+// rolled back and are yielded when they are added.
+type modelStreamCollector struct {
+	*modelEnumCollector
+	yield     func(*model.Model) bool
+	immediate bool
+	stopped   bool
+}
+
+func (c *modelStreamCollector) AddModel(
+	modelFromSolver []bool, solver *sat.Solver, relevantAllIndices []int32, hdl handler.Handler,
+) handler.State {
+	if state := c.modelEnumCollector.AddModel(modelFromSolver, solver, relevantAllIndices, hdl); !state.Success {
+		return state
+	}
+	if c.immediate {
+		return c.yieldUncommittedModels()
+	}
+	return succ
+}
+
+func (c *modelStreamCollector) Commit(hdl handler.Handler) handler.State {
+	if state := c.yieldUncommittedModels(); !state.Success {
+		return state
+	}
+	if e := event.ModelEnumerationCommit; !hdl.ShouldResume(e) {
+		return handler.Cancelation(e)
+	}
+	return succ
+}
+
+func (c *modelStreamCollector) Result() struct{} {
+	return struct{}{}
+}
+
+// yieldUncommittedModels yields the uncommitted models expanded by the
+// don't care variables which are not on the solver.
+func (c *modelStreamCollector) yieldUncommittedModels() handler.State {
+	if c.stopped {
+		return handler.Cancelation(event.ModelEnumerationStreamStopped)
+	}
+	uncommitted := c.uncommittedModels
+	c.uncommittedModels = make([][]f.Literal, 0, 4)
+	for _, baseModel := range c.baseModels {
+		for _, uncommittedModel := range uncommitted {
+			completeModel := make([]f.Literal, 0, len(baseModel)+len(uncommittedModel))
+			completeModel = append(completeModel, baseModel...)
+			completeModel = append(completeModel, uncommittedModel...)
+			if !c.yield(model.New(completeModel...)) {
+				c.stopped = true
+				return handler.Cancelation(event.ModelEnumerationStreamStopped)
+			}
+		}
+	}
+	return succ
+}
@@ -0,0 +1,213 @@
+package enum
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeqSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("A & (B | C)")
+	vars := fac.Vars("A", "B", "C", "D")
+	for _, cfg := range cfgs {
+		solver := sat.NewSolver(fac)
+		solver.Add(formula)
+		var models []*model.Model
+		for mdl := range SeqOnSolverWithConfig(solver, vars, cfg, fac.Vars("E")...) {
+			models = append(models, mdl)
+		}
+		expected, _ := OnSolverWithConfig(solver, vars, cfg, fac.Vars("E")...)
+		assert.ElementsMatch(modelStrings(fac, expected), modelStrings(fac, models))
+		assert.Len(models, 6)
+	}
+	assert.Len(slices.Collect(SeqOnFormula(fac, formula, vars[:3])), 3)
+	assert.Empty(slices.Collect(SeqOnFormula(fac, fac.Falsum(), vars)))
+	assert.Len(slices.Collect(SeqOnFormula(fac, fac.Verum(), nil)), 1)
+}
+
+func TestSeqRandom(t *testing.T) {
+	fac := f.NewFactory()
+	for _, cfg := range cfgs {
+		for i := range 50 {
+			config := randomizer.DefaultConfig()
+			config.Seed = int64(i)
+			config.NumVars = 12
+			formula := randomizer.New(fac, config).Formula(3)
+			solver := sat.NewSolver(fac)
+			solver.Add(formula)
+			vars := f.Variables(fac, formula).Content()
+			pmeVars := append(slices.Clone(vars[:len(vars)*2/3]), fac.Var("notOnSolver"))
+			additionalVars := vars[len(vars)*2/3:]
+
+			expected, _ := OnSolverWithConfig(solver, pmeVars, cfg, additionalVars...)
+			models := slices.Collect(SeqOnSolverWithConfig(solver, pmeVars, cfg, additionalVars...))
+			assert.ElementsMatch(t, modelStrings(fac, expected, pmeVars...), modelStrings(fac, models, pmeVars...))
+			for _, mdl := range models {
+				assert.Equal(t, len(vars)+1, mdl.Size())
+			}
+		}
+	}
+}
+
+func TestSeqBreak(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 8)
+	vars := f.Variables(fac, queens).Content()
+	for _, cfg := range []*iter.Config{
+		iter.DefaultConfig(),
+		{Handler: handler.NopHandler, Strategy: iter.NewNoSplitMEStrategy()},
+		{Handler: handler.NopHandler, Strategy: iter.NewBasicStrategy(iter.DefaultMostCommonVarProvider(), 5)},
+	} {
+		solver := sat.NewSolver(fac)
+		solver.Add(queens)
+		var models []*model.Model
+		for mdl := range SeqOnSolverWithConfig(solver, vars, cfg) {
+			models = append(models, mdl)
+			if len(models) == 10 {
+				break
+			}
+		}
+		assert.Len(models, 10)
+		assert.Len(OnSolver(solver, vars), 92)
+		assert.Len(slices.Collect(SeqOnSolverWithConfig(solver, vars, cfg)), 92)
+	}
+}
+
+func TestSeqLazy(t *testing.T) {
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 8)
+	solver := sat.NewSolver(fac)
+	solver.Add(queens)
+	hdl := &foundModelsHandler{}
+	cfg := &iter.Config{Handler: hdl, Strategy: iter.NewNoSplitMEStrategy()}
+	for range SeqOnSolverWithConfig(solver, f.Variables(fac, queens).Content(), cfg) {
+		break
+	}
+	assert.Equal(t, 1, hdl.found)
+}
+
+func TestSeqHandler(t *testing.T) {
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 8)
+	solver := sat.NewSolver(fac)
+	solver.Add(queens)
+	cfg := &iter.Config{Handler: iter.HandlerWithLimit(20), Strategy: iter.NewNoSplitMEStrategy()}
+	models := slices.Collect(SeqOnSolverWithConfig(solver, f.Variables(fac, queens).Content(), cfg))
+	assert.Len(t, models, 20)
+}
+
+func TestSeqState(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 8)
+	vars := f.Variables(fac, queens).Content()
+	solver := sat.NewSolver(fac)
+	solver.Add(queens)
+
+	cfg := &iter.Config{Handler: iter.HandlerWithLimit(20), Strategy: iter.NewNoSplitMEStrategy()}
+	seq, state := SeqOnSolverWithState(solver, vars, cfg)
+	assert.True(state().Success)
+	assert.Len(slices.Collect(seq), 20)
+	assert.False(state().Success)
+	assert.Equal(iter.EventIteratorFoundModels{NumberOfModels: 1}, state().CancelCause)
+
+	seq, state = SeqOnSolverWithState(solver, vars, iter.DefaultConfig())
+	for range seq {
+		break
+	}
+	assert.True(state().Success)
+	assert.Len(slices.Collect(seq), 92)
+	assert.True(state().Success)
+}
+
+func TestChan(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 8)
+	vars := f.Variables(fac, queens).Content()
+	solver := sat.NewSolver(fac)
+	solver.Add(queens)
+
+	count := 0
+	for range ChanOnSolverWithConfig(solver, vars, iter.DefaultConfig(), nil) {
+		count++
+	}
+	assert.Equal(92, count)
+
+	done := make(chan struct{})
+	models := ChanOnSolverWithConfig(solver, vars, iter.DefaultConfig(), done)
+	<-models
+	<-models
+	close(done)
+	for range models {
+	}
+	assert.Len(OnSolver(solver, vars), 92)
+}
+
+func TestChanState(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	queens := sat.GenerateNQueens(fac, 8)
+	vars := f.Variables(fac, queens).Content()
+	solver := sat.NewSolver(fac)
+	solver.Add(queens)
+
+	cfg := &iter.Config{Handler: iter.HandlerWithLimit(20), Strategy: iter.NewNoSplitMEStrategy()}
+	models, state := ChanOnSolverWithState(solver, vars, cfg, nil)
+	count := 0
+	for range models {
+		count++
+	}
+	assert.Equal(20, count)
+	assert.False(state().Success)
+	assert.Equal(iter.EventIteratorFoundModels{NumberOfModels: 1}, state().CancelCause)
+
+	done := make(chan struct{})
+	models, state = ChanOnSolverWithState(solver, vars, iter.DefaultConfig(), done)
+	<-models
+	close(done)
+	for range models {
+	}
+	assert.True(state().Success)
+}
+
+// modelStrings returns the sorted models restricted to the given variables.
+// If no variables are given, the models are not restricted.
+func modelStrings(fac f.Factory, models []*model.Model, variables ...f.Variable) []string {
+	result := make([]string, len(models))
+	for i, mdl := range models {
+		var literals []f.Literal
+		for _, literal := range mdl.Literals {
+			if len(variables) == 0 || slices.Contains(variables, literal.Variable()) {
+				literals = append(literals, literal)
+			}
+		}
+		slices.Sort(literals)
+		result[i] = model.New(literals...).Sprint(fac)
+	}
+	return result
+}
+
+type foundModelsHandler struct {
+	found int
+}
+
+func (h *foundModelsHandler) ShouldResume(e event.Event) bool {
+	if efm, ok := e.(iter.EventIteratorFoundModels); ok {
+		h.found += efm.NumberOfModels
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+package enum
+
+import (
+	"slices"
+
+	"github.com/booleworks/logicng-go/bdd"
+	"github.com/booleworks/logicng-go/dnnf"
+	"github.com/booleworks/logicng-go/encoding"
+	"github.com/booleworks/logicng-go/errorx"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/sat"
+)
+
+// A RankedModel is a model of a top-k enumeration together with its cost.
+type RankedModel struct {
+	Model *model.Model
+	Cost  int
+}
+
+// TopKConfig describes the configuration of a top-k enumeration.  If Ties
+// is set, all models with the same cost as the k-th model are also
+// returned.  The handler can be used to cancel the SAT solver based
+// enumeration.
+type TopKConfig struct {
+	Ties    bool
+	Handler handler.Handler
+}
+
+// DefaultTopKConfig returns the default configuration for a top-k
+// enumeration: exactly k models are returned and no handler is used.
+func DefaultTopKConfig() *TopKConfig {
+	return &TopKConfig{
+		Ties:    false,
+		Handler: handler.NopHandler,
+	}
+}
+
+// TopKOnFormula returns the k models of a formula over the given variables
+// with the smallest cost in ascending order of their cost.  The cost of a
+// model is the sum of the weights of its literals, literals which are not in
+// the weight map have weight 0.  Weights may be negative, so the k models
+// with the largest cost can be computed by negating the weights.  If the
+// weights contain literals over variables which are not projected, the cost
+// of a model is the smallest cost of all its extensions to these variables.
+// Models with the same cost are returned in no particular order.
+//
+// The models are computed with a SAT solver: the objective is encoded once
+// as an incremental pseudo-Boolean constraint like in the weighted
+// optimization of the solver.  The next optimal cost is computed by a linear
+// search with bound assumptions on this constraint, then all models with
+// this cost are enumerated under the bound and blocked, until k models are
+// found.  Returns an error if k is negative or if the objective cannot be
+// encoded.
+func TopKOnFormula(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	weights map[f.Literal]int,
+	k int,
+	config ...*TopKConfig,
+) ([]RankedModel, handler.State, error) {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	return TopKOnSolver(solver, variables, weights, k, config...)
+}
+
+// TopKOnSolver returns the k models on the given SAT solver over the given
+// variables with the smallest cost in ascending order of their cost like
+// TopKOnFormula.  The formulas on the solver are not changed.  Returns an
+// error if k is negative or if the objective cannot be encoded.
+func TopKOnSolver(
+	solver *sat.Solver,
+	variables []f.Variable,
+	weights map[f.Literal]int,
+	k int,
+	config ...*TopKConfig,
+) ([]RankedModel, handler.State, error) {
+	if k < 0 {
+		return nil, succ, errorx.BadInput("k must not be negative, but was %d", k)
+	}
+	cfg := determineTopKConfig(config)
+	literals, coefficients := objective(weights)
+	initialState := solver.SaveState()
+	defer func() { _ = solver.LoadState(initialState) }()
+	registerVariables(solver, variables, literals)
+	obj, err := newTopKObjective(solver, literals, coefficients)
+	if err != nil {
+		return nil, succ, err
+	}
+
+	result := make([]RankedModel, 0, k)
+	for len(result) < k {
+		optimum, found, state, err := obj.nextOptimum(cfg.Handler)
+		if err != nil {
+			return nil, state, err
+		} else if !state.Success {
+			return result, state, nil
+		} else if !found {
+			break
+		}
+		assumptions, err := obj.boundAssumptions(optimum)
+		if err != nil {
+			return nil, succ, err
+		}
+		for len(result) < k || cfg.Ties {
+			params := sat.Params().Handler(cfg.Handler).WithModel(variables).Literal(assumptions...)
+			sResult := solver.Call(params)
+			if sResult.Canceled() {
+				return result, sResult.State(), nil
+			} else if !sResult.Sat() {
+				break
+			}
+			mdl := sResult.Model()
+			result = append(result, RankedModel{mdl, optimum})
+			if e := (iter.EventIteratorFoundModels{NumberOfModels: 1}); !cfg.Handler.ShouldResume(e) {
+				return result, handler.Cancelation(e), nil
+			}
+			solver.Add(blockingClause(solver.Factory(), mdl))
+		}
+	}
+	return result, succ, nil
+}
+
+// topKObjective holds the objective of a top-k enumeration on a solver.  The
+// objective is encoded once as an incremental pseudo-Boolean constraint
+// whose bound is only enforced by assumptions, such that it can be raised
+// again for the next cost level.
+type topKObjective struct {
+	solver       *sat.Solver
+	literals     []f.Literal
+	coefficients []int
+	variables    []f.Variable
+	incData      *encoding.PBCIncrementalData
+	lowerBound   int
+}
+
+func newTopKObjective(solver *sat.Solver, literals []f.Literal, coefficients []int) (*topKObjective, error) {
+	variables := f.NewMutableVarSet()
+	for _, literal := range literals {
+		variables.Add(literal.Variable())
+	}
+	obj := &topKObjective{
+		solver:       solver,
+		literals:     literals,
+		coefficients: coefficients,
+		variables:    variables.Content(),
+	}
+	if len(literals) == 0 {
+		return obj, nil
+	}
+	phaseCosts := make(map[f.Variable][2]int)
+	for i, literal := range literals {
+		costs := phaseCosts[literal.Variable()]
+		if literal.IsPos() {
+			costs[1] += coefficients[i]
+		} else {
+			costs[0] += coefficients[i]
+		}
+		phaseCosts[literal.Variable()] = costs
+	}
+	upperBound := 0
+	for _, costs := range phaseCosts {
+		obj.lowerBound += min(costs[0], costs[1])
+		upperBound += max(costs[0], costs[1])
+	}
+	if obj.lowerBound == upperBound {
+		return obj, nil
+	}
+	pbc := solver.Factory().PBC(f.LE, upperBound, literals, coefficients)
+	incData, err := solver.AddIncrementalPBC(pbc)
+	if err != nil {
+		return nil, err
+	}
+	obj.incData = incData
+	return obj, nil
+}
+
+// nextOptimum returns the smallest cost of a model on the solver and
+// whether there is a model at all.
+func (o *topKObjective) nextOptimum(hdl handler.Handler) (int, bool, handler.State, error) {
+	if e := event.OptimizationFunctionStarted; !hdl.ShouldResume(e) {
+		return 0, false, handler.Cancelation(e), nil
+	}
+	params := sat.Params().Handler(hdl).WithModel(o.variables)
+	sResult := o.solver.Call(params)
+	if sResult.Canceled() {
+		return 0, false, sResult.State(), nil
+	} else if !sResult.Sat() {
+		return 0, false, succ, nil
+	}
+	best := o.cost(sResult.Model())
+	for best > o.lowerBound {
+		assumptions, err := o.boundAssumptions(best - 1)
+		if err != nil {
+			return 0, false, succ, err
+		}
+		sResult = o.solver.Call(sat.Params().Handler(hdl).WithModel(o.variables).Literal(assumptions...))
+		if sResult.Canceled() {
+			return 0, false, sResult.State(), nil
+		} else if !sResult.Sat() {
+			break
+		}
+		best = o.cost(sResult.Model())
+	}
+	return best, true, succ, nil
+}
+
+// boundAssumptions returns the assumptions which restrict the cost of the
+// models on the solver to the given bound.
+func (o *topKObjective) boundAssumptions(bound int) ([]f.Literal, error) {
+	if o.incData == nil {
+		return nil, nil
+	}
+	return o.incData.UpperBoundAssumptions(bound)
+}
+
+func (o *topKObjective) cost(mdl *model.Model) int {
+	positive := f.NewMutableVarSet(mdl.PosVars()...)
+	cost := 0
+	for i, literal := range o.literals {
+		if positive.Contains(literal.Variable()) == literal.IsPos() {
+			cost += o.coefficients[i]
+		}
+	}
+	return cost
+}
+
+// TopKOnBDD returns the k models of the BDD over the given variables with
+// the smallest cost in ascending order of their cost like TopKOnFormula.  If
+// no variables are given, the models over all variables of the BDD's kernel
+// are returned.  The models are computed by a best-first search over the
+// paths of the BDD, the handler of the config is not used.
+func TopKOnBDD(
+	b *bdd.BDD,
+	variables []f.Variable,
+	weights map[f.Literal]int,
+	k int,
+	config ...*TopKConfig,
+) []RankedModel {
+	cfg := determineTopKConfig(config)
+	models, costs := b.TopK(k, cfg.Ties, weights, variables...)
+	return rankedModels(models, costs)
+}
+
+// TopKOnDNNF returns the k models of the DNNF over the given variables with
+// the smallest cost in ascending order of their cost like TopKOnFormula.  If
+// no variables are given, the models over the original variables of the DNNF
+// are returned.  The models are computed by merging the k best models of the
+// nodes of the DNNF bottom-up, the handler of the config is not used.
+func TopKOnDNNF(
+	d *dnnf.DNNF,
+	variables []f.Variable,
+	weights map[f.Literal]int,
+	k int,
+	config ...*TopKConfig,
+) []RankedModel {
+	cfg := determineTopKConfig(config)
+	models, costs := d.TopK(k, cfg.Ties, weights, variables...)
+	return rankedModels(models, costs)
+}
+
+func determineTopKConfig(config []*TopKConfig) *TopKConfig {
+	if len(config) > 0 && config[0] != nil {
+		return config[0]
+	}
+	return DefaultTopKConfig()
+}
+
+// objective returns the literals with a non-zero weight and their weights in
+// a deterministic order.
+func objective(weights map[f.Literal]int) ([]f.Literal, []int) {
+	literals := make([]f.Literal, 0, len(weights))
+	for literal, weight := range weights {
+		if weight != 0 {
+			literals = append(literals, literal)
+		}
+	}
+	slices.Sort(literals)
+	coefficients := make([]int, len(literals))
+	for i, literal := range literals {
+		coefficients[i] = weights[literal]
+	}
+	return literals, coefficients
+}
+
+// registerVariables adds the variables and the variables of the literals
+// which are not yet known to the solver, such that they are assigned in the
+// models and the optimization.  Each such variable v is added with a clause
+// v | x for a new auxiliary variable x, which does not restrict v.
+func registerVariables(solver *sat.Solver, variables []f.Variable, literals []f.Literal) {
+	fac := solver.Factory()
+	known := solver.CoreSolver().KnownVariables(fac)
+	unknown := f.NewMutableVarSet()
+	for _, variable := range variables {
+		if !known.Contains(variable) {
+			unknown.Add(variable)
+		}
+	}
+	for _, literal := range literals {
+		if !known.Contains(literal.Variable()) {
+			unknown.Add(literal.Variable())
+		}
+	}
+	for _, variable := range unknown.Content() {
+		solver.Add(fac.Or(variable.AsFormula(), fac.NewAuxVar(f.AuxCNF).AsFormula()))
+	}
+}
+
+func blockingClause(fac f.Factory, mdl *model.Model) f.Formula {
+	literals := make([]f.Literal, len(mdl.Literals))
+	for i, literal := range mdl.Literals {
+		literals[i] = literal.Negate(fac)
+	}
+	return fac.Clause(literals...)
+}
+
+func rankedModels(models []*model.Model, costs []int) []RankedModel {
+	result := make([]RankedModel, len(models))
+	for i, mdl := range models {
+		result[i] = RankedModel{mdl, costs[i]}
+	}
+	return result
+}
@@ -0,0 +1,213 @@
+package enum
+
+import (
+	"maps"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/booleworks/logicng-go/bdd"
+	"github.com/booleworks/logicng-go/dnnf"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopKSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b | c) & (~a | ~b)")
+	vars := fac.Vars("a", "b", "c")
+	costs := map[f.Literal]int{fac.Lit("a", true): 3, fac.Lit("b", true): 2, fac.Lit("c", true): 4}
+
+	exp := []string{"[~a, b, ~c]", "[a, ~b, ~c]", "[~a, ~b, c]"}
+	models, state, err := TopKOnFormula(fac, formula, vars, costs, 3)
+	assert.Nil(err)
+	assert.True(state.Success)
+	assert.Equal(exp, rankedModelStrings(fac, models))
+	assert.Equal([]int{2, 3, 4}, rankedModelCosts(models))
+
+	models = TopKOnBDD(bdd.Compile(fac, formula), vars, costs, 3)
+	assert.Equal(exp, rankedModelStrings(fac, models))
+	models = TopKOnDNNF(dnnf.Compile(fac, formula), vars, costs, 3)
+	assert.Equal(exp, rankedModelStrings(fac, models))
+
+	models, _, err = TopKOnFormula(fac, formula, vars, costs, 10)
+	assert.Nil(err)
+	assert.Equal(5, len(models))
+	assert.Equal([]int{2, 3, 4, 6, 7}, rankedModelCosts(models))
+	models, _, err = TopKOnFormula(fac, fac.Falsum(), vars, costs, 10)
+	assert.Nil(err)
+	assert.Empty(models)
+	models, _, err = TopKOnFormula(fac, formula, vars, costs, 0)
+	assert.Nil(err)
+	assert.Empty(models)
+}
+
+func TestTopKTies(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b | c) & (a | b | d) & (a | c | d) & (b | c | d) & (~a | ~b | ~c) & (~a | ~b | ~d) & (~a | ~c | ~d) & (~b | ~c | ~d)")
+	vars := fac.Vars("a", "b", "c", "d")
+	costs := map[f.Literal]int{fac.Lit("a", true): 1}
+	config := &TopKConfig{Ties: true, Handler: iter.HandlerWithLimit(math.MaxInt)}
+
+	models, state, err := TopKOnFormula(fac, formula, vars, costs, 2, config)
+	assert.Nil(err)
+	assert.True(state.Success)
+	assert.Equal([]int{0, 0, 0}, rankedModelCosts(models))
+	assert.Equal([]int{0, 0, 0}, rankedModelCosts(TopKOnBDD(bdd.Compile(fac, formula), vars, costs, 2, config)))
+	assert.Equal([]int{0, 0, 0}, rankedModelCosts(TopKOnDNNF(dnnf.Compile(fac, formula), vars, costs, 2, config)))
+
+	models, _, err = TopKOnFormula(fac, formula, vars, costs, 4, config)
+	assert.Nil(err)
+	assert.Equal([]int{0, 0, 0, 1, 1, 1}, rankedModelCosts(models))
+	models, _, err = TopKOnFormula(fac, formula, vars, costs, 4)
+	assert.Nil(err)
+	assert.Equal([]int{0, 0, 0, 1}, rankedModelCosts(models))
+}
+
+func TestTopKHandler(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("a + b + c + d = 2")
+	config := &TopKConfig{Handler: iter.HandlerWithLimit(3)}
+	models, state, err := TopKOnFormula(fac, formula, fac.Vars("a", "b", "c", "d"), nil, 5, config)
+	assert.Nil(err)
+	assert.False(state.Success)
+	assert.NotEqual(event.Nothing, state.CancelCause)
+	assert.Equal(3, len(models))
+}
+
+func TestTopKSolverUnchanged(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := sat.NewSolver(fac)
+	solver.Add(p.ParseUnsafe("a | b"))
+	vars := fac.Vars("a", "b", "x")
+	costs := map[f.Literal]int{fac.Lit("x", true): -1, fac.Lit("a", true): 1}
+	models, _, err := TopKOnSolver(solver, vars, costs, 2)
+	assert.Nil(err)
+	assert.Equal("[~a, b, x]", rankedModelStrings(fac, models)[0])
+	assert.Equal([]int{-1, 0}, rankedModelCosts(models))
+
+	_, _, err = TopKOnSolver(solver, vars, costs, -1)
+	assert.NotNil(err)
+
+	costs = map[f.Literal]int{fac.Lit("a", true): 2, fac.Lit("a", false): 2}
+	models, _, err = TopKOnSolver(solver, vars, costs, 2)
+	assert.Nil(err)
+	assert.Equal([]int{2, 2}, rankedModelCosts(models))
+	assert.Equal(3, len(OnSolver(solver, fac.Vars("a", "b"))))
+}
+
+func TestTopKRandom(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	for i := range 30 {
+		config := randomizer.DefaultConfig()
+		config.Seed = int64(i)
+		config.NumVars = 8
+		formula := randomizer.New(fac, config).Formula(3)
+		variables := f.Variables(fac, formula).Content()
+		rng := rand.New(rand.NewSource(int64(i)))
+		costs := make(map[f.Literal]int)
+		for _, variable := range variables {
+			costs[variable.AsLiteral()] = rng.Intn(11) - 3
+			costs[variable.Negate(fac)] = rng.Intn(5)
+		}
+		if len(variables) < 3 {
+			continue
+		}
+		projected := variables[:len(variables)*2/3]
+		compiledBDD := bdd.Compile(fac, formula)
+		compiledDNNF := dnnf.Compile(fac, formula)
+		for _, ties := range []bool{false, true} {
+			topKConfig := &TopKConfig{Ties: ties, Handler: iter.HandlerWithLimit(math.MaxInt)}
+			for _, vars := range [][]f.Variable{variables, projected} {
+				exp := bruteForceCosts(fac, formula, vars, costs)
+				for _, k := range []int{1, 5, 20} {
+					models, _, err := TopKOnFormula(fac, formula, vars, costs, k, topKConfig)
+					assert.Nil(err)
+					assertTopK(assert, fac, formula, vars, costs, exp, k, ties, models)
+					models = TopKOnBDD(compiledBDD, vars, costs, k, topKConfig)
+					assertTopK(assert, fac, formula, vars, costs, exp, k, ties, models)
+					models = TopKOnDNNF(compiledDNNF, vars, costs, k, topKConfig)
+					assertTopK(assert, fac, formula, vars, costs, exp, k, ties, models)
+				}
+			}
+		}
+	}
+}
+
+func assertTopK(
+	assert *assert.Assertions,
+	fac f.Factory,
+	formula f.Formula,
+	vars []f.Variable,
+	costs map[f.Literal]int,
+	exp map[string]int,
+	k int,
+	ties bool,
+	models []RankedModel,
+) {
+	expCosts := append([]int{}, slices.Sorted(maps.Values(exp))...)
+	count := min(k, len(expCosts))
+	if ties {
+		for count > 0 && count < len(expCosts) && expCosts[count] == expCosts[count-1] {
+			count++
+		}
+	}
+	assert.Equal(expCosts[:count], rankedModelCosts(models))
+	strings := rankedModelStrings(fac, models)
+	for i, mdl := range models {
+		assert.Equal(exp[strings[i]], mdl.Cost)
+		assert.Equal(len(vars), mdl.Model.Size())
+	}
+	slices.Sort(strings)
+	assert.Equal(len(strings), len(slices.Compact(strings)))
+}
+
+// bruteForceCosts returns the minimal cost of each projected model.
+func bruteForceCosts(fac f.Factory, formula f.Formula, vars []f.Variable, costs map[f.Literal]int) map[string]int {
+	allVars := f.NewMutableVarSet(vars...)
+	allVars.AddAll(f.Variables(fac, formula))
+	result := make(map[string]int)
+	for _, mdl := range OnFormula(fac, formula, allVars.Content()) {
+		cost := 0
+		for _, literal := range mdl.Literals {
+			cost += costs[literal]
+		}
+		key := modelStrings(fac, []*model.Model{mdl}, vars...)[0]
+		if current, ok := result[key]; !ok || cost < current {
+			result[key] = cost
+		}
+	}
+	return result
+}
+
+func rankedModelStrings(fac f.Factory, models []RankedModel) []string {
+	mdls := make([]*model.Model, len(models))
+	for i, mdl := range models {
+		mdls[i] = mdl.Model
+	}
+	return modelStrings(fac, mdls)
+}
+
+func rankedModelCosts(models []RankedModel) []int {
+	costs := make([]int, len(models))
+	for i, mdl := range models {
+		costs[i] = mdl.Cost
+	}
+	return costs
+}
@@ -4,4 +4,8 @@
 // can be configured with different strategies.  The default strategy is to
 // recursively split the models and iterate over sub-sets.  Especially for large
 // enumerations this should yield better performance than iterating in one go.
+//
+// The split assignments can also be processed in parallel by multiple
+// workers with IterateParallel.  Since formula factories are not thread-safe,
+// each worker iterates on its own copy of the factory and its own SAT solver.
 package iter
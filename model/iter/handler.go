@@ -2,6 +2,7 @@ package iter
 
 import (
 	"github.com/booleworks/logicng-go/event"
+	"github.com/booleworks/logicng-go/handler"
 )
 
 // A LimitHandler can be used to cancel a model iteration depending on the
@@ -36,3 +37,39 @@ func (h *LimitHandler) ShouldResume(e event.Event) bool {
 	}
 	return h.countUncommitted+h.countCommitted < h.bound
 }
+
+// A BoundedHandler cancels a model iteration if a given handler cancels it
+// or if a limit of found models is reached.  In contrast to the LimitHandler
+// it can distinguish between the two reasons of a cancellation.
+type BoundedHandler struct {
+	hdl         handler.Handler
+	limit       *LimitHandler
+	cancelCause event.Event
+}
+
+// HandlerWithBound generates a new handler which cancels a model iteration if
+// the given handler cancels it or after the limit of found models is
+// reached.
+func HandlerWithBound(hdl handler.Handler, limit int) *BoundedHandler {
+	return &BoundedHandler{hdl: hdl, limit: HandlerWithLimit(limit)}
+}
+
+// ShouldResume processes the given event and returns true if the
+// computation should be resumed and false if it should be canceled.
+func (h *BoundedHandler) ShouldResume(e event.Event) bool {
+	if !h.hdl.ShouldResume(e) {
+		h.cancelCause = e
+		return false
+	}
+	return h.limit.ShouldResume(e)
+}
+
+// State returns the state of the given handler, i.e. a cancellation with its
+// cause if the given handler canceled the iteration.  A cancellation due to
+// the limit is a success.
+func (h *BoundedHandler) State() handler.State {
+	if h.cancelCause != nil {
+		return handler.Cancelation(h.cancelCause)
+	}
+	return handler.Success()
+}
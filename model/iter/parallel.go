@@ -0,0 +1,192 @@
+package iter
+
+import (
+	"sync"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/sat"
+)
+
+// IterateParallel iterates over all models of the given formulas like
+// Iterate, but processes the split assignments in parallel.  The split
+// assignments are computed with the iterator's strategy on recursion depth 0
+// and distributed over the given number of workers.  Each worker holds its
+// own copy of the factory and its own SAT solver and iterates the models of
+// its split assignments with its own collector.  The merge function is used
+// to merge the results of the workers' collectors, starting with the
+// emptyElement.
+//
+// The handler of the iterator is shared by all workers and is called
+// synchronously, it receives the events of all workers interleaved.  If the
+// handler cancels the iteration, all workers are stopped and the merged
+// result of the models committed so far is returned together with the
+// cancellation.
+//
+// If the strategy yields no split variables or the number of workers is
+// smaller than 2, the models are iterated sequentially on a single solver.
+// Since each worker copies the factory, the memory consumption grows with
+// the number of workers.
+func (m *ModelIterator[R]) IterateParallel(
+	fac f.Factory,
+	formulas []f.Formula,
+	workers int,
+	newCollector func(fac f.Factory, knownVars, dontCareVars, additionalVars *f.VarSet) Collector[R],
+	merge func(R, R) R,
+	emptyElement R,
+) (R, handler.State) {
+	hdl := &syncHandler{hdl: m.hdl}
+	if e := event.ModelEnumerationStarted; !hdl.ShouldResume(e) {
+		return emptyElement, handler.Cancelation(e)
+	}
+	// all literals of the models must exist before the factory is copied,
+	// such that the workers' models are valid on the original factory
+	for _, variable := range m.vars.Content() {
+		variable.Negate(fac)
+	}
+	if m.additionalVars != nil {
+		for _, variable := range m.additionalVars.Content() {
+			variable.Negate(fac)
+		}
+	}
+	solver := sat.NewSolver(fac)
+	solver.Add(formulas...)
+	collector := m.newSolverCollector(solver, newCollector)
+	splitVars := m.strategy.SplitVarsForRecursionDepth(m.vars, solver, 0)
+	if workers < 2 || splitVars.Size() == 0 {
+		worker := &ModelIterator[R]{m.vars, m.additionalVars, hdl, m.strategy}
+		state := worker.iterRecursive(collector, solver, []f.Literal{}, m.vars, splitVars, 0)
+		return collector.Result(), hdl.state(state)
+	}
+
+	splitAssignments, state := m.splitAssignments(collector, solver, splitVars, hdl)
+	if !state.Success {
+		return emptyElement, hdl.state(state)
+	}
+	workers = min(workers, len(splitAssignments))
+	assignments := make(chan *model.Model, len(splitAssignments))
+	for _, assignment := range splitAssignments {
+		assignments <- assignment
+	}
+	close(assignments)
+
+	mutableRemainingVars := f.NewMutableVarSetCopy(m.vars)
+	mutableRemainingVars.RemoveAll(splitVars)
+	remainingVars := mutableRemainingVars.AsImmutable()
+	// the content of a variable set is computed lazily, so it must be
+	// computed before the set is shared
+	remainingVars.Content()
+	results := make([]R, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workerFac := fac.(*f.CachingFactory).Copy()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := &ModelIterator[R]{m.vars, m.additionalVars, hdl, m.strategy}
+			results[i] = worker.iterSplitAssignments(workerFac, formulas, assignments, remainingVars, newCollector)
+		}()
+	}
+	wg.Wait()
+
+	result := emptyElement
+	for _, r := range results {
+		result = merge(result, r)
+	}
+	return result, hdl.state(succ)
+}
+
+// newSolverCollector returns a new collector for the variables on the given
+// solver.
+func (m *ModelIterator[R]) newSolverCollector(
+	solver *sat.Solver,
+	newCollector func(fac f.Factory, knownVars, dontCareVars, additionalVars *f.VarSet) Collector[R],
+) Collector[R] {
+	knownVariables := solver.CoreSolver().KnownVariables(solver.Factory())
+	additionalVarsNotOnSolver := difference(m.additionalVars, knownVariables)
+	dontCareVariablesNotOnSolver := difference(m.vars, knownVariables)
+	return newCollector(solver.Factory(), knownVariables, dontCareVariablesNotOnSolver, additionalVarsNotOnSolver)
+}
+
+// splitAssignments returns all assignments of the split variables on the
+// solver.  The split variables are reduced by the strategy until the number
+// of assignments does not exceed the strategy's maximum.
+func (m *ModelIterator[R]) splitAssignments(
+	collector Collector[R],
+	solver *sat.Solver,
+	splitVars *f.VarSet,
+	hdl handler.Handler,
+) ([]*model.Model, handler.State) {
+	maxModelsForSplitAssignments := m.strategy.MaxModelsForSplitAssignments(0)
+	for {
+		finished, state := iterate(collector, solver, splitVars, nil, maxModelsForSplitAssignments, hdl)
+		if !state.Success {
+			collector.Rollback(hdl)
+			return nil, state
+		} else if finished {
+			return collector.RollbackAndReturnModels(solver, hdl)
+		}
+		if state = collector.Rollback(hdl); !state.Success {
+			return nil, state
+		}
+		splitVars = m.strategy.ReduceSplitVars(splitVars, 0)
+	}
+}
+
+// iterSplitAssignments iterates the models for the split assignments of the
+// channel on a new solver on the given factory copy and returns the result
+// of its collector.
+func (m *ModelIterator[R]) iterSplitAssignments(
+	fac f.Factory,
+	formulas []f.Formula,
+	assignments <-chan *model.Model,
+	remainingVars *f.VarSet,
+	newCollector func(fac f.Factory, knownVars, dontCareVars, additionalVars *f.VarSet) Collector[R],
+) R {
+	solver := sat.NewSolver(fac)
+	solver.Add(formulas...)
+	collector := m.newSolverCollector(solver, newCollector)
+	recursiveSplitVars := m.strategy.SplitVarsForRecursionDepth(remainingVars, solver, 1)
+	for assignment := range assignments {
+		m.iterRecursive(collector, solver, assignment.Literals, m.vars, recursiveSplitVars, 1)
+		if s := collector.Commit(m.hdl); !s.Success {
+			break
+		}
+	}
+	return collector.Result()
+}
+
+// A syncHandler serializes the calls to a handler which is shared by
+// multiple workers.  Once the handler canceled the computation, it cancels
+// the computations of all workers.
+type syncHandler struct {
+	mu          sync.Mutex
+	hdl         handler.Handler
+	cancelCause event.Event
+}
+
+func (h *syncHandler) ShouldResume(e event.Event) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancelCause != nil {
+		return false
+	}
+	if !h.hdl.ShouldResume(e) {
+		h.cancelCause = e
+		return false
+	}
+	return true
+}
+
+// state returns the cancellation of the handler if it canceled the
+// computation and the given state otherwise.
+func (h *syncHandler) state(state handler.State) handler.State {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancelCause != nil {
+		return handler.Cancelation(h.cancelCause)
+	}
+	return state
+}
@@ -0,0 +1,17 @@
+// Package sample provides near-uniform sampling of models with a SAT solver
+// in LogicNG.
+//
+// Sampling models by repeated SAT solver calls is heavily biased.  The
+// sampler in this package follows the UniGen algorithm: random XOR
+// constraints over the sampling variables partition the models into small
+// cells, a cell is enumerated and a model of the cell is chosen uniformly at
+// random.  Each model is drawn with a probability which deviates from the
+// uniform distribution by a user-defined tolerance:
+//
+//	rng := rand.New(rand.NewSource(42))
+//	samples, err := sample.OnFormula(fac, formula, variables, 100, rng)
+//
+// Formulas which can be compiled to a BDD or DNNF can be sampled exactly
+// uniformly (or according to literal weights) with the sampling methods of
+// [bdd.BDD] and [dnnf.DNNF].
+package sample
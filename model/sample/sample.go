@@ -0,0 +1,227 @@
+package sample
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+
+	"github.com/booleworks/logicng-go/encoding"
+	"github.com/booleworks/logicng-go/errorx"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/count"
+	"github.com/booleworks/logicng-go/model/enum"
+	"github.com/booleworks/logicng-go/model/iter"
+	"github.com/booleworks/logicng-go/sat"
+)
+
+var succ = handler.Success()
+
+// Maximum number of consecutive attempts to find a cell of a suitable size.
+// Each attempt succeeds with a probability of at least 0.62, so reaching the
+// limit indicates a wrong estimate of the number of models.
+const maxFailedAttempts = 100
+
+// Config describes the configuration of the sampler.  Each model of the n
+// models R projected to the sampling variables is drawn with a probability p
+// with 1/((1+Epsilon)*n) <= p <= (1+Epsilon)/n.  A smaller tolerance
+// requires larger cells and therefore more time per sample.
+type Config struct {
+	Epsilon float64 // tolerance of the uniformity, must be > 1.71
+}
+
+// DefaultConfig returns the default configuration of the sampler with a
+// tolerance of 6.
+func DefaultConfig() *Config {
+	return &Config{Epsilon: 6}
+}
+
+// OnFormula returns n models of the formula projected to the given variables
+// which are drawn independently and near-uniformly at random.  See OnSolver
+// for details.
+func OnFormula(
+	fac f.Factory,
+	formula f.Formula,
+	variables []f.Variable,
+	n int,
+	rng *rand.Rand,
+	config ...*Config,
+) ([]*model.Model, error) {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	samples, _, err := OnSolverWithHandler(solver, variables, n, rng, handler.NopHandler, config...)
+	return samples, err
+}
+
+// OnSolver returns n models of the formulas on the solver projected to the
+// given variables which are drawn independently and near-uniformly at
+// random.  The random number generator rng makes the samples reproducible.
+// If the formulas have only few models, all models are enumerated and the
+// samples are drawn exactly uniformly.  Otherwise the number of models is
+// estimated with count.ApproxOnSolver first, which determines the number of
+// XOR constraints per cell.  Returns an empty slice if the formulas are
+// unsatisfiable.  The state of the solver is restored after the sampling.
+//
+// Returns an error if n is negative, if the tolerance of the configuration is
+// not greater than 1.71, or if no cell of a suitable size was found in 100
+// consecutive attempts, which indicates a wrong estimate of the number of
+// models.
+func OnSolver(
+	solver *sat.Solver,
+	variables []f.Variable,
+	n int,
+	rng *rand.Rand,
+	config ...*Config,
+) ([]*model.Model, error) {
+	samples, _, err := OnSolverWithHandler(solver, variables, n, rng, handler.NopHandler, config...)
+	return samples, err
+}
+
+// OnSolverWithHandler returns n models of the formulas on the solver
+// projected to the given variables like OnSolver.  The handler can be used
+// to cancel the sampling, it is also passed to the model count and to the
+// enumerations of the cells.
+func OnSolverWithHandler(
+	solver *sat.Solver,
+	variables []f.Variable,
+	n int,
+	rng *rand.Rand,
+	hdl handler.Handler,
+	config ...*Config,
+) ([]*model.Model, handler.State, error) {
+	cfg := DefaultConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if n < 0 {
+		return nil, succ, errorx.BadInput("number of samples must not be negative: %d", n)
+	}
+	if cfg.Epsilon <= 1.71 {
+		return nil, succ, errorx.BadInput("tolerance must be greater than 1.71: %f", cfg.Epsilon)
+	}
+	if e := event.ModelSamplingStarted; !hdl.ShouldResume(e) {
+		return nil, handler.Cancelation(e), nil
+	}
+	kappa := kappa(cfg.Epsilon)
+	pivot := math.Ceil(4.03 * (1 + 1/kappa) * (1 + 1/kappa))
+	s := &sampler{
+		solver:   solver,
+		vars:     f.NewVarSet(variables...).Content(),
+		rng:      rng,
+		hdl:      hdl,
+		loThresh: int(pivot / (math.Sqrt2 * (1 + kappa))),
+		hiThresh: int(1 + math.Sqrt2*(1+kappa)*pivot),
+	}
+	samples := make([]*model.Model, 0, n)
+	models, state := s.cell(0)
+	if !state.Success {
+		return nil, state, nil
+	}
+	if len(models) <= s.hiThresh {
+		for i := 0; i < n && len(models) > 0; i++ {
+			samples = append(samples, model.New(models[rng.Intn(len(models))].Literals...))
+		}
+		return samples, succ, nil
+	}
+
+	approxConfig := count.DefaultApproxConfig()
+	approxConfig.Seed = rng.Int63()
+	estimate, state := count.ApproxOnSolverWithHandler(solver, s.vars, hdl, approxConfig)
+	if !state.Success {
+		return nil, state, nil
+	}
+	estimateFloat, _ := new(big.Float).SetInt(estimate).Float64()
+	q := int(math.Ceil(math.Log2(estimateFloat) + math.Log2(1.8) - math.Log2(pivot)))
+	return s.samples(samples, n, q)
+}
+
+// kappa returns the parameter of the cell size thresholds for the given
+// tolerance by solving epsilon = (1+kappa)(2.23+0.48/(1-kappa)^2)-1 for
+// kappa in (0,1).
+func kappa(epsilon float64) float64 {
+	low, high := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if (1+mid)*(2.23+0.48/((1-mid)*(1-mid)))-1 < epsilon {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return low
+}
+
+type sampler struct {
+	solver   *sat.Solver
+	vars     []f.Variable
+	rng      *rand.Rand
+	hdl      handler.Handler
+	loThresh int
+	hiThresh int
+}
+
+// samples draws models from cells with q-3 to q random XOR constraints and
+// appends them to the given samples until there are n samples.  Returns an
+// error if maxFailedAttempts consecutive attempts failed.
+func (s *sampler) samples(samples []*model.Model, n, q int) ([]*model.Model, handler.State, error) {
+	failed := 0
+	for len(samples) < n {
+		sample, state := s.sample(q)
+		if !state.Success {
+			return nil, state, nil
+		}
+		if sample == nil {
+			failed++
+			if failed == maxFailedAttempts {
+				return nil, succ, errorx.IllegalState("no cell of a suitable size found in %d attempts", failed)
+			}
+			continue
+		}
+		failed = 0
+		samples = append(samples, sample)
+	}
+	return samples, succ, nil
+}
+
+// sample tries to draw one model from a cell with q-3 to q random XOR
+// constraints.  Returns nil if none of the cells has a size between the
+// thresholds.
+func (s *sampler) sample(q int) (*model.Model, handler.State) {
+	for i := max(q-3, 0); i <= q; i++ {
+		models, state := s.cell(i)
+		if !state.Success {
+			return nil, state
+		}
+		if s.loThresh <= len(models) && len(models) <= s.hiThresh {
+			return model.New(models[s.rng.Intn(len(models))].Literals...), succ
+		}
+	}
+	return nil, succ
+}
+
+// cell enumerates the models of the cell of m new random XOR constraints up
+// to a size of one more than the upper threshold.
+func (s *sampler) cell(m int) ([]*model.Model, handler.State) {
+	solverState := s.solver.SaveState()
+	defer func() {
+		if err := s.solver.LoadState(solverState); err != nil {
+			panic(err)
+		}
+	}()
+	fac := s.solver.Factory()
+	for i := 0; i < m; i++ {
+		var vars []f.Variable
+		for _, variable := range s.vars {
+			if s.rng.Intn(2) == 0 {
+				vars = append(vars, variable)
+			}
+		}
+		s.solver.Add(encoding.EncodeXOR(fac, vars, s.rng.Intn(2) == 0)...)
+	}
+	hdl := iter.HandlerWithBound(s.hdl, s.hiThresh+1)
+	config := &iter.Config{Handler: hdl, Strategy: iter.NewNoSplitMEStrategy()}
+	models, _ := enum.OnSolverWithConfig(s.solver, s.vars, config)
+	return models, hdl.State()
+}
@@ -0,0 +1,162 @@
+package sample
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/model/count"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleSmall(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+	formula := p.ParseUnsafe("(a | b) & (a | c) & (~a | c | d)")
+
+	samples, err := OnFormula(fac, formula, []f.Variable{a, b}, 3000, rand.New(rand.NewSource(1)))
+	assert.Nil(err)
+	assert.Len(samples, 3000)
+	frequencies := sampleFrequencies(fac, samples)
+	assert.Len(frequencies, 3)
+	assert.InDelta(1.0/3, frequencies["[a, b]"], 0.04)
+	assert.InDelta(1.0/3, frequencies["[a, ~b]"], 0.04)
+	assert.InDelta(1.0/3, frequencies["[~a, b]"], 0.04)
+
+	samples, _ = OnFormula(fac, formula, []f.Variable{a, b, c}, 10, rand.New(rand.NewSource(2)))
+	again, _ := OnFormula(fac, formula, []f.Variable{a, b, c}, 10, rand.New(rand.NewSource(2)))
+	assert.Equal(samples, again)
+	samples, err = OnFormula(fac, fac.Falsum(), []f.Variable{a}, 10, rand.New(rand.NewSource(3)))
+	assert.Nil(err)
+	assert.Empty(samples)
+}
+
+func TestSampleIllegalInput(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	a := fac.Var("a")
+	rng := rand.New(rand.NewSource(1))
+	_, err := OnFormula(fac, a.AsFormula(), []f.Variable{a}, -1, rng)
+	assert.NotNil(err)
+	_, err = OnFormula(fac, a.AsFormula(), []f.Variable{a}, 10, rng, &Config{Epsilon: 1.71})
+	assert.NotNil(err)
+	samples, err := OnFormula(fac, a.AsFormula(), []f.Variable{a}, 0, rng)
+	assert.Nil(err)
+	assert.Empty(samples)
+}
+
+func TestSampleFailedAttempts(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	formula := parser.New(fac).ParseUnsafe("a + b + c + d + e + f + g + h + i + j <= 5")
+	vars := f.Variables(fac, formula).Content()
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	s := &sampler{
+		solver:   solver,
+		vars:     vars,
+		rng:      rand.New(rand.NewSource(1)),
+		hdl:      handler.NopHandler,
+		loThresh: 2,
+		hiThresh: 4,
+	}
+	// cells with more XOR constraints than variables contain at most one model
+	samples, state, err := s.samples(nil, 1, len(vars)+10)
+	assert.Nil(samples)
+	assert.True(state.Success)
+	assert.NotNil(err)
+}
+
+func TestSampleLarge(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a + b + c + d + e + f + g + h + i + j + k + l <= 4) & (x => a | b) & (y | z)")
+	vars := f.Variables(fac, p.ParseUnsafe("a & b & c & d & e & f & g & h & i & j & k & l & x")).Content()
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+
+	samples, err := OnSolver(solver, vars, 200, rand.New(rand.NewSource(1)))
+	assert.Nil(err)
+	assert.Len(samples, 200)
+	frequencies := make(map[f.Literal]float64)
+	for _, sample := range samples {
+		assert.Equal(len(vars), sample.Size())
+		check := sat.NewSolver(fac)
+		check.Add(formula)
+		check.Add(sample.Formula(fac))
+		assert.True(check.Sat())
+		for _, literal := range sample.Literals {
+			frequencies[literal] += 1.0 / float64(len(samples))
+		}
+	}
+	total, _ := count.ProjectedCount(fac, vars, formula).Float64()
+	for _, literal := range []f.Literal{fac.Lit("x", false), fac.Lit("c", true)} {
+		literalCount, _ := count.ProjectedCount(fac, vars, formula, literal.AsFormula()).Float64()
+		assert.InDelta(literalCount/total, frequencies[literal], 0.1)
+	}
+	assert.Zero(count.ProjectedCount(fac, vars, formula).Cmp(count.OnSolver(solver, vars)))
+}
+
+func TestSampleHandler(t *testing.T) {
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("a + b + c + d + e + f + g + h + i + j <= 5")
+	vars := f.Variables(fac, formula).Content()
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+
+	samples, state, err := OnSolverWithHandler(solver, vars, 10, rand.New(rand.NewSource(1)), handler.NopHandler)
+	assert.Nil(t, err)
+	assert.True(t, state.Success)
+	assert.Len(t, samples, 10)
+
+	hdl := &eventLimitHandler{event: event.ModelSamplingStarted}
+	samples, state, _ = OnSolverWithHandler(solver, vars, 10, rand.New(rand.NewSource(1)), hdl)
+	assert.Nil(t, samples)
+	assert.False(t, state.Success)
+	assert.Equal(t, event.ModelSamplingStarted, state.CancelCause)
+
+	hdl = &eventLimitHandler{event: event.ModelEnumerationStarted, limit: 5}
+	samples, state, _ = OnSolverWithHandler(solver, vars, 10, rand.New(rand.NewSource(1)), hdl)
+	assert.Nil(t, samples)
+	assert.False(t, state.Success)
+	assert.Equal(t, event.ModelEnumerationStarted, state.CancelCause)
+	assert.True(t, solver.Sat())
+}
+
+func TestKappa(t *testing.T) {
+	assert.InDelta(t, 0.0, kappa(1.71), 1e-3)
+	for _, epsilon := range []float64{2, 6, 16} {
+		k := kappa(epsilon)
+		assert.InDelta(t, epsilon, (1+k)*(2.23+0.48/((1-k)*(1-k)))-1, 1e-9)
+	}
+}
+
+func sampleFrequencies(fac f.Factory, samples []*model.Model) map[string]float64 {
+	frequencies := make(map[string]float64)
+	for _, sample := range samples {
+		frequencies[sample.Sprint(fac)] += 1 / float64(len(samples))
+	}
+	return frequencies
+}
+
+type eventLimitHandler struct {
+	event event.Event
+	limit int
+	count int
+}
+
+func (h *eventLimitHandler) ShouldResume(e event.Event) bool {
+	if e == h.event {
+		h.count++
+	}
+	return h.count <= h.limit
+}
@@ -123,10 +123,14 @@ func computeGeneric(
 	for {
 		var hModel *model.Model
 		var state handler.State
+		var err error
 		if maximize {
-			hModel, state = hSolver.MaximizeWithHandler(literals, hdl)
+			hModel, state, err = hSolver.MaximizeWithHandler(literals, hdl)
 		} else {
-			hModel, state = hSolver.MinimizeWithHandler(literals, hdl)
+			hModel, state, err = hSolver.MinimizeWithHandler(literals, hdl)
+		}
+		if err != nil {
+			panic(err)
 		}
 		if !state.Success {
 			return nil, nil, state
@@ -124,3 +124,17 @@ func testPrimeImplicantProperty(t *testing.T, fac f.Factory, formula f.Formula,
 		assert.True(solver.Call(sat.WithAssumptions(reducedPrimeImplicant.Content())).Sat())
 	}
 }
+
+func TestImplicantReduction(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	d := f.NewTestData(fac)
+	parser := parser.New(fac)
+
+	reduction := NewImplicantReduction(fac, parser.ParseUnsafe("a&b|c&d"))
+	assert.Equal([]f.Literal{d.LA, d.LB}, reduction.Reduce([]f.Literal{d.LA, d.LB, d.LC, d.LD.Negate(fac)}))
+	assert.Equal([]f.Literal{d.LC, d.LD}, reduction.Reduce([]f.Literal{d.LNA, d.LB, d.LC, d.LD}))
+
+	reduction = NewImplicantReduction(fac, d.True)
+	assert.Empty(reduction.Reduce([]f.Literal{d.LA, d.LB}))
+}
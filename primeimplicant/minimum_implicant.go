@@ -44,7 +44,10 @@ func Minimum(fac f.Factory, formula f.Formula) ([]f.Literal, error) {
 	if !solver.Sat() {
 		return nil, errorx.BadInput("formula was unsatisfiable")
 	}
-	minimumModel := solver.Minimize(newLiterals)
+	minimumModel, err := solver.Minimize(newLiterals)
+	if err != nil {
+		return nil, err
+	}
 	primeImplicant := make([]f.Literal, 0)
 
 	for _, variable := range minimumModel.PosVars() {
@@ -0,0 +1,41 @@
+package primeimplicant
+
+import (
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/sat"
+)
+
+// An ImplicantReduction reduces implicants of a formula to prime implicants.
+// It holds a SAT solver on the negation of the formula and can therefore be
+// used to reduce many implicants of the same formula efficiently.
+type ImplicantReduction struct {
+	reduction *primeReduction
+}
+
+// NewImplicantReduction returns a new implicant reduction for the given
+// formula.
+func NewImplicantReduction(fac f.Factory, formula f.Formula) *ImplicantReduction {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula.Negate(fac))
+	return &ImplicantReduction{&primeReduction{implicantSolver: solver}}
+}
+
+// Reduce reduces the given implicant of the formula to a prime implicant by
+// removing literals as long as the remaining literals still imply the
+// formula.  The result is a subset of the given implicant.  If the given
+// literals are not an implicant of the formula, the result is undefined.
+func (r *ImplicantReduction) Reduce(implicant []f.Literal) []f.Literal {
+	prime, _ := r.ReduceWithHandler(implicant, handler.NopHandler)
+	return prime
+}
+
+// ReduceWithHandler reduces the given implicant of the formula to a prime
+// implicant like Reduce.  The given handler can be used to cancel the SAT
+// calls of the reduction.
+func (r *ImplicantReduction) ReduceWithHandler(
+	implicant []f.Literal,
+	hdl handler.Handler,
+) ([]f.Literal, handler.State) {
+	return r.reduction.reduceImplicant(implicant, hdl)
+}
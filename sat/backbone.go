@@ -236,10 +236,18 @@ func (m *CoreSolver) isRotatable(lit int32) bool {
 			return false
 		}
 	}
+	for _, pb := range m.pbOccurrences[lit] {
+		if m.isUnitPB(lit, pb) {
+			return false
+		}
+	}
 	return true
 }
 
 func (m *CoreSolver) isUnit(lit int32, clause *clause) bool {
+	if clause.pb != nil {
+		return m.isUnitPB(lit, clause)
+	}
 	if !clause.isAtMost {
 		for i := 0; i < clause.size(); i++ {
 			clauseLit := clause.get(i)
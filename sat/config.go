@@ -30,6 +30,7 @@ const (
 type Config struct {
 	ProofGeneration    bool               // record proof generation information on-the-fly
 	UseAtMostClauses   bool               // use a special representation of at-most-one clauses
+	UsePBConstraints   bool               // propagate pseudo-Boolean constraints natively
 	CNFMethod          CNFMethod          // method for adding CNFs
 	ClauseMinimization ClauseMinimization // algorithm for minimizing learnt clauses
 	InitialPhase       bool               // initial phase for assigning literals
@@ -80,12 +81,21 @@ func (c *Config) UseAtMost(useAtMost bool) *Config {
 	return c
 }
 
+// UsePB sets the flag whether pseudo-Boolean and cardinality constraints
+// should be propagated natively on the solver instead of being encoded to
+// CNF and returns the config.
+func (c *Config) UsePB(usePB bool) *Config {
+	c.UsePBConstraints = usePB
+	return c
+}
+
 // DefaultConfig returns the default configuration for a SAT solver
 // configuration.
 func DefaultConfig() *Config {
 	return &Config{
 		ProofGeneration:    false,
 		UseAtMostClauses:   false,
+		UsePBConstraints:   false,
 		CNFMethod:          CNFPG,
 		ClauseMinimization: ClauseMinDeep,
 		InitialPhase:       false,
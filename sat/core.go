@@ -37,6 +37,7 @@ type CoreSolver struct {
 	unitClauses     []int32
 	clauses         []*clause
 	learnts         []*clause
+	pbOccurrences   map[int32][]*clause
 	watches         [][]*watcher
 	vars            []*variable
 	orderHeap       lngheap
@@ -153,6 +154,7 @@ func initialize(m *CoreSolver, config *Config) {
 	m.qhead = 0
 	m.clauses = []*clause{}
 	m.learnts = []*clause{}
+	m.pbOccurrences = map[int32][]*clause{}
 	m.watches = [][]*watcher{}
 	m.vars = []*variable{}
 	m.orderHeap = *newLngHeap(m)
@@ -578,6 +580,12 @@ func (m *CoreSolver) loadState(solverState *SolverState) error {
 	newClausesSize := min(state[2], len(m.clauses))
 	for i := len(m.clauses) - 1; i >= newClausesSize; i-- {
 		m.simpleRemoveClause(m.clauses[i])
+		if c := m.clauses[i]; c.pb != nil {
+			for j := 0; j < c.size(); j++ {
+				lit := c.get(j)
+				m.pbOccurrences[lit] = m.pbOccurrences[lit][:len(m.pbOccurrences[lit])-1]
+			}
+		}
 	}
 	shrinkTo(&m.clauses, newClausesSize)
 
@@ -614,6 +622,7 @@ func (m *CoreSolver) completeBacktrack() {
 		vari := m.vars[v]
 		vari.assignment = f.TristateUndef
 		vari.reason = nil
+		m.undoPB(vari)
 		if !m.orderHeap.inHeap(int32(v)) && vari.decision {
 			m.orderHeap.insert(int32(v))
 		}
@@ -624,7 +633,9 @@ func (m *CoreSolver) completeBacktrack() {
 }
 
 func (m *CoreSolver) simpleRemoveClause(c *clause) {
-	if c.isAtMost {
+	if c.pb != nil {
+		m.simpleRemovePB(c)
+	} else if c.isAtMost {
 		for i := 0; i < c.atMostWatchers; i++ {
 			removeWatcher(&m.watches[c.get(i)], c)
 		}
@@ -820,7 +831,23 @@ func (m *CoreSolver) propagate() *clause {
 			}
 			c := i.clause
 
-			if c.isAtMost {
+			if c.pb != nil {
+				keep, pbConfl := m.propagatePB(c, Not(p))
+				if keep {
+					(*ws)[jInd] = i
+					jInd++
+				}
+				iInd++
+				if pbConfl != nil {
+					confl = pbConfl
+					m.qhead = len(m.trail)
+					for iInd < len(*ws) {
+						(*ws)[jInd] = (*ws)[iInd]
+						jInd++
+						iInd++
+					}
+				}
+			} else if c.isAtMost {
 				switch newWatch := m.findNewWatchForAtMostClause(c, p); newWatch {
 				case LitUndef:
 					for k := 0; k < c.atMostWatchers; k++ {
@@ -1247,6 +1274,7 @@ func (m *CoreSolver) cancelUntil(level int) {
 				v := m.vars[x]
 				v.assignment = f.TristateUndef
 				v.polarity = Sign(m.trail[c])
+				m.undoPB(v)
 				m.insertVarOrder(x)
 			}
 		} else {
@@ -1255,6 +1283,7 @@ func (m *CoreSolver) cancelUntil(level int) {
 				v := m.vars[x]
 				v.assignment = f.TristateUndef
 				v.polarity = !m.computingBackbone && Sign(m.trail[c])
+				m.undoPB(v)
 				m.insertVarOrder(x)
 			}
 		}
@@ -13,6 +13,7 @@ type CoreState struct {
 	UnitClauses           *[]int32
 	Clauses               *[]*clause
 	Learnts               *[]*clause
+	PBConstraints         *[]*clause
 	Watches               *[][]*watcher
 	Vars                  *[]*variable
 	OrderHeap             *lngheap
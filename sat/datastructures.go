@@ -18,6 +18,7 @@ type clause struct {
 	canBeDel       bool
 	oneWatched     bool
 	atMostWatchers int
+	pb             *pbConstraint
 }
 
 func newClause(ps []int32, learntOnState int32) *clause {
@@ -127,6 +128,7 @@ type variable struct {
 	activity   float64
 	polarity   bool
 	decision   bool
+	pbCounts   []pbCount
 }
 
 func newVariable(polarity bool) *variable {
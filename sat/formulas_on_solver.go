@@ -24,7 +24,9 @@ func (s *Solver) FormulasOnSolver() []f.Formula {
 			litInt := clause.get(i)
 			lits[i] = s.fac.Lit(s.core.idx2name[litInt>>1], (litInt&1) != 1)
 		}
-		if !clause.isAtMost {
+		if clause.pb != nil {
+			formulas.Add(s.fac.PBC(f.GE, clause.pb.degree, lits, clause.pb.coeffs))
+		} else if !clause.isAtMost {
 			formulas.Add(s.fac.Clause(lits...))
 		} else {
 			rhs := clause.size() + 1 - clause.atMostWatchers
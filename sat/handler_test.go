@@ -69,8 +69,9 @@ func TestOptimizationTimeoutHandler(t *testing.T) {
 	end := time.Now().Add(duration)
 	optHandler := handler.NewTimeoutWithEnd(end)
 
-	result, state := solver.MaximizeWithHandler(vars, optHandler)
+	result, state, err := solver.MaximizeWithHandler(vars, optHandler)
 
+	assert.Nil(err)
 	assert.False(state.Success)
 	assert.NotEqual(event.Nothing, state.CancelCause)
 	assert.NotNil(result)
@@ -82,8 +83,9 @@ func TestOptimizationTimeoutHandler(t *testing.T) {
 	duration, _ = time.ParseDuration("2h")
 	optHandler = handler.NewTimeoutWithDuration(duration)
 
-	result, state = solver.MaximizeWithHandler(vars, optHandler)
+	result, state, err = solver.MaximizeWithHandler(vars, optHandler)
 
+	assert.Nil(err)
 	assert.True(state.Success)
 	assert.Equal(event.Nothing, state.CancelCause)
 	assert.NotNil(result)
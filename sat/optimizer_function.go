@@ -3,6 +3,7 @@ package sat
 import (
 	"fmt"
 
+	"github.com/booleworks/logicng-go/encoding"
 	"github.com/booleworks/logicng-go/event"
 	f "github.com/booleworks/logicng-go/formula"
 	"github.com/booleworks/logicng-go/handler"
@@ -21,37 +22,41 @@ const selPrefix = "@SEL_OPT_"
 
 // Maximize searches for a model on the solver with the maximum of the given
 // literals set to true.  The returned model will also include the additional
-// variables.
-func (s *Solver) Maximize(literals []f.Literal, additionalVariables ...f.Variable) *model.Model {
-	opt, _ := s.optimize(true, literals, handler.NopHandler, additionalVariables)
-	return opt
+// variables.  Returns an error if the incremental cardinality constraint for
+// the bound could not be generated or tightened.
+func (s *Solver) Maximize(literals []f.Literal, additionalVariables ...f.Variable) (*model.Model, error) {
+	opt, _, err := s.optimize(true, literals, handler.NopHandler, additionalVariables)
+	return opt, err
 }
 
 // MaximizeWithHandler searches for a model on the solver with the maximum of
 // the given literals set to true.  The returned model will also include the
 // additional variables.  The given optimizationHandler can be used to cancel
-// the optimization process.
+// the optimization process.  Returns an error if the incremental cardinality
+// constraint for the bound could not be generated or tightened.
 func (s *Solver) MaximizeWithHandler(
 	literals []f.Literal, hdl handler.Handler, additionalVariables ...f.Variable,
-) (*model.Model, handler.State) {
+) (*model.Model, handler.State, error) {
 	return s.optimize(true, literals, hdl, additionalVariables)
 }
 
 // Minimize searches for a model on the solver with the minimum of the given
 // literals set to true.  The returned model will also include the additional
-// variables.
-func (s *Solver) Minimize(literals []f.Literal, additionalVariables ...f.Variable) *model.Model {
-	opt, _ := s.optimize(false, literals, handler.NopHandler, additionalVariables)
-	return opt
+// variables.  Returns an error if the incremental cardinality constraint for
+// the bound could not be generated or tightened.
+func (s *Solver) Minimize(literals []f.Literal, additionalVariables ...f.Variable) (*model.Model, error) {
+	opt, _, err := s.optimize(false, literals, handler.NopHandler, additionalVariables)
+	return opt, err
 }
 
 // MinimizeWithHandler searches for a model on the solver with the minimum of
 // the given literals set to true.  The returned model will also include the
 // additional variables.  The given optimizationHandler can be used to cancel
-// the optimization process.
+// the optimization process.  Returns an error if the incremental cardinality
+// constraint for the bound could not be generated or tightened.
 func (s *Solver) MinimizeWithHandler(
 	literals []f.Literal, hdl handler.Handler, additionalVariables ...f.Variable,
-) (*model.Model, handler.State) {
+) (*model.Model, handler.State, error) {
 	return s.optimize(false, literals, hdl, additionalVariables)
 }
 
@@ -60,8 +65,9 @@ func (s *Solver) optimize(
 	literals []f.Literal,
 	hdl handler.Handler,
 	additionalVariables []f.Variable,
-) (*model.Model, handler.State) {
+) (*model.Model, handler.State, error) {
 	initialState := s.SaveState()
+	defer func() { _ = s.LoadState(initialState) }()
 	resultModelVariables := f.NewMutableVarSet(additionalVariables...)
 	for _, lit := range literals {
 		variable := lit.Variable()
@@ -75,9 +81,7 @@ func (s *Solver) optimize(
 			relevantIndices = append(relevantIndices, idx)
 		}
 	}
-	mdl, state := s.maximize(maximize, literals, relevantIndices, hdl)
-	_ = s.LoadState(initialState)
-	return mdl, state
+	return s.maximize(maximize, literals, relevantIndices, hdl)
 }
 
 func (s *Solver) maximize(
@@ -85,9 +89,9 @@ func (s *Solver) maximize(
 	literals []f.Literal,
 	relevantIndices []int32,
 	hdl handler.Handler,
-) (*model.Model, handler.State) {
+) (*model.Model, handler.State, error) {
 	if e := event.OptimizationFunctionStarted; !hdl.ShouldResume(e) {
-		return nil, handler.Cancelation(e)
+		return nil, handler.Cancelation(e), nil
 	}
 	fac := s.fac
 	selectorMap := make(map[f.Variable]f.Literal)
@@ -113,10 +117,10 @@ func (s *Solver) maximize(
 	params := Params().Handler(hdl).WithModel(selectors)
 	sResult := s.Call(params)
 	if sResult.Canceled() {
-		return nil, sResult.state
+		return nil, sResult.state, nil
 	}
 	if !sResult.Sat() {
-		return nil, succ
+		return nil, succ, nil
 	}
 	internalModel := s.core.Model()
 	currentModel := sResult.Model()
@@ -126,23 +130,33 @@ func (s *Solver) maximize(
 		s.Add(fac.CC(f.GE, 1, selectors...))
 		sResult = s.Call(params)
 		if sResult.Canceled() {
-			return s.core.CreateModel(s.fac, internalModel, relevantIndices), sResult.state
+			return s.core.CreateModel(s.fac, internalModel, relevantIndices), sResult.state, nil
 		} else if !sResult.Sat() {
-			return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ
+			return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ, nil
 		}
 		internalModel = s.core.Model()
 		currentModel = sResult.Model()
 		currentBound = len(currentModel.PosVars())
 	} else if currentBound == len(selectors) {
-		return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ
+		return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ, nil
 	}
 
 	cc := fac.CC(f.GE, uint32(currentBound+1), selectors...)
 
-	incrementalData, _ := s.AddIncrementalCC(cc)
+	var incrementalData *encoding.CCIncrementalData
+	if currentBound+1 < len(selectors) {
+		var err error
+		if incrementalData, err = s.AddIncrementalCC(cc); err != nil {
+			return nil, succ, err
+		}
+	} else {
+		// the constraint is a conjunction of all selectors, so each further
+		// model is optimal and the bound never has to be tightened
+		s.Add(cc)
+	}
 	sResult = s.Call(params)
 	if sResult.Canceled() {
-		return s.core.CreateModel(s.fac, internalModel, relevantIndices), sResult.state
+		return s.core.CreateModel(s.fac, internalModel, relevantIndices), sResult.state, nil
 	}
 
 	for sResult.Sat() {
@@ -151,18 +165,20 @@ func (s *Solver) maximize(
 			return s.core.CreateModel(s.fac, internalModel, relevantIndices)
 		}}
 		if !hdl.ShouldResume(betterBoundEvent) {
-			return s.core.CreateModel(s.fac, internalModel, relevantIndices), handler.Cancelation(betterBoundEvent)
+			return s.core.CreateModel(s.fac, internalModel, relevantIndices), handler.Cancelation(betterBoundEvent), nil
 		}
 		currentModel = sResult.Model()
 		currentBound = len(currentModel.PosVars())
 		if currentBound == len(selectors) {
-			return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ
+			return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ, nil
+		}
+		if err := incrementalData.NewLowerBoundForSolver(currentBound + 1); err != nil {
+			return nil, succ, err
 		}
-		incrementalData.NewLowerBoundForSolver(currentBound + 1)
 		sResult = s.Call(params)
 		if sResult.Canceled() {
-			return s.core.CreateModel(s.fac, internalModel, relevantIndices), sResult.state
+			return s.core.CreateModel(s.fac, internalModel, relevantIndices), sResult.state, nil
 		}
 	}
-	return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ
+	return s.core.CreateModel(s.fac, internalModel, relevantIndices), succ, nil
 }
@@ -114,15 +114,17 @@ func TestOptimizerFunctionIncMinMax(t *testing.T) {
 		vars := f.VariablesAsLiterals(variables.Content())
 		solver.Add(formula)
 
-		minimumModel := solver.Minimize(vars)
-		maximumModel := solver.Maximize(vars)
+		minimumModel, err := solver.Minimize(vars)
+		assert.Nil(err)
+		maximumModel, err := solver.Maximize(vars)
+		assert.Nil(err)
 		assert.Equal(3, len(minimumModel.PosVars()))
 		assert.Equal(10, len(maximumModel.PosVars()))
 
 		formula = parser.ParseUnsafe("~p")
 		solver.Add(formula)
-		minimumModel = solver.Minimize(vars)
-		maximumModel = solver.Maximize(vars)
+		minimumModel, _ = solver.Minimize(vars)
+		maximumModel, _ = solver.Maximize(vars)
 		assert.Equal(3, len(minimumModel.PosVars()))
 		assert.Equal(9, len(maximumModel.PosVars()))
 
@@ -130,8 +132,8 @@ func TestOptimizerFunctionIncMinMax(t *testing.T) {
 		variables.AddAll(f.Variables(fac, formula))
 		vars = f.VariablesAsLiterals(variables.Content())
 		solver.Add(formula)
-		minimumModel = solver.Minimize(vars)
-		maximumModel = solver.Maximize(vars)
+		minimumModel, _ = solver.Minimize(vars)
+		maximumModel, _ = solver.Maximize(vars)
 		assert.Equal(3, len(minimumModel.PosVars()))
 		assert.True(slices.Contains(minimumModel.PosVars(), fac.Var("q")))
 		assert.True(slices.Contains(minimumModel.PosVars(), fac.Var("z")))
@@ -143,8 +145,8 @@ func TestOptimizerFunctionIncMinMax(t *testing.T) {
 		variables.AddAll(f.Variables(fac, formula))
 		vars = f.VariablesAsLiterals(variables.Content())
 		solver.Add(formula)
-		minimumModel = solver.Minimize(vars)
-		maximumModel = solver.Maximize(vars)
+		minimumModel, _ = solver.Minimize(vars)
+		maximumModel, _ = solver.Maximize(vars)
 		assert.Equal(4, len(minimumModel.PosVars()))
 		assert.True(slices.Contains(minimumModel.PosVars(), fac.Var("q")))
 		assert.True(slices.Contains(minimumModel.PosVars(), fac.Var("x")))
@@ -159,8 +161,8 @@ func TestOptimizerFunctionIncMinMax(t *testing.T) {
 
 		formula = parser.ParseUnsafe("~q")
 		solver.Add(formula)
-		minimumModel = solver.Minimize(vars)
-		maximumModel = solver.Maximize(vars)
+		minimumModel, _ = solver.Minimize(vars)
+		maximumModel, _ = solver.Maximize(vars)
 		assert.Nil(minimumModel)
 		assert.Nil(maximumModel)
 	}
@@ -282,10 +284,17 @@ func optimize(
 ) *model.Model {
 	solver := NewSolver(fac, config)
 	solver.Add(formulas...)
+	var mdl *model.Model
+	var err error
 	if maximize {
-		return solver.Maximize(literals, additionalVariables...)
+		mdl, err = solver.Maximize(literals, additionalVariables...)
+	} else {
+		mdl, err = solver.Minimize(literals, additionalVariables...)
+	}
+	if err != nil {
+		panic(err)
 	}
-	return solver.Minimize(literals, additionalVariables...)
+	return mdl
 }
 
 func testMinimumModel(
@@ -0,0 +1,236 @@
+package sat
+
+import (
+	"github.com/booleworks/logicng-go/errorx"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+)
+
+// OptimizationStrategy encodes the search strategy for the optimization of a
+// weighted objective function.
+type OptimizationStrategy byte
+
+const (
+	OptLinearSearch OptimizationStrategy = iota
+	OptBinarySearch
+)
+
+//go:generate stringer -type=OptimizationStrategy
+
+// OptimizationResult represents the result of the optimization of a weighted
+// objective function.  It holds a flag whether the formula on the solver was
+// satisfiable.  In case it was satisfiable, the optimal value of the
+// objective function is stored as the Optimum and a model for this optimum is
+// retained.
+type OptimizationResult struct {
+	Satisfiable bool
+	Optimum     int
+	Model       *model.Model
+}
+
+// MinimizeWeighted searches for a model on the solver which minimizes the
+// weighted sum of the given literals, i.e. the sum of the weights of the
+// literals which are true in the model.  Weights may be negative.  The
+// search is performed by a linear search with an incremental
+// pseudo-Boolean upper bound.  The returned model will also include the
+// additional variables.  Returns an error if the number of literals and
+// weights differ or if the objective cannot be encoded as an incremental
+// pseudo-Boolean constraint.
+func (s *Solver) MinimizeWeighted(
+	literals []f.Literal, weights []int, additionalVariables ...f.Variable,
+) (OptimizationResult, error) {
+	res, _, err := s.optimizeWeighted(false, literals, weights, OptLinearSearch, handler.NopHandler, additionalVariables)
+	return res, err
+}
+
+// MinimizeWeightedWithHandler searches for a model on the solver which
+// minimizes the weighted sum of the given literals with the given search
+// strategy.  The returned model will also include the additional variables.
+// The given handler can be used to cancel the optimization process.  In this
+// case the best model found so far is returned.  Returns an error if the
+// number of literals and weights differ or if the objective cannot be
+// encoded as an incremental pseudo-Boolean constraint.
+func (s *Solver) MinimizeWeightedWithHandler(
+	literals []f.Literal,
+	weights []int,
+	strategy OptimizationStrategy,
+	hdl handler.Handler,
+	additionalVariables ...f.Variable,
+) (OptimizationResult, handler.State, error) {
+	return s.optimizeWeighted(false, literals, weights, strategy, hdl, additionalVariables)
+}
+
+// MaximizeWeighted searches for a model on the solver which maximizes the
+// weighted sum of the given literals, i.e. the sum of the weights of the
+// literals which are true in the model.  Weights may be negative.  The
+// search is performed by a linear search with an incremental
+// pseudo-Boolean upper bound.  The returned model will also include the
+// additional variables.  Returns an error if the number of literals and
+// weights differ or if the objective cannot be encoded as an incremental
+// pseudo-Boolean constraint.
+func (s *Solver) MaximizeWeighted(
+	literals []f.Literal, weights []int, additionalVariables ...f.Variable,
+) (OptimizationResult, error) {
+	res, _, err := s.optimizeWeighted(true, literals, weights, OptLinearSearch, handler.NopHandler, additionalVariables)
+	return res, err
+}
+
+// MaximizeWeightedWithHandler searches for a model on the solver which
+// maximizes the weighted sum of the given literals with the given search
+// strategy.  The returned model will also include the additional variables.
+// The given handler can be used to cancel the optimization process.  In this
+// case the best model found so far is returned.  Returns an error if the
+// number of literals and weights differ or if the objective cannot be
+// encoded as an incremental pseudo-Boolean constraint.
+func (s *Solver) MaximizeWeightedWithHandler(
+	literals []f.Literal,
+	weights []int,
+	strategy OptimizationStrategy,
+	hdl handler.Handler,
+	additionalVariables ...f.Variable,
+) (OptimizationResult, handler.State, error) {
+	return s.optimizeWeighted(true, literals, weights, strategy, hdl, additionalVariables)
+}
+
+func (s *Solver) optimizeWeighted(
+	maximize bool,
+	literals []f.Literal,
+	weights []int,
+	strategy OptimizationStrategy,
+	hdl handler.Handler,
+	additionalVariables []f.Variable,
+) (OptimizationResult, handler.State, error) {
+	if len(literals) != len(weights) {
+		return OptimizationResult{}, succ, errorx.BadInput(
+			"number of literals %d and weights %d differ", len(literals), len(weights))
+	}
+	if strategy != OptLinearSearch && strategy != OptBinarySearch {
+		return OptimizationResult{}, succ, errorx.UnknownEnumValue(strategy)
+	}
+	initialState := s.SaveState()
+	defer func() { _ = s.LoadState(initialState) }()
+
+	opt := &weightedOptimizer{solver: s, hdl: hdl}
+	for i, lit := range literals {
+		if weights[i] == 0 {
+			continue
+		}
+		opt.lits = append(opt.lits, lit)
+		opt.litIndices = append(opt.litIndices, s.getOrAddIndex(lit))
+		if maximize {
+			opt.coeffs = append(opt.coeffs, -weights[i])
+		} else {
+			opt.coeffs = append(opt.coeffs, weights[i])
+		}
+	}
+	resultModelVariables := f.NewMutableVarSet(additionalVariables...)
+	for _, lit := range literals {
+		resultModelVariables.Add(lit.Variable())
+	}
+	for _, variable := range resultModelVariables.Content() {
+		name, _ := s.fac.VarName(variable)
+		if idx := s.core.IdxForName(name); idx != -1 {
+			opt.relevantIndices = append(opt.relevantIndices, idx)
+		}
+	}
+
+	res, state, err := opt.search(strategy)
+	if err != nil {
+		return OptimizationResult{}, state, err
+	}
+	if res.Satisfiable && maximize {
+		res.Optimum = -res.Optimum
+	}
+	return res, state, nil
+}
+
+type weightedOptimizer struct {
+	solver          *Solver
+	hdl             handler.Handler
+	lits            []f.Literal
+	litIndices      []int32
+	coeffs          []int
+	relevantIndices []int32
+	bestModel       []bool
+	bestCost        int
+}
+
+func (o *weightedOptimizer) search(strategy OptimizationStrategy) (OptimizationResult, handler.State, error) {
+	if e := event.OptimizationFunctionStarted; !o.hdl.ShouldResume(e) {
+		return OptimizationResult{}, handler.Cancelation(e), nil
+	}
+	sResult := o.solver.Call(WithHandler(o.hdl))
+	if sResult.Canceled() {
+		return OptimizationResult{}, sResult.state, nil
+	}
+	if !sResult.Sat() {
+		return OptimizationResult{}, succ, nil
+	}
+	o.storeModel()
+	lowerBound := 0
+	for _, c := range o.coeffs {
+		lowerBound += min(c, 0)
+	}
+	if o.bestCost == lowerBound {
+		return o.result(), succ, nil
+	}
+	pbc := o.solver.fac.PBC(f.LE, o.bestCost-1, o.lits, o.coeffs)
+	incData, err := o.solver.AddIncrementalPBC(pbc)
+	if err != nil {
+		return OptimizationResult{}, succ, err
+	}
+	for lowerBound < o.bestCost {
+		bound := o.bestCost - 1
+		params := WithHandler(o.hdl)
+		if strategy == OptBinarySearch {
+			bound = lowerBound + (o.bestCost-1-lowerBound)/2
+			assumptions, err := incData.UpperBoundAssumptions(bound)
+			if err != nil {
+				return OptimizationResult{}, succ, err
+			}
+			params.Literal(assumptions...)
+		}
+		sResult = o.solver.Call(params)
+		if sResult.Canceled() {
+			return o.result(), sResult.state, nil
+		}
+		if !sResult.Sat() {
+			lowerBound = bound + 1
+			continue
+		}
+		o.storeModel()
+		betterBoundEvent := EventFoundBetterBound{o.createModelFunc()}
+		if !o.hdl.ShouldResume(betterBoundEvent) {
+			return o.result(), handler.Cancelation(betterBoundEvent), nil
+		}
+		if o.bestCost > lowerBound {
+			if err := incData.NewUpperBoundForSolver(o.bestCost - 1); err != nil {
+				return OptimizationResult{}, succ, err
+			}
+		}
+	}
+	return o.result(), succ, nil
+}
+
+func (o *weightedOptimizer) storeModel() {
+	o.bestModel = o.solver.core.Model()
+	o.bestCost = 0
+	for i, idx := range o.litIndices {
+		if o.bestModel[idx] == o.lits[i].IsPos() {
+			o.bestCost += o.coeffs[i]
+		}
+	}
+}
+
+func (o *weightedOptimizer) createModelFunc() func() *model.Model {
+	mdl := o.bestModel
+	return func() *model.Model {
+		return o.solver.core.CreateModel(o.solver.fac, mdl, o.relevantIndices)
+	}
+}
+
+func (o *weightedOptimizer) result() OptimizationResult {
+	return OptimizationResult{true, o.bestCost, o.createModelFunc()()}
+}
@@ -0,0 +1,241 @@
+package sat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/booleworks/logicng-go/assignment"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedOptimizerSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b | c) & (~a | ~b) & (c => d)")
+	lits := []f.Literal{fac.Lit("a", true), fac.Lit("b", true), fac.Lit("c", true), fac.Lit("d", true)}
+	weights := []int{5, 3, 1, 2}
+
+	for _, strategy := range []OptimizationStrategy{OptLinearSearch, OptBinarySearch} {
+		for _, config := range configs() {
+			solver := NewSolver(fac, config)
+			solver.Add(formula)
+			res, state, err := solver.MinimizeWeightedWithHandler(lits, weights, strategy, handler.NopHandler)
+			assert.Nil(err)
+			assert.True(state.Success)
+			assert.True(res.Satisfiable)
+			assert.Equal(3, res.Optimum)
+			assert.Equal(4, res.Model.Size())
+			res, state, err = solver.MaximizeWeightedWithHandler(lits, weights, strategy, handler.NopHandler)
+			assert.Nil(err)
+			assert.True(state.Success)
+			assert.Equal(8, res.Optimum)
+			assert.True(solver.Sat())
+		}
+	}
+}
+
+func TestWeightedOptimizerUnsatAndErrors(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := NewSolver(fac)
+	solver.Add(p.ParseUnsafe("a & ~a"))
+	res, err := solver.MinimizeWeighted([]f.Literal{fac.Lit("a", true)}, []int{1})
+	assert.Nil(err)
+	assert.False(res.Satisfiable)
+	assert.Nil(res.Model)
+
+	_, err = solver.MinimizeWeighted([]f.Literal{fac.Lit("a", true)}, []int{1, 2})
+	assert.NotNil(err)
+}
+
+func TestWeightedOptimizerNegativeWeights(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := NewSolver(fac)
+	solver.Add(p.ParseUnsafe("a + b + c <= 2"))
+	lits := []f.Literal{fac.Lit("a", true), fac.Lit("b", false), fac.Lit("c", true), fac.Lit("a", true)}
+	res, err := solver.MinimizeWeighted(lits, []int{-4, 2, -3, 1})
+	assert.Nil(err)
+	assert.Equal(-4, res.Optimum)
+	res, err = solver.MaximizeWeighted(lits, []int{-4, 2, -3, 1})
+	assert.Nil(err)
+	assert.Equal(2, res.Optimum)
+}
+
+func TestWeightedOptimizerHandler(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := NewSolver(fac)
+	solver.Add(p.ParseUnsafe("(a | b | c | d) & (~a | ~b)"))
+	lits := []f.Literal{fac.Lit("a", true), fac.Lit("b", true), fac.Lit("c", true), fac.Lit("d", true)}
+
+	hdl := &satCallHandler{maxCalls: 1}
+	res, state, err := solver.MaximizeWeightedWithHandler(lits, []int{1, 2, 3, 4}, OptLinearSearch, hdl)
+	assert.Nil(err)
+	assert.False(state.Success)
+	assert.Equal(event.SatCallStarted, state.CancelCause)
+	assert.Equal(2, hdl.calls)
+	assert.True(res.Satisfiable)
+	assert.NotNil(res.Model)
+
+	res, state, err = solver.MaximizeWeightedWithHandler(lits, []int{1, 2, 3, 4}, OptLinearSearch, &satCallHandler{maxCalls: 100})
+	assert.Nil(err)
+	assert.True(state.Success)
+	assert.Equal(9, res.Optimum)
+}
+
+type satCallHandler struct {
+	maxCalls int
+	calls    int
+}
+
+func (h *satCallHandler) ShouldResume(e event.Event) bool {
+	if e == event.SatCallStarted {
+		h.calls++
+		return h.calls <= h.maxCalls
+	}
+	return true
+}
+
+func TestWeightedOptimizerBetterBounds(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b | c | d | e) & (~a | ~b) & (~c | ~d | ~e)")
+	lits := []f.Literal{fac.Lit("a", true), fac.Lit("b", true), fac.Lit("c", true), fac.Lit("d", true), fac.Lit("e", true)}
+	weights := []int{1, 2, 3, 4, 5}
+
+	for _, strategy := range []OptimizationStrategy{OptLinearSearch, OptBinarySearch} {
+		for _, config := range configs() {
+			solver := NewSolver(fac, config)
+			solver.Add(formula)
+			hdl := &betterBoundHandler{}
+			res, state, err := solver.MaximizeWeightedWithHandler(lits, weights, strategy, hdl)
+			assert.Nil(err)
+			assert.True(state.Success)
+			assert.Equal(11, res.Optimum)
+			assert.NotEmpty(hdl.models)
+			costs := make([]int, len(hdl.models))
+			for i, mdl := range hdl.models {
+				costs[i] = weightedCost(fac, mdl.Literals, lits, weights)
+				ass, _ := mdl.Assignment(fac)
+				assert.True(assignment.Evaluate(fac, formula, ass))
+				if i > 0 {
+					assert.Greater(costs[i], costs[i-1])
+				}
+			}
+			assert.Equal(res.Optimum, costs[len(costs)-1])
+
+			solver = NewSolver(fac, config)
+			solver.Add(formula)
+			hdl = &betterBoundHandler{maxBounds: 1}
+			res, state, err = solver.MaximizeWeightedWithHandler(lits, weights, strategy, hdl)
+			assert.Nil(err)
+			assert.False(state.Success)
+			assert.IsType(EventFoundBetterBound{}, state.CancelCause)
+			assert.Equal(1, len(hdl.models))
+			assert.Equal(weightedCost(fac, hdl.models[0].Literals, lits, weights), res.Optimum)
+			assert.Equal(hdl.models[0].Literals, res.Model.Literals)
+		}
+	}
+}
+
+type betterBoundHandler struct {
+	maxBounds int
+	models    []*model.Model
+}
+
+func (h *betterBoundHandler) ShouldResume(e event.Event) bool {
+	if bound, ok := e.(EventFoundBetterBound); ok {
+		h.models = append(h.models, bound.Model())
+		return h.maxBounds == 0 || len(h.models) < h.maxBounds
+	}
+	return true
+}
+
+func TestWeightedOptimizerRandom(t *testing.T) {
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 6
+	config.WeightPBC = 2
+	config.Seed = 42
+	rand := rand.New(rand.NewSource(42))
+	randomizer := randomizer.New(fac, config)
+
+	for range 300 {
+		formula := randomizer.Formula(2)
+		vars := f.Variables(fac, formula).Content()
+		lits := make([]f.Literal, len(vars))
+		weights := make([]int, len(vars))
+		for i, v := range vars {
+			lits[i] = v.AsLiteral()
+			if rand.Intn(2) == 0 {
+				lits[i] = v.Negate(fac)
+			}
+			weights[i] = rand.Intn(21) - 5
+		}
+		expMin, expMax, sat := bruteForceOptimum(fac, formula, vars, lits, weights)
+		for _, strategy := range []OptimizationStrategy{OptLinearSearch, OptBinarySearch} {
+			solver := NewSolver(fac)
+			solver.Add(formula)
+			minRes, _, _ := solver.MinimizeWeightedWithHandler(lits, weights, strategy, handler.NopHandler)
+			maxRes, _, _ := solver.MaximizeWeightedWithHandler(lits, weights, strategy, handler.NopHandler)
+			assert.Equal(t, sat, minRes.Satisfiable)
+			assert.Equal(t, sat, maxRes.Satisfiable)
+			if sat {
+				assert.Equal(t, expMin, minRes.Optimum)
+				assert.Equal(t, expMax, maxRes.Optimum)
+				assert.Equal(t, expMin, weightedCost(fac, minRes.Model.Literals, lits, weights))
+				ass, _ := minRes.Model.Assignment(fac)
+				assert.True(t, assignment.Evaluate(fac, formula, ass))
+			}
+		}
+	}
+}
+
+func bruteForceOptimum(
+	fac f.Factory, formula f.Formula, vars []f.Variable, lits []f.Literal, weights []int,
+) (int, int, bool) {
+	minCost, maxCost := math.MaxInt, math.MinInt
+	sat := false
+	for bits := 0; bits < 1<<len(vars); bits++ {
+		modelLits := make([]f.Literal, len(vars))
+		for i, v := range vars {
+			if bits&(1<<i) != 0 {
+				modelLits[i] = v.AsLiteral()
+			} else {
+				modelLits[i] = v.Negate(fac)
+			}
+		}
+		ass, _ := assignment.New(fac, modelLits...)
+		if assignment.Evaluate(fac, formula, ass) {
+			sat = true
+			cost := weightedCost(fac, modelLits, lits, weights)
+			minCost = min(minCost, cost)
+			maxCost = max(maxCost, cost)
+		}
+	}
+	return minCost, maxCost, sat
+}
+
+func weightedCost(fac f.Factory, modelLits, lits []f.Literal, weights []int) int {
+	ass, _ := assignment.New(fac, modelLits...)
+	cost := 0
+	for i, lit := range lits {
+		if assignment.Evaluate(fac, lit.AsFormula(), ass) {
+			cost += weights[i]
+		}
+	}
+	return cost
+}
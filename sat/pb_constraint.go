@@ -0,0 +1,246 @@
+package sat
+
+import (
+	"slices"
+
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// pbConstraint holds the additional data of a native pseudo-Boolean
+// constraint sum(coeffs[i] * lits[i]) >= degree on the core solver.  The
+// literals are stored in the data of the surrounding clause and are sorted by
+// descending coefficients.
+//
+// The constraint is propagated with watched sums: only a subset of the
+// literals is watched, and slack is the sum of the coefficients of all
+// watched literals which are not falsified (and already processed) minus the
+// degree.  As long as the slack is at least the maximal coefficient, the
+// constraint can neither propagate nor be in conflict.  Since each variable
+// occurs only once in the constraint, the position of a literal can be looked
+// up in positions.
+type pbConstraint struct {
+	coeffs    []int
+	degree    int
+	sum       int
+	slack     int
+	watched   []bool
+	positions map[int32]int
+}
+
+type pbCount struct {
+	clause *clause
+	coeff  int
+}
+
+func newPBConstraint(ps []int32, coeffs []int, degree int) *clause {
+	c := newClause(ps, -1)
+	sum := 0
+	for _, coeff := range coeffs {
+		sum += coeff
+	}
+	positions := make(map[int32]int, len(ps))
+	for i, lit := range ps {
+		positions[lit] = i
+	}
+	c.pb = &pbConstraint{
+		coeffs:    coeffs,
+		degree:    degree,
+		sum:       sum,
+		slack:     -degree,
+		watched:   make([]bool, len(ps)),
+		positions: positions,
+	}
+	return c
+}
+
+func (c *pbConstraint) maxCoeff() int {
+	return c.coeffs[0]
+}
+
+// addPB adds the pseudo-Boolean constraint sum(coeffs[i] * ps[i]) >= degree
+// to the solver.  The coefficients may be negative and variables may occur
+// multiple times.
+func (m *CoreSolver) addPB(ps []int32, coeffs []int, degree int) {
+	if !m.ok {
+		return
+	}
+	varCoeffs := make(map[int32]int, len(ps))
+	vars := make([]int32, 0, len(ps))
+	for i, lit := range ps {
+		coeff := coeffs[i]
+		if Sign(lit) {
+			degree -= coeff
+			coeff = -coeff
+		}
+		v := Vari(lit)
+		if _, ok := varCoeffs[v]; !ok {
+			vars = append(vars, v)
+		}
+		varCoeffs[v] += coeff
+	}
+	lits := make([]int32, 0, len(vars))
+	cs := make([]int, 0, len(vars))
+	for _, v := range vars {
+		coeff := varCoeffs[v]
+		lit := MkLit(v, false)
+		if coeff < 0 {
+			degree -= coeff
+			coeff = -coeff
+			lit = Not(lit)
+		}
+		if coeff == 0 || m.value(lit) == f.TristateFalse {
+			continue
+		}
+		if m.value(lit) == f.TristateTrue {
+			degree -= coeff
+			continue
+		}
+		lits = append(lits, lit)
+		cs = append(cs, coeff)
+	}
+	if degree <= 0 {
+		return
+	}
+	sum := 0
+	for i := range cs {
+		cs[i] = min(cs[i], degree)
+		sum += cs[i]
+	}
+	if sum < degree {
+		m.ok = false
+		return
+	}
+	indices := make([]int, len(lits))
+	for i := range indices {
+		indices[i] = i
+	}
+	slices.SortStableFunc(indices, func(i, j int) int { return cs[j] - cs[i] })
+	sortedLits := make([]int32, len(lits))
+	sortedCoeffs := make([]int, len(lits))
+	for i, index := range indices {
+		sortedLits[i] = lits[index]
+		sortedCoeffs[i] = cs[index]
+	}
+	if sortedCoeffs[len(sortedCoeffs)-1] == degree {
+		m.AddClause(sortedLits, nil)
+		return
+	}
+
+	c := newPBConstraint(sortedLits, sortedCoeffs, degree)
+	m.clauses = append(m.clauses, c)
+	for _, lit := range sortedLits {
+		m.pbOccurrences[lit] = append(m.pbOccurrences[lit], c)
+	}
+	m.clausesLiterals += c.size()
+	pb := c.pb
+	for i := 0; i < c.size() && pb.slack < pb.maxCoeff(); i++ {
+		m.watchPB(c, i)
+	}
+	propagated := false
+	for i := 0; i < c.size() && pb.coeffs[i] > sum-degree; i++ {
+		m.enqueueFunction(m, c.get(i), nil)
+		m.unitClauses = append(m.unitClauses, c.get(i))
+		propagated = true
+	}
+	if propagated {
+		m.ok = m.propagate() == nil
+	}
+}
+
+func (m *CoreSolver) watchPB(c *clause, i int) {
+	lit := c.get(i)
+	c.pb.watched[i] = true
+	c.pb.slack += c.pb.coeffs[i]
+	m.watches[Not(lit)] = append(m.watches[Not(lit)], newWatcher(c, lit))
+}
+
+// propagatePB is called when the watched literal falseLit of the given
+// pseudo-Boolean constraint was falsified.  It returns whether the watch on
+// the literal should be kept and an optional conflict clause.
+func (m *CoreSolver) propagatePB(c *clause, falseLit int32) (bool, *clause) {
+	pb := c.pb
+	index := pb.positions[falseLit]
+	coeff := pb.coeffs[index]
+	for i := 0; i < c.size() && pb.slack-coeff < pb.maxCoeff(); i++ {
+		if !pb.watched[i] && m.value(c.get(i)) != f.TristateFalse {
+			m.watchPB(c, i)
+		}
+	}
+	if pb.slack-coeff >= pb.maxCoeff() {
+		pb.watched[index] = false
+		pb.slack -= coeff
+		return false, nil
+	}
+
+	// all non-falsified literals are watched now
+	pb.slack -= coeff
+	v := m.v(falseLit)
+	v.pbCounts = append(v.pbCounts, pbCount{c, coeff})
+	if pb.slack < 0 {
+		return true, m.explainPB(c, LitUndef, falseLit)
+	}
+	for i := 0; i < c.size() && pb.coeffs[i] > pb.slack; i++ {
+		if m.value(c.get(i)) == f.TristateUndef {
+			m.enqueueFunction(m, c.get(i), m.explainPB(c, c.get(i), falseLit))
+		}
+	}
+	return true, nil
+}
+
+// explainPB generates a clausal explanation for the propagation of the given
+// literal by the pseudo-Boolean constraint or - if the literal is undefined -
+// for a conflict of the constraint.  The explanation consists of the
+// propagated literal and falsified literals of the constraint with large
+// coefficients.  The literal which triggered the propagation is always part
+// of the explanation.
+func (m *CoreSolver) explainPB(c *clause, lit int32, trigger int32) *clause {
+	pb := c.pb
+	remaining := pb.sum
+	explanation := make([]int32, 0, 4)
+	if lit != LitUndef {
+		remaining -= pb.coeffs[pb.positions[lit]]
+		explanation = append(explanation, lit)
+	}
+	remaining -= pb.coeffs[pb.positions[trigger]]
+	explanation = append(explanation, trigger)
+	for i := 0; i < c.size() && remaining >= pb.degree; i++ {
+		if l := c.get(i); l != trigger && m.value(l) == f.TristateFalse {
+			remaining -= pb.coeffs[i]
+			explanation = append(explanation, l)
+		}
+	}
+	return newClause(explanation, -1)
+}
+
+// undoPB restores the slack of all pseudo-Boolean constraints for which the
+// falsification of the given variable was processed.
+func (m *CoreSolver) undoPB(v *variable) {
+	for _, count := range v.pbCounts {
+		count.clause.pb.slack += count.coeff
+	}
+	v.pbCounts = v.pbCounts[:0]
+}
+
+func (m *CoreSolver) simpleRemovePB(c *clause) {
+	for i := 0; i < c.size(); i++ {
+		if c.pb.watched[i] {
+			removeWatcher(&m.watches[Not(c.get(i))], c)
+		}
+	}
+}
+
+// isUnitPB reports whether the given literal is required to satisfy the
+// pseudo-Boolean constraint in the current model.
+func (m *CoreSolver) isUnitPB(lit int32, c *clause) bool {
+	index, ok := c.pb.positions[lit]
+	if !ok {
+		return false
+	}
+	satisfied := 0
+	for i := 0; i < c.size(); i++ {
+		if clauseLit := c.get(i); m.model[Vari(clauseLit)] != Sign(clauseLit) {
+			satisfied += c.pb.coeffs[i]
+		}
+	}
+	return satisfied-c.pb.coeffs[index] < c.pb.degree
+}
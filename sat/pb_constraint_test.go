@@ -0,0 +1,145 @@
+package sat
+
+import (
+	"testing"
+
+	"github.com/booleworks/logicng-go/assignment"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func pbConfigs() []*Config {
+	return []*Config{
+		DefaultConfig().UsePB(true),
+		DefaultConfig().UsePB(true).InitPhase(true),
+		DefaultConfig().UsePB(true).UseAtMost(true),
+		DefaultConfig().UsePB(true).ClauseMin(ClauseMinBasic),
+	}
+}
+
+func TestPBConstraintsSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	for _, config := range pbConfigs() {
+		solver := NewSolver(fac, config)
+		solver.Add(p.ParseUnsafe("3*a + 2*b + 2*c + d >= 5"))
+		solver.Add(p.ParseUnsafe("~a"))
+		assert.True(solver.Sat())
+		model := solver.Call(WithModel(fac.Vars("a", "b", "c", "d"))).Model()
+		assert.ElementsMatch([]f.Literal{
+			fac.Lit("a", false), fac.Lit("b", true), fac.Lit("c", true), fac.Lit("d", true),
+		}, model.Literals)
+		solver.Add(p.ParseUnsafe("b + c + d <= 2"))
+		assert.False(solver.Sat())
+	}
+}
+
+func TestPBConstraintsFormulasOnSolver(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	solver := NewSolver(fac, DefaultConfig().UsePB(true))
+	solver.Add(p.ParseUnsafe("2*a + 3*b + -4*c <= 1"))
+	solver.Add(p.ParseUnsafe("a + b + c >= 1"))
+	assert.ElementsMatch([]f.Formula{
+		p.ParseUnsafe("4*c + 3*~b + 2*~a >= 4"),
+		p.ParseUnsafe("a | b | c"),
+	}, solver.FormulasOnSolver())
+}
+
+func TestPBConstraintsRandom(t *testing.T) {
+	fac := f.NewFactory()
+	for i := range 200 {
+		config := randomizer.DefaultConfig()
+		config.NumVars = 8
+		config.Seed = int64(i)
+		config.WeightPBC = 2
+		config.WeightCC = 1
+		config.MaximumOpsPBC = 8
+		config.MaximumOpsCC = 6
+		r := randomizer.New(fac, config)
+		formulas := make([]f.Formula, 0, 8)
+		for j := range 8 {
+			switch j % 3 {
+			case 0:
+				formulas = append(formulas, r.PBC())
+			case 1:
+				formulas = append(formulas, r.CC())
+			default:
+				formulas = append(formulas, r.Formula(2))
+			}
+		}
+		formula := fac.And(formulas...)
+		vars := f.Variables(fac, formula).Content()
+		expected := NewSolver(fac)
+		expected.Add(formulas...)
+		expectedSat := expected.Sat()
+
+		for _, solverConfig := range pbConfigs() {
+			solver := NewSolver(fac, solverConfig)
+			solver.Add(formulas...)
+			assert.Equal(t, expectedSat, solver.Sat())
+			if expectedSat {
+				res := solver.Call(WithModel(vars))
+				ass, _ := res.Model().Assignment(fac)
+				assert.True(t, assignment.Evaluate(fac, formula, ass))
+			}
+			for _, v := range vars {
+				for _, lit := range []f.Literal{v.AsLiteral(), v.Negate(fac)} {
+					assert.Equal(t, expected.Call(WithAssumptions([]f.Literal{lit})).Sat(),
+						solver.Call(WithAssumptions([]f.Literal{lit})).Sat())
+				}
+			}
+			if expectedSat {
+				assert.Equal(t, expected.ComputeBackbone(fac, vars), solver.ComputeBackbone(fac, vars))
+			}
+		}
+	}
+}
+
+func TestPBConstraintsIncremental(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	for _, config := range pbConfigs() {
+		solver := NewSolver(fac, config)
+		solver.Add(p.ParseUnsafe("2*a + 3*b + 4*c + 5*d <= 9"))
+		state := solver.SaveState()
+		solver.Add(p.ParseUnsafe("3*a + 2*b + 5*c + 4*d >= 10"))
+		assert.True(solver.Sat())
+		solver.Add(p.ParseUnsafe("a + b + c + d >= 4"))
+		assert.False(solver.Sat())
+		assert.Nil(solver.LoadState(state))
+		solver.Add(p.ParseUnsafe("a + b + c + d >= 3"))
+		assert.True(solver.Sat())
+		solver.Add(p.ParseUnsafe("d"))
+		assert.False(solver.Sat())
+		assert.Nil(solver.LoadState(state))
+		assert.True(solver.Sat())
+		assert.Equal(4, pbOccurrenceCount(solver))
+	}
+}
+
+func TestPBConstraintsWithProposition(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	for _, config := range pbConfigs() {
+		solver := NewSolver(fac, config)
+		solver.AddProposition(f.NewStandardProposition(p.ParseUnsafe("2*a + 3*b + 4*c + 5*d <= 9")))
+		assert.Equal(0, pbOccurrenceCount(solver))
+		solver.Add(p.ParseUnsafe("b & c & d"))
+		assert.False(solver.Sat())
+	}
+}
+
+func pbOccurrenceCount(solver *Solver) int {
+	count := 0
+	for _, occurrences := range solver.core.pbOccurrences {
+		count += len(occurrences)
+	}
+	return count
+}
@@ -80,7 +80,9 @@ func (s *Solver) AddProposition(propositions ...f.Proposition) {
 }
 
 func (s *Solver) addWithProp(formula f.Formula, proposition f.Proposition) {
-	if formula.Sort() == f.SortCC {
+	if proposition == nil && s.usePB(formula) {
+		s.addPBConstraint(formula)
+	} else if formula.Sort() == f.SortCC {
 		if s.config.UseAtMostClauses {
 			comparator, rhs, literals, _, _ := s.fac.PBCOps(formula)
 			if comparator == f.LE {
@@ -108,6 +110,45 @@ func (s *Solver) addWithProp(formula f.Formula, proposition f.Proposition) {
 	}
 }
 
+// usePB reports whether the given formula is added as native pseudo-Boolean
+// constraint.  Native constraints are not used when proofs are generated and
+// cardinality constraints are handled by at-most clauses if configured.
+// Constraints added with a proposition are always encoded, since a native
+// constraint cannot carry a proposition.
+func (s *Solver) usePB(formula f.Formula) bool {
+	if !s.config.UsePBConstraints || s.config.ProofGeneration {
+		return false
+	}
+	return formula.Sort() == f.SortPBC || formula.Sort() == f.SortCC && !s.config.UseAtMostClauses
+}
+
+func (s *Solver) addPBConstraint(formula f.Formula) {
+	comparator, rhs, literals, coefficients, _ := s.fac.PBCOps(formula)
+	lits := make([]int32, len(literals))
+	for i, lit := range literals {
+		lits[i] = MkLit(s.getOrAddIndex(lit), !lit.IsPos())
+	}
+	negCoeffs := make([]int, len(coefficients))
+	for i, coeff := range coefficients {
+		negCoeffs[i] = -coeff
+	}
+	switch comparator {
+	case f.GE:
+		s.core.addPB(lits, slices.Clone(coefficients), rhs)
+	case f.GT:
+		s.core.addPB(lits, slices.Clone(coefficients), rhs+1)
+	case f.LE:
+		s.core.addPB(lits, negCoeffs, -rhs)
+	case f.LT:
+		s.core.addPB(lits, negCoeffs, -rhs+1)
+	case f.EQ:
+		s.core.addPB(lits, slices.Clone(coefficients), rhs)
+		s.core.addPB(lits, negCoeffs, -rhs)
+	default:
+		panic(errorx.UnknownEnumValue(comparator))
+	}
+}
+
 func (s *Solver) addFormulaAsCNF(formula f.Formula, proposition f.Proposition) {
 	switch s.config.CNFMethod {
 	case CNFFactory:
@@ -203,6 +244,15 @@ func (s *Solver) AddIncrementalCC(cc f.Formula) (*encoding.CCIncrementalData, er
 	return encoding.EncodeIncremental(s.fac, cc, result)
 }
 
+// AddIncrementalPBC adds the given constraint as an incremental
+// pseudo-Boolean constraint to the solver.  It returns the incremental data
+// used to tighten the upper bound of the formula on the solver.  Returns with
+// an error if the incremental constraint could not be generated.
+func (s *Solver) AddIncrementalPBC(pbc f.Formula) (*encoding.PBCIncrementalData, error) {
+	result := resultForSolver(s.fac, s, nil)
+	return encoding.EncodeIncrementalPBC(s.fac, pbc, result)
+}
+
 // Factory returns the solver's formula factory.
 func (s *Solver) Factory() f.Factory {
 	return s.fac
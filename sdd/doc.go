@@ -0,0 +1,44 @@
+// Package sdd provides datastructures and algorithms for compiling and
+// manipulating sentential decision diagrams, or short [SDD] in LogicNG.
+//
+// SDDs generalize ordered BDDs: instead of a linear variable order, an SDD
+// is structured by a vtree - a binary tree whose leaves are the variables.
+// A decomposition node of an SDD for a vtree node with variables X in its
+// left and Y in its right subtree represents a Boolean function as
+//
+//	(p1(X) & s1(Y)) | ... | (pn(X) & sn(Y))
+//
+// where the primes p1, ..., pn are mutually exclusive and exhaustive.  SDDs
+// support the same polytime operations as BDDs (conjoin, disjoin, negate,
+// conditioning, model counting) but can be exponentially more succinct.  The
+// SDDs of a manager are compressed and trimmed and therefore canonical for
+// the manager's vtree.
+//
+// A vtree can be right-linear (then the SDD is an OBDD), balanced, or
+// induced by a decomposition tree (dtree) of the formula:
+//
+//	vtree := sdd.NewVtreeFromDtree(fac, formula)
+//	vtree := sdd.NewBalancedVtree(variables)
+//	vtree := sdd.NewRightLinearVtree(variables)
+//
+// The following example compiles an SDD for a formula and counts its models:
+//
+//	fac := formula.NewFactory()
+//	p := parser.New(fac)
+//	formula := p.ParseUnsafe("(A | B) & (~A | C) & (C | D)")
+//	sdd := sdd.Compile(fac, formula)
+//	count := sdd.ModelCount()
+//
+// Several SDDs can be combined if they were compiled with the same manager:
+//
+//	manager := sdd.NewManager(fac, vtree)
+//	s1, err := sdd.CompileWithManager(fac, f1, manager)
+//	s2, err := sdd.CompileWithManager(fac, f2, manager)
+//	conjunction := s1.And(s2)
+//
+// The size of an SDD heavily depends on its vtree.  The function Minimize
+// searches for a smaller SDD by rotating and swapping vtree nodes,
+// MinimizeWithHandler allows to cancel this search.
+//
+// [SDD]: https://www.ijcai.org/Proceedings/11/Papers/143.pdf
+package sdd
@@ -0,0 +1,349 @@
+package sdd
+
+import (
+	"encoding/binary"
+	"slices"
+
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+const (
+	sddFalse int32 = 0
+	sddTrue  int32 = 1
+)
+
+type operation byte
+
+const (
+	opAnd operation = iota
+	opOr
+)
+
+// A Manager holds the nodes of SDDs which are all normalized for the same
+// vtree.  The SDDs of a manager are compressed and trimmed and therefore
+// canonical: two SDDs of the same manager represent the same Boolean function
+// if and only if they have the same index.
+type Manager struct {
+	fac    f.Factory
+	vtree  *Vtree
+	vnodes []vnode
+	leafOf map[f.Variable]int32
+
+	nodes      []sddNode
+	literals   map[f.Literal]int32
+	unique     map[string]int32
+	applyCache map[applyKey]int32
+	negCache   map[int32]int32
+}
+
+// vnode is the flattened representation of a vtree node in the manager.  The
+// nodes are numbered in-order, so the nodes of a subtree form the interval
+// [first, last] which contains the number of the node itself.
+type vnode struct {
+	left, right int32 // -1 for a leaf
+	parent      int32 // -1 for the root
+	first, last int32
+	variable    f.Variable
+	varCount    int
+}
+
+type element struct {
+	prime, sub int32
+}
+
+type sddNode struct {
+	vtree    int32 // -1 for the constants
+	literal  f.Literal
+	elements []element // nil for literals and constants
+}
+
+type applyKey struct {
+	op   operation
+	l, r int32
+}
+
+// NewManager returns a new SDD manager for the given vtree.
+func NewManager(fac f.Factory, vtree *Vtree) *Manager {
+	m := &Manager{
+		fac:        fac,
+		vtree:      vtree,
+		leafOf:     make(map[f.Variable]int32),
+		nodes:      []sddNode{{vtree: -1}, {vtree: -1}},
+		literals:   make(map[f.Literal]int32),
+		unique:     make(map[string]int32),
+		applyCache: make(map[applyKey]int32),
+		negCache:   make(map[int32]int32),
+	}
+	if vtree != nil {
+		m.flatten(vtree, -1)
+	}
+	return m
+}
+
+func (m *Manager) flatten(vtree *Vtree, parent int32) int32 {
+	if vtree.IsLeaf() {
+		index := int32(len(m.vnodes))
+		m.vnodes = append(m.vnodes, vnode{-1, -1, parent, index, index, vtree.Variable, 1})
+		m.leafOf[vtree.Variable] = index
+		return index
+	}
+	first := int32(len(m.vnodes))
+	left := m.flatten(vtree.Left, -1)
+	index := int32(len(m.vnodes))
+	m.vnodes = append(m.vnodes, vnode{left: left, parent: parent, first: first})
+	right := m.flatten(vtree.Right, index)
+	m.vnodes[left].parent = index
+	m.vnodes[index].right = right
+	m.vnodes[index].last = m.vnodes[right].last
+	m.vnodes[index].varCount = m.vnodes[left].varCount + m.vnodes[right].varCount
+	return index
+}
+
+// Factory returns the formula factory of the manager.
+func (m *Manager) Factory() f.Factory {
+	return m.fac
+}
+
+// Vtree returns the vtree of the manager.
+func (m *Manager) Vtree() *Vtree {
+	return m.vtree
+}
+
+// Variables returns the variables of the manager's vtree from left to right.
+func (m *Manager) Variables() []f.Variable {
+	if m.vtree == nil {
+		return nil
+	}
+	return m.vtree.Variables()
+}
+
+// Verum returns the SDD for the constant true.
+func (m *Manager) Verum() *SDD {
+	return &SDD{m, sddTrue}
+}
+
+// Falsum returns the SDD for the constant false.
+func (m *Manager) Falsum() *SDD {
+	return &SDD{m, sddFalse}
+}
+
+// Literal returns the SDD for the given literal.  Returns an error if the
+// variable of the literal is not in the vtree of the manager.
+func (m *Manager) Literal(literal f.Literal) (*SDD, error) {
+	if _, ok := m.leafOf[literal.Variable()]; !ok {
+		return nil, errorx.BadInput("variable %s is not in the vtree", literal.Variable().Sprint(m.fac))
+	}
+	return &SDD{m, m.literal(literal)}, nil
+}
+
+func (m *Manager) literal(literal f.Literal) int32 {
+	if index, ok := m.literals[literal]; ok {
+		return index
+	}
+	index := int32(len(m.nodes))
+	m.nodes = append(m.nodes, sddNode{vtree: m.leafOf[literal.Variable()], literal: literal})
+	m.literals[literal] = index
+	return index
+}
+
+func (m *Manager) isLiteral(node int32) bool {
+	return node > sddTrue && m.nodes[node].elements == nil
+}
+
+// isSub reports whether the vtree node v is in the subtree of w.
+func (m *Manager) isSub(v, w int32) bool {
+	return m.vnodes[w].first <= v && v <= m.vnodes[w].last
+}
+
+func (m *Manager) lca(v, w int32) int32 {
+	for !m.isSub(w, v) {
+		v = m.vnodes[v].parent
+	}
+	return v
+}
+
+func (m *Manager) negate(node int32) int32 {
+	switch {
+	case node == sddFalse:
+		return sddTrue
+	case node == sddTrue:
+		return sddFalse
+	case m.isLiteral(node):
+		return m.literal(m.nodes[node].literal.Negate(m.fac))
+	}
+	if cached, ok := m.negCache[node]; ok {
+		return cached
+	}
+	n := m.nodes[node]
+	elements := make([]element, len(n.elements))
+	for i, e := range n.elements {
+		elements[i] = element{e.prime, m.negate(e.sub)}
+	}
+	result := m.decomposition(n.vtree, elements)
+	m.negCache[node] = result
+	m.negCache[result] = node
+	return result
+}
+
+func (m *Manager) apply(l, r int32, op operation) int32 {
+	switch op {
+	case opAnd:
+		if l == sddFalse || r == sddFalse {
+			return sddFalse
+		}
+		if l == sddTrue || l == r {
+			return r
+		}
+		if r == sddTrue {
+			return l
+		}
+	case opOr:
+		if l == sddTrue || r == sddTrue {
+			return sddTrue
+		}
+		if l == sddFalse || l == r {
+			return r
+		}
+		if r == sddFalse {
+			return l
+		}
+	}
+	if l > r {
+		l, r = r, l
+	}
+	key := applyKey{op, l, r}
+	if cached, ok := m.applyCache[key]; ok {
+		return cached
+	}
+	vl, vr := m.nodes[l].vtree, m.nodes[r].vtree
+	var result int32
+	if vl == vr && m.isLiteral(l) {
+		// different literals of the same variable are complementary
+		if op == opAnd {
+			result = sddFalse
+		} else {
+			result = sddTrue
+		}
+	} else {
+		var w int32
+		switch {
+		case m.isSub(vl, vr):
+			w = vr
+		case m.isSub(vr, vl):
+			w = vl
+		default:
+			w = m.lca(vl, vr)
+		}
+		elements := m.product(m.elementsAt(l, w), m.elementsAt(r, w), op)
+		result = m.decomposition(w, elements)
+	}
+	m.applyCache[key] = result
+	return result
+}
+
+// elementsAt returns the elements of the node normalized for the vtree node
+// w which is the node's vtree node or one of its ancestors.
+func (m *Manager) elementsAt(node, w int32) []element {
+	v := m.nodes[node].vtree
+	switch {
+	case v == w:
+		return m.nodes[node].elements
+	case m.isSub(v, m.vnodes[w].left):
+		return []element{{node, sddTrue}, {m.negate(node), sddFalse}}
+	default:
+		return []element{{sddTrue, node}}
+	}
+}
+
+func (m *Manager) product(left, right []element, op operation) []element {
+	elements := make([]element, 0, len(left)*len(right))
+	for _, e1 := range left {
+		for _, e2 := range right {
+			prime := m.apply(e1.prime, e2.prime, opAnd)
+			if prime == sddFalse {
+				continue
+			}
+			elements = append(elements, element{prime, m.apply(e1.sub, e2.sub, op)})
+		}
+	}
+	return elements
+}
+
+// decomposition returns the canonical node for the given elements which form
+// a partition for the vtree node w.  Elements with the same sub are merged
+// (compression) and decompositions of the form {(true, s)} or
+// {(p, true), (~p, false)} are replaced by s and p respectively (trimming).
+func (m *Manager) decomposition(w int32, elements []element) int32 {
+	slices.SortFunc(elements, func(a, b element) int { return int(a.sub) - int(b.sub) })
+	compressed := make([]element, 0, len(elements))
+	for _, e := range elements {
+		if last := len(compressed) - 1; last >= 0 && compressed[last].sub == e.sub {
+			compressed[last].prime = m.apply(compressed[last].prime, e.prime, opOr)
+		} else {
+			compressed = append(compressed, e)
+		}
+	}
+	if len(compressed) == 1 {
+		return compressed[0].sub
+	}
+	if len(compressed) == 2 && compressed[0].sub == sddFalse && compressed[1].sub == sddTrue {
+		return compressed[1].prime
+	}
+	slices.SortFunc(compressed, func(a, b element) int { return int(a.prime) - int(b.prime) })
+	key := make([]byte, 4+8*len(compressed))
+	binary.LittleEndian.PutUint32(key, uint32(w))
+	for i, e := range compressed {
+		binary.LittleEndian.PutUint32(key[4+8*i:], uint32(e.prime))
+		binary.LittleEndian.PutUint32(key[8+8*i:], uint32(e.sub))
+	}
+	if index, ok := m.unique[string(key)]; ok {
+		return index
+	}
+	index := int32(len(m.nodes))
+	m.nodes = append(m.nodes, sddNode{vtree: w, elements: compressed})
+	m.unique[string(key)] = index
+	return index
+}
+
+// condition conditions the node on the given literal whose variable has the
+// vtree leaf leaf.
+func (m *Manager) condition(node int32, literal f.Literal, leaf int32, cache map[int32]int32) int32 {
+	if node <= sddTrue || !m.isSub(leaf, m.nodes[node].vtree) {
+		return node
+	}
+	if m.isLiteral(node) {
+		if m.nodes[node].literal == literal {
+			return sddTrue
+		}
+		return sddFalse
+	}
+	if cached, ok := cache[node]; ok {
+		return cached
+	}
+	result := sddFalse
+	for _, e := range m.nodes[node].elements {
+		prime := m.condition(e.prime, literal, leaf, cache)
+		sub := m.condition(e.sub, literal, leaf, cache)
+		result = m.apply(result, m.apply(prime, sub, opAnd), opOr)
+	}
+	cache[node] = result
+	return result
+}
+
+func (m *Manager) conditionLiteral(node int32, literal f.Literal) int32 {
+	leaf, ok := m.leafOf[literal.Variable()]
+	if !ok {
+		return node
+	}
+	return m.condition(node, literal, leaf, make(map[int32]int32))
+}
+
+func (m *Manager) quantify(node int32, variable f.Variable, op operation) int32 {
+	if _, ok := m.leafOf[variable]; !ok {
+		return node
+	}
+	pos := m.conditionLiteral(node, variable.AsLiteral())
+	neg := m.conditionLiteral(node, variable.Negate(m.fac))
+	return m.apply(pos, neg, op)
+}
@@ -0,0 +1,567 @@
+package sdd
+
+import (
+	"math/big"
+
+	"github.com/booleworks/logicng-go/errorx"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model"
+)
+
+var succ = handler.Success()
+
+// An SDD is a sentential decision diagram.  It contains a pointer to the
+// manager which was used to generate the SDD and the node index of the SDD
+// within this manager.
+type SDD struct {
+	Manager *Manager
+	Index   int32
+}
+
+// Compile creates an SDD for the given formula.  The vtree of the SDD is
+// induced by a decomposition tree of the formula (see NewVtreeFromDtree).
+func Compile(fac f.Factory, formula f.Formula) *SDD {
+	sdd, _ := CompileWithHandler(fac, formula, handler.NopHandler)
+	return sdd
+}
+
+// CompileWithHandler creates an SDD for the given formula.  The vtree of the
+// SDD is induced by a decomposition tree of the formula.  The handler can be
+// used to cancel the SDD compilation.
+func CompileWithHandler(fac f.Factory, formula f.Formula, hdl handler.Handler) (*SDD, handler.State) {
+	manager := NewManager(fac, NewVtreeFromDtree(fac, formula))
+	sdd, state, _ := CompileWithManagerAndHandler(fac, formula, manager, hdl)
+	return sdd, state
+}
+
+// CompileWithVtree creates an SDD for the given formula and vtree.  Returns
+// an error if the formula contains a variable which is not in the vtree.
+func CompileWithVtree(fac f.Factory, formula f.Formula, vtree *Vtree) (*SDD, error) {
+	return CompileWithManager(fac, formula, NewManager(fac, vtree))
+}
+
+// CompileWithManager creates an SDD for the given formula in the given
+// manager.  Returns an error if the formula contains a variable which is not
+// in the vtree of the manager.
+func CompileWithManager(fac f.Factory, formula f.Formula, manager *Manager) (*SDD, error) {
+	sdd, _, err := CompileWithManagerAndHandler(fac, formula, manager, handler.NopHandler)
+	return sdd, err
+}
+
+// CompileWithManagerAndHandler creates an SDD for the given formula in the
+// given manager.  The handler can be used to cancel the SDD compilation.
+// Returns an error if the formula contains a variable which is not in the
+// vtree of the manager.
+func CompileWithManagerAndHandler(
+	fac f.Factory,
+	formula f.Formula,
+	manager *Manager,
+	hdl handler.Handler,
+) (*SDD, handler.State, error) {
+	for _, variable := range f.Variables(fac, formula).Content() {
+		if _, ok := manager.leafOf[variable]; !ok {
+			return nil, succ, errorx.BadInput("variable %s is not in the vtree", variable.Sprint(fac))
+		}
+	}
+	if e := event.SddComputationStarted; !hdl.ShouldResume(e) {
+		return nil, handler.Cancelation(e), nil
+	}
+	index, state := compile(fac, formula, manager, hdl)
+	if !state.Success {
+		return nil, state, nil
+	}
+	return &SDD{manager, index}, succ, nil
+}
+
+func compile(fac f.Factory, formula f.Formula, m *Manager, hdl handler.Handler) (int32, handler.State) {
+	switch formula.Sort() {
+	case f.SortFalse:
+		return sddFalse, succ
+	case f.SortTrue:
+		return sddTrue, succ
+	case f.SortLiteral:
+		return m.literal(f.Literal(formula)), succ
+	case f.SortNot:
+		op, _ := fac.NotOperand(formula)
+		operand, state := compile(fac, op, m, hdl)
+		if !state.Success {
+			return 0, state
+		}
+		return m.negate(operand), succ
+	case f.SortImpl, f.SortEquiv:
+		l, r, _ := fac.BinaryLeftRight(formula)
+		left, state := compile(fac, l, m, hdl)
+		if !state.Success {
+			return 0, state
+		}
+		right, state := compile(fac, r, m, hdl)
+		if !state.Success {
+			return 0, state
+		}
+		if e := event.SddApplyPerformed; !hdl.ShouldResume(e) {
+			return 0, handler.Cancelation(e)
+		}
+		if formula.Sort() == f.SortImpl {
+			return m.apply(m.negate(left), right, opOr), succ
+		}
+		return m.apply(m.apply(left, right, opAnd), m.apply(m.negate(left), m.negate(right), opAnd), opOr), succ
+	case f.SortAnd, f.SortOr:
+		op := opAnd
+		if formula.Sort() == f.SortOr {
+			op = opOr
+		}
+		ops, _ := fac.NaryOperands(formula)
+		res, state := compile(fac, ops[0], m, hdl)
+		if !state.Success {
+			return 0, state
+		}
+		for i := 1; i < len(ops); i++ {
+			operand, state := compile(fac, ops[i], m, hdl)
+			if !state.Success {
+				return 0, state
+			}
+			if e := event.SddApplyPerformed; !hdl.ShouldResume(e) {
+				return 0, handler.Cancelation(e)
+			}
+			res = m.apply(res, operand, op)
+		}
+		return res, succ
+	case f.SortCC, f.SortPBC:
+		comparator, rhs, literals, coefficients, _ := fac.PBCOps(formula)
+		return m.compilePBC(comparator, rhs, literals, coefficients, 0, 0, make(map[[2]int]int32)), succ
+	default:
+		panic(errorx.UnknownEnumValue(formula.Sort()))
+	}
+}
+
+// compilePBC compiles a pseudo-Boolean constraint by a Shannon expansion over
+// its literals.  The intermediate results are cached for the index of the
+// next literal and the sum of the coefficients of the literals set to true.
+func (m *Manager) compilePBC(
+	comparator f.CSort,
+	rhs int,
+	literals []f.Literal,
+	coefficients []int,
+	index, lhs int,
+	cache map[[2]int]int32,
+) int32 {
+	if index == len(literals) {
+		if comparator.Evaluate(lhs, rhs) {
+			return sddTrue
+		}
+		return sddFalse
+	}
+	key := [2]int{index, lhs}
+	if cached, ok := cache[key]; ok {
+		return cached
+	}
+	pos := m.compilePBC(comparator, rhs, literals, coefficients, index+1, lhs+coefficients[index], cache)
+	neg := m.compilePBC(comparator, rhs, literals, coefficients, index+1, lhs, cache)
+	lit := m.literal(literals[index])
+	result := m.apply(m.apply(lit, pos, opAnd), m.apply(m.negate(lit), neg, opAnd), opOr)
+	cache[key] = result
+	return result
+}
+
+func (s *SDD) other(other *SDD) int32 {
+	if other.Manager != s.Manager {
+		panic(errorx.BadInput("SDDs have different managers"))
+	}
+	return other.Index
+}
+
+// And returns the conjunction of the SDD and the given SDD.  Both SDDs must
+// belong to the same manager.
+func (s *SDD) And(other *SDD) *SDD {
+	return &SDD{s.Manager, s.Manager.apply(s.Index, s.other(other), opAnd)}
+}
+
+// Or returns the disjunction of the SDD and the given SDD.  Both SDDs must
+// belong to the same manager.
+func (s *SDD) Or(other *SDD) *SDD {
+	return &SDD{s.Manager, s.Manager.apply(s.Index, s.other(other), opOr)}
+}
+
+// Negate returns the negation of the SDD.
+func (s *SDD) Negate() *SDD {
+	return &SDD{s.Manager, s.Manager.negate(s.Index)}
+}
+
+// IsTautology reports whether the SDD is a tautology.
+func (s *SDD) IsTautology() bool {
+	return s.Index == sddTrue
+}
+
+// IsContradiction reports whether the SDD is a contradiction.
+func (s *SDD) IsContradiction() bool {
+	return s.Index == sddFalse
+}
+
+// Condition returns the SDD conditioned on the given literals, i.e. the
+// variables of the literals are replaced by the respective constants.
+func (s *SDD) Condition(literals ...f.Literal) *SDD {
+	index := s.Index
+	for _, literal := range literals {
+		index = s.Manager.conditionLiteral(index, literal)
+	}
+	return &SDD{s.Manager, index}
+}
+
+// Exists returns the SDD where the given variables are existentially
+// quantified.
+func (s *SDD) Exists(variables ...f.Variable) *SDD {
+	index := s.Index
+	for _, variable := range variables {
+		index = s.Manager.quantify(index, variable, opOr)
+	}
+	return &SDD{s.Manager, index}
+}
+
+// ForAll returns the SDD where the given variables are universally
+// quantified.
+func (s *SDD) ForAll(variables ...f.Variable) *SDD {
+	index := s.Index
+	for _, variable := range variables {
+		index = s.Manager.quantify(index, variable, opAnd)
+	}
+	return &SDD{s.Manager, index}
+}
+
+// ModelCount returns the number of models of the SDD over all variables of
+// the manager's vtree.
+func (s *SDD) ModelCount() *big.Int {
+	m := s.Manager
+	if m.vtree == nil {
+		if s.Index == sddTrue {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	}
+	return m.countAt(s.Index, m.root(), make(map[int32]*big.Int))
+}
+
+func (m *Manager) root() int32 {
+	for i, v := range m.vnodes {
+		if v.parent == -1 {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+// countAt returns the number of models of the node over the variables of the
+// vtree node v.
+func (m *Manager) countAt(node, v int32, cache map[int32]*big.Int) *big.Int {
+	switch node {
+	case sddFalse:
+		return big.NewInt(0)
+	case sddTrue:
+		return new(big.Int).Lsh(big.NewInt(1), uint(m.vnodes[v].varCount))
+	}
+	count := m.count(node, cache)
+	dontCares := m.vnodes[v].varCount - m.vnodes[m.nodes[node].vtree].varCount
+	return new(big.Int).Lsh(count, uint(dontCares))
+}
+
+// count returns the number of models of the node over the variables of its
+// vtree node.
+func (m *Manager) count(node int32, cache map[int32]*big.Int) *big.Int {
+	if m.isLiteral(node) {
+		return big.NewInt(1)
+	}
+	if cached, ok := cache[node]; ok {
+		return cached
+	}
+	n := m.nodes[node]
+	result := big.NewInt(0)
+	for _, e := range n.elements {
+		prime := m.countAt(e.prime, m.vnodes[n.vtree].left, cache)
+		sub := m.countAt(e.sub, m.vnodes[n.vtree].right, cache)
+		result.Add(result, prime.Mul(prime, sub))
+	}
+	cache[node] = result
+	return result
+}
+
+// ModelEnumeration returns all models of the SDD projected to the given
+// variables.  If no variables are given, all variables of the manager's
+// vtree are used.  Variables which are not in the vtree are ignored.
+func (s *SDD) ModelEnumeration(variables ...f.Variable) []*model.Model {
+	m := s.Manager
+	if len(variables) == 0 {
+		variables = m.Variables()
+	}
+	relevant := make(map[f.Variable]bool, len(variables))
+	for _, variable := range variables {
+		if _, ok := m.leafOf[variable]; ok {
+			relevant[variable] = true
+		}
+	}
+	var irrelevant []f.Variable
+	for _, variable := range m.Variables() {
+		if !relevant[variable] {
+			irrelevant = append(irrelevant, variable)
+		}
+	}
+	projected := s.Exists(irrelevant...)
+	if projected.Index == sddFalse {
+		return []*model.Model{}
+	}
+	var assignments [][]f.Literal
+	if m.vtree == nil {
+		assignments = [][]f.Literal{{}}
+	} else {
+		assignments = m.modelsAt(projected.Index, m.root(), relevant, make(map[int32][][]f.Literal))
+	}
+	models := make([]*model.Model, len(assignments))
+	for i, assignment := range assignments {
+		models[i] = model.New(assignment...)
+	}
+	return models
+}
+
+// modelsAt returns the models of the node over the relevant variables of the
+// vtree node v.
+func (m *Manager) modelsAt(node, v int32, relevant map[f.Variable]bool, cache map[int32][][]f.Literal) [][]f.Literal {
+	if node == sddFalse {
+		return nil
+	}
+	models := [][]f.Literal{{}}
+	if node != sddTrue {
+		models = m.models(node, relevant, cache)
+	}
+	for i := m.vnodes[v].first; i <= m.vnodes[v].last; i++ {
+		vn := m.vnodes[i]
+		if vn.left != -1 || !relevant[vn.variable] || node != sddTrue && m.isSub(i, m.nodes[node].vtree) {
+			continue
+		}
+		expanded := make([][]f.Literal, 0, 2*len(models))
+		for _, mdl := range models {
+			expanded = append(expanded, append(clone(mdl), vn.variable.AsLiteral()))
+			expanded = append(expanded, append(clone(mdl), vn.variable.Negate(m.fac)))
+		}
+		models = expanded
+	}
+	return models
+}
+
+func (m *Manager) models(node int32, relevant map[f.Variable]bool, cache map[int32][][]f.Literal) [][]f.Literal {
+	if m.isLiteral(node) {
+		if !relevant[m.nodes[node].literal.Variable()] {
+			return [][]f.Literal{{}}
+		}
+		return [][]f.Literal{{m.nodes[node].literal}}
+	}
+	if cached, ok := cache[node]; ok {
+		return cached
+	}
+	n := m.nodes[node]
+	var result [][]f.Literal
+	for _, e := range n.elements {
+		primes := m.modelsAt(e.prime, m.vnodes[n.vtree].left, relevant, cache)
+		subs := m.modelsAt(e.sub, m.vnodes[n.vtree].right, relevant, cache)
+		for _, prime := range primes {
+			for _, sub := range subs {
+				result = append(result, append(clone(prime), sub...))
+			}
+		}
+	}
+	cache[node] = result
+	return result
+}
+
+func clone(literals []f.Literal) []f.Literal {
+	return append(make([]f.Literal, 0, len(literals)+1), literals...)
+}
+
+// ToFormula returns a formula representation of the SDD.  Each decomposition
+// node is represented as a disjunction of the conjunctions of its primes and
+// subs.
+func (s *SDD) ToFormula(fac f.Factory) f.Formula {
+	return s.Manager.toFormula(fac, s.Index, make(map[int32]f.Formula))
+}
+
+func (m *Manager) toFormula(fac f.Factory, node int32, cache map[int32]f.Formula) f.Formula {
+	switch {
+	case node == sddFalse:
+		return fac.Falsum()
+	case node == sddTrue:
+		return fac.Verum()
+	case m.isLiteral(node):
+		return m.nodes[node].literal.AsFormula()
+	}
+	if cached, ok := cache[node]; ok {
+		return cached
+	}
+	elements := m.nodes[node].elements
+	ops := make([]f.Formula, len(elements))
+	for i, e := range elements {
+		ops[i] = fac.And(m.toFormula(fac, e.prime, cache), m.toFormula(fac, e.sub, cache))
+	}
+	result := fac.Or(ops...)
+	cache[node] = result
+	return result
+}
+
+// Size returns the size of the SDD which is the total number of elements of
+// its decomposition nodes.
+func (s *SDD) Size() int {
+	size := 0
+	s.Manager.visit(s.Index, make(map[int32]bool), func(n sddNode) { size += len(n.elements) })
+	return size
+}
+
+// NodeCount returns the number of decomposition nodes of the SDD.
+func (s *SDD) NodeCount() int {
+	count := 0
+	s.Manager.visit(s.Index, make(map[int32]bool), func(sddNode) { count++ })
+	return count
+}
+
+// Support returns all variables the SDD depends on.
+func (s *SDD) Support() []f.Variable {
+	m := s.Manager
+	inSupport := make(map[f.Variable]bool)
+	m.collectSupport(s.Index, make(map[int32]bool), inSupport)
+	var support []f.Variable
+	for _, variable := range m.Variables() {
+		if inSupport[variable] {
+			support = append(support, variable)
+		}
+	}
+	return support
+}
+
+func (m *Manager) collectSupport(node int32, seen map[int32]bool, support map[f.Variable]bool) {
+	if node <= sddTrue || seen[node] {
+		return
+	}
+	seen[node] = true
+	if m.isLiteral(node) {
+		support[m.nodes[node].literal.Variable()] = true
+		return
+	}
+	for _, e := range m.nodes[node].elements {
+		m.collectSupport(e.prime, seen, support)
+		m.collectSupport(e.sub, seen, support)
+	}
+}
+
+func (m *Manager) visit(node int32, seen map[int32]bool, visitor func(sddNode)) {
+	n := m.nodes[node]
+	if seen[node] || n.elements == nil {
+		return
+	}
+	seen[node] = true
+	visitor(n)
+	for _, e := range n.elements {
+		m.visit(e.prime, seen, visitor)
+		m.visit(e.sub, seen, visitor)
+	}
+}
+
+// Transfer returns an SDD for the same Boolean function in the given
+// manager.  Returns an error if the SDD depends on a variable which is not in
+// the vtree of the given manager.
+func (s *SDD) Transfer(manager *Manager) (*SDD, error) {
+	sdd, _, err := s.TransferWithHandler(manager, handler.NopHandler)
+	return sdd, err
+}
+
+// TransferWithHandler returns an SDD for the same Boolean function in the
+// given manager.  The handler can be used to cancel the transfer.  Returns an
+// error if the SDD depends on a variable which is not in the vtree of the
+// given manager.
+func (s *SDD) TransferWithHandler(manager *Manager, hdl handler.Handler) (*SDD, handler.State, error) {
+	for _, variable := range s.Support() {
+		if _, ok := manager.leafOf[variable]; !ok {
+			return nil, succ, errorx.BadInput("variable %s is not in the vtree", variable.Sprint(manager.fac))
+		}
+	}
+	index, state := s.Manager.transfer(s.Index, manager, make(map[int32]int32), hdl)
+	if !state.Success {
+		return nil, state, nil
+	}
+	return &SDD{manager, index}, succ, nil
+}
+
+func (m *Manager) transfer(node int32, target *Manager, cache map[int32]int32, hdl handler.Handler) (int32, handler.State) {
+	switch {
+	case node <= sddTrue:
+		return node, succ
+	case m.isLiteral(node):
+		return target.literal(m.nodes[node].literal), succ
+	}
+	if cached, ok := cache[node]; ok {
+		return cached, succ
+	}
+	result := sddFalse
+	for _, e := range m.nodes[node].elements {
+		prime, state := m.transfer(e.prime, target, cache, hdl)
+		if !state.Success {
+			return 0, state
+		}
+		sub, state := m.transfer(e.sub, target, cache, hdl)
+		if !state.Success {
+			return 0, state
+		}
+		if e := event.SddApplyPerformed; !hdl.ShouldResume(e) {
+			return 0, handler.Cancelation(e)
+		}
+		result = target.apply(result, target.apply(prime, sub, opAnd), opOr)
+	}
+	cache[node] = result
+	return result, succ
+}
+
+// Minimize searches for a vtree for which the SDD has a smaller size.  It
+// performs a local search over the vtrees which can be reached by left and
+// right rotations and swaps of the vtree nodes.  A change of the vtree is
+// kept if it reduces the size of the SDD.  The search stops if no single
+// rotation or swap reduces the size.  The resulting SDD is in a new manager.
+func (s *SDD) Minimize() *SDD {
+	sdd, _, _ := s.MinimizeWithHandler(handler.NopHandler)
+	return sdd
+}
+
+// MinimizeWithHandler searches for a vtree for which the SDD has a smaller
+// size like Minimize.  The handler can be used to cancel the search, in this
+// case the smallest SDD found so far is returned together with the
+// cancelation state.  Returns an error if the SDD could not be transferred to
+// a manager with a changed vtree.
+func (s *SDD) MinimizeWithHandler(hdl handler.Handler) (*SDD, handler.State, error) {
+	if e := event.SddComputationStarted; !hdl.ShouldResume(e) {
+		return s, handler.Cancelation(e), nil
+	}
+	best := s
+	bestSize := s.Size()
+	for improved := best.Manager.vtree != nil; improved; {
+		improved = false
+		vtree := best.Manager.vtree
+	search:
+		for _, node := range vtree.innerNodes() {
+			for _, op := range []func(*Vtree) (*Vtree, error){(*Vtree).RotateLeft, (*Vtree).RotateRight, (*Vtree).Swap} {
+				replacement, err := op(node)
+				if err != nil {
+					continue
+				}
+				manager := NewManager(best.Manager.fac, vtree.replace(node, replacement))
+				candidate, state, err := best.TransferWithHandler(manager, hdl)
+				if err != nil {
+					return nil, succ, err
+				}
+				if !state.Success {
+					return best, state, nil
+				}
+				if size := candidate.Size(); size < bestSize {
+					best, bestSize = candidate, size
+					improved = true
+					break search
+				}
+			}
+		}
+	}
+	return best, succ, nil
+}
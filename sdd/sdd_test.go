@@ -0,0 +1,298 @@
+package sdd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/booleworks/logicng-go/bdd"
+	"github.com/booleworks/logicng-go/event"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/handler"
+	"github.com/booleworks/logicng-go/model/count"
+	"github.com/booleworks/logicng-go/normalform"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/randomizer"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSddTrivialFormulas(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+
+	verum := Compile(fac, fac.Verum())
+	assert.True(verum.IsTautology())
+	assert.Equal(big.NewInt(1), verum.ModelCount())
+	falsum := Compile(fac, fac.Falsum())
+	assert.True(falsum.IsContradiction())
+	assert.Equal(big.NewInt(0), falsum.ModelCount())
+	assert.Empty(falsum.ModelEnumeration())
+
+	testFormula(t, fac, p.ParseUnsafe("a"))
+	testFormula(t, fac, p.ParseUnsafe("~a"))
+	testFormula(t, fac, p.ParseUnsafe("a & b"))
+	testFormula(t, fac, p.ParseUnsafe("a | b"))
+	testFormula(t, fac, p.ParseUnsafe("a => b"))
+	testFormula(t, fac, p.ParseUnsafe("a <=> b"))
+	testFormula(t, fac, p.ParseUnsafe("a & ~a"))
+	testFormula(t, fac, p.ParseUnsafe("a | ~a"))
+	testFormula(t, fac, p.ParseUnsafe("f & ((~b | c) <=> ~a & ~c)"))
+	testFormula(t, fac, p.ParseUnsafe("a | ((b & ~c) | (c & (~d | ~a & b)) & e)"))
+	testFormula(t, fac, p.ParseUnsafe("a + b + c + d <= 1"))
+	testFormula(t, fac, p.ParseUnsafe("2*a + 3*b + -2*c + d < 5"))
+}
+
+func TestSddRandomFormulas(t *testing.T) {
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 10
+	config.Seed = 42
+	r := randomizer.New(fac, config)
+	for i := 0; i < 100; i++ {
+		testFormula(t, fac, r.Formula(4))
+	}
+}
+
+func TestSddVtrees(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b) & (~a | c) & (c | d | ~e) & (e <=> f)")
+	vars := f.Variables(fac, formula).Content()
+	expected := countWithSolver(fac, formula, vars)
+
+	vtrees := []*Vtree{NewRightLinearVtree(vars), NewBalancedVtree(vars), NewVtreeFromDtree(fac, formula)}
+	for _, vtree := range vtrees {
+		assert.ElementsMatch(vars, vtree.Variables())
+		sdd, err := CompileWithVtree(fac, formula, vtree)
+		assert.Nil(err)
+		assert.Equal(expected, sdd.ModelCount())
+		assert.True(sat.IsEquivalent(fac, formula, sdd.ToFormula(fac)))
+	}
+
+	rightLinear := NewRightLinearVtree(vars[:3])
+	assert.True(rightLinear.Left.IsLeaf())
+	assert.Equal(vars[0], rightLinear.Left.Variable)
+	assert.True(rightLinear.Right.Left.IsLeaf())
+	balanced := NewBalancedVtree(vars[:4])
+	assert.Equal([]f.Variable{vars[0], vars[1]}, balanced.Left.Variables())
+	assert.Equal([]f.Variable{vars[2], vars[3]}, balanced.Right.Variables())
+
+	_, err := CompileWithVtree(fac, formula, NewBalancedVtree(vars[:3]))
+	assert.NotNil(err)
+}
+
+func TestSddVtreeOperations(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	a, b, c := fac.Var("a"), fac.Var("b"), fac.Var("c")
+	vtree := NewRightLinearVtree([]f.Variable{a, b, c})
+
+	rotated, err := vtree.RotateLeft()
+	assert.Nil(err)
+	assert.Equal([]f.Variable{a, b}, rotated.Left.Variables())
+	assert.Equal(c, rotated.Right.Variable)
+	back, err := rotated.RotateRight()
+	assert.Nil(err)
+	assert.Equal(vtree, back)
+	swapped, err := vtree.Swap()
+	assert.Nil(err)
+	assert.Equal([]f.Variable{b, c, a}, swapped.Variables())
+
+	_, err = vtree.RotateRight()
+	assert.NotNil(err)
+	_, err = vtree.Left.Swap()
+	assert.NotNil(err)
+}
+
+func TestSddCanonicity(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 8
+	config.Seed = 7
+	r := randomizer.New(fac, config)
+	for i := 0; i < 30; i++ {
+		formula := r.Formula(3)
+		manager := NewManager(fac, NewBalancedVtree(f.Variables(fac, formula).Content()))
+		sdd, _ := CompileWithManager(fac, formula, manager)
+		nnf, _ := CompileWithManager(fac, normalform.NNF(fac, formula), manager)
+		cnf, _ := CompileWithManager(fac, normalform.FactorizedCNF(fac, formula), manager)
+		assert.Equal(sdd.Index, nnf.Index)
+		assert.Equal(sdd.Index, cnf.Index)
+		assert.Equal(sdd.Index, sdd.Negate().Negate().Index)
+	}
+}
+
+func TestSddApply(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	f1 := p.ParseUnsafe("(a | b) & (c | ~d)")
+	f2 := p.ParseUnsafe("(~a | d) & (b <=> c)")
+	vars := f.Variables(fac, f1, f2).Content()
+	manager := NewManager(fac, NewBalancedVtree(vars))
+	s1, _ := CompileWithManager(fac, f1, manager)
+	s2, _ := CompileWithManager(fac, f2, manager)
+
+	conj, _ := CompileWithManager(fac, fac.And(f1, f2), manager)
+	disj, _ := CompileWithManager(fac, fac.Or(f1, f2), manager)
+	neg, _ := CompileWithManager(fac, fac.Not(f1), manager)
+	assert.Equal(conj, s1.And(s2))
+	assert.Equal(disj, s1.Or(s2))
+	assert.Equal(neg, s1.Negate())
+	assert.True(s1.Or(s1.Negate()).IsTautology())
+	assert.True(s1.And(s1.Negate()).IsContradiction())
+
+	lit, err := manager.Literal(fac.Lit("a", false))
+	assert.Nil(err)
+	assert.Equal(big.NewInt(8), lit.ModelCount())
+	_, err = manager.Literal(fac.Lit("x", true))
+	assert.NotNil(err)
+
+	other := NewManager(fac, NewBalancedVtree(vars))
+	s3, _ := CompileWithManager(fac, f2, other)
+	assert.Panics(func() { s1.And(s3) })
+}
+
+func TestSddConditionAndQuantification(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	config := randomizer.DefaultConfig()
+	config.NumVars = 8
+	config.Seed = 13
+	r := randomizer.New(fac, config)
+	for i := 0; i < 50; i++ {
+		formula := r.Formula(4)
+		vars := f.Variables(fac, formula).Content()
+		if len(vars) < 3 {
+			continue
+		}
+		order := bdd.ForceOrder(fac, formula)
+		kernel := bdd.NewKernelWithOrdering(fac, order, 10000, 10000)
+		b := bdd.CompileWithKernel(fac, formula, kernel)
+		sdd, _ := CompileWithVtree(fac, formula, NewVtreeFromDtree(fac, formula))
+
+		lits := []f.Literal{vars[0].AsLiteral(), vars[1].Negate(fac)}
+		conditioned := sdd.Condition(lits...)
+		assert.True(sat.IsEquivalent(fac, b.Restrict(lits...).ToFormula(fac), conditioned.ToFormula(fac)))
+
+		exists := sdd.Exists(vars[1], vars[2])
+		assert.True(sat.IsEquivalent(fac, b.Exists(vars[1], vars[2]).ToFormula(fac), exists.ToFormula(fac)))
+		forAll := sdd.ForAll(vars[0])
+		assert.True(sat.IsEquivalent(fac, b.ForAll(vars[0]).ToFormula(fac), forAll.ToFormula(fac)))
+		for _, v := range exists.Support() {
+			assert.NotEqual(vars[1], v)
+			assert.NotEqual(vars[2], v)
+		}
+	}
+}
+
+func TestSddModelEnumeration(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b) & (~a | c) & (b => d)")
+	sdd := Compile(fac, formula)
+
+	models := sdd.ModelEnumeration()
+	assert.Equal(sdd.ModelCount().Int64(), int64(len(models)))
+	for _, model := range models {
+		assert.Equal(4, model.Size())
+		assert.True(sat.IsSatisfiable(fac, fac.And(formula, model.Formula(fac))))
+	}
+
+	projected := sdd.ModelEnumeration(fac.Var("a"), fac.Var("b"))
+	assert.Len(projected, 3)
+	for _, model := range projected {
+		assert.Equal(2, model.Size())
+	}
+	extended := sdd.ModelEnumeration(fac.Var("a"), fac.Var("b"), fac.Var("x"))
+	assert.Len(extended, 3)
+}
+
+func TestSddMinimize(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a1 <=> b1) & (a2 <=> b2) & (a3 <=> b3) & (a4 <=> b4)")
+	order := []f.Variable{
+		fac.Var("a1"), fac.Var("a2"), fac.Var("a3"), fac.Var("a4"),
+		fac.Var("b1"), fac.Var("b2"), fac.Var("b3"), fac.Var("b4"),
+	}
+	sdd, _ := CompileWithVtree(fac, formula, NewRightLinearVtree(order))
+	minimized := sdd.Minimize()
+	assert.Less(minimized.Size(), sdd.Size())
+	assert.Equal(sdd.ModelCount(), minimized.ModelCount())
+	assert.True(sat.IsEquivalent(fac, formula, minimized.ToFormula(fac)))
+
+	back, err := minimized.Transfer(sdd.Manager)
+	assert.Nil(err)
+	assert.Equal(sdd.Index, back.Index)
+	_, err = sdd.Transfer(NewManager(fac, NewBalancedVtree(order[:4])))
+	assert.NotNil(err)
+}
+
+func TestSddMinimizeHandler(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a1 <=> b1) & (a2 <=> b2) & (a3 <=> b3)")
+	order := fac.Vars("a1", "a2", "a3", "b1", "b2", "b3")
+	sdd, _ := CompileWithVtree(fac, formula, NewRightLinearVtree(order))
+
+	minimized, state, err := sdd.MinimizeWithHandler(&applyHandler{bound: 0})
+	assert.Nil(err)
+	assert.False(state.Success)
+	assert.Equal(event.SddApplyPerformed, state.CancelCause)
+	assert.Equal(sdd, minimized)
+
+	minimized, state, err = sdd.MinimizeWithHandler(&applyHandler{bound: 50})
+	assert.Nil(err)
+	assert.False(state.Success)
+	assert.LessOrEqual(minimized.Size(), sdd.Size())
+	assert.True(sat.IsEquivalent(fac, formula, minimized.ToFormula(fac)))
+
+	minimized, state, err = sdd.MinimizeWithHandler(handler.NopHandler)
+	assert.Nil(err)
+	assert.True(state.Success)
+	assert.Equal(sdd.Minimize().Size(), minimized.Size())
+}
+
+type applyHandler struct {
+	bound int
+	steps int
+}
+
+func (h *applyHandler) ShouldResume(e event.Event) bool {
+	if e == event.SddApplyPerformed {
+		h.steps++
+		return h.steps <= h.bound
+	}
+	return true
+}
+
+func TestSddHandler(t *testing.T) {
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	formula := p.ParseUnsafe("(a | b) & (c | d) & (e | f)")
+	sdd, state := CompileWithHandler(fac, formula, handler.NopHandler)
+	assert.True(t, state.Success)
+	assert.NotNil(t, sdd)
+}
+
+func testFormula(t *testing.T, fac f.Factory, formula f.Formula) {
+	sdd := Compile(fac, formula)
+	vars := f.Variables(fac, formula).Content()
+	assert.ElementsMatch(t, vars, sdd.Manager.Variables())
+	assert.Equal(t, countWithSolver(fac, formula, vars), sdd.ModelCount())
+	assert.True(t, sat.IsEquivalent(fac, formula, sdd.ToFormula(fac)))
+	assert.LessOrEqual(t, sdd.NodeCount(), sdd.Size())
+}
+
+func countWithSolver(fac f.Factory, formula f.Formula, vars []f.Variable) *big.Int {
+	solver := sat.NewSolver(fac)
+	solver.Add(formula)
+	return count.OnSolver(solver, vars)
+}
@@ -0,0 +1,189 @@
+package sdd
+
+import (
+	"github.com/booleworks/logicng-go/dnnf"
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/normalform"
+)
+
+// A Vtree is a full binary tree whose leaves are labeled with variables.  It
+// determines the structure of an SDD: each decomposition node of an SDD is
+// normalized for an inner node of the vtree, its primes are over the
+// variables of the left subtree and its subs over the variables of the right
+// subtree.  Vtrees are immutable, the rotations and swaps return new vtrees.
+type Vtree struct {
+	Left     *Vtree     // left subtree of an inner node, nil for a leaf
+	Right    *Vtree     // right subtree of an inner node, nil for a leaf
+	Variable f.Variable // variable of a leaf
+}
+
+// NewVtreeLeaf returns a new vtree leaf for the given variable.
+func NewVtreeLeaf(variable f.Variable) *Vtree {
+	return &Vtree{Variable: variable}
+}
+
+// NewVtreeNode returns a new inner vtree node with the given subtrees.
+func NewVtreeNode(left, right *Vtree) *Vtree {
+	return &Vtree{Left: left, Right: right}
+}
+
+// NewRightLinearVtree returns a right-linear vtree for the given variables.
+// Each left subtree of the vtree is a leaf and the variables occur in the
+// given order.  An SDD for a right-linear vtree corresponds to an OBDD with
+// the same variable order.  Returns nil if no variables are given.
+func NewRightLinearVtree(variables []f.Variable) *Vtree {
+	if len(variables) == 0 {
+		return nil
+	}
+	vtree := NewVtreeLeaf(variables[len(variables)-1])
+	for i := len(variables) - 2; i >= 0; i-- {
+		vtree = NewVtreeNode(NewVtreeLeaf(variables[i]), vtree)
+	}
+	return vtree
+}
+
+// NewBalancedVtree returns a balanced vtree for the given variables.  The
+// variables occur in the given order.  Returns nil if no variables are given.
+func NewBalancedVtree(variables []f.Variable) *Vtree {
+	if len(variables) == 0 {
+		return nil
+	}
+	if len(variables) == 1 {
+		return NewVtreeLeaf(variables[0])
+	}
+	mid := len(variables) / 2
+	return NewVtreeNode(NewBalancedVtree(variables[:mid]), NewBalancedVtree(variables[mid:]))
+}
+
+// NewVtreeFromDtree returns a vtree which is induced by a decomposition tree
+// of the given formula.  The formula is converted to CNF and its dtree is
+// computed with the min-fill heuristic of the DNNF compiler.  The leaves of
+// the dtree are replaced by balanced vtrees of the variables of their clause
+// which did not occur in a leaf further left.  Auxiliary variables of the CNF
+// are not part of the vtree and variables which are only in the given formula
+// but not in its CNF are added to the right.  Returns nil if the formula has
+// no variables.
+func NewVtreeFromDtree(fac f.Factory, formula f.Formula) *Vtree {
+	variables := f.Variables(fac, formula)
+	decomposition, err := dnnf.ComputeDecomposition(fac, normalform.CNF(fac, formula))
+	if err != nil {
+		panic(err) // the formula is in CNF
+	}
+	seen := make(map[f.Variable]bool)
+	vtree := fromDecomposition(fac, decomposition, variables, seen)
+	var missing []f.Variable
+	for _, variable := range variables.Content() {
+		if !seen[variable] {
+			missing = append(missing, variable)
+		}
+	}
+	if rest := NewBalancedVtree(missing); rest != nil {
+		if vtree == nil {
+			return rest
+		}
+		vtree = NewVtreeNode(vtree, rest)
+	}
+	return vtree
+}
+
+func fromDecomposition(
+	fac f.Factory,
+	decomposition *dnnf.Decomposition,
+	allowed *f.VarSet,
+	seen map[f.Variable]bool,
+) *Vtree {
+	if decomposition.IsLeaf() {
+		var variables []f.Variable
+		for _, variable := range f.Variables(fac, decomposition.Clause).Content() {
+			if allowed.Contains(variable) && !seen[variable] {
+				seen[variable] = true
+				variables = append(variables, variable)
+			}
+		}
+		return NewBalancedVtree(variables)
+	}
+	left := fromDecomposition(fac, decomposition.Left, allowed, seen)
+	right := fromDecomposition(fac, decomposition.Right, allowed, seen)
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return NewVtreeNode(left, right)
+}
+
+// IsLeaf reports whether the vtree is a leaf.
+func (v *Vtree) IsLeaf() bool {
+	return v.Left == nil
+}
+
+// Variables returns the variables of the vtree from left to right.
+func (v *Vtree) Variables() []f.Variable {
+	var variables []f.Variable
+	v.collectVariables(&variables)
+	return variables
+}
+
+func (v *Vtree) collectVariables(variables *[]f.Variable) {
+	if v.IsLeaf() {
+		*variables = append(*variables, v.Variable)
+		return
+	}
+	v.Left.collectVariables(variables)
+	v.Right.collectVariables(variables)
+}
+
+// RotateLeft rotates the vtree (a, (b, c)) to ((a, b), c).  Returns an error
+// if the right subtree of the vtree is a leaf.
+func (v *Vtree) RotateLeft() (*Vtree, error) {
+	if v.IsLeaf() || v.Right.IsLeaf() {
+		return nil, errorx.BadInput("left rotation requires an inner right subtree")
+	}
+	return NewVtreeNode(NewVtreeNode(v.Left, v.Right.Left), v.Right.Right), nil
+}
+
+// RotateRight rotates the vtree ((a, b), c) to (a, (b, c)).  Returns an error
+// if the left subtree of the vtree is a leaf.
+func (v *Vtree) RotateRight() (*Vtree, error) {
+	if v.IsLeaf() || v.Left.IsLeaf() {
+		return nil, errorx.BadInput("right rotation requires an inner left subtree")
+	}
+	return NewVtreeNode(v.Left.Left, NewVtreeNode(v.Left.Right, v.Right)), nil
+}
+
+// Swap swaps the vtree (a, b) to (b, a).  Returns an error if the vtree is a
+// leaf.
+func (v *Vtree) Swap() (*Vtree, error) {
+	if v.IsLeaf() {
+		return nil, errorx.BadInput("cannot swap a vtree leaf")
+	}
+	return NewVtreeNode(v.Right, v.Left), nil
+}
+
+// innerNodes returns all inner nodes of the vtree in pre-order.
+func (v *Vtree) innerNodes() []*Vtree {
+	if v.IsLeaf() {
+		return nil
+	}
+	nodes := []*Vtree{v}
+	nodes = append(nodes, v.Left.innerNodes()...)
+	return append(nodes, v.Right.innerNodes()...)
+}
+
+// replace returns a copy of the vtree where the given subtree is replaced.
+func (v *Vtree) replace(subtree, replacement *Vtree) *Vtree {
+	if v == subtree {
+		return replacement
+	}
+	if v.IsLeaf() {
+		return v
+	}
+	left := v.Left.replace(subtree, replacement)
+	right := v.Right.replace(subtree, replacement)
+	if left == v.Left && right == v.Right {
+		return v
+	}
+	return NewVtreeNode(left, right)
+}
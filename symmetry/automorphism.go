@@ -0,0 +1,310 @@
+package symmetry
+
+import (
+	"cmp"
+	"slices"
+)
+
+// partition is an ordered partition of the vertices of a graph.  The cells of
+// the partition are contiguous ranges of perm and are identified by the
+// position of their first vertex.
+type partition struct {
+	perm    []int // position -> vertex
+	pos     []int // vertex -> position
+	cellOf  []int // vertex -> first position of its cell
+	cellEnd []int // first position of a cell -> position after its cell
+	cells   int
+}
+
+func (p *partition) copy() *partition {
+	return &partition{
+		slices.Clone(p.perm),
+		slices.Clone(p.pos),
+		slices.Clone(p.cellOf),
+		slices.Clone(p.cellEnd),
+		p.cells,
+	}
+}
+
+func (p *partition) discrete() bool {
+	return p.cells == len(p.perm)
+}
+
+// sameShape reports whether both partitions have the same cells at the same
+// positions.
+func (p *partition) sameShape(other *partition) bool {
+	if p.cells != other.cells {
+		return false
+	}
+	for start := 0; start < len(p.perm); start = p.cellEnd[start] {
+		if other.cellOf[other.perm[start]] != start || other.cellEnd[start] != p.cellEnd[start] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *partition) firstNonSingleton() int {
+	for start := 0; start < len(p.perm); start = p.cellEnd[start] {
+		if p.cellEnd[start]-start > 1 {
+			return start
+		}
+	}
+	return -1
+}
+
+// searchLevel is a node on the first path of the search tree.
+type searchLevel struct {
+	partition *partition
+	target    int   // first position of the target cell
+	cell      []int // vertices of the target cell
+	vertex    int   // vertex which was individualized
+}
+
+// automorphismSearch computes generators of the automorphism group of a
+// vertex-colored graph.  It is a basic individualization-refinement algorithm
+// in the style of nauty or saucy: the initial coloring is refined to an
+// equitable partition and vertices are individualized until the partition is
+// discrete.  Each leaf of the search tree induces a labeling of the graph and
+// two leaves with the same shape yield a candidate automorphism.
+type automorphismSearch struct {
+	adj     [][]int
+	count   []int
+	inQueue []bool
+	marked  []bool
+}
+
+func newAutomorphismSearch(adj [][]int) *automorphismSearch {
+	n := len(adj)
+	return &automorphismSearch{adj, make([]int, n), make([]bool, n), make([]bool, n)}
+}
+
+// generators returns the generators of the automorphism group of the graph
+// with the given vertex colors.  An automorphism is returned as a slice
+// which maps each vertex to its image.
+func (s *automorphismSearch) generators(colors []int) [][]int {
+	n := len(s.adj)
+	if n == 0 {
+		return nil
+	}
+	p := s.initialPartition(colors)
+	var path []searchLevel
+	for !p.discrete() {
+		target := p.firstNonSingleton()
+		cell := slices.Clone(p.perm[target:p.cellEnd[target]])
+		vertex := slices.Min(cell)
+		path = append(path, searchLevel{p.copy(), target, cell, vertex})
+		s.individualize(p, vertex)
+	}
+	shapes := make([]*partition, len(path)+1)
+	for i, level := range path {
+		shapes[i] = level.partition
+	}
+	shapes[len(path)] = p
+	leaf := p.perm
+
+	orbits := newUnionFind(n)
+	var generators [][]int
+	for i := len(path) - 1; i >= 0; i-- {
+		level := path[i]
+		for _, w := range level.cell {
+			if orbits.find(w) == orbits.find(level.vertex) {
+				continue
+			}
+			q := level.partition.copy()
+			s.individualize(q, w)
+			if gamma := s.search(q, i+1, path, shapes, leaf); gamma != nil {
+				generators = append(generators, gamma)
+				for v, image := range gamma {
+					orbits.union(v, image)
+				}
+			}
+		}
+	}
+	return generators
+}
+
+// search searches the subtree of the given partition for a leaf which yields
+// an automorphism together with the leaf of the first path.
+func (s *automorphismSearch) search(p *partition, depth int, path []searchLevel, shapes []*partition, leaf []int) []int {
+	if !p.sameShape(shapes[depth]) {
+		return nil
+	}
+	if p.discrete() {
+		gamma := make([]int, len(leaf))
+		for i, v := range leaf {
+			gamma[v] = p.perm[i]
+		}
+		if s.isAutomorphism(gamma) {
+			return gamma
+		}
+		return nil
+	}
+	target := path[depth].target
+	for _, u := range slices.Clone(p.perm[target:p.cellEnd[target]]) {
+		q := p.copy()
+		s.individualize(q, u)
+		if gamma := s.search(q, depth+1, path, shapes, leaf); gamma != nil {
+			return gamma
+		}
+	}
+	return nil
+}
+
+func (s *automorphismSearch) isAutomorphism(gamma []int) bool {
+	for u, neighbours := range s.adj {
+		imageNeighbours := s.adj[gamma[u]]
+		if len(neighbours) != len(imageNeighbours) {
+			return false
+		}
+		for _, v := range neighbours {
+			if _, found := slices.BinarySearch(imageNeighbours, gamma[v]); !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *automorphismSearch) initialPartition(colors []int) *partition {
+	n := len(colors)
+	p := &partition{make([]int, n), make([]int, n), make([]int, n), make([]int, n), 0}
+	for v := range p.perm {
+		p.perm[v] = v
+	}
+	slices.SortStableFunc(p.perm, func(a, b int) int { return cmp.Compare(colors[a], colors[b]) })
+	var queue []int
+	start := 0
+	for i, v := range p.perm {
+		if i > 0 && colors[v] != colors[p.perm[i-1]] {
+			p.cellEnd[start] = i
+			queue = append(queue, start)
+			start = i
+		}
+		p.pos[v] = i
+		p.cellOf[v] = start
+	}
+	p.cellEnd[start] = n
+	p.cells = len(queue) + 1
+	s.refine(p, append(queue, start))
+	return p
+}
+
+// individualize moves the given vertex to a new singleton cell in front of
+// its current cell and refines the partition.
+func (s *automorphismSearch) individualize(p *partition, v int) {
+	start := p.cellOf[v]
+	end := p.cellEnd[start]
+	position, first := p.pos[v], p.perm[start]
+	p.perm[start], p.perm[position] = v, first
+	p.pos[v], p.pos[first] = start, position
+	p.cellEnd[start] = start + 1
+	p.cellEnd[start+1] = end
+	for i := start + 1; i < end; i++ {
+		p.cellOf[p.perm[i]] = start + 1
+	}
+	p.cells++
+	s.refine(p, []int{start})
+}
+
+// refine refines the partition to an equitable partition.  The queue holds
+// the cells which are used as splitters.
+func (s *automorphismSearch) refine(p *partition, queue []int) {
+	for _, start := range queue {
+		s.inQueue[start] = true
+	}
+	for len(queue) > 0 {
+		splitter := queue[0]
+		queue = queue[1:]
+		s.inQueue[splitter] = false
+		var touched, cells []int
+		for i := splitter; i < p.cellEnd[splitter]; i++ {
+			for _, u := range s.adj[p.perm[i]] {
+				if s.count[u] == 0 {
+					touched = append(touched, u)
+				}
+				s.count[u]++
+			}
+		}
+		for _, u := range touched {
+			cell := p.cellOf[u]
+			if p.cellEnd[cell]-cell > 1 && !s.marked[cell] {
+				s.marked[cell] = true
+				cells = append(cells, cell)
+			}
+		}
+		slices.Sort(cells)
+		for _, cell := range cells {
+			s.marked[cell] = false
+			queue = s.split(p, cell, queue)
+		}
+		for _, u := range touched {
+			s.count[u] = 0
+		}
+	}
+}
+
+// split splits the given cell by the current neighbour counts of its vertices
+// and adds the new cells to the queue.  The new cells are ordered by their
+// count.  If the cell was not in the queue, its largest part is not added.
+func (s *automorphismSearch) split(p *partition, cell int, queue []int) []int {
+	end := p.cellEnd[cell]
+	vertices := p.perm[cell:end]
+	if !slices.ContainsFunc(vertices, func(v int) bool { return s.count[v] != s.count[vertices[0]] }) {
+		return queue
+	}
+	slices.SortStableFunc(vertices, func(a, b int) int { return cmp.Compare(s.count[a], s.count[b]) })
+	starts := []int{cell}
+	for i := cell; i < end; i++ {
+		v := p.perm[i]
+		if i > cell && s.count[v] != s.count[p.perm[i-1]] {
+			p.cellEnd[starts[len(starts)-1]] = i
+			starts = append(starts, i)
+		}
+		p.pos[v] = i
+		p.cellOf[v] = starts[len(starts)-1]
+	}
+	p.cellEnd[starts[len(starts)-1]] = end
+	p.cells += len(starts) - 1
+
+	queued := s.inQueue[cell]
+	largest := cell
+	if !queued {
+		for _, start := range starts {
+			if p.cellEnd[start]-start > p.cellEnd[largest]-largest {
+				largest = start
+			}
+		}
+	}
+	for _, start := range starts {
+		if !s.inQueue[start] && (queued || start != largest) {
+			s.inQueue[start] = true
+			queue = append(queue, start)
+		}
+	}
+	return queue
+}
+
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent}
+}
+
+func (u *unionFind) find(v int) int {
+	for u.parent[v] != v {
+		u.parent[v] = u.parent[u.parent[v]]
+		v = u.parent[v]
+	}
+	return v
+}
+
+func (u *unionFind) union(v, w int) {
+	u.parent[u.find(v)] = u.find(w)
+}
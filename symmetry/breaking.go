@@ -0,0 +1,92 @@
+package symmetry
+
+import (
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// Config describes the configuration for the generation of
+// symmetry-breaking predicates.
+type Config struct {
+	// MaxPredicateSize is the maximum number of variables of a single
+	// lex-leader constraint.  Longer constraints are cut off which only
+	// weakens the symmetry breaking.  A value <= 0 means no limit.
+	MaxPredicateSize int
+}
+
+// DefaultConfig returns the default configuration for the generation of
+// symmetry-breaking predicates.
+func DefaultConfig() *Config {
+	return &Config{
+		MaxPredicateSize: 50,
+	}
+}
+
+// BreakingClauses returns the lex-leader symmetry-breaking predicates for the
+// given symmetries as a list of clauses.  For each permutation p the clauses
+// encode that the assignment of the variables of the support of p is
+// lexicographically smaller or equal than its image under p.  Variables are
+// ordered by their creation on the formula factory and false is smaller than
+// true.
+//
+// The clauses remove models which are symmetric to other models.  Therefore,
+// adding them to a CNF preserves its satisfiability but changes its models
+// and model count.  The clauses introduce new auxiliary variables.
+func BreakingClauses(fac f.Factory, generators []Permutation, config ...*Config) []f.Formula {
+	cfg := DefaultConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	var clauses []f.Formula
+	for _, generator := range generators {
+		clauses = append(clauses, lexLeader(fac, generator, cfg.MaxPredicateSize)...)
+	}
+	return clauses
+}
+
+// BreakSymmetries computes generators of the symmetry group of the given CNF
+// formulas and returns the lex-leader symmetry-breaking predicates for them.
+// The predicates can be added to a SAT solver together with the CNF in order
+// to avoid searching symmetric parts of the search space.
+//
+// Returns an error if one of the formulas is not in CNF.
+func BreakSymmetries(fac f.Factory, cnf []f.Formula, config ...*Config) ([]f.Formula, error) {
+	generators, err := ComputeGenerators(fac, cnf...)
+	if err != nil {
+		return nil, err
+	}
+	return BreakingClauses(fac, generators, config...), nil
+}
+
+// lexLeader encodes x <= p(x) for the support x_1, ..., x_n of the
+// permutation p.  The auxiliary variable e_i is true if x_j = p(x_j) for all
+// j <= i and the encoding consists of the clauses
+//
+//	e_{i-1} => (x_i => p(x_i))
+//	e_{i-1} & x_i => e_i
+//	e_{i-1} & ~p(x_i) => e_i
+func lexLeader(fac f.Factory, permutation Permutation, maxSize int) []f.Formula {
+	support := permutation.Support()
+	if maxSize > 0 && len(support) > maxSize {
+		support = support[:maxSize]
+	}
+	var clauses []f.Formula
+	var equal []f.Literal
+	for i, variable := range support {
+		x := variable.AsLiteral()
+		image := permutation.Image(x)
+		notX := x.Negate(fac)
+		if image == notX {
+			clauses = append(clauses, fac.Clause(append(equal, notX)...))
+			break
+		}
+		clauses = append(clauses, fac.Clause(append(equal, notX, image)...))
+		if i == len(support)-1 {
+			break
+		}
+		e := fac.NewAuxVar(f.AuxCNF)
+		clauses = append(clauses, fac.Clause(append(equal, notX, e.AsLiteral())...))
+		clauses = append(clauses, fac.Clause(append(equal, image, e.AsLiteral())...))
+		equal = []f.Literal{e.Negate(fac)}
+	}
+	return clauses
+}
@@ -0,0 +1,33 @@
+// Package symmetry provides symmetry detection and symmetry breaking for
+// CNF formulas in LogicNG.
+//
+// A symmetry of a CNF is a permutation of its literals which maps the set of
+// clauses to itself.  Symmetries map models to models and non-models to
+// non-models.  A SAT solver can therefore waste a lot of time in searching
+// symmetric parts of the search space, e.g. for pigeonhole problems.
+//
+// The symmetries are computed as automorphisms of the colored clause graph of
+// the CNF.  This graph holds a node for each literal and for each clause and
+// can be generated with
+//
+//	clauseGraph, err := symmetry.GenerateClauseGraph(fac, cnf)
+//
+// Generators of the symmetry group are computed by a built-in
+// individualization-refinement algorithm similar to saucy or bliss:
+//
+//	generators, err := symmetry.ComputeGenerators(fac, cnf)
+//
+// For the generators, lex-leader symmetry-breaking predicates can be
+// generated.  These are clauses which exclude all but the lexicographically
+// smallest models of (parts of) each symmetry class.  They can be added to a
+// SAT solver together with the CNF:
+//
+//	sbp := symmetry.BreakingClauses(fac, generators)
+//	solver := sat.NewSolver(fac)
+//	solver.Add(cnf)
+//	solver.Add(sbp...)
+//
+// Symmetry breaking preserves the satisfiability of the CNF, but it removes
+// models.  Therefore, it must not be used if all models of a formula are
+// required, e.g. for model enumeration or model counting.
+package symmetry
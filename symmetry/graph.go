@@ -0,0 +1,85 @@
+package symmetry
+
+import (
+	"github.com/booleworks/logicng-go/errorx"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/graph"
+	"github.com/booleworks/logicng-go/normalform"
+)
+
+const (
+	colorLiteral = iota
+	colorUnitLiteral
+	colorNegatedUnitLiteral
+	colorClause
+)
+
+// A ClauseGraph is the colored graph of a CNF whose automorphisms are the
+// symmetries of the CNF.
+//
+// For each variable of the CNF the graph holds a node for its positive and
+// its negative literal which are connected with each other.  For each clause
+// with at least two literals the graph holds a node for the clause which is
+// connected to the nodes of its literals.  Unit clauses are represented by
+// the colors of their literal nodes.  Nodes can only be mapped to nodes with
+// the same color.
+type ClauseGraph struct {
+	Graph  *graph.FormulaGraph
+	Colors map[f.Formula]int
+}
+
+// GenerateClauseGraph generates the colored clause graph for the given CNF
+// formulas.  Returns an error if one of the formulas is not in CNF.
+func GenerateClauseGraph(fac f.Factory, cnf ...f.Formula) (*ClauseGraph, error) {
+	clauses, err := collectClauses(fac, cnf)
+	if err != nil {
+		return nil, err
+	}
+	g := graph.NewFormulaGraph()
+	colors := make(map[f.Formula]int)
+	for _, variable := range f.Variables(fac, cnf...).Content() {
+		pos := variable.AsFormula()
+		neg := variable.Negate(fac).AsFormula()
+		g.Connect(pos, neg)
+		colors[pos] = colorLiteral
+		colors[neg] = colorLiteral
+	}
+	for _, clause := range clauses {
+		if clause.Sort() == f.SortLiteral {
+			lit := f.Literal(clause)
+			colors[clause] = colorUnitLiteral
+			if negated := lit.Negate(fac).AsFormula(); colors[negated] != colorUnitLiteral {
+				colors[negated] = colorNegatedUnitLiteral
+			}
+			continue
+		}
+		g.AddNode(clause)
+		colors[clause] = colorClause
+		for _, lit := range fac.Operands(clause) {
+			g.Connect(clause, lit)
+		}
+	}
+	return &ClauseGraph{g, colors}, nil
+}
+
+// collectClauses returns the clauses of the given CNF formulas.  Constants
+// are not part of the result, since they are invariant under every
+// permutation of literals.
+func collectClauses(fac f.Factory, cnf []f.Formula) ([]f.Formula, error) {
+	var clauses []f.Formula
+	for _, formula := range cnf {
+		if !normalform.IsCNF(fac, formula) {
+			return nil, errorx.BadInput("formula %s is not in CNF", formula.Sprint(fac))
+		}
+		switch formula.Sort() {
+		case f.SortTrue, f.SortFalse:
+		case f.SortLiteral, f.SortOr:
+			clauses = append(clauses, formula)
+		case f.SortAnd:
+			clauses = append(clauses, fac.Operands(formula)...)
+		default:
+			return nil, errorx.BadFormulaSort(formula.Sort())
+		}
+	}
+	return clauses, nil
+}
@@ -0,0 +1,80 @@
+package symmetry
+
+import (
+	"slices"
+
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// A Permutation is a symmetry of a CNF given as a permutation of its
+// literals.  Only literals which are not mapped to themselves are stored.
+// A permutation is always consistent with negation: if a literal l is mapped
+// to m, then ~l is mapped to ~m.  A variable can be mapped to its own
+// negation (a phase-shift symmetry).
+type Permutation map[f.Literal]f.Literal
+
+// Image returns the image of the given literal under the permutation.
+func (p Permutation) Image(literal f.Literal) f.Literal {
+	if image, ok := p[literal]; ok {
+		return image
+	}
+	return literal
+}
+
+// Support returns the variables which are moved by the permutation ordered
+// by their creation on the formula factory.
+func (p Permutation) Support() []f.Variable {
+	seen := make(map[f.Variable]bool)
+	var support []f.Variable
+	for lit := range p {
+		if v := lit.Variable(); !seen[v] {
+			seen[v] = true
+			support = append(support, v)
+		}
+	}
+	slices.Sort(support)
+	return support
+}
+
+// ComputeGenerators computes generators of the symmetry group of the given
+// CNF formulas.  A symmetry is a permutation of literals which maps the set
+// of clauses to itself.  The symmetries are computed as automorphisms of the
+// colored clause graph of the CNF.  Every symmetry of the CNF can be composed
+// from the returned generators.
+//
+// Returns an error if one of the formulas is not in CNF.
+func ComputeGenerators(fac f.Factory, cnf ...f.Formula) ([]Permutation, error) {
+	clauseGraph, err := GenerateClauseGraph(fac, cnf...)
+	if err != nil {
+		return nil, err
+	}
+	nodes := clauseGraph.Graph.Nodes()
+	indices := make(map[f.Formula]int, len(nodes))
+	for i, node := range nodes {
+		indices[node] = i
+	}
+	adj := make([][]int, len(nodes))
+	colors := make([]int, len(nodes))
+	for i, node := range nodes {
+		colors[i] = clauseGraph.Colors[node]
+		for _, neighbour := range clauseGraph.Graph.Neighbours(node) {
+			adj[i] = append(adj[i], indices[neighbour])
+		}
+		slices.Sort(adj[i])
+	}
+
+	automorphisms := newAutomorphismSearch(adj).generators(colors)
+	generators := make([]Permutation, 0, len(automorphisms))
+	for _, gamma := range automorphisms {
+		permutation := make(Permutation)
+		for i, image := range gamma {
+			if i != image && nodes[i].Sort() == f.SortLiteral {
+				permutation[f.Literal(nodes[i])] = f.Literal(nodes[image])
+			}
+		}
+		if len(permutation) > 0 {
+			generators = append(generators, permutation)
+		}
+	}
+	return generators, nil
+}
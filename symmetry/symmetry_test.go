@@ -0,0 +1,260 @@
+package symmetry
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model/count"
+	"github.com/booleworks/logicng-go/parser"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClauseGraph(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	cnf := p.ParseUnsafe("(a | b) & (~a | c) & ~c")
+
+	clauseGraph, err := GenerateClauseGraph(fac, cnf)
+	assert.Nil(err)
+	assert.Len(clauseGraph.Graph.Nodes(), 8)
+	assert.Equal(colorUnitLiteral, clauseGraph.Colors[p.ParseUnsafe("~c")])
+	assert.Equal(colorNegatedUnitLiteral, clauseGraph.Colors[p.ParseUnsafe("c")])
+	assert.Equal(colorLiteral, clauseGraph.Colors[p.ParseUnsafe("a")])
+	assert.Equal(colorClause, clauseGraph.Colors[p.ParseUnsafe("a | b")])
+	assert.ElementsMatch(
+		[]f.Formula{p.ParseUnsafe("a"), p.ParseUnsafe("b")},
+		clauseGraph.Graph.Neighbours(p.ParseUnsafe("a | b")),
+	)
+
+	_, err = GenerateClauseGraph(fac, p.ParseUnsafe("a => b"))
+	assert.NotNil(err)
+	_, err = ComputeGenerators(fac, p.ParseUnsafe("a | b & c"))
+	assert.NotNil(err)
+}
+
+func TestGeneratorsSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+
+	generators, err := ComputeGenerators(fac, p.ParseUnsafe("(a | b) & (~a | c) & (~b | c)"))
+	assert.Nil(err)
+	assert.Len(generators, 1)
+	assert.Equal(fac.Lit("b", true), generators[0].Image(fac.Lit("a", true)))
+	assert.Equal(fac.Lit("a", false), generators[0].Image(fac.Lit("b", false)))
+	assert.Equal(fac.Lit("c", true), generators[0].Image(fac.Lit("c", true)))
+
+	generators, err = ComputeGenerators(fac, p.ParseUnsafe("(a | b) & (~a | ~b)"))
+	assert.Nil(err)
+	assert.NotEmpty(generators)
+	assertOrbit(t, fac, generators, fac.Lit("a", true), fac.Lit("a", false), fac.Lit("b", true), fac.Lit("b", false))
+
+	generators, err = ComputeGenerators(fac, p.ParseUnsafe("(a | b) & (~b | c) & c"))
+	assert.Nil(err)
+	assert.Empty(generators)
+
+	generators, err = ComputeGenerators(fac, fac.Verum())
+	assert.Nil(err)
+	assert.Empty(generators)
+}
+
+func TestGeneratorsPigeonHole(t *testing.T) {
+	for n := 2; n <= 5; n++ {
+		fac := f.NewFactory()
+		php := sat.GeneratePigeonHole(fac, n)
+		generators, err := ComputeGenerators(fac, php)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, generators)
+		assertPreservesClauses(t, fac, generators, php)
+		var lits []f.Literal
+		for _, v := range f.Variables(fac, php).Content() {
+			lits = append(lits, v.AsLiteral())
+		}
+		assertOrbit(t, fac, generators, lits...)
+	}
+}
+
+func TestGeneratorsQueens(t *testing.T) {
+	fac := f.NewFactory()
+	cnf := queens(fac, 6)
+	generators, err := ComputeGenerators(fac, cnf...)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, generators)
+	assertPreservesClauses(t, fac, generators, cnf...)
+}
+
+func TestBreakingPreservesSatisfiability(t *testing.T) {
+	assert := assert.New(t)
+	for n := 2; n <= 5; n++ {
+		fac := f.NewFactory()
+		php := sat.GeneratePigeonHole(fac, n)
+		sbp, err := BreakSymmetries(fac, []f.Formula{php})
+		assert.Nil(err)
+		assert.NotEmpty(sbp)
+		solver := sat.NewSolver(fac)
+		solver.Add(php)
+		solver.Add(sbp...)
+		assert.False(solver.Sat())
+	}
+
+	for n := 4; n <= 6; n++ {
+		fac := f.NewFactory()
+		cnf := queens(fac, n)
+		vars := f.Variables(fac, cnf...).Content()
+		solver := sat.NewSolver(fac)
+		solver.Add(cnf...)
+		original := count.OnSolver(solver, vars)
+
+		sbp, err := BreakSymmetries(fac, cnf)
+		assert.Nil(err)
+		solver = sat.NewSolver(fac)
+		solver.Add(cnf...)
+		solver.Add(sbp...)
+		broken := count.OnSolver(solver, vars)
+		assert.Equal(original.Sign() > 0, broken.Sign() > 0)
+		assert.True(broken.Cmp(original) <= 0)
+		if original.Int64() > 1 {
+			assert.True(broken.Cmp(original) < 0)
+		}
+	}
+}
+
+func TestBreakingRandom(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	r := rand.New(rand.NewSource(42))
+	vars := make([]f.Variable, 6)
+	for i := range vars {
+		vars[i] = fac.Var(fmt.Sprintf("v%d", i))
+	}
+	for i := 0; i < 200; i++ {
+		clauses := make([]f.Formula, 5+r.Intn(20))
+		for j := range clauses {
+			lits := make([]f.Literal, 1+r.Intn(3))
+			for k := range lits {
+				lits[k] = fac.Lit(fmt.Sprintf("v%d", r.Intn(len(vars))), r.Intn(2) == 0)
+			}
+			clauses[j] = fac.Clause(lits...)
+		}
+		generators, err := ComputeGenerators(fac, clauses...)
+		assert.Nil(err)
+		assertPreservesClauses(t, fac, generators, clauses...)
+
+		solver := sat.NewSolver(fac)
+		solver.Add(clauses...)
+		original := count.OnSolver(solver, vars)
+		solver.Add(BreakingClauses(fac, generators)...)
+		broken := count.OnSolver(solver, vars)
+		assert.Equal(original.Sign() > 0, broken.Sign() > 0)
+		assert.True(broken.Cmp(original) <= 0)
+	}
+}
+
+func TestBreakingPhaseShift(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	cnf := p.ParseUnsafe("(a | b) & (~a | ~b)")
+	generators, _ := ComputeGenerators(fac, cnf)
+	sbp := BreakingClauses(fac, generators)
+
+	solver := sat.NewSolver(fac)
+	solver.Add(cnf)
+	solver.Add(sbp...)
+	vars := []f.Variable{fac.Var("a"), fac.Var("b")}
+	assert.Equal(int64(1), count.OnSolver(solver, vars).Int64())
+}
+
+func TestBreakingMaxPredicateSize(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	p := parser.New(fac)
+	permutation := Permutation{
+		fac.Lit("a", true): fac.Lit("b", true), fac.Lit("a", false): fac.Lit("b", false),
+		fac.Lit("b", true): fac.Lit("a", true), fac.Lit("b", false): fac.Lit("a", false),
+		fac.Lit("c", true): fac.Lit("d", true), fac.Lit("c", false): fac.Lit("d", false),
+		fac.Lit("d", true): fac.Lit("c", true), fac.Lit("d", false): fac.Lit("c", false),
+	}
+	assert.Equal([]f.Variable{fac.Var("a"), fac.Var("b"), fac.Var("c"), fac.Var("d")}, permutation.Support())
+	assert.Equal([]f.Formula{p.ParseUnsafe("~a | b")}, BreakingClauses(fac, []Permutation{permutation}, &Config{1}))
+	assert.Len(BreakingClauses(fac, []Permutation{permutation}, &Config{2}), 4)
+	assert.Len(BreakingClauses(fac, []Permutation{permutation}), 10)
+}
+
+func assertPreservesClauses(t *testing.T, fac f.Factory, generators []Permutation, cnf ...f.Formula) {
+	clauses, err := collectClauses(fac, cnf)
+	assert.Nil(t, err)
+	clauseSet := make(map[string]bool)
+	for _, clause := range clauses {
+		clauseSet[clauseKey(f.Literals(fac, clause).Content())] = true
+	}
+	for _, generator := range generators {
+		for lit, image := range generator {
+			assert.Equal(t, image.Negate(fac), generator.Image(lit.Negate(fac)))
+		}
+		for _, clause := range clauses {
+			lits := f.Literals(fac, clause).Content()
+			mapped := make([]f.Literal, len(lits))
+			for i, lit := range lits {
+				mapped[i] = generator.Image(lit)
+			}
+			assert.True(t, clauseSet[clauseKey(mapped)])
+		}
+	}
+}
+
+func clauseKey(lits []f.Literal) string {
+	sorted := slices.Clone(lits)
+	slices.Sort(sorted)
+	return fmt.Sprint(sorted)
+}
+
+func assertOrbit(t *testing.T, fac f.Factory, generators []Permutation, lits ...f.Literal) {
+	orbit := map[f.Literal]bool{lits[0]: true}
+	queue := []f.Literal{lits[0]}
+	for len(queue) > 0 {
+		lit := queue[0]
+		queue = queue[1:]
+		for _, generator := range generators {
+			if image := generator.Image(lit); !orbit[image] {
+				orbit[image] = true
+				queue = append(queue, image)
+			}
+		}
+	}
+	for _, lit := range lits {
+		assert.True(t, orbit[lit], lit.Sprint(fac))
+	}
+}
+
+func queens(fac f.Factory, n int) []f.Formula {
+	v := func(i, j int) f.Literal { return fac.Lit(fmt.Sprintf("q_%d_%d", i, j), true) }
+	var clauses []f.Formula
+	for i := 0; i < n; i++ {
+		row := make([]f.Literal, n)
+		for j := 0; j < n; j++ {
+			row[j] = v(i, j)
+		}
+		clauses = append(clauses, fac.Clause(row...))
+	}
+	for i1 := 0; i1 < n; i1++ {
+		for j1 := 0; j1 < n; j1++ {
+			for i2 := 0; i2 < n; i2++ {
+				for j2 := 0; j2 < n; j2++ {
+					if i1*n+j1 >= i2*n+j2 {
+						continue
+					}
+					if i1 == i2 || j1 == j2 || i1-j1 == i2-j2 || i1+j1 == i2+j2 {
+						clauses = append(clauses, fac.Clause(v(i1, j1).Negate(fac), v(i2, j2).Negate(fac)))
+					}
+				}
+			}
+		}
+	}
+	return clauses
+}
@@ -54,6 +54,17 @@ func TestAlkSolver(t *testing.T) {
 	}
 }
 
+func TestAlkTotalizerSize(t *testing.T) {
+	fac := f.NewFactory()
+	vars := make([]f.Variable, 30)
+	for i := range vars {
+		vars[i] = fac.Var(fmt.Sprintf("x%d", i))
+	}
+	cnf, err := encoding.EncodeCC(fac, fac.CC(f.GE, 5, vars...), &encoding.Config{ALKEncoder: encoding.ALKTotalizer})
+	assert.Nil(t, err)
+	assert.Equal(t, 588, len(cnf))
+}
+
 func testAlkFormula(
 	t *testing.T, fac f.Factory, config *encoding.Config, numLits, rhs, expected int, useSolver bool,
 ) {
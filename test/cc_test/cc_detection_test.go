@@ -0,0 +1,77 @@
+package cc
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	e "github.com/booleworks/logicng-go/encoding"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model/enum"
+	"github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectionRandom(t *testing.T) {
+	assert := assert.New(t)
+	encoders := []e.AMOEncoder{
+		e.AMOPure, e.AMOLadder, e.AMOProduct, e.AMONested, e.AMOCommander, e.AMOBinary, e.AMOBimander,
+	}
+	for seed := range 50 {
+		rnd := rand.New(rand.NewSource(int64(seed)))
+		fac := f.NewFactory()
+		vars := make([]f.Variable, 10)
+		for i := range vars {
+			vars[i] = fac.Var(fmt.Sprintf("x%d", i))
+		}
+		var clauses []f.Formula
+		for range 2 {
+			perm := rnd.Perm(len(vars))[:3+rnd.Intn(5)]
+			amoVars := make([]f.Variable, len(perm))
+			for i, index := range perm {
+				amoVars[i] = vars[index]
+			}
+			config := e.DefaultConfig()
+			config.AMOEncoder = encoders[rnd.Intn(len(encoders))]
+			cnf, err := e.EncodeCC(fac, fac.AMO(amoVars...), config)
+			assert.Nil(err)
+			clauses = append(clauses, cnf...)
+		}
+		for range 4 {
+			lits := make([]f.Literal, 1+rnd.Intn(3))
+			for i := range lits {
+				lits[i] = fac.Lit(fmt.Sprintf("x%d", rnd.Intn(len(vars))), rnd.Intn(2) == 0)
+			}
+			clauses = append(clauses, fac.Clause(lits...))
+		}
+
+		detection, err := e.DetectCardinalityConstraints(fac, clauses, &e.DetectionConfig{OriginalVars: f.NewVarSet(vars...)})
+		assert.Nil(err)
+		assert.NotEmpty(detection.Constraints)
+		assertLiftedEquivalent(t, fac, clauses, detection, vars)
+
+		detection, err = e.DetectCardinalityConstraints(fac, clauses, &e.DetectionConfig{GuessAuxVars: true})
+		assert.Nil(err)
+		projected := f.NewMutableVarSet(vars...)
+		projected.RemoveAll(f.NewVarSet(detection.AuxVars...))
+		assertLiftedEquivalent(t, fac, clauses, detection, projected.AsImmutable().Content())
+	}
+}
+
+func assertLiftedEquivalent(t *testing.T, fac f.Factory, clauses []f.Formula, detection *e.Detection, vars []f.Variable) {
+	for _, v := range detection.AuxVars {
+		for _, c := range append(detection.Constraints, detection.Remaining...) {
+			assert.False(t, f.Variables(fac, c).Contains(v))
+		}
+	}
+	lifted := fac.And(append(detection.Constraints, detection.Remaining...)...)
+	solver := sat.NewSolver(fac)
+	solver.Add(clauses...)
+	models := enum.OnSolver(solver, vars)
+	liftedSolver := sat.NewSolver(fac)
+	liftedSolver.Add(lifted)
+	assert.Equal(t, len(models), len(enum.OnSolver(liftedSolver, vars)))
+	for _, model := range models {
+		assert.True(t, liftedSolver.Call(sat.WithAssumptions(model.Literals)).Sat())
+	}
+}
@@ -43,24 +43,24 @@ func TestIncrementalEncodingSimpleAMK(t *testing.T) {
 
 		solver.Add(incData.Result.Formulas()...)
 		assert.True(solver.Sat())
-		solver.Add(incData.NewUpperBound(8)...)
+		addBound(t, solver)(incData.NewUpperBound(8))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewUpperBound(7)...)
+		addBound(t, solver)(incData.NewUpperBound(7))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewUpperBound(6)...)
+		addBound(t, solver)(incData.NewUpperBound(6))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewUpperBound(5)...)
+		addBound(t, solver)(incData.NewUpperBound(5))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewUpperBound(4)...)
+		addBound(t, solver)(incData.NewUpperBound(4))
 		assert.True(solver.Sat())
 
 		state := solver.SaveState()
-		solver.Add(incData.NewUpperBound(3)...)
+		addBound(t, solver)(incData.NewUpperBound(3))
 		assert.False(solver.Sat())
 		solver.LoadState(state)
 		assert.True(solver.Sat())
 
-		solver.Add(incData.NewUpperBound(2)...)
+		addBound(t, solver)(incData.NewUpperBound(2))
 		assert.False(solver.Sat())
 	}
 }
@@ -85,23 +85,23 @@ func TestIncrementalEncodingSimpleALK(t *testing.T) {
 		solver.Add(incData.Result.Formulas()...)
 		assert.True(solver.Sat())
 
-		solver.Add(incData.NewLowerBound(3)...)
+		addBound(t, solver)(incData.NewLowerBound(3))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewLowerBound(4)...)
+		addBound(t, solver)(incData.NewLowerBound(4))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewLowerBound(5)...)
+		addBound(t, solver)(incData.NewLowerBound(5))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewLowerBound(6)...)
+		addBound(t, solver)(incData.NewLowerBound(6))
 		assert.True(solver.Sat())
-		solver.Add(incData.NewLowerBound(7)...)
+		addBound(t, solver)(incData.NewLowerBound(7))
 		assert.True(solver.Sat())
 
 		state := solver.SaveState()
-		solver.Add(incData.NewLowerBound(8)...)
+		addBound(t, solver)(incData.NewLowerBound(8))
 		assert.False(solver.Sat())
 		solver.LoadState(state)
 		assert.True(solver.Sat())
-		solver.Add(incData.NewLowerBound(9)...)
+		addBound(t, solver)(incData.NewLowerBound(9))
 		assert.False(solver.Sat())
 	}
 }
@@ -127,7 +127,7 @@ func TestIncrementalEncodingLargeTotalizerAMK(t *testing.T) {
 	// search the lower bound
 	for solver.Sat() {
 		currentBound--
-		solver.Add(incData.NewUpperBound(currentBound)...)
+		addBound(t, solver)(incData.NewUpperBound(currentBound))
 	}
 	assert.Equal(41, currentBound)
 }
@@ -154,7 +154,7 @@ func TestIncrementalEncodingLargeTotalizerALK(t *testing.T) {
 	// search the lower bound
 	for solver.Sat() {
 		currentBound++
-		solver.Add(incData.NewLowerBound(currentBound)...)
+		addBound(t, solver)(incData.NewLowerBound(currentBound))
 	}
 	assert.Equal(88, currentBound)
 }
@@ -183,7 +183,7 @@ func TestIncrementalEncodingVeryLargeModularTotalizerAMK(t *testing.T) {
 	// search the lower bound
 	for solver.Sat() {
 		currentBound--
-		solver.Add(incData.NewUpperBound(currentBound)...)
+		addBound(t, solver)(incData.NewUpperBound(currentBound))
 	}
 	assert.Equal(233, currentBound)
 }
@@ -206,24 +206,24 @@ func TestIncrementalEncodingSimpleAMKSolver(t *testing.T) {
 		assert.Nil(err)
 		assert.True(solver.Sat())
 
-		incData.NewUpperBoundForSolver(8)
+		assert.Nil(incData.NewUpperBoundForSolver(8))
 		assert.True(solver.Sat())
-		incData.NewUpperBoundForSolver(7)
+		assert.Nil(incData.NewUpperBoundForSolver(7))
 		assert.True(solver.Sat())
-		incData.NewUpperBoundForSolver(6)
+		assert.Nil(incData.NewUpperBoundForSolver(6))
 		assert.True(solver.Sat())
-		incData.NewUpperBoundForSolver(5)
+		assert.Nil(incData.NewUpperBoundForSolver(5))
 		assert.True(solver.Sat())
-		incData.NewUpperBoundForSolver(4)
+		assert.Nil(incData.NewUpperBoundForSolver(4))
 		assert.True(solver.Sat())
 
 		state := solver.SaveState()
-		incData.NewUpperBoundForSolver(3)
+		assert.Nil(incData.NewUpperBoundForSolver(3))
 		assert.False(solver.Sat())
 		solver.LoadState(state)
 		assert.True(solver.Sat())
 
-		incData.NewUpperBoundForSolver(2)
+		assert.Nil(incData.NewUpperBoundForSolver(2))
 		assert.False(solver.Sat())
 	}
 }
@@ -247,23 +247,23 @@ func TestIncrementalEncodingSimpleALKSolver(t *testing.T) {
 		assert.Nil(err)
 		assert.True(solver.Sat())
 
-		incData.NewLowerBoundForSolver(3)
+		assert.Nil(incData.NewLowerBoundForSolver(3))
 		assert.True(solver.Sat())
-		incData.NewLowerBoundForSolver(4)
+		assert.Nil(incData.NewLowerBoundForSolver(4))
 		assert.True(solver.Sat())
-		incData.NewLowerBoundForSolver(5)
+		assert.Nil(incData.NewLowerBoundForSolver(5))
 		assert.True(solver.Sat())
-		incData.NewLowerBoundForSolver(6)
+		assert.Nil(incData.NewLowerBoundForSolver(6))
 		assert.True(solver.Sat())
-		incData.NewLowerBoundForSolver(7)
+		assert.Nil(incData.NewLowerBoundForSolver(7))
 		assert.True(solver.Sat())
 
 		state := solver.SaveState()
-		incData.NewLowerBoundForSolver(8)
+		assert.Nil(incData.NewLowerBoundForSolver(8))
 		assert.False(solver.Sat())
 		solver.LoadState(state)
 		assert.True(solver.Sat())
-		incData.NewLowerBoundForSolver(9)
+		assert.Nil(incData.NewLowerBoundForSolver(9))
 		assert.False(solver.Sat())
 	}
 }
@@ -288,7 +288,7 @@ func TestIncrementalEncodingLargeTotalizerAMKSolver(t *testing.T) {
 	// search the lower bound
 	for solver.Sat() {
 		currentBound--
-		incData.NewUpperBoundForSolver(currentBound)
+		assert.Nil(incData.NewUpperBoundForSolver(currentBound))
 	}
 	assert.Equal(41, currentBound)
 }
@@ -314,8 +314,90 @@ func TestIncrementalEncodingLargeTotalizerALKSolver(t *testing.T) {
 		// search the lower bound
 		for solver.Sat() {
 			currentBound++
-			incData.NewLowerBoundForSolver(currentBound)
+			assert.Nil(incData.NewLowerBoundForSolver(currentBound))
 		}
 		assert.Equal(88, currentBound)
 	}
 }
+
+func TestIncrementalEncodingALKUpperBound(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	vars := fac.Vars("a", "b", "c", "d", "e", "f")
+	config := e.DefaultConfig()
+	config.ALKEncoder = e.ALKTotalizer
+	solver := sat.NewSolver(fac)
+	solver.Add(fac.CC(f.GE, 3, vars...))
+	incData, err := e.EncodeIncremental(fac, fac.CC(f.GE, 2, vars...), e.ResultForFormula(fac), config)
+	assert.Nil(err)
+	solver.Add(incData.Result.Formulas()...)
+	assert.Equal(6, incData.CurrentUpperBound())
+	assert.Equal(2, incData.CurrentLowerBound())
+
+	addBound(t, solver)(incData.NewUpperBound(4))
+	assert.True(solver.Sat())
+	addBound(t, solver)(incData.NewLowerBound(4))
+	assert.True(solver.Sat())
+	model := solver.Call(sat.WithModel(vars)).Model()
+	assert.Equal(4, len(model.PosVars()))
+	addBound(t, solver)(incData.NewUpperBound(3))
+	assert.False(solver.Sat())
+}
+
+func TestIncrementalEncodingEXK(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	vars := fac.Vars("a", "b", "c", "d", "e", "f", "g", "h")
+	solver := sat.NewSolver(fac)
+	incData, err := solver.AddIncrementalCC(fac.CC(f.EQ, 5, vars...))
+	assert.Nil(err)
+	assert.True(solver.Sat())
+
+	state := solver.SaveState()
+	assert.Nil(incData.NewUpperBoundForSolver(4))
+	assert.False(solver.Sat())
+	solver.LoadState(state)
+	assert.Nil(incData.NewLowerBoundForSolver(6))
+	assert.False(solver.Sat())
+
+	solver = sat.NewSolver(fac)
+	incData, err = solver.AddIncrementalCC(fac.CC(f.EQ, 1, vars...))
+	assert.Nil(err)
+	assert.True(solver.Sat())
+	assert.Nil(incData.NewLowerBoundForSolver(2))
+	assert.False(solver.Sat())
+}
+
+func TestIncrementalEncodingErrors(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	vars := fac.Vars("a", "b", "c", "d", "e", "f")
+	for _, config := range cfgs() {
+		amk, err := e.EncodeIncremental(fac, fac.CC(f.LE, 4, vars...), e.ResultForFormula(fac), config)
+		assert.Nil(err)
+		_, err = amk.NewUpperBound(4)
+		assert.NotNil(err)
+		_, err = amk.NewLowerBound(2)
+		assert.NotNil(err)
+		assert.Nil(amk.NewUpperBoundForSolver(3))
+		assert.NotNil(amk.NewUpperBoundForSolver(5))
+
+		alk, err := e.EncodeIncremental(fac, fac.CC(f.GE, 3, vars...), e.ResultForFormula(fac), config)
+		assert.Nil(err)
+		assert.NotNil(alk.NewLowerBoundForSolver(2))
+		if config.ALKEncoder == e.ALKTotalizer {
+			assert.Nil(alk.NewUpperBoundForSolver(5))
+		} else {
+			assert.NotNil(alk.NewUpperBoundForSolver(5))
+		}
+	}
+	_, err := e.EncodeIncremental(fac, fac.Var("a").AsFormula(), e.ResultForFormula(fac))
+	assert.NotNil(err)
+}
+
+func addBound(t *testing.T, solver *sat.Solver) func([]f.Formula, error) {
+	return func(formulas []f.Formula, err error) {
+		assert.Nil(t, err)
+		solver.Add(formulas...)
+	}
+}
@@ -0,0 +1,34 @@
+package cc_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/booleworks/logicng-go/encoding"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/model/enum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXOREncoding(t *testing.T) {
+	fac := f.NewFactory()
+	for n := 0; n <= 8; n++ {
+		vars := make([]f.Variable, n)
+		for i := range vars {
+			vars[i] = fac.Var(fmt.Sprintf("v%d", i))
+		}
+		for _, parity := range []bool{false, true} {
+			models := enum.OnFormula(fac, fac.And(encoding.EncodeXOR(fac, vars, parity)...), vars)
+			expected := 0
+			if n > 0 {
+				expected = 1 << (n - 1)
+			} else if !parity {
+				expected = 1
+			}
+			assert.Equal(t, expected, len(models))
+			for _, model := range models {
+				assert.Equal(t, parity, len(model.PosVars())%2 == 1)
+			}
+		}
+	}
+}
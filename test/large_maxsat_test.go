@@ -81,7 +81,11 @@ func solveMaxSat(fac f.Factory, formulas []f.Formula, variables []f.Variable, so
 
 func minimize(formulas []f.Formula, literals []f.Variable, solver *sat.Solver) *model.Model {
 	solver.Add(formulas...)
-	return solver.Minimize(f.VariablesAsLiterals(literals))
+	mdl, err := solver.Minimize(f.VariablesAsLiterals(literals))
+	if err != nil {
+		panic(err)
+	}
+	return mdl
 }
 
 func readResult(filename string) map[string]int {
@@ -0,0 +1,90 @@
+package pbc_test
+
+import (
+	"testing"
+
+	"github.com/booleworks/logicng-go/assignment"
+	e "github.com/booleworks/logicng-go/encoding"
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/booleworks/logicng-go/randomizer"
+	s "github.com/booleworks/logicng-go/sat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReifiedEncodingSimple(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	a, b, c := fac.Lit("a", true), fac.Lit("b", true), fac.Lit("c", true)
+	sel := fac.Lit("s", true)
+	pbc := fac.PBC(f.GE, 4, []f.Literal{a, b, c}, []int{3, 2, 1})
+
+	solver := s.NewSolver(fac)
+	cnf, err := e.EncodeHalfReified(fac, pbc, sel)
+	assert.Nil(err)
+	solver.Add(cnf...)
+	solver.Add(a.Negate(fac).AsFormula())
+	assert.True(solver.Sat())
+	assert.False(solver.Call(s.WithAssumptions([]f.Literal{sel, b.Negate(fac)})).Sat())
+	assert.True(solver.Call(s.WithAssumptions([]f.Literal{sel.Negate(fac), b.Negate(fac)})).Sat())
+
+	solver = s.NewSolver(fac)
+	cnf, err = e.EncodeReified(fac, pbc, sel)
+	assert.Nil(err)
+	solver.Add(cnf...)
+	solver.Add(a.Negate(fac).AsFormula())
+	assert.True(solver.Call(s.WithAssumptions([]f.Literal{sel.Negate(fac), b, c})).Sat())
+	assert.False(solver.Call(s.WithAssumptions([]f.Literal{sel, b, c})).Sat())
+
+	_, err = e.EncodeReified(fac, fac.And(a.AsFormula(), b.AsFormula()), sel)
+	assert.NotNil(err)
+}
+
+func TestReifiedEncodingRandom(t *testing.T) {
+	fac := f.NewFactory()
+	sel := fac.Lit("@sel", true)
+	for i := range 40 {
+		config := randomizer.DefaultConfig()
+		config.NumVars = 5
+		config.Seed = int64(i)
+		config.MaximumOpsPBC = 5
+		config.MaximumOpsCC = 5
+		r := randomizer.New(fac, config)
+		constraints := []f.Formula{r.PBC(), r.CC()}
+		for _, constraint := range constraints {
+			vars := f.Variables(fac, constraint).Content()
+			for _, encConfig := range pbcConfigs {
+				half, err := e.EncodeHalfReified(fac, constraint, sel, &encConfig)
+				assert.Nil(t, err)
+				full, err := e.EncodeReified(fac, constraint, sel, &encConfig)
+				assert.Nil(t, err)
+				halfSolver := s.NewSolver(fac)
+				halfSolver.Add(half...)
+				fullSolver := s.NewSolver(fac)
+				fullSolver.Add(full...)
+				for _, lits := range allAssignments(fac, vars) {
+					ass, _ := assignment.New(fac, lits...)
+					expected := assignment.Evaluate(fac, constraint, ass)
+					on := append([]f.Literal{sel}, lits...)
+					off := append([]f.Literal{sel.Negate(fac)}, lits...)
+					assert.Equal(t, expected, halfSolver.Call(s.WithAssumptions(on)).Sat())
+					assert.True(t, halfSolver.Call(s.WithAssumptions(off)).Sat())
+					assert.Equal(t, expected, fullSolver.Call(s.WithAssumptions(on)).Sat())
+					assert.Equal(t, !expected, fullSolver.Call(s.WithAssumptions(off)).Sat())
+				}
+			}
+		}
+	}
+}
+
+func allAssignments(fac f.Factory, vars []f.Variable) [][]f.Literal {
+	assignments := [][]f.Literal{{}}
+	for _, v := range vars {
+		next := make([][]f.Literal, 0, 2*len(assignments))
+		for _, lits := range assignments {
+			next = append(next, append(append([]f.Literal{}, lits...), v.AsLiteral()))
+			next = append(next, append(append([]f.Literal{}, lits...), v.Negate(fac)))
+		}
+		assignments = next
+	}
+	return assignments
+}
@@ -16,6 +16,9 @@ import (
 var pbcConfigs = []e.Config{
 	{PBCEncoder: e.PBCSWC},
 	{PBCEncoder: e.PBCAdderNetworks},
+	{PBCEncoder: e.PBCGTE},
+	{PBCEncoder: e.PBCBDD},
+	{PBCEncoder: e.PBCLadder},
 	{PBCEncoder: e.PBCBinaryMerge, BinaryMergeUseGAC: true, BinaryMergeNoSupportForSingleBit: true, BinaryMergeUseWatchDog: true},
 	{PBCEncoder: e.PBCBinaryMerge, BinaryMergeUseGAC: true, BinaryMergeNoSupportForSingleBit: true, BinaryMergeUseWatchDog: false},
 	{PBCEncoder: e.PBCBinaryMerge, BinaryMergeUseGAC: true, BinaryMergeNoSupportForSingleBit: false, BinaryMergeUseWatchDog: true},
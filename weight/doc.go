@@ -0,0 +1,25 @@
+// Package weight provides the arithmetic for weighted model counting in
+// LogicNG.
+//
+// The weighted model count of a formula is the sum of the weights of its
+// models, where the weight of a model is the product of the weights of its
+// literals.  Weighted model counts can be computed on compiled knowledge
+// representations like BDDs and d-DNNFs, see the functions
+// WeightedModelCount and Marginals in the packages bdd and dnnf.
+//
+// The computations are parametrized by a Semiring which defines the number
+// representation:
+//   - Rational computes exact results with big.Rat numbers,
+//   - Float computes with float64 numbers,
+//   - Log computes with the natural logarithms of float64 numbers and
+//     therefore avoids underflows for small probabilities over many
+//     variables,
+//   - MinCost computes the minimal cost of a model, where the cost of a model
+//     is the sum of the costs of its literals.
+//
+// A typical use case are probabilities: if the weights of the two literals of
+// each variable add up to one, the weighted model count is the probability
+// that a random assignment is a model and the marginal of a literal is its
+// probability among the models (e.g. the take rate of an option over all
+// valid configurations).
+package weight
@@ -0,0 +1,183 @@
+package weight
+
+import (
+	"math"
+	"math/big"
+
+	f "github.com/booleworks/logicng-go/formula"
+)
+
+// A Semiring defines the arithmetic of a weighted model count.  The
+// operations must not modify their arguments.  Div is only called with a
+// divisor which is not zero.
+type Semiring[T any] interface {
+	Zero() T         // neutral element of the addition
+	One() T          // neutral element of the multiplication
+	Add(a, b T) T    // returns a + b
+	Mul(a, b T) T    // returns a * b
+	Div(a, b T) T    // returns a / b
+	IsZero(a T) bool // reports whether a is the neutral element of the addition
+}
+
+var (
+	// Rational computes exact weights with big.Rat numbers.
+	Rational Semiring[*big.Rat] = rational{}
+	// Float computes weights with float64 numbers.
+	Float Semiring[float64] = float{}
+	// Log computes weights in log-space: each number is represented by its
+	// natural logarithm.
+	Log Semiring[float64] = logarithm{}
+	// MinCost computes minimal costs with int numbers: the addition is the
+	// minimum and the multiplication is the sum of the costs.  The zero, i.e.
+	// an infinite cost, is represented by math.MaxInt.  The weighted model
+	// count in this semiring is the minimal cost of a model.
+	MinCost Semiring[int] = minCost{}
+)
+
+type rational struct{}
+
+func (rational) Zero() *big.Rat             { return new(big.Rat) }
+func (rational) One() *big.Rat              { return big.NewRat(1, 1) }
+func (rational) Add(a, b *big.Rat) *big.Rat { return new(big.Rat).Add(a, b) }
+func (rational) Mul(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }
+func (rational) Div(a, b *big.Rat) *big.Rat { return new(big.Rat).Quo(a, b) }
+func (rational) IsZero(a *big.Rat) bool     { return a.Sign() == 0 }
+
+type float struct{}
+
+func (float) Zero() float64            { return 0 }
+func (float) One() float64             { return 1 }
+func (float) Add(a, b float64) float64 { return a + b }
+func (float) Mul(a, b float64) float64 { return a * b }
+func (float) Div(a, b float64) float64 { return a / b }
+func (float) IsZero(a float64) bool    { return a == 0 }
+
+type logarithm struct{}
+
+func (logarithm) Zero() float64 { return math.Inf(-1) }
+func (logarithm) One() float64  { return 0 }
+
+// Add computes log(exp(a) + exp(b)) without leaving the log-space.
+func (logarithm) Add(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
+
+func (logarithm) Mul(a, b float64) float64 { return a + b }
+func (logarithm) Div(a, b float64) float64 { return a - b }
+func (logarithm) IsZero(a float64) bool    { return math.IsInf(a, -1) }
+
+type minCost struct{}
+
+func (minCost) Zero() int        { return math.MaxInt }
+func (minCost) One() int         { return 0 }
+func (minCost) Add(a, b int) int { return min(a, b) }
+
+// Mul adds the costs, an infinite cost is absorbing.
+func (minCost) Mul(a, b int) int {
+	if a == math.MaxInt || b == math.MaxInt {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func (minCost) Div(a, b int) int  { return a - b }
+func (minCost) IsZero(a int) bool { return a == math.MaxInt }
+
+// Of returns the weight of the given literal in the weight map.  Literals
+// without a weight have weight one.
+func Of[T any](s Semiring[T], weights map[f.Literal]T, literal f.Literal) T {
+	if w, ok := weights[literal]; ok {
+		return w
+	}
+	return s.One()
+}
+
+// ToLog converts the given weights to log-space.
+func ToLog(weights map[f.Literal]float64) map[f.Literal]float64 {
+	result := make(map[f.Literal]float64, len(weights))
+	for literal, w := range weights {
+		result[literal] = math.Log(w)
+	}
+	return result
+}
+
+// FromLog converts the given weights from log-space.
+func FromLog(weights map[f.Literal]float64) map[f.Literal]float64 {
+	result := make(map[f.Literal]float64, len(weights))
+	for literal, w := range weights {
+		result[literal] = math.Exp(w)
+	}
+	return result
+}
+
+// FreeVariables adds the weights of the given free variables to the given
+// literal weights of a weighted model count.  A free variable x is
+// unconstrained, so its factor in the count is w(x) + w(~x).  The product of
+// all these factors multiplied with base is returned.  If literalWeights is
+// not nil, the weight of the models in which a literal of a free variable is
+// true, i.e. base times the factors of all other free variables times the
+// weight of the literal, is added to the literal's entry.
+func FreeVariables[T any](
+	fac f.Factory,
+	s Semiring[T],
+	weights map[f.Literal]T,
+	variables []f.Variable,
+	base T,
+	literalWeights map[f.Literal]T,
+) T {
+	sums := make([]T, len(variables))
+	for i, variable := range variables {
+		sums[i] = s.Add(Of(s, weights, variable.AsLiteral()), Of(s, weights, variable.Negate(fac)))
+	}
+	if literalWeights != nil && len(variables) > 0 {
+		// suffix[i] is the product of the sums i, ..., n-1
+		suffix := make([]T, len(variables)+1)
+		suffix[len(variables)] = s.One()
+		for i := len(variables) - 1; i >= 0; i-- {
+			suffix[i] = s.Mul(sums[i], suffix[i+1])
+		}
+		prefix := base
+		for i, variable := range variables {
+			others := s.Mul(prefix, suffix[i+1])
+			for _, literal := range []f.Literal{variable.AsLiteral(), variable.Negate(fac)} {
+				AddTo(s, literalWeights, literal, s.Mul(others, Of(s, weights, literal)))
+			}
+			prefix = s.Mul(prefix, sums[i])
+		}
+		return prefix
+	}
+	result := base
+	for _, sum := range sums {
+		result = s.Mul(result, sum)
+	}
+	return result
+}
+
+// AddTo adds the given value to the entry of the key in the given map.  A
+// missing entry is treated as zero.
+func AddTo[K comparable, T any](s Semiring[T], values map[K]T, key K, value T) {
+	if current, ok := values[key]; ok {
+		values[key] = s.Add(current, value)
+	} else {
+		values[key] = value
+	}
+}
+
+// Normalize divides all literal weights by the given total.  If the total is
+// zero, all literal weights are set to zero.
+func Normalize[T any](s Semiring[T], literalWeights map[f.Literal]T, total T) map[f.Literal]T {
+	for literal, w := range literalWeights {
+		if s.IsZero(total) {
+			literalWeights[literal] = s.Zero()
+		} else {
+			literalWeights[literal] = s.Div(w, total)
+		}
+	}
+	return literalWeights
+}
@@ -0,0 +1,63 @@
+package weight
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	f "github.com/booleworks/logicng-go/formula"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemirings(t *testing.T) {
+	assert := assert.New(t)
+	a, b := big.NewRat(1, 3), big.NewRat(1, 6)
+	assert.Equal(big.NewRat(1, 2), Rational.Add(a, b))
+	assert.Equal(big.NewRat(1, 18), Rational.Mul(a, b))
+	assert.Equal(big.NewRat(2, 1), Rational.Div(a, b))
+	assert.Equal(big.NewRat(1, 3), a)
+	assert.True(Rational.IsZero(Rational.Zero()))
+
+	assert.Equal(0.5, Float.Add(0.25, 0.25))
+	assert.Equal(0.0625, Float.Mul(0.25, 0.25))
+	assert.True(Float.IsZero(Float.Zero()))
+
+	assert.InDelta(math.Log(0.5), Log.Add(math.Log(0.25), math.Log(0.25)), 1e-12)
+	assert.InDelta(math.Log(0.0625), Log.Mul(math.Log(0.25), math.Log(0.25)), 1e-12)
+	assert.InDelta(math.Log(2), Log.Div(math.Log(0.5), math.Log(0.25)), 1e-12)
+	assert.Equal(math.Log(0.25), Log.Add(Log.Zero(), math.Log(0.25)))
+	assert.True(Log.IsZero(Log.Add(Log.Zero(), Log.Zero())))
+	assert.Equal(0.0, Log.One())
+	assert.InDelta(-1000+math.Log(2), Log.Add(-1000, -1000), 1e-9)
+
+	assert.Equal(-2, MinCost.Add(3, -2))
+	assert.Equal(1, MinCost.Mul(3, -2))
+	assert.Equal(5, MinCost.Div(3, -2))
+	assert.Equal(3, MinCost.Add(MinCost.Zero(), 3))
+	assert.True(MinCost.IsZero(MinCost.Mul(MinCost.Zero(), -5)))
+	assert.Equal(7, MinCost.Mul(MinCost.One(), 7))
+}
+
+func TestFreeVariables(t *testing.T) {
+	assert := assert.New(t)
+	fac := f.NewFactory()
+	a, b := fac.Var("a"), fac.Var("b")
+	weights := map[f.Literal]*big.Rat{a.AsLiteral(): big.NewRat(1, 4), a.Negate(fac): big.NewRat(1, 2)}
+	literalWeights := make(map[f.Literal]*big.Rat)
+	result := FreeVariables(fac, Rational, weights, []f.Variable{a, b}, big.NewRat(2, 1), literalWeights)
+	assert.Equal(big.NewRat(3, 1), result)
+	assert.Equal(big.NewRat(1, 1), literalWeights[a.AsLiteral()])
+	assert.Equal(big.NewRat(2, 1), literalWeights[a.Negate(fac)])
+	assert.Equal(big.NewRat(3, 2), literalWeights[b.AsLiteral()])
+	assert.Equal(big.NewRat(3, 2), literalWeights[b.Negate(fac)])
+
+	normalized := Normalize(Rational, literalWeights, result)
+	assert.Equal(big.NewRat(1, 3), normalized[a.AsLiteral()])
+	assert.Equal(big.NewRat(1, 2), normalized[b.AsLiteral()])
+	zero := Normalize(Float, map[f.Literal]float64{a.AsLiteral(): 1}, 0)
+	assert.Equal(0.0, zero[a.AsLiteral()])
+
+	logWeights := ToLog(map[f.Literal]float64{a.AsLiteral(): 0.5})
+	assert.InDelta(math.Log(0.5), logWeights[a.AsLiteral()], 1e-12)
+	assert.InDelta(0.5, FromLog(logWeights)[a.AsLiteral()], 1e-12)
+}